@@ -18,9 +18,11 @@ package main
 import (
 	"fmt"
 	"os"
+	"path/filepath"
 	"strings"
 
 	akamai "github.com/akamai/cli-common-golang"
+	"github.com/fatih/color"
 	"github.com/kardianos/osext"
 	"github.com/urfave/cli"
 )
@@ -31,11 +33,30 @@ const (
 
 func main() {
 	os.Setenv("AKAMAI_CLI", "1")
+	detectGlobalFlags(os.Args[1:])
+	configureColor()
 
 	getAkamaiCliCachePath()
 	exportConfigEnv()
 	createApp()
 
+	if os.Getenv(backgroundCheckEnv) != "" {
+		runBackgroundCheck()
+		return
+	}
+
+	args, err := expandAlias(os.Args[1:], getConfigSectionValues(aliasSection))
+	if err != nil {
+		fmt.Fprintln(akamai.App.ErrWriter, color.RedString(err.Error()))
+		os.Exit(1)
+	}
+	var packages []string
+	for _, dir := range getAllPackageDirs() {
+		packages = append(packages, filepath.Base(getPackageRoot(dir)))
+	}
+	args = expandNamespace(args, packages)
+	os.Args = append(os.Args[:1], args...)
+
 	firstRun()
 	if latestVersion := checkForUpgrade(false); latestVersion != "" {
 		if upgradeCli(latestVersion) {
@@ -47,6 +68,8 @@ func main() {
 	}
 
 	checkPing()
+	startBackgroundCheck()
+	startTelemetry()
 	akamai.App.Run(os.Args)
 }
 
@@ -66,6 +89,50 @@ func createApp() {
 			Name:  "proxy",
 			Usage: "Set a proxy to use",
 		},
+		cli.StringFlag{
+			Name:  "registry",
+			Usage: "Set the URL of the package list to search and install from, or a comma-separated list in priority order",
+		},
+		cli.StringFlag{
+			Name:  "format",
+			Usage: "Output `FORMAT` for list, install, update, and uninstall: table, json, or yaml",
+			Value: formatTable,
+		},
+		cli.BoolFlag{
+			Name:  "verbose",
+			Usage: "Log network requests, git operations, and subprocesses (info level)",
+		},
+		cli.BoolFlag{
+			Name:  "debug",
+			Usage: "Log everything, including details of each request (debug level)",
+		},
+		cli.BoolFlag{
+			Name:   "no-retry",
+			Usage:  "Do not retry network requests and git operations that fail",
+			EnvVar: noRetryEnv,
+		},
+		cli.StringFlag{
+			Name:  "profile",
+			Usage: "Use the credentials of profile `NAME` for installed commands, see \"akamai profile\"",
+		},
+		cli.StringFlag{
+			Name:  "package",
+			Usage: "Run the command of package `NAME`, when several packages provide it",
+		},
+		cli.BoolFlag{
+			Name:   "quiet, q",
+			Usage:  "Only print results, warnings, and errors, without progress or informational messages",
+			EnvVar: quietEnv,
+		},
+		cli.BoolFlag{
+			Name:  "no-color",
+			Usage: "Do not color the output (also set by NO_COLOR)",
+		},
+		cli.BoolFlag{
+			Name:   "offline",
+			Usage:  "Disable network access, using only the cached package list and installed packages",
+			EnvVar: offlineEnv,
+		},
 	}
 
 	akamai.App.Action = func(c *cli.Context) {
@@ -73,8 +140,12 @@ func createApp() {
 	}
 
 	akamai.App.Before = func(c *cli.Context) error {
-		if c.IsSet("proxy") {
-			proxy := c.String("proxy")
+		proxy := c.String("proxy")
+		if !c.IsSet("proxy") && os.Getenv("HTTP_PROXY") == "" && os.Getenv("http_proxy") == "" {
+			// The proxy saved by "akamai setup", unless the environment has its own
+			proxy = getConfigValue("cli", "proxy")
+		}
+		if proxy != "" {
 			os.Setenv("HTTP_PROXY", proxy)
 			os.Setenv("http_proxy", proxy)
 			if strings.HasPrefix(proxy, "https") {
@@ -83,6 +154,50 @@ func createApp() {
 			}
 		}
 
+		if c.IsSet("registry") {
+			os.Setenv(registryEnv, c.String("registry"))
+		}
+
+		if c.Bool("offline") {
+			os.Setenv(offlineEnv, "1")
+		}
+
+		if c.IsSet("format") {
+			if err := validateOutputFormat(c.String("format")); err != nil {
+				return cli.NewExitError(color.RedString(err.Error()), 1)
+			}
+			os.Setenv(formatEnv, c.String("format"))
+		}
+
+		if c.IsSet("profile") {
+			os.Setenv(profileEnv, c.String("profile"))
+		}
+
+		if c.Bool("quiet") {
+			os.Setenv(quietEnv, "1")
+		}
+
+		if c.Bool("no-color") {
+			os.Setenv(noColorEnv, "1")
+		}
+		configureColor()
+
+		if c.Bool("no-retry") {
+			os.Setenv(noRetryEnv, "1")
+		}
+
+		if c.Bool("debug") {
+			os.Setenv(logEnv, "debug")
+		} else if c.Bool("verbose") {
+			os.Setenv(logEnv, "info")
+		}
+		configureLogging()
+
+		// A broken network setting shouldn't stop it being fixed with "akamai config"
+		if err := useHTTPClientForGit(); err != nil {
+			fmt.Fprintln(akamai.App.ErrWriter, color.YellowString("Warning: %s", err.Error()))
+		}
+
 		return nil
 	}
 }
@@ -93,35 +208,22 @@ func defaultAction(c *cli.Context) {
 		cmd = self()
 	}
 
-	zshScript := `set -k
+	zshComments := `set -k
 # To enable zsh auto-completion, run: eval "$(` + cmd + ` --zsh)"
-# We recommend adding this to your .zshrc file
-autoload -U compinit && compinit
-autoload -U bashcompinit && bashcompinit`
+# We recommend adding this to your .zshrc file`
 
 	bashComments := `# To enable bash auto-completion, run: eval "$(` + cmd + ` --bash)"
 # We recommend adding this to your .bashrc or .bash_profile file`
 
-	bashScript := `_akamai_cli_bash_autocomplete() {
-    local cur opts base
-    COMPREPLY=()
-    cur="${COMP_WORDS[COMP_CWORD]}"
-    opts=$( ${COMP_WORDS[@]:0:$COMP_CWORD} --generate-auto-complete )
-    COMPREPLY=( $(compgen -W "${opts}" -- ${cur}) )
-    return 0
-}
-
-complete -F _akamai_cli_bash_autocomplete ` + self()
-
 	if c.Bool("bash") {
 		fmt.Fprintln(akamai.App.Writer, bashComments)
-		fmt.Fprintln(akamai.App.Writer, bashScript)
+		fmt.Fprintln(akamai.App.Writer, getBashCompletionScript())
 		return
 	}
 
 	if c.Bool("zsh") {
-		fmt.Fprintln(akamai.App.Writer, zshScript)
-		fmt.Fprintln(akamai.App.Writer, bashScript)
+		fmt.Fprintln(akamai.App.Writer, zshComments)
+		fmt.Fprintln(akamai.App.Writer, getZshCompletionScript())
 		return
 	}
 
@@ -0,0 +1,118 @@
+/*
+ Copyright 2018. Akamai Technologies, Inc
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"os/exec"
+	"runtime"
+	"strings"
+	"text/template"
+)
+
+// isRosettaTranslated reports whether the CLI is an Intel build running on
+// Apple Silicon under Rosetta 2
+func isRosettaTranslated() bool {
+	if runtime.GOOS != "darwin" || runtime.GOARCH != "amd64" {
+		return false
+	}
+
+	output, err := exec.Command("sysctl", "-n", "sysctl.proc_translated").Output()
+	return err == nil && strings.TrimSpace(string(output)) == "1"
+}
+
+// getHostArch returns the architecture of the machine, which is not that of
+// the CLI when an Intel build runs under Rosetta 2
+func getHostArch() string {
+	if isRosettaTranslated() {
+		return "arm64"
+	}
+
+	return runtime.GOARCH
+}
+
+// getBinaryArchs returns the architectures to download binaries for, most
+// preferred first. Apple Silicon can also run Intel binaries, under Rosetta 2.
+func getBinaryArchs(goos string, arch string) []string {
+	if goos == "darwin" && arch == "arm64" {
+		return []string{"arm64", "amd64"}
+	}
+
+	return []string{arch}
+}
+
+// getBinaryURLs renders the URL template of a binary for this machine, once
+// for each architecture it can run
+func getBinaryURLs(cmd Command) ([]string, error) {
+	cmd.OS = runtime.GOOS
+	if runtime.GOOS == "darwin" {
+		cmd.OS = "mac"
+	}
+
+	if runtime.GOOS == "windows" {
+		cmd.BinSuffix = ".exe"
+	}
+
+	t, err := template.New("url").Parse(cmd.Bin)
+	if err != nil {
+		return nil, err
+	}
+
+	var urls []string
+	for _, arch := range getBinaryArchs(runtime.GOOS, getHostArch()) {
+		cmd.Arch = arch
+
+		buf := &bytes.Buffer{}
+		if err := t.Execute(buf, cmd); err != nil {
+			return nil, err
+		}
+		urls = append(urls, buf.String())
+	}
+
+	return urls, nil
+}
+
+// getBinary requests the first of urls that exists, moving on to the next one
+// only when a binary is not found. The caller closes the response body.
+func getBinary(client *http.Client, urls []string) (*http.Response, string, error) {
+	var err error
+	for i, url := range urls {
+		var res *http.Response
+		res, err = client.Get(url)
+		if err != nil {
+			return nil, url, err
+		}
+
+		if res.StatusCode == http.StatusOK {
+			if i > 0 {
+				logInfo("using binary for another architecture", "url", url, "preferred", urls[0])
+			}
+			return res, url, nil
+		}
+		res.Body.Close()
+
+		err = fmt.Errorf("%s: %s", url, res.Status)
+		if res.StatusCode != http.StatusNotFound {
+			return nil, url, err
+		}
+		logDebug("binary not found", "url", url)
+	}
+
+	return nil, "", err
+}
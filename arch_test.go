@@ -0,0 +1,42 @@
+/*
+ Copyright 2018. Akamai Technologies, Inc
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestGetBinaryArchs(t *testing.T) {
+	archTests := []struct {
+		goos  string
+		arch  string
+		archs []string
+	}{
+		{"darwin", "arm64", []string{"arm64", "amd64"}},
+		{"darwin", "amd64", []string{"amd64"}},
+		{"linux", "arm64", []string{"arm64"}},
+		{"linux", "amd64", []string{"amd64"}},
+		{"windows", "386", []string{"386"}},
+	}
+
+	for _, tt := range archTests {
+		if archs := getBinaryArchs(tt.goos, tt.arch); !reflect.DeepEqual(archs, tt.archs) {
+			t.Errorf("getBinaryArchs(%s, %s) => %q, wanted: %q", tt.goos, tt.arch, archs, tt.archs)
+		}
+	}
+}
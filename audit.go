@@ -0,0 +1,211 @@
+/*
+ Copyright 2018. Akamai Technologies, Inc
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// auditExecEvent is logged for each run of an installed package command
+const auditExecEvent = "command.exec"
+
+// auditEntry is a single line of the append-only audit log
+type auditEntry struct {
+	Time    string            `json:"time"`
+	Event   string            `json:"event"`
+	Details map[string]string `json:"details,omitempty"`
+}
+
+func getAuditLogPath() (string, error) {
+	cliPath, err := getAkamaiCliPath()
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(cliPath, "audit.log"), nil
+}
+
+// writeAuditLog appends an event to the audit log. Failing to write the log
+// never prevents the operation being audited from completing.
+func writeAuditLog(event string, details map[string]string) {
+	path, err := getAuditLogPath()
+	if err != nil {
+		return
+	}
+
+	entry, err := json.Marshal(auditEntry{
+		Time:    time.Now().Format(time.RFC3339),
+		Event:   event,
+		Details: details,
+	})
+	if err != nil {
+		return
+	}
+
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return
+	}
+	defer f.Close()
+
+	f.Write(append(entry, '\n'))
+}
+
+// readAuditLog returns the entries of the audit log, oldest first
+func readAuditLog() ([]auditEntry, error) {
+	path, err := getAuditLogPath()
+	if err != nil {
+		return nil, err
+	}
+
+	f, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var entries []auditEntry
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		var entry auditEntry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err == nil {
+			entries = append(entries, entry)
+		}
+	}
+
+	return entries, scanner.Err()
+}
+
+// writeExecAuditLog records a run of an installed package command, with any
+// secrets in its arguments redacted
+func writeExecAuditLog(command string, args []string, exitCode int, duration time.Duration) {
+	details := map[string]string{
+		"command":  command,
+		"args":     quoteArgs(redactArgs(args)),
+		"exitCode": strconv.Itoa(exitCode),
+		"duration": duration.Round(time.Millisecond).String(),
+	}
+	if user := getAuditUser(); user != "" {
+		details["user"] = user
+	}
+	if dir, err := os.Getwd(); err == nil {
+		details["dir"] = dir
+	}
+
+	writeAuditLog(auditExecEvent, details)
+}
+
+func getAuditUser() string {
+	for _, env := range []string{"USER", "USERNAME", "LOGNAME"} {
+		if user := os.Getenv(env); user != "" {
+			return user
+		}
+	}
+
+	return ""
+}
+
+const redacted = "xxxxx"
+
+var (
+	// secretNameParts are the words that mark a flag or variable as holding a secret
+	secretNameParts = map[string]bool{
+		"password": true, "passwd": true, "pass": true, "pw": true, "secret": true,
+		"token": true, "key": true, "apikey": true, "auth": true, "authorization": true,
+		"credential": true, "credentials": true, "cookie": true, "session": true,
+	}
+	secretHeader  = regexp.MustCompile(`(?i)^\s*(authorization|proxy-authorization|cookie|x-api-key)\s*:`)
+	envAssignment = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*=`)
+)
+
+// isSecretName reports whether a flag or variable name, e.g. --client-secret or API_TOKEN, looks like it holds a secret
+func isSecretName(name string) bool {
+	for _, part := range strings.FieldsFunc(strings.ToLower(name), func(r rune) bool {
+		return r == '-' || r == '_' || r == '.'
+	}) {
+		if secretNameParts[part] {
+			return true
+		}
+	}
+
+	return false
+}
+
+// redactArgs replaces the values of secret flags (--password=x, --token x),
+// secret variables (API_KEY=x), credentials in URLs, and secret headers, also
+// when they are the value of a flag (--header=Cookie: x)
+func redactArgs(args []string) []string {
+	result := make([]string, len(args))
+	redactNext := false
+
+	for i, arg := range args {
+		switch {
+		case redactNext && !strings.HasPrefix(arg, "-"):
+			result[i] = redacted
+			redactNext = false
+		case strings.HasPrefix(arg, "-") && arg != "-" && arg != "--":
+			redactNext = false
+			name := strings.TrimLeft(arg, "-")
+			if j := strings.Index(name, "="); j != -1 {
+				prefix, value := arg[:len(arg)-len(name)+j+1], name[j+1:]
+				switch {
+				case isSecretName(name[:j]):
+					result[i] = prefix + redacted
+				case secretHeader.MatchString(value):
+					result[i] = prefix + value[:strings.Index(value, ":")+1] + " " + redacted
+				default:
+					result[i] = prefix + redactURL(value)
+				}
+				continue
+			}
+			result[i] = arg
+			redactNext = isSecretName(name)
+		case envAssignment.MatchString(arg) && isSecretName(arg[:strings.Index(arg, "=")]):
+			result[i] = arg[:strings.Index(arg, "=")+1] + redacted
+		case secretHeader.MatchString(arg):
+			result[i] = arg[:strings.Index(arg, ":")+1] + " " + redacted
+		default:
+			result[i] = redactURL(arg)
+		}
+	}
+
+	return result
+}
+
+// quoteArgs joins args with spaces, quoting those that would otherwise be ambiguous
+func quoteArgs(args []string) string {
+	quoted := make([]string, len(args))
+	for i, arg := range args {
+		if arg == "" || strings.ContainsAny(arg, " \t\n\"'\\$`|&;<>()*?") {
+			quoted[i] = strconv.Quote(arg)
+			continue
+		}
+		quoted[i] = arg
+	}
+
+	return strings.Join(quoted, " ")
+}
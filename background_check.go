@@ -0,0 +1,283 @@
+/*
+ Copyright 2018. Akamai Technologies, Inc
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/kardianos/osext"
+	"github.com/mattn/go-isatty"
+)
+
+// backgroundCheckEnv is set for the background process that checks for updates.
+// It differs from AKAMAI_CLI_BACKGROUND_CHECK, which cli.background-check is exported as.
+const backgroundCheckEnv = "AKAMAI_CLI_RUN_BACKGROUND_CHECK"
+
+const defaultBackgroundCheckInterval = 24 * time.Hour
+
+// updateState is what the last background check found, kept in the cache
+type updateState struct {
+	Started    time.Time            `json:"started"`
+	Checked    time.Time            `json:"checked"`
+	CliVersion string               `json:"cliVersion,omitempty"`
+	Packages   []updateStatePackage `json:"packages,omitempty"`
+	// Notified is when the updates were last printed, see printUpdateFooter
+	Notified time.Time `json:"notified"`
+}
+
+type updateStatePackage struct {
+	Name       string `json:"name"`
+	OldVersion string `json:"oldVersion"`
+	NewVersion string `json:"newVersion"`
+}
+
+// updateStateLock serializes changes to the update state within the process
+var updateStateLock sync.Mutex
+
+func isBackgroundCheckEnabled() bool {
+	switch strings.ToLower(getConfigValue("cli", "background-check")) {
+	case "on", "true", "yes", "1":
+		return true
+	}
+
+	return false
+}
+
+// getBackgroundCheckInterval returns cli.background-check-interval, or the default
+func getBackgroundCheckInterval() time.Duration {
+	if interval, err := time.ParseDuration(getConfigValue("cli", "background-check-interval")); err == nil && interval > 0 {
+		return interval
+	}
+
+	return defaultBackgroundCheckInterval
+}
+
+// startBackgroundCheck starts a check for updates in a detached process, if
+// the interval has passed since the last one started. What it finds is
+// printed after later commands, see printUpdateFooter.
+func startBackgroundCheck() {
+	if !isBackgroundCheckEnabled() {
+		return
+	}
+
+	updateStateLock.Lock()
+	defer updateStateLock.Unlock()
+
+	state := readUpdateState()
+	if isOffline() || !shouldStartBackgroundCheck(state, time.Now(), getBackgroundCheckInterval()) {
+		return
+	}
+
+	selfPath, err := osext.Executable()
+	if err != nil {
+		return
+	}
+
+	state.Started = time.Now()
+	if err := writeUpdateState(state); err != nil {
+		logWarn("unable to save update state", "error", err)
+		return
+	}
+
+	cmd := newDetachedCommand(selfPath)
+	cmd.Env = append(os.Environ(), backgroundCheckEnv+"=1")
+	if err := cmd.Start(); err != nil {
+		logWarn("unable to start background update check", "error", err)
+		return
+	}
+	logDebug("started background update check", "pid", cmd.Process.Pid)
+	cmd.Process.Release()
+}
+
+// showUpdateNotice reports whether to print available updates after running
+// a command: only on a terminal, and not for commands that update, or whose
+// output is meant for other programs
+func showUpdateNotice(args []string) bool {
+	if isQuiet() || getOutputFormat() != formatTable || !isatty.IsTerminal(os.Stderr.Fd()) {
+		return false
+	}
+
+	switch getCommandArg(args) {
+	case "update", "upgrade", "completion", "exec":
+		return false
+	}
+
+	for _, arg := range args {
+		if arg == "--generate-bash-completion" {
+			return false
+		}
+	}
+
+	return true
+}
+
+func shouldStartBackgroundCheck(state updateState, now time.Time, interval time.Duration) bool {
+	return now.Sub(state.Checked) >= interval && now.Sub(state.Started) >= interval
+}
+
+// formatUpdateNotice summarizes the updates in state for packages that are
+// still installed, and for the CLI if it is newer than version
+func formatUpdateNotice(state updateState, version string, installed func(string) bool) string {
+	packages := 0
+	for _, pkg := range state.Packages {
+		if installed(pkg.Name) {
+			packages++
+		}
+	}
+	cliUpdate := state.CliVersion != "" && compareVersions(state.CliVersion, version) > 0
+
+	updates := fmt.Sprintf("%d updates available", packages)
+	if packages == 1 {
+		updates = "1 update available"
+	}
+
+	switch {
+	case packages > 0 && cliUpdate:
+		return fmt.Sprintf("%s, run \"%s update\", and Akamai CLI %s is available, run \"%s upgrade\"", updates, self(), state.CliVersion, self())
+	case packages > 0:
+		return fmt.Sprintf("%s, run \"%s update\"", updates, self())
+	case cliUpdate:
+		return fmt.Sprintf("Akamai CLI %s is available, run \"%s upgrade\"", state.CliVersion, self())
+	}
+
+	return ""
+}
+
+func isPackageInstalled(name string) bool {
+	srcPath, err := getAkamaiCliSrcPath()
+	if err != nil {
+		return false
+	}
+
+	_, err = os.Stat(filepath.Join(srcPath, name))
+	return err == nil
+}
+
+// runBackgroundCheck checks for CLI and package updates, and saves what it
+// finds for the next commands to show. It is run in a detached process.
+func runBackgroundCheck() {
+	if isOffline() {
+		return
+	}
+
+	found := updateState{Checked: time.Now()}
+	if latest := getLatestReleaseVersion(); compareVersions(latest, VERSION) > 0 {
+		found.CliVersion = latest
+	}
+
+	// Checking fetches into the package repositories, which must not race an
+	// update. The next check catches up if a package operation is running.
+	unlock, err := lockOperation(backgroundCheckOperation, false)
+	if err != nil {
+		logDebug("skipping package update check", "error", err)
+		return
+	}
+	defer unlock()
+
+	for _, dir := range getPackageDirs() {
+		update, err := checkPackageUpdate(dir)
+		if err != nil {
+			logWarn("unable to check for package update", "package", filepath.Base(getPackageRoot(dir)), "error", err)
+			continue
+		}
+
+		if update.available() {
+			found.Packages = append(found.Packages, updateStatePackage{Name: update.name, OldVersion: update.oldVersion, NewVersion: update.newVersion})
+		}
+	}
+
+	updateStateLock.Lock()
+	defer updateStateLock.Unlock()
+
+	previous := readUpdateState()
+	found.Started = previous.Started
+	found.Notified = previous.Notified
+	if err := writeUpdateState(found); err != nil {
+		logWarn("unable to save update state", "error", err)
+	}
+}
+
+// clearPackageUpdateState forgets the update found for a package, once it is updated
+func clearPackageUpdateState(name string) {
+	updateStateLock.Lock()
+	defer updateStateLock.Unlock()
+
+	state := readUpdateState()
+	packages := state.Packages[:0]
+	for _, pkg := range state.Packages {
+		if pkg.Name != name {
+			packages = append(packages, pkg)
+		}
+	}
+
+	if len(packages) != len(state.Packages) {
+		state.Packages = packages
+		writeUpdateState(state)
+	}
+}
+
+func getUpdateStatePath() (string, error) {
+	cachePath, err := getAkamaiCliCachePath()
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(cachePath, "update-state.json"), nil
+}
+
+func readUpdateState() updateState {
+	var state updateState
+
+	path, err := getUpdateStatePath()
+	if err != nil {
+		return state
+	}
+
+	if data, err := ioutil.ReadFile(path); err == nil {
+		json.Unmarshal(data, &state)
+	}
+
+	return state
+}
+
+// writeUpdateState saves the update state, replacing the file so that it is
+// never read half written
+func writeUpdateState(state updateState) error {
+	path, err := getUpdateStatePath()
+	if err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	tmp := fmt.Sprintf("%s.%d", path, os.Getpid())
+	if err := ioutil.WriteFile(tmp, data, 0664); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp, path)
+}
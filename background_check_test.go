@@ -0,0 +1,87 @@
+/*
+ Copyright 2018. Akamai Technologies, Inc
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestShouldStartBackgroundCheck(t *testing.T) {
+	now := time.Date(2018, 6, 1, 12, 0, 0, 0, time.UTC)
+	startTests := []struct {
+		started time.Time
+		checked time.Time
+		start   bool
+	}{
+		{time.Time{}, time.Time{}, true},
+		{now.Add(-25 * time.Hour), now.Add(-25 * time.Hour), true},
+		{now.Add(-time.Hour), now.Add(-25 * time.Hour), false},
+		{now.Add(-25 * time.Hour), now.Add(-time.Hour), false},
+		{now.Add(-2 * time.Hour), time.Time{}, false},
+	}
+
+	for _, tt := range startTests {
+		if start := shouldStartBackgroundCheck(updateState{Started: tt.started, Checked: tt.checked}, now, 24*time.Hour); start != tt.start {
+			t.Errorf("shouldStartBackgroundCheck(started %s, checked %s) => %t, wanted: %t", tt.started, tt.checked, start, tt.start)
+		}
+	}
+}
+
+func TestFormatUpdateNotice(t *testing.T) {
+	installed := func(name string) bool { return name != "cli-gone" }
+	purge := updateStatePackage{Name: "cli-purge", OldVersion: "1.0.0", NewVersion: "1.1.0"}
+	property := updateStatePackage{Name: "cli-property", OldVersion: "0.6.0", NewVersion: "0.7.0"}
+	gone := updateStatePackage{Name: "cli-gone", OldVersion: "1.0.0", NewVersion: "2.0.0"}
+
+	noticeTests := []struct {
+		state  updateState
+		notice string
+	}{
+		{updateState{}, ""},
+		{updateState{Packages: []updateStatePackage{purge, property}}, "2 updates available, run \"" + self() + " update\""},
+		{updateState{Packages: []updateStatePackage{purge, gone}}, "1 update available, run \"" + self() + " update\""},
+		{updateState{Packages: []updateStatePackage{gone}, CliVersion: "1.0.0"}, ""},
+		{updateState{CliVersion: "1.3.0"}, "Akamai CLI 1.3.0 is available, run \"" + self() + " upgrade\""},
+		{updateState{Packages: []updateStatePackage{purge}, CliVersion: "1.3.0"}, "1 update available, run \"" + self() + " update\", and Akamai CLI 1.3.0 is available, run \"" + self() + " upgrade\""},
+	}
+
+	for _, tt := range noticeTests {
+		if notice := formatUpdateNotice(tt.state, "1.2.0", installed); notice != tt.notice {
+			t.Errorf("formatUpdateNotice(%+v) => %s, wanted: %s", tt.state, notice, tt.notice)
+		}
+	}
+}
+
+func TestGetCommandArg(t *testing.T) {
+	argTests := []struct {
+		args    []string
+		command string
+	}{
+		{[]string{"update"}, "update"},
+		{[]string{"--quiet", "--format", "json", "list", "--remote"}, "list"},
+		{[]string{"--profile", "prod", "property", "update"}, "property"},
+		{[]string{"--offline"}, ""},
+		{nil, ""},
+	}
+
+	for _, tt := range argTests {
+		if command := getCommandArg(tt.args); command != tt.command {
+			t.Errorf("getCommandArg(%q) => %s, wanted: %s", tt.args, command, tt.command)
+		}
+	}
+}
@@ -1,5 +1,11 @@
 #!/bin/bash
-# Creates unsigned binaries for macOS (64bit), and Linux/Windows (32 and 64bit) 
+# Creates binaries for macOS (Intel and Apple Silicon), Linux (32 and 64bit,
+# and arm64), and Windows (32 and 64bit), and a SHA256SUMS file of their
+# checksums.
+#
+# UPGRADE_SIGNING_KEY must be set to the ed25519 private key (PEM) matching
+# upgradePublicKey in upgrade_verify.go, which signs SHA256SUMS: self-upgrades
+# refuse releases without a valid SHA256SUMS.sig.
 function check_version {
 	grep "VERSION" ./akamai.go | grep  \"$1\"
 	if [[ $? -eq 1 ]]
@@ -18,15 +24,28 @@ fi
 
 check_version $1
 
+if [[ -z "$UPGRADE_SIGNING_KEY" ]]
+then
+	echo "UPGRADE_SIGNING_KEY not set, releases must be signed."
+	exit 1
+fi
+
 mkdir -p build
 
 GOOS=darwin GOARCH=amd64 go build -o build/akamai-$1-macamd64 .
 shasum -a 256 build/akamai-$1-macamd64 | awk '{print $1}' > build/akamai-$1-macamd64.sig
+GOOS=darwin GOARCH=arm64 go build -o build/akamai-$1-macarm64 .
+shasum -a 256 build/akamai-$1-macarm64 | awk '{print $1}' > build/akamai-$1-macarm64.sig
 GOOS=linux GOARCH=amd64 go build -o build/akamai-$1-linuxamd64 .
 shasum -a 256 build/akamai-$1-linuxamd64 | awk '{print $1}' > build/akamai-$1-linuxamd64.sig
 GOOS=linux GOARCH=386 go build -o build/akamai-$1-linux386 .
 shasum -a 256 build/akamai-$1-linux386 | awk '{print $1}' > build/akamai-$1-linux386.sig
+GOOS=linux GOARCH=arm64 go build -o build/akamai-$1-linuxarm64 .
+shasum -a 256 build/akamai-$1-linuxarm64 | awk '{print $1}' > build/akamai-$1-linuxarm64.sig
 GOOS=windows GOARCH=386 go build -o build/akamai-$1-windows386.exe .
 shasum -a 256 build/akamai-$1-windows386.exe | awk '{print $1}' > build/akamai-$1-windows386.exe.sig
 GOOS=windows GOARCH=amd64 go build -o build/akamai-$1-windowsamd64.exe .
 shasum -a 256 build/akamai-$1-windowsamd64.exe | awk '{print $1}' > build/akamai-$1-windowsamd64.exe.sig
+
+(cd build && shasum -a 256 akamai-$1-* | grep -v "\.sig$" > SHA256SUMS)
+openssl pkeyutl -sign -rawin -inkey "$UPGRADE_SIGNING_KEY" -in build/SHA256SUMS | base64 | tr -d '\n' > build/SHA256SUMS.sig
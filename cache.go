@@ -0,0 +1,83 @@
+/*
+ Copyright 2018. Akamai Technologies, Inc
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package main
+
+import (
+	"fmt"
+	"hash/fnv"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// defaultPackageListTTL is how long a cached package list is used before it is
+// fetched again, unless cli.package-list-ttl is set
+const defaultPackageListTTL = time.Hour
+
+func getPackageListTTL() time.Duration {
+	if value := getConfigValue("cli", "package-list-ttl"); value != "" {
+		if ttl, err := time.ParseDuration(value); err == nil {
+			return ttl
+		}
+	}
+
+	return defaultPackageListTTL
+}
+
+// getPackageListCachePath returns where the package list of a registry is
+// cached, each registry is cached separately
+func getPackageListCachePath(registry string) (string, error) {
+	cachePath, err := getAkamaiCliCachePath()
+	if err != nil {
+		return "", err
+	}
+
+	hash := fnv.New32a()
+	hash.Write([]byte(registry))
+
+	return filepath.Join(cachePath, fmt.Sprintf("package-list-%08x.json", hash.Sum32())), nil
+}
+
+// readPackageListCache returns the cached package list, and how long ago it was fetched
+func readPackageListCache(registry string) ([]byte, time.Duration, error) {
+	path, err := getPackageListCachePath(registry)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return data, time.Since(info.ModTime()), nil
+}
+
+func writePackageListCache(registry string, data []byte) error {
+	path, err := getPackageListCachePath(registry)
+	if err != nil {
+		return err
+	}
+
+	return ioutil.WriteFile(path, data, 0664)
+}
@@ -0,0 +1,157 @@
+/*
+ Copyright 2018. Akamai Technologies, Inc
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	akamai "github.com/akamai/cli-common-golang"
+	"github.com/fatih/color"
+	"gopkg.in/src-d/go-git.v4"
+	"gopkg.in/src-d/go-git.v4/plumbing"
+)
+
+// maxChangelogCommits is how many commits of an update are shown at most,
+// and how many are fetched of a shallow clone to show them
+const maxChangelogCommits = 20
+
+// getCommitChangelog returns the subjects of the commits from to back to from,
+// newest first, and whether from was reached. Only first parents are
+// followed, and at most maxChangelogCommits commits are returned. With a
+// subpath, only the commits that change it are.
+func getCommitChangelog(repo *git.Repository, from plumbing.Hash, to plumbing.Hash, subpath string) ([]string, bool) {
+	var changes []string
+	hash := to
+	for len(changes) < maxChangelogCommits {
+		if hash == from {
+			return changes, true
+		}
+
+		commit, err := repo.CommitObject(hash)
+		if err != nil {
+			// The rest of a shallow clone's history is missing
+			return changes, false
+		}
+
+		if commit.NumParents() == 0 {
+			return append(changes, formatChangelogCommit(commit.Hash.String(), commit.Message)), false
+		}
+
+		if subpathChanged(repo, commit.ParentHashes[0], hash, subpath) {
+			changes = append(changes, formatChangelogCommit(commit.Hash.String(), commit.Message))
+		}
+		hash = commit.ParentHashes[0]
+	}
+
+	return changes, hash == from
+}
+
+// formatChangelogCommit returns the short hash and subject of a commit
+func formatChangelogCommit(hash string, message string) string {
+	if len(hash) > 7 {
+		hash = hash[:7]
+	}
+
+	subject := strings.TrimSpace(strings.SplitN(strings.TrimSpace(message), "\n", 2)[0])
+	return color.YellowString(hash) + " " + subject
+}
+
+// getNewerReleases returns the releases of pkg with notes that are newer than
+// the one at installedCommit, oldest first. If installedCommit is not a
+// release, only the latest release is returned.
+func getNewerReleases(pkg packageListPackage, installedCommit string) []packageRelease {
+	installed := ""
+	for _, release := range pkg.Releases {
+		if release.Commit != "" && strings.EqualFold(release.Commit, installedCommit) {
+			installed = release.Version
+		}
+	}
+
+	var releases []packageRelease
+	for _, release := range pkg.Releases {
+		if release.Notes == "" {
+			continue
+		}
+
+		if installed != "" && compareVersions(release.Version, installed) <= 0 {
+			continue
+		}
+
+		if installed == "" && strings.TrimPrefix(release.Version, "v") != strings.TrimPrefix(pkg.Version, "v") {
+			continue
+		}
+		releases = append(releases, release)
+	}
+
+	sort.SliceStable(releases, func(i, j int) bool {
+		return compareVersions(releases[i].Version, releases[j].Version) < 0
+	})
+
+	return releases
+}
+
+// printChangelog shows what an update of name pulls in: the notes of the
+// releases it crosses, and the commits, if known
+func printChangelog(name string, releases []packageRelease, changes []string, complete bool) {
+	fmt.Fprintf(akamai.App.Writer, "\nChanges in %s:\n", color.New(color.Bold).Sprint(name))
+
+	for _, release := range releases {
+		fmt.Fprintf(akamai.App.Writer, "\n  %s\n", color.CyanString("v"+strings.TrimPrefix(release.Version, "v")))
+		for _, line := range strings.Split(strings.TrimSpace(release.Notes), "\n") {
+			fmt.Fprintln(akamai.App.Writer, "    "+strings.TrimRight(line, " \t\r"))
+		}
+	}
+
+	if len(changes) > 0 {
+		fmt.Fprintln(akamai.App.Writer)
+		for _, change := range changes {
+			fmt.Fprintln(akamai.App.Writer, "  "+change)
+		}
+
+		if !complete {
+			fmt.Fprintln(akamai.App.Writer, "  ...and earlier commits")
+		}
+	}
+
+	if len(releases) == 0 && len(changes) == 0 {
+		fmt.Fprintln(akamai.App.Writer, "  No changelog available")
+	}
+
+	fmt.Fprintln(akamai.App.Writer)
+}
+
+// confirmUpdate asks whether to apply the update of name, if opts.confirm is set
+func confirmUpdate(name string, opts installOptions) bool {
+	if !opts.confirm {
+		return true
+	}
+
+	return promptYesNo(fmt.Sprintf("Update %s?", name), true)
+}
+
+// findCachedPackage returns the package list entry of the package installed in
+// name, from the cached package list only
+func findCachedPackage(name string) (packageListPackage, bool) {
+	list := readCachedPackageList()
+	if list == nil {
+		return packageListPackage{}, false
+	}
+
+	return list.findPackage(normalizePackageName(name))
+}
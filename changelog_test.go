@@ -0,0 +1,73 @@
+/*
+ Copyright 2018. Akamai Technologies, Inc
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestGetNewerReleases(t *testing.T) {
+	pkg := packageListPackage{
+		Version: "1.2.0",
+		Releases: []packageRelease{
+			{Version: "1.2.0", Commit: "c", Notes: "third"},
+			{Version: "1.0.0", Commit: "a", Notes: "first"},
+			{Version: "v1.1.0", Commit: "b", Notes: "second"},
+			{Version: "1.1.1", Commit: "d"},
+		},
+	}
+
+	releaseTests := []struct {
+		installedCommit string
+		versions        string
+	}{
+		{"a", "v1.1.0,1.2.0"},
+		{"b", "1.2.0"},
+		{"c", ""},
+		{"unknown", "1.2.0"},
+	}
+
+	for _, tt := range releaseTests {
+		var versions []string
+		for _, release := range getNewerReleases(pkg, tt.installedCommit) {
+			versions = append(versions, release.Version)
+		}
+
+		if strings.Join(versions, ",") != tt.versions {
+			t.Errorf("getNewerReleases(%s) => %s, wanted: %s", tt.installedCommit, strings.Join(versions, ","), tt.versions)
+		}
+	}
+}
+
+func TestFormatChangelogCommit(t *testing.T) {
+	commitTests := []struct {
+		hash     string
+		message  string
+		expected string
+	}{
+		{"0123456789abcdef", "Fix the thing\n\nLonger description", "0123456 Fix the thing"},
+		{"0123456789abcdef", "\n  Leading blank line  \n", "0123456 Leading blank line"},
+		{"abc", "Short hash", "abc Short hash"},
+	}
+
+	for _, tt := range commitTests {
+		if formatted := stripColor(formatChangelogCommit(tt.hash, tt.message)); formatted != tt.expected {
+			t.Errorf("formatChangelogCommit(%s, %q) => %s, wanted: %s", tt.hash, tt.message, formatted, tt.expected)
+		}
+	}
+}
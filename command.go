@@ -42,6 +42,8 @@ type Command struct {
 	OS          string        `json:"-"`
 	Arch        string        `json:"-"`
 	Subcommands []cli.Command `json:"-"`
+	// SkipFlagParsing passes all arguments to the action unparsed
+	SkipFlagParsing bool `json:"-"`
 }
 
 func packageListDiff(oldcmds []commandPackage) {
@@ -92,7 +94,9 @@ func packageListDiff(oldcmds []commandPackage) {
 		}
 	}
 
-	listInstalledCommands(added, removed)
+	if !isQuiet() {
+		listInstalledCommands(added, removed)
+	}
 }
 
 func getBuiltinCommands() []commandPackage {
@@ -115,13 +119,93 @@ func getBuiltinCommands() []commandPackage {
 					Flags: []cli.Flag{
 						cli.BoolFlag{
 							Name:  "remote",
-							Usage: "Display all available packages",
+							Usage: "Display all available packages, with their install status",
 						},
 					},
 				},
 			},
 			action: cmdList,
 		},
+		{
+			Commands: []Command{
+				{
+					Name:        "alias",
+					Arguments:   "<action> [name] [command]...",
+					Description: "Manage shortcuts for commands",
+					Subcommands: []cli.Command{
+						{
+							Name:      "set",
+							ArgsUsage: "<name> <command>...",
+							Usage:     "Define an alias, e.g. \"alias set pls property list --json\"",
+							Action:    cmdAliasSet,
+						},
+						{
+							Name:   "list",
+							Usage:  "List the defined aliases",
+							Action: cmdAliasList,
+						},
+						{
+							Name:      "unset",
+							Aliases:   []string{"rm"},
+							ArgsUsage: "<name>...",
+							Usage:     "Remove aliases",
+							Action:    cmdAliasUnset,
+						},
+					},
+					Docs: "Aliases expand before the command is run, with any further arguments appended, so \"akamai pls --section prod\" runs \"akamai property list --json --section prod\". Built-in commands cannot be used as aliases.",
+				},
+			},
+		},
+		{
+			Commands: []Command{
+				{
+					Name:        "browse",
+					Description: "Browse the package repository in an interactive list, and install, update, or uninstall packages with a key",
+					Docs:        "Type to search, use up/down (or page up/down) to select a package, enter to install it or update it if it is installed, ctrl-d to uninstall it, and esc to exit. In the list, * marks installed packages, ^ those with an update, and ! deprecated ones.",
+				},
+			},
+			action: cmdBrowse,
+		},
+		{
+			Commands: []Command{
+				{
+					Name:        "completion",
+					Arguments:   "<shell>",
+					Description: "Output a shell completion script for bash, zsh, fish, or powershell",
+					Docs:        "Examples:\n\n   eval \"$(akamai completion bash)\"\n   akamai completion fish | source\n   akamai completion powershell | Out-String | Invoke-Expression",
+				},
+			},
+			action: cmdCompletion,
+		},
+		{
+			Commands: []Command{
+				{
+					Name:        "clean",
+					Description: "Show the disk space used by installed packages and caches, and free it",
+					Flags: []cli.Flag{
+						cli.BoolFlag{
+							Name:  "caches",
+							Usage: "Remove build caches (Python bytecode, npm tool caches, Go build artifacts) and cached package lists",
+						},
+						cli.BoolFlag{
+							Name:  "orphans",
+							Usage: "Remove directories that are not installed packages, and manifests of removed packages",
+						},
+						cli.BoolFlag{
+							Name:  "rollback",
+							Usage: "Remove the versions kept for rollback from package histories",
+						},
+						cli.BoolFlag{
+							Name:  "all",
+							Usage: "Remove all of the above",
+						},
+						waitFlag,
+					},
+					Docs: "Without flags, only reports the disk usage. Packages cleaned up with --rollback can no longer be rolled back until they are updated again.",
+				},
+			},
+			action: withOperationLock("clean", cmdClean),
+		},
 		{
 			Commands: []Command{
 				{
@@ -154,23 +238,324 @@ func getBuiltinCommands() []commandPackage {
 				},
 			},
 		},
+		{
+			Commands: []Command{
+				{
+					Name:        "groups",
+					Description: "List the curated package groups available to \"akamai install --group\"",
+					Docs:        "Groups are published by the package repository, and can be defined locally in the [groups] section of the config:\n\n   [groups]\n   getting-started = property, purge",
+				},
+			},
+			action: cmdGroups,
+		},
+		{
+			Commands: []Command{
+				{
+					Name:        "history",
+					Description: "Show the installed package commands that have been run, from the audit log",
+					Flags: []cli.Flag{
+						cli.StringFlag{
+							Name:  "command",
+							Usage: "Only show runs of `COMMAND`",
+						},
+						cli.StringFlag{
+							Name:  "since",
+							Usage: "Only show runs since `TIME`, a duration (24h, 7d) or a date (2006-01-02)",
+						},
+						cli.BoolFlag{
+							Name:  "failed",
+							Usage: "Only show runs that exited with a non-zero status",
+						},
+						cli.IntFlag{
+							Name:  "limit",
+							Usage: "Show at most the `N` most recent runs, 0 for all",
+							Value: 20,
+						},
+					},
+					Docs: "Secrets in arguments, such as the values of --password or --token flags, are redacted before they are logged.",
+				},
+			},
+			action: cmdHistory,
+		},
+		{
+			Commands: []Command{
+				{
+					Name:        "info",
+					Arguments:   "<package name>",
+					Description: "Display everything the package repository knows about a package, and whether it is installed",
+				},
+			},
+			action: cmdInfo,
+		},
+		{
+			Commands: []Command{
+				{
+					Name:        "credentials",
+					Arguments:   "<action>",
+					Description: "Keep the client secrets of .edgerc in the OS keychain instead of in plaintext",
+					Subcommands: []cli.Command{
+						{
+							Name:   "store",
+							Usage:  "Move the client secret of a section into the keychain",
+							Action: cmdCredentialsStore,
+							Flags: []cli.Flag{
+								cli.StringFlag{
+									Name:  "edgerc",
+									Usage: "Location of the credentials `FILE`, that of the current profile or ~/.edgerc if not given",
+								},
+								cli.StringFlag{
+									Name:  "section",
+									Usage: "`SECTION` of the credentials file, that of the current profile or \"default\" if not given",
+								},
+							},
+						},
+						{
+							Name:   "restore",
+							Usage:  "Move the client secret of a section back into .edgerc",
+							Action: cmdCredentialsRestore,
+							Flags: []cli.Flag{
+								cli.StringFlag{
+									Name:  "edgerc",
+									Usage: "Location of the credentials `FILE`, that of the current profile or ~/.edgerc if not given",
+								},
+								cli.StringFlag{
+									Name:  "section",
+									Usage: "`SECTION` of the credentials file, that of the current profile or \"default\" if not given",
+								},
+							},
+						},
+						{
+							Name:   "list",
+							Usage:  "Show where the client secret of each section is kept",
+							Action: cmdCredentialsList,
+							Flags: []cli.Flag{
+								cli.StringFlag{
+									Name:  "edgerc",
+									Usage: "Location of the credentials `FILE`, that of the current profile or ~/.edgerc if not given",
+								},
+							},
+						},
+					},
+					Docs: "Secrets are kept in the macOS Keychain, the Windows Credential Manager, or the secret service on Linux (with secret-tool), and passed to installed commands in the AKAMAI_[SECTION_]CLIENT_SECRET environment variables.",
+				},
+			},
+		},
+		{
+			Commands: []Command{
+				{
+					Name:        "docs",
+					Arguments:   "[package]",
+					Description: "Generate a markdown or man page reference of the built-in commands and installed packages",
+					Flags: []cli.Flag{
+						cli.StringFlag{
+							Name:  "format",
+							Usage: "Generate `FORMAT`: markdown or man",
+							Value: docsFormatMarkdown,
+						},
+						cli.StringFlag{
+							Name:  "output",
+							Usage: "Write a page per command, and an index, to `DIR` instead of printing them",
+						},
+					},
+					Docs: "The reference of installed commands comes from their cli.json and their own help output. Pass a package, or any command within it, to generate only its pages, or a built-in command for its page.",
+				},
+			},
+			action: cmdDocs,
+		},
+		{
+			Commands: []Command{
+				{
+					Name:        "doctor",
+					Description: "Check the environment for problems that prevent packages from installing or running",
+				},
+			},
+			action: cmdDoctor,
+		},
+		{
+			Commands: []Command{
+				{
+					Name:            "exec",
+					Arguments:       "<command> [--] [args]...",
+					Description:     "Run an installed command, passing it all arguments as they are",
+					Docs:            "Examples:\n\n   akamai exec property -- -v list",
+					SkipFlagParsing: true,
+				},
+			},
+			action: withErrorReport("exec", cmdExec),
+		},
+		{
+			Commands: []Command{
+				{
+					Name:        "export-state",
+					Arguments:   "[file]",
+					Description: "Save the installed packages, config, and aliases to a file (default: " + defaultStateFile + "), to set up another machine the same way",
+					Docs:        "Credentials, settings that look like secrets, and settings specific to this machine are left out.",
+				},
+			},
+			action: cmdExportState,
+		},
+		{
+			Commands: []Command{
+				{
+					Name:        "import-state",
+					Arguments:   "[file]",
+					Description: "Apply the config and aliases saved with \"akamai export-state\", and install its packages at the same commits",
+					Flags: []cli.Flag{
+						cli.BoolFlag{
+							Name:  "force",
+							Usage: "Force binary installation if available when source installation fails",
+						},
+						cli.BoolFlag{
+							Name:  "accept-license",
+							Usage: "Accept the license of packages that require it, without asking",
+						},
+						waitFlag,
+					},
+					Docs: "Packages that are already installed are left as they are.",
+				},
+			},
+			action: withOperationLock("import-state", cmdImportState),
+		},
+		{
+			Commands: []Command{
+				{
+					Name:        "init-package",
+					Arguments:   "[<directory>]",
+					Description: "Create the skeleton of a new package",
+					Flags: []cli.Flag{
+						cli.StringFlag{
+							Name:  "language",
+							Usage: "Language of the package: go, python, or node",
+							Value: "go",
+						},
+						cli.StringFlag{
+							Name:  "name",
+							Usage: "Name of the command, defaults to the directory name without its cli- prefix",
+						},
+						cli.StringFlag{
+							Name:  "description",
+							Usage: "Short description of the command",
+						},
+					},
+				},
+			},
+			action: cmdInitPackage,
+		},
 		{
 			Commands: []Command{
 				{
 					Name:        "install",
-					Arguments:   "<package name or repository URL>...",
+					Arguments:   "<package name, repository URL, or local path>...",
 					Description: "Fetch and install packages from a Git repository.",
 					Flags: []cli.Flag{
 						cli.BoolFlag{
 							Name:  "force",
-							Usage: "Force binary installation if available when source installation fails",
+							Usage: "Install even if required runtimes are missing, and use binaries if available when source installation fails",
+						},
+						cli.StringFlag{
+							Name:  "from-search",
+							Usage: "Install all packages matching the given search keywords",
+						},
+						cli.IntFlag{
+							Name:  "min-rank",
+							Usage: "Minimum search rank a package must have to be installed with --from-search",
+						},
+						cli.BoolFlag{
+							Name:  "yes",
+							Usage: "Do not ask for confirmation before installing packages found with --from-search",
+						},
+						cli.BoolFlag{
+							Name:  "accept-license",
+							Usage: "Accept the license of packages that require it, without asking",
+						},
+						cli.StringFlag{
+							Name:  "group",
+							Usage: "Install all packages in the named group, see \"akamai groups\"",
+						},
+						cli.IntFlag{
+							Name:  "jobs",
+							Usage: "Install up to `N` packages at a time",
+							Value: 1,
+						},
+						cli.StringFlag{
+							Name:  "from-lock",
+							Usage: "Install the exact packages and commits recorded in a lockfile, see \"akamai lock\"",
+						},
+						cli.StringFlag{
+							Name:  "version",
+							Usage: "Install the given version (git tag) of the packages, like <package>@<version>",
 						},
+						cli.BoolFlag{
+							Name:  "insecure",
+							Usage: "Install packages even if they do not match the commit, checksums, or signature published by the registry",
+						},
+						cli.BoolFlag{
+							Name:  "insecure-skip-index-verify",
+							Usage: "Use the package list even if its signature cannot be verified",
+						},
+						cli.BoolFlag{
+							Name:  "refresh",
+							Usage: "Fetch the package list again, even if the cached copy has not expired",
+						},
+						cli.BoolFlag{
+							Name:  "from-source",
+							Usage: "Clone and build packages from source, even if the registry publishes a pre-built binary for this platform",
+						},
+						waitFlag,
 					},
 					Aliases: []string{"get"},
-					Docs:    "Examples:\n\n   akamai install property purge\n   akamai install akamai/cli-property\n   akamai install git@github.com:akamai/cli-property.git\n   akamai install https://github.com/akamai/cli-property.git",
+					Docs:    "Examples:\n\n   akamai install property purge\n   akamai install akamai/cli-property\n   akamai install property@1.2.0\n   akamai install git@github.com:akamai/cli-property.git\n   akamai install https://github.com/akamai/cli-property.git\n   akamai install --from-search \"security\" --min-rank 100\n   akamai install --group getting-started",
+				},
+			},
+			action: withReport("install", withOperationLock("install", cmdInstall)),
+		},
+		{
+			Commands: []Command{
+				{
+					Name:        "lock",
+					Arguments:   "<action> [file]",
+					Description: "Record the installed packages, to reproduce them with \"akamai install --from-lock\"",
+					Subcommands: []cli.Command{
+						{
+							Name:      "write",
+							ArgsUsage: "[file]",
+							Usage:     "Write the installed packages and their commits to a lockfile (default: " + defaultLockfile + ")",
+							Action:    cmdLockWrite,
+						},
+					},
+				},
+			},
+		},
+		{
+			Commands: []Command{
+				{
+					Name:        "matrix",
+					Description: "Display the runtime requirements of all packages in the official Akamai CLI package repository",
+					Flags: []cli.Flag{
+						cli.BoolFlag{
+							Name:  "json",
+							Usage: "Output the matrix as JSON",
+						},
+						cli.BoolFlag{
+							Name:  "csv",
+							Usage: "Output the matrix as CSV",
+						},
+					},
+				},
+			},
+			action: cmdMatrix,
+		},
+		{
+			Commands: []Command{
+				{
+					Name:        "migrate-home",
+					Arguments:   "<path>",
+					Description: "Move installed packages, config, and caches to another home directory",
+					Flags:       []cli.Flag{waitFlag},
+					Docs:        "The .akamai-cli directory is moved into <path>, and the CLI is pointed at it with cli.home in the config of your home directory, unless AKAMAI_CLI_HOME is set, which you then need to change yourself. Config values and symlinks in packages that point into the old directory are updated.",
 				},
 			},
-			action: cmdInstall,
+			action: withOperationLock("migrate-home", cmdMigrateHome),
 		},
 		{
 			Commands: []Command{
@@ -178,10 +563,343 @@ func getBuiltinCommands() []commandPackage {
 					Name:        "search",
 					Arguments:   "<keyword>...",
 					Description: "Search for packages in the official Akamai CLI package repository",
-					Docs:        "Examples:\n\n   akamai search property",
+					Flags: []cli.Flag{
+						cli.BoolFlag{
+							Name:  "diff-installed-version",
+							Usage: "Show installed and registry versions side by side for installed packages",
+						},
+						cli.StringFlag{
+							Name:  "header-format",
+							Usage: "Template for the package header line, e.g. '{{.Title}} [{{.Name}}]'",
+							Value: defaultSearchHeaderFormat,
+						},
+						cli.DurationFlag{
+							Name:  "timeout-per-keyword",
+							Usage: "Abort scoring if it takes longer than this per keyword",
+							Value: defaultSearchTimeoutPerKeyword,
+						},
+						cli.BoolFlag{
+							Name:  "no-banner",
+							Usage: "Do not display the number of results found",
+						},
+						cli.StringSliceFlag{
+							Name:  "lang",
+							Usage: "Only show packages that can run with the given language runtimes (go, php, node, ruby, python)",
+						},
+						cli.BoolFlag{
+							Name:  "available",
+							Usage: "Only show packages that can run with the language runtimes installed locally",
+						},
+						cli.StringSliceFlag{
+							Name:  "tag",
+							Usage: "Only show packages with the given tag or category, repeat for packages with all of them, see \"akamai tags\"",
+						},
+						cli.BoolFlag{
+							Name:  "installed",
+							Usage: "Search the commands and descriptions of the installed packages, instead of the package repository",
+						},
+						cli.IntFlag{
+							Name:  "limit",
+							Usage: "Show at most `N` results",
+						},
+						cli.IntFlag{
+							Name:  "page",
+							Usage: "Show the `N`th page of --limit results",
+							Value: 1,
+						},
+						cli.BoolFlag{
+							Name:  "no-pager",
+							Usage: "Do not page long results through $PAGER",
+						},
+						cli.BoolFlag{
+							Name:  "no-prompt",
+							Usage: "Do not offer to install one of the results",
+						},
+						cli.BoolFlag{
+							Name:  "install-first",
+							Usage: "Install the top result without asking",
+						},
+						cli.BoolFlag{
+							Name:  "exact",
+							Usage: "Only match keywords exactly, without tolerating typos",
+						},
+						cli.BoolFlag{
+							Name:  "case-sensitive",
+							Usage: "Match keywords exactly, without ignoring case",
+						},
+						cli.StringFlag{
+							Name:   "palette",
+							Usage:  "Color package names using a palette: default, high-contrast, or colorblind",
+							EnvVar: "AKAMAI_CLI_PALETTE",
+						},
+						cli.BoolFlag{
+							Name:  "raw",
+							Usage: "Output the registry records of matching packages as JSON, exactly as received",
+						},
+						cli.IntFlag{
+							Name:  "relevance-threshold",
+							Usage: "Only show results scoring at least this percentage of the top result",
+						},
+						cli.StringFlag{
+							Name:  "in",
+							Usage: "Search only the commands of the named package",
+						},
+						cli.StringFlag{
+							Name:  "sort",
+							Usage: "Comma-separated sort keys (relevance, name, title, version, updated), each optionally suffixed with :asc or :desc",
+							Value: defaultSearchSort,
+						},
+						cli.BoolFlag{
+							Name:  "explain",
+							Usage: "Show how the rank of each result was scored, field by field",
+						},
+						cli.BoolFlag{
+							Name:  "allow-partial",
+							Usage: "Search whatever packages were received if the package list is truncated",
+						},
+						cli.BoolFlag{
+							Name:  "runtime-count",
+							Usage: "Summarize the language runtimes required by the results",
+						},
+						cli.BoolFlag{
+							Name:  "watch",
+							Usage: "Interactively update results as you type, and install the selected package",
+						},
+						cli.StringFlag{
+							Name:  "format",
+							Usage: "Output format: text or markdown",
+							Value: "text",
+						},
+						cli.StringFlag{
+							Name:  "count-by",
+							Usage: "Print the number of matching packages grouped by a field (runtime, namespace, or tag), instead of the results",
+						},
+						cli.BoolFlag{
+							Name:  "top-commands",
+							Usage: "Print the commands provided by the most matching packages (or all packages, without keywords), instead of the results",
+						},
+						cli.IntFlag{
+							Name:  "n",
+							Usage: "Maximum number of commands to show with --top-commands",
+							Value: 10,
+						},
+						cli.BoolFlag{
+							Name:  "json",
+							Usage: "Output results as JSON",
+						},
+						cli.BoolFlag{
+							Name:  "insecure-skip-index-verify",
+							Usage: "Use the package list even if its signature cannot be verified",
+						},
+						cli.BoolFlag{
+							Name:  "refresh",
+							Usage: "Fetch the package list again, even if the cached copy has not expired",
+						},
+					},
+					Docs: "Examples:\n\n   akamai search property\n   akamai search --diff-installed-version property\n   akamai search --in property activate\n   akamai search --installed purge\n   akamai search --tag security",
+				},
+			},
+			action: withErrorReport("search", cmdSearch),
+		},
+		{
+			Commands: []Command{
+				{
+					Name:        "outdated",
+					Description: "List the installed packages that have newer versions, exiting with status 2 if there are any",
+					Docs:        "Versions are compared with the package repository, or for packages it does not list, with the version tags of their git repository.",
+				},
+			},
+			action: cmdOutdated,
+		},
+		{
+			Commands: []Command{
+				{
+					Name:        "package",
+					Arguments:   "<action> [name]...",
+					Description: "Disable or enable installed packages, without uninstalling them",
+					Subcommands: []cli.Command{
+						{
+							Name:      "disable",
+							ArgsUsage: "<package|command>...",
+							Usage:     "Stop a package's commands from being run or shown in help",
+							Action:    cmdPackageDisable,
+						},
+						{
+							Name:      "enable",
+							ArgsUsage: "<package|command>...",
+							Usage:     "Make the commands of a disabled package available again",
+							Action:    cmdPackageEnable,
+						},
+						{
+							Name:   "list",
+							Usage:  "List the installed packages, and whether they are enabled",
+							Action: cmdPackageList,
+						},
+					},
+				},
+			},
+		},
+		{
+			Commands: []Command{
+				{
+					Name:        "profile",
+					Arguments:   "<action> [name]",
+					Description: "Manage named credential profiles, each an .edgerc file and section",
+					Subcommands: []cli.Command{
+						{
+							Name:      "add",
+							ArgsUsage: "<name>",
+							Usage:     "Create or change a profile",
+							Action:    cmdProfileAdd,
+							Flags: []cli.Flag{
+								cli.StringFlag{
+									Name:  "edgerc",
+									Usage: "Location of the credentials `FILE`",
+									Value: defaultEdgerc,
+								},
+								cli.StringFlag{
+									Name:  "section",
+									Usage: "`SECTION` of the credentials file to use, the profile name by default",
+								},
+							},
+						},
+						{
+							Name:   "list",
+							Usage:  "List the profiles, marking the one in use",
+							Action: cmdProfileList,
+						},
+						{
+							Name:      "use",
+							ArgsUsage: "<name>",
+							Usage:     "Use a profile for all commands, unless --profile is given",
+							Action:    cmdProfileUse,
+							Flags: []cli.Flag{
+								cli.BoolFlag{
+									Name:  "none",
+									Usage: "Stop using a profile",
+								},
+							},
+						},
+						{
+							Name:      "remove",
+							Aliases:   []string{"rm"},
+							ArgsUsage: "<name>",
+							Usage:     "Remove a profile",
+							Action:    cmdProfileRemove,
+						},
+						{
+							Name:      "validate",
+							ArgsUsage: "[name]...",
+							Usage:     "Check that profiles have all of their credentials",
+							Action:    cmdProfileValidate,
+						},
+					},
 				},
 			},
-			action: cmdSearch,
+		},
+		{
+			Commands: []Command{
+				{
+					Name:        "rollback",
+					Arguments:   "<command>...",
+					Description: "Restore the version of the package containing <command> from before it was last updated",
+					Flags: []cli.Flag{
+						cli.BoolFlag{
+							Name:  "force",
+							Usage: "Force binary installation if available when source installation fails",
+						},
+						waitFlag,
+					},
+				},
+			},
+			action: withOperationLock("rollback", cmdRollback),
+		},
+		{
+			Commands: []Command{
+				{
+					Name:        "sbom",
+					Description: "Export a software bill of materials of the CLI and its installed packages",
+					Flags: []cli.Flag{
+						cli.StringFlag{
+							Name:  "format",
+							Usage: "Format of the bill of materials: cyclonedx or spdx",
+							Value: sbomFormatCycloneDX,
+						},
+						cli.StringFlag{
+							Name:  "output",
+							Usage: "Write the bill of materials to `FILE` instead of stdout",
+						},
+					},
+					Docs: "Each package is listed with its version, git commit, and source repository, along with the language dependencies declared in its package.json, requirements.txt, composer.json, go.mod, or Gemfile.lock.",
+				},
+			},
+			action: cmdSBOM,
+		},
+		{
+			Commands: []Command{
+				{
+					Name:        "setup",
+					Description: "Choose your credentials, proxy, upgrade checks, and usage statistics",
+					Flags: []cli.Flag{
+						cli.BoolFlag{
+							Name:   "non-interactive",
+							Usage:  "Do not ask any questions, settings not given with flags are left as they are",
+							EnvVar: nonInteractiveEnv,
+						},
+						cli.StringFlag{
+							Name:   "edgerc",
+							Usage:  "Location of the credentials `FILE`",
+							Value:  defaultEdgerc,
+							EnvVar: setupEnvPrefix + "EDGERC",
+						},
+						cli.StringFlag{
+							Name:   "section",
+							Usage:  "`SECTION` of the credentials file to use by default",
+							EnvVar: setupEnvPrefix + "SECTION",
+						},
+						cli.StringFlag{
+							Name:   "proxy",
+							Usage:  "Save the `URL` of a proxy to use for all network access",
+							EnvVar: setupEnvPrefix + "PROXY",
+						},
+						cli.BoolFlag{
+							Name:  "no-proxy",
+							Usage: "Remove the saved proxy",
+						},
+						cli.StringFlag{
+							Name:   "ca-bundle",
+							Usage:  "PEM `FILE` of CA certificates to trust, for proxies that intercept TLS",
+							EnvVar: setupEnvPrefix + "CA_BUNDLE",
+						},
+						cli.StringFlag{
+							Name:   "auto-upgrade",
+							Usage:  "Check for a new version daily: on or off",
+							EnvVar: setupEnvPrefix + "AUTO_UPGRADE",
+						},
+						cli.StringFlag{
+							Name:   "upgrade-channel",
+							Usage:  "`CHANNEL` to upgrade from: stable, beta, or nightly",
+							EnvVar: setupEnvPrefix + "UPGRADE_CHANNEL",
+						},
+						cli.StringFlag{
+							Name:   "telemetry",
+							Usage:  "Send anonymous usage statistics: on or off",
+							EnvVar: setupEnvPrefix + "TELEMETRY",
+						},
+					},
+					Docs: "Run in a terminal, each setting not given with a flag is asked for. With --non-interactive, or without a terminal, only the flags are used: upgrade checks and usage statistics are turned off unless they were already chosen, so build agents can be provisioned without any prompts.",
+				},
+			},
+			action: cmdSetup,
+		},
+		{
+			Commands: []Command{
+				{
+					Name:        "tags",
+					Description: "List the tags of the packages in the package repository, with the number of packages for each, for \"akamai search --tag\"",
+				},
+			},
+			action: cmdTags,
 		},
 		{
 			Commands: []Command{
@@ -189,9 +907,16 @@ func getBuiltinCommands() []commandPackage {
 					Name:        "uninstall",
 					Arguments:   "<command>...",
 					Description: "Uninstall package containing <command>",
+					Flags: []cli.Flag{
+						cli.BoolFlag{
+							Name:  "purge",
+							Usage: "Also remove the package's config and cached data",
+						},
+						waitFlag,
+					},
 				},
 			},
-			action: cmdUninstall,
+			action: withReport("uninstall", withOperationLock("uninstall", cmdUninstall)),
 		},
 		{
 			Commands: []Command{
@@ -204,10 +929,92 @@ func getBuiltinCommands() []commandPackage {
 							Name:  "force",
 							Usage: "Force binary installation if available when source installation fails",
 						},
+						cli.BoolFlag{
+							Name:  "check",
+							Usage: "Only report which packages have updates available, exiting with status 2 if any do",
+						},
+						cli.BoolFlag{
+							Name:  "migrate",
+							Usage: "Offer to install the replacements of installed packages that are deprecated",
+						},
+						cli.BoolFlag{
+							Name:  "yes",
+							Usage: "Update named commands without asking, after showing their changelog",
+						},
+						cli.IntFlag{
+							Name:  "jobs",
+							Usage: "When updating all packages, update up to `N` at a time",
+							Value: defaultUpdateJobs,
+						},
+						waitFlag,
+					},
+				},
+			},
+			action: withReport("update", withOperationLock("update", cmdUpdate)),
+		},
+		{
+			Commands: []Command{
+				{
+					Name:        "verify",
+					Arguments:   "[command]...",
+					Description: "Check that installed packages are unmodified, at the commit they were installed or updated to",
+					Flags: []cli.Flag{
+						cli.BoolFlag{
+							Name:  "repair",
+							Usage: "Restore packages that fail verification, cloning them again if their repository is corrupted",
+						},
+						cli.BoolFlag{
+							Name:  "force",
+							Usage: "Force binary installation if available when source installation fails",
+						},
+						waitFlag,
+					},
+					Docs: "Changes to tracked files, a different commit being checked out, or a corrupted git repository fail verification. Untracked files, like those the build step creates, are not checked.",
+				},
+			},
+			action: cmdVerifyLocked,
+		},
+		{
+			Commands: []Command{
+				{
+					Name:        "which",
+					Arguments:   "<command>",
+					Description: "Show which installed package provides a command, with its executable, language, and version",
+					Docs:        "Packages that provide the same command are listed in the order they are looked for in; the first one runs, and the others with \"akamai <package>/<command>\".",
+				},
+			},
+			action: cmdWhich,
+		},
+		{
+			Commands: []Command{
+				{
+					Name:        "workspace",
+					Arguments:   "<action>",
+					Description: "Install the packages a project lists in its " + workspaceFile + ", for use within the project",
+					Subcommands: []cli.Command{
+						{
+							Name:   "sync",
+							Usage:  "Install the packages of the workspace at their pinned versions, and remove those no longer listed",
+							Action: withOperationLock("workspace sync", cmdWorkspaceSync),
+							Flags: []cli.Flag{
+								cli.BoolFlag{
+									Name:  "force",
+									Usage: "Force binary installation if available when source installation fails",
+								},
+								cli.BoolFlag{
+									Name:  "accept-license",
+									Usage: "Accept the license of packages that require it, without asking",
+								},
+								cli.BoolFlag{
+									Name:  "insecure",
+									Usage: "Install packages even if they do not match the commit, checksums, or signature published by the registry",
+								},
+								waitFlag,
+							},
+						},
 					},
 				},
 			},
-			action: cmdUpdate,
 		},
 	}
 
@@ -256,21 +1063,29 @@ var commandLocator akamai.CommandLocator = func() ([]cli.Command, error) {
 				Usage:        cmd.Commands[0].Usage,
 				ArgsUsage:    cmd.Commands[0].Arguments,
 				Description:  cmd.Commands[0].Description,
-				Action:       cmd.action,
+				Action:       withTelemetry(true, withUpdateFooter(true, cmd.action)),
 				UsageText:    cmd.Commands[0].Docs,
 				Flags:        cmd.Commands[0].Flags,
 				Subcommands:  cmd.Commands[0].Subcommands,
 				HideHelp:     true,
 				BashComplete: akamai.DefaultAutoComplete,
+
+				SkipFlagParsing: cmd.Commands[0].SkipFlagParsing,
 			},
 		)
 	}
 
+	// Packages are in priority order, so the first of several commands of the same name is the one that runs
+	installedCmds := make(map[string]bool)
 	for _, cmd := range getCommands() {
 		for _, command := range cmd.Commands {
 			if _, ok := builtinCmds[command.Name]; ok {
 				continue
 			}
+			if installedCmds[command.Name] {
+				continue
+			}
+			installedCmds[command.Name] = true
 
 			commands = append(
 				commands,
@@ -279,7 +1094,7 @@ var commandLocator akamai.CommandLocator = func() ([]cli.Command, error) {
 					Aliases:     command.Aliases,
 					Description: command.Description,
 
-					Action:          cmdSubcommand,
+					Action:          withTelemetry(false, withUpdateFooter(false, cmdSubcommand)),
 					Category:        color.YellowString("Installed Commands:"),
 					SkipFlagParsing: true,
 					BashComplete: func(c *cli.Context) {
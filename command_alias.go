@@ -0,0 +1,232 @@
+/*
+ Copyright 2018. Akamai Technologies, Inc
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	akamai "github.com/akamai/cli-common-golang"
+	"github.com/fatih/color"
+	"github.com/urfave/cli"
+)
+
+// aliasSection is the config section aliases are stored in, e.g. pls = property list --json
+const aliasSection = "alias"
+
+func cmdAliasSet(c *cli.Context) error {
+	if c.NArg() < 2 {
+		return cli.NewExitError(color.RedString("Usage: %s alias set <name> <command>...", self()), 1)
+	}
+
+	name := strings.ToLower(c.Args().First())
+	if err := validateAliasName(name); err != nil {
+		return cli.NewExitError(color.RedString(err.Error()), 1)
+	}
+
+	expansion := strings.Join(c.Args().Tail(), " ")
+	if c.NArg() > 2 {
+		// Keep arguments that were quoted on the command line together
+		expansion = quoteArgs(c.Args().Tail())
+	}
+
+	args, err := splitAliasArgs(expansion)
+	if err != nil || len(args) == 0 {
+		return cli.NewExitError(color.RedString("Invalid alias command \"%s\"", expansion), 1)
+	}
+
+	if strings.ToLower(args[0]) == name {
+		return cli.NewExitError(color.RedString("Alias \"%s\" cannot expand to itself", name), 1)
+	}
+
+	if isInstalledCommand(name) {
+		fmt.Fprintln(akamai.App.ErrWriter, color.YellowString("Warning: alias \"%s\" hides the installed command of the same name", name))
+	}
+
+	setConfigValue(aliasSection, name, expansion)
+	saveConfig()
+
+	return nil
+}
+
+func cmdAliasList(c *cli.Context) error {
+	aliases := getConfigSectionValues(aliasSection)
+
+	if getOutputFormat() != formatTable {
+		if aliases == nil {
+			aliases = map[string]string{}
+		}
+		return printStructured(aliases)
+	}
+
+	if len(aliases) == 0 {
+		fmt.Fprintln(akamai.App.Writer, color.YellowString("No aliases are defined, add one with \"%s alias set <name> <command>...\"", self()))
+		return nil
+	}
+
+	names := make([]string, 0, len(aliases))
+	for name := range aliases {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		fmt.Fprintf(akamai.App.Writer, "%s = %s\n", color.New(color.Bold).Sprint(name), aliases[name])
+	}
+
+	return nil
+}
+
+func cmdAliasUnset(c *cli.Context) error {
+	if !c.Args().Present() {
+		return cli.NewExitError(color.RedString("Usage: %s alias unset <name>", self()), 1)
+	}
+
+	for _, name := range c.Args() {
+		name = strings.ToLower(name)
+		if getConfigValue(aliasSection, name) == "" {
+			return cli.NewExitError(color.RedString("Alias \"%s\" is not defined", name), 1)
+		}
+		unsetConfigValue(aliasSection, name)
+	}
+	saveConfig()
+
+	return nil
+}
+
+// validateAliasName makes sure an alias is a single word that does not shadow a built-in command
+func validateAliasName(name string) error {
+	if name == "" || strings.HasPrefix(name, "-") || strings.ContainsAny(name, " \t=[]\"'") {
+		return fmt.Errorf("Invalid alias name \"%s\"", name)
+	}
+
+	for _, cmd := range getBuiltinCommands() {
+		for _, command := range cmd.Commands {
+			if command.Name == name {
+				return fmt.Errorf("\"%s\" is a built-in command and cannot be used as an alias", name)
+			}
+			for _, alias := range command.Aliases {
+				if alias == name {
+					return fmt.Errorf("\"%s\" is an alias of the built-in %s command and cannot be used as an alias", name, command.Name)
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
+func isInstalledCommand(name string) bool {
+	for _, cmd := range getCommands() {
+		for _, command := range cmd.Commands {
+			if command.Name == name {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// expandAlias replaces the command in args (os.Args without the program name)
+// with its alias, if it has one. Global flags before the command are kept, and
+// the arguments after it are appended to the expansion. Aliases are expanded
+// only once, so they cannot loop.
+func expandAlias(args []string, aliases map[string]string) ([]string, error) {
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		if strings.HasPrefix(arg, "-") {
+			if globalFlagsWithValues[strings.TrimLeft(arg, "-")] {
+				i++
+			}
+			continue
+		}
+
+		expansion, ok := aliases[strings.ToLower(arg)]
+		if !ok || validateAliasName(strings.ToLower(arg)) != nil {
+			return args, nil
+		}
+
+		expanded, err := splitAliasArgs(expansion)
+		if err != nil {
+			return args, fmt.Errorf("Invalid alias \"%s\": %s", arg, err.Error())
+		}
+
+		result := append([]string{}, args[:i]...)
+		result = append(result, expanded...)
+		return append(result, args[i+1:]...), nil
+	}
+
+	return args, nil
+}
+
+// splitAliasArgs splits an alias into arguments like a shell would, honoring
+// single and double quotes and backslash escapes
+func splitAliasArgs(s string) ([]string, error) {
+	var args []string
+	var current []rune
+	inArg := false
+	var quote rune
+
+	runes := []rune(s)
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+		switch {
+		case quote == '\'':
+			if r == '\'' {
+				quote = 0
+				continue
+			}
+			current = append(current, r)
+		case r == '\\' && quote != '\'':
+			if i+1 < len(runes) {
+				i++
+				current = append(current, runes[i])
+				inArg = true
+			}
+		case quote == '"':
+			if r == '"' {
+				quote = 0
+				continue
+			}
+			current = append(current, r)
+		case r == '"' || r == '\'':
+			quote = r
+			inArg = true
+		case r == ' ' || r == '\t' || r == '\n':
+			if inArg {
+				args = append(args, string(current))
+				current = current[:0]
+				inArg = false
+			}
+		default:
+			current = append(current, r)
+			inArg = true
+		}
+	}
+
+	if quote != 0 {
+		return nil, fmt.Errorf("unterminated %c quote", quote)
+	}
+
+	if inArg {
+		args = append(args, string(current))
+	}
+
+	return args, nil
+}
@@ -0,0 +1,90 @@
+/*
+ Copyright 2018. Akamai Technologies, Inc
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSplitAliasArgs(t *testing.T) {
+	splitTests := []struct {
+		alias string
+		args  []string
+		err   bool
+	}{
+		{"property list --json", []string{"property", "list", "--json"}, false},
+		{`  purge  invalidate  `, []string{"purge", "invalidate"}, false},
+		{`search "two words" 'single quoted' escaped\ space`, []string{"search", "two words", "single quoted", "escaped space"}, false},
+		{`property --filter ""`, []string{"property", "--filter", ""}, false},
+		{`property "unterminated`, nil, true},
+	}
+
+	for _, tt := range splitTests {
+		args, err := splitAliasArgs(tt.alias)
+		if (err != nil) != tt.err || (!tt.err && !reflect.DeepEqual(args, tt.args)) {
+			t.Errorf("splitAliasArgs(%s) => %q, %v, wanted: %q", tt.alias, args, err, tt.args)
+		}
+	}
+}
+
+func TestExpandAlias(t *testing.T) {
+	aliases := map[string]string{
+		"pls":    "property list --json",
+		"loop":   "loop again",
+		"update": "uninstall property",
+	}
+
+	expandTests := []struct {
+		args []string
+		want []string
+	}{
+		{[]string{"pls"}, []string{"property", "list", "--json"}},
+		{[]string{"pls", "--section", "prod"}, []string{"property", "list", "--json", "--section", "prod"}},
+		{[]string{"--format", "json", "pls"}, []string{"--format", "json", "property", "list", "--json"}},
+		{[]string{"--offline", "PLS"}, []string{"--offline", "property", "list", "--json"}},
+		{[]string{"loop"}, []string{"loop", "again"}},
+		{[]string{"property", "pls"}, []string{"property", "pls"}},
+		{[]string{"update"}, []string{"update"}},
+		{[]string{}, []string{}},
+	}
+
+	for _, tt := range expandTests {
+		if args, err := expandAlias(tt.args, aliases); err != nil || !reflect.DeepEqual(args, tt.want) {
+			t.Errorf("expandAlias(%q) => %q, %v, wanted: %q", tt.args, args, err, tt.want)
+		}
+	}
+}
+
+func TestValidateAliasName(t *testing.T) {
+	aliasTests := []struct {
+		name  string
+		valid bool
+	}{
+		{"pls", true},
+		{"install", false},
+		{"get", false},
+		{"-x", false},
+		{"two words", false},
+	}
+
+	for _, tt := range aliasTests {
+		if err := validateAliasName(tt.name); (err == nil) != tt.valid {
+			t.Errorf("validateAliasName(%s) => %v, wanted valid: %t", tt.name, err, tt.valid)
+		}
+	}
+}
@@ -0,0 +1,420 @@
+/*
+ Copyright 2018. Akamai Technologies, Inc
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package main
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	akamai "github.com/akamai/cli-common-golang"
+	"github.com/fatih/color"
+	"github.com/mattn/go-isatty"
+	"github.com/urfave/cli"
+)
+
+const keyCtrlD = 4
+
+// browseState is what "akamai browse" shows: the registry packages matching
+// the query, and which of them is selected
+type browseState struct {
+	list     *packageList
+	statuses map[string]remotePackageStatus
+	query    string
+	packages []packageListPackage
+	selected int
+	// offset is the first package shown, when they don't all fit
+	offset int
+}
+
+// cmdBrowse shows the registry in a list, with the details of the selected
+// package next to it. Typing searches as you type, and packages are installed,
+// updated, or uninstalled with a key.
+func cmdBrowse(c *cli.Context) error {
+	if !isatty.IsTerminal(os.Stdin.Fd()) || !isatty.IsTerminal(os.Stdout.Fd()) {
+		return newError(errUsage, "browse requires an interactive terminal, use \"%s search\" instead", self())
+	}
+
+	list, err := fetchPackageList(fetchOptions{})
+	if err != nil {
+		return registryError(err)
+	}
+
+	restore, err := makeRawTerminal()
+	if err != nil {
+		return cli.NewExitError(color.RedString(err.Error()), 1)
+	}
+	// restore is replaced each time the terminal is made raw again
+	defer func() {
+		restore()
+	}()
+
+	state := &browseState{list: list}
+	state.refreshStatuses()
+	state.filter()
+
+	buf := make([]byte, 8)
+	for {
+		renderBrowse(state)
+
+		n, err := os.Stdin.Read(buf)
+		if err != nil {
+			return nil
+		}
+		key := string(buf[:n])
+
+		switch {
+		case key == "\033[A":
+			state.move(-1)
+		case key == "\033[B":
+			state.move(1)
+		case key == "\033[5~":
+			state.move(-getBrowseListHeight())
+		case key == "\033[6~":
+			state.move(getBrowseListHeight())
+		case (n == 1 && buf[0] == keyEscape) || buf[0] == keyCtrlC:
+			restore()
+			fmt.Fprint(akamai.App.Writer, "\033[H\033[2J")
+			return nil
+		case buf[0] == keyEnter || buf[0] == keyCtrlD:
+			pkg, ok := state.current()
+			if !ok {
+				continue
+			}
+
+			restore()
+			fmt.Fprint(akamai.App.Writer, "\033[H\033[2J")
+			if buf[0] == keyCtrlD {
+				err = browseUninstall(c, pkg, state.statuses[pkg.Name])
+			} else {
+				err = browseInstallOrUpdate(c, pkg, state.statuses[pkg.Name])
+			}
+			if err != nil && err.Error() != "" {
+				fmt.Fprintln(akamai.App.ErrWriter, err.Error())
+			}
+
+			fmt.Fprint(akamai.App.Writer, "\nPress enter to return to the package list")
+			readAnswer()
+
+			rearmed, rawErr := makeRawTerminal()
+			if rawErr != nil {
+				return cli.NewExitError(color.RedString(rawErr.Error()), 1)
+			}
+			restore = rearmed
+			state.refreshStatuses()
+		case buf[0] == keyBackspace || buf[0] == keyDelete:
+			if len(state.query) > 0 {
+				state.query = state.query[:len(state.query)-1]
+				state.filter()
+			}
+		case n == 1 && buf[0] >= ' ' && buf[0] < keyDelete:
+			state.query += string(buf[0])
+			state.filter()
+		}
+	}
+}
+
+// browseInstallOrUpdate installs pkg, or updates it if it is installed, the
+// same way "akamai install" and "akamai update" would
+func browseInstallOrUpdate(c *cli.Context, pkg packageListPackage, status remotePackageStatus) error {
+	if status.Status == packageStatusNotInstalled {
+		return withOperationLock("install", func(*cli.Context) error {
+			oldCmds := getCommands()
+			if err := installTarget(pkg.getInstallTarget(), installOptions{}.forPackage(pkg)); err != nil {
+				return err
+			}
+			packageListDiff(oldCmds)
+			return nil
+		})(c)
+	}
+
+	if len(pkg.Commands) == 0 {
+		return cli.NewExitError(color.RedString("Package \"%s\" has no commands to update it by", pkg.Name), 1)
+	}
+
+	return withOperationLock("update", func(*cli.Context) error {
+		_, err := updatePackage(pkg.Commands[0].Name, installOptions{changelog: true, confirm: true})
+		return err
+	})(c)
+}
+
+// browseUninstall uninstalls pkg, after asking
+func browseUninstall(c *cli.Context, pkg packageListPackage, status remotePackageStatus) error {
+	if status.Status == packageStatusNotInstalled || len(pkg.Commands) == 0 {
+		printInfo(akamai.App.Writer, "%s is not installed", pkg.Name)
+		return nil
+	}
+
+	if !promptYesNo(fmt.Sprintf("Uninstall %s?", pkg.Name), false) {
+		return nil
+	}
+
+	return withOperationLock("uninstall", func(*cli.Context) error {
+		return uninstallPackage(pkg.Commands[0].Name, false)
+	})(c)
+}
+
+// refreshStatuses finds which packages are installed, and which have updates
+func (state *browseState) refreshStatuses() {
+	var installed []commandPackage
+	for _, dir := range getPackageDirs() {
+		if cmdPackage, err := readPackage(dir); err == nil {
+			installed = append(installed, cmdPackage)
+		}
+	}
+
+	state.statuses = make(map[string]remotePackageStatus)
+	for _, status := range getRemotePackageStatuses(state.list, installed) {
+		state.statuses[status.Name] = status
+	}
+}
+
+// filter lists the packages matching the query, best matches first, or every
+// package by name without a query
+func (state *browseState) filter() {
+	state.packages = nil
+	state.selected = 0
+	state.offset = 0
+
+	if strings.TrimSpace(state.query) == "" {
+		state.packages = append(state.packages, state.list.Packages...)
+		sort.SliceStable(state.packages, func(i, j int) bool {
+			return state.packages[i].Name < state.packages[j].Name
+		})
+		return
+	}
+
+	// Search results have their commands trimmed to those that matched
+	for _, result := range watchSearchResults(state.query, state.list, searchOptions{}) {
+		if pkg, ok := state.list.findPackage(result.Package.Name); ok {
+			state.packages = append(state.packages, pkg)
+		}
+	}
+}
+
+func (state *browseState) current() (packageListPackage, bool) {
+	if state.selected < 0 || state.selected >= len(state.packages) {
+		return packageListPackage{}, false
+	}
+
+	return state.packages[state.selected], true
+}
+
+// move moves the selection by delta, scrolling to keep it in view
+func (state *browseState) move(delta int) {
+	state.selected += delta
+	if state.selected >= len(state.packages) {
+		state.selected = len(state.packages) - 1
+	}
+	if state.selected < 0 {
+		state.selected = 0
+	}
+
+	height := getBrowseListHeight()
+	if state.selected < state.offset {
+		state.offset = state.selected
+	} else if state.selected >= state.offset+height {
+		state.offset = state.selected - height + 1
+	}
+}
+
+func getBrowseSize() (int, int) {
+	rows, cols, err := getTerminalSize()
+	if err != nil || rows < 8 || cols < 40 {
+		return 24, 80
+	}
+
+	return rows, cols
+}
+
+// getBrowseListHeight returns how many packages fit, below the search line and
+// above the key help
+func getBrowseListHeight() int {
+	rows, _ := getBrowseSize()
+	return rows - 4
+}
+
+func renderBrowse(state *browseState) {
+	rows, cols := getBrowseSize()
+	height := rows - 4
+
+	listWidth := cols * 2 / 5
+	if listWidth > 40 {
+		listWidth = 40
+	}
+	detailWidth := cols - listWidth - 3
+
+	var details []string
+	if pkg, ok := state.current(); ok {
+		details = getBrowseDetails(pkg, state.statuses[pkg.Name], detailWidth)
+	}
+
+	// In raw mode, output needs explicit carriage returns
+	fmt.Fprint(akamai.App.Writer, "\033[H\033[2J")
+	fmt.Fprintf(akamai.App.Writer, "%s %s\r\n", color.YellowString("Search:"), state.query)
+	fmt.Fprintf(akamai.App.Writer, "%s\r\n", color.New(color.Faint).Sprintf("%d package(s)", len(state.packages)))
+
+	for row := 0; row < height; row++ {
+		line := strings.Repeat(" ", listWidth)
+		if i := state.offset + row; i < len(state.packages) {
+			pkg := state.packages[i]
+			line = fitWidth(getBrowseMarker(state.statuses[pkg.Name])+" "+pkg.Name, listWidth)
+			if i == state.selected {
+				line = color.New(color.FgBlack, color.BgGreen).Sprint(line)
+			}
+		}
+
+		detail := ""
+		if row < len(details) {
+			detail = fitWidth(details[row], detailWidth)
+			if row == 0 {
+				detail = color.New(color.Bold).Sprint(detail)
+			}
+		}
+
+		fmt.Fprintf(akamai.App.Writer, "%s | %s\r\n", line, detail)
+	}
+
+	footer := "type to search, up/down to select, enter to install or update, ctrl-d to uninstall, esc to exit"
+	fmt.Fprint(akamai.App.Writer, "\r\n"+color.New(color.Faint).Sprint(fitWidth(footer, cols-1)))
+}
+
+// getBrowseMarker returns the marker of a package in the list: * when it is
+// installed, ^ when it has an update, ! when it is deprecated
+func getBrowseMarker(status remotePackageStatus) string {
+	switch {
+	case status.Status == packageStatusUpdateAvailable:
+		return "^"
+	case status.Status == packageStatusInstalled:
+		return "*"
+	case status.Deprecated:
+		return "!"
+	}
+
+	return " "
+}
+
+// getBrowseDetails returns the lines of the detail pane of pkg, wrapped to width
+func getBrowseDetails(pkg packageListPackage, status remotePackageStatus, width int) []string {
+	title := pkg.Title
+	if title == "" {
+		title = pkg.Name
+	}
+	lines := []string{title, ""}
+
+	add := func(label string, value string) {
+		if value != "" {
+			lines = append(lines, wrapText(label+": "+value, width)...)
+		}
+	}
+
+	add("Name", pkg.Name)
+	add("Version", pkg.Version)
+	if status.Status != "" {
+		state := status.Status
+		if status.Installed != "" && status.Status != packageStatusNotInstalled {
+			state += " (" + status.Installed + ")"
+		}
+		add("Status", state)
+	}
+	if pkg.Deprecated {
+		notice := "yes"
+		if pkg.Replacement != "" {
+			notice += ", use " + pkg.Replacement + " instead"
+		}
+		add("Deprecated", notice)
+	}
+	add("Updated", pkg.Updated)
+	add("Requires", formatRequirements(pkg.Requirements))
+	add("Tags", strings.Join(pkg.Tags, ", "))
+	add("URL", pkg.URL)
+
+	if len(pkg.Commands) > 0 {
+		lines = append(lines, "", "Commands:")
+		for _, command := range pkg.Commands {
+			lines = append(lines, wrapText("  "+command.Name+": "+command.Description, width)...)
+		}
+	}
+
+	return lines
+}
+
+// formatRequirements lists the language runtimes a package needs
+func formatRequirements(requirements packageRequirements) string {
+	var runtimes []string
+	for _, requirement := range []struct {
+		name    string
+		version string
+	}{
+		{"go", requirements.Go},
+		{"node", requirements.Node},
+		{"php", requirements.Php},
+		{"python", requirements.Python},
+		{"ruby", requirements.Ruby},
+	} {
+		switch requirement.version {
+		case "":
+		case "*":
+			runtimes = append(runtimes, requirement.name)
+		default:
+			runtimes = append(runtimes, requirement.name+" "+requirement.version)
+		}
+	}
+
+	return strings.Join(runtimes, ", ")
+}
+
+// wrapText wraps text at spaces to lines of at most width characters. Lines
+// keep the indent of text, continuation lines are indented two more spaces.
+func wrapText(text string, width int) []string {
+	indent := strings.Repeat(" ", len(text)-len(strings.TrimLeft(text, " ")))
+	words := strings.Fields(text)
+	if width < 10 || len(words) == 0 {
+		return []string{text}
+	}
+
+	var lines []string
+	line := indent + words[0]
+	for _, word := range words[1:] {
+		if len([]rune(line))+1+len([]rune(word)) > width {
+			lines = append(lines, line)
+			line = indent + "  " + word
+			continue
+		}
+		line += " " + word
+	}
+
+	return append(lines, line)
+}
+
+// fitWidth pads or truncates text to exactly width characters
+func fitWidth(text string, width int) string {
+	if width <= 0 {
+		return ""
+	}
+
+	runes := []rune(text)
+	if len(runes) > width {
+		if width > 3 {
+			return string(runes[:width-3]) + "..."
+		}
+		return string(runes[:width])
+	}
+
+	return text + strings.Repeat(" ", width-len(runes))
+}
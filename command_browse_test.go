@@ -0,0 +1,97 @@
+/*
+ Copyright 2018. Akamai Technologies, Inc
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestFitWidth(t *testing.T) {
+	fitTests := []struct {
+		text   string
+		width  int
+		result string
+	}{
+		{"property", 10, "property  "},
+		{"property", 8, "property"},
+		{"property-manager", 10, "propert..."},
+		{"property", 2, "pr"},
+		{"property", 0, ""},
+	}
+
+	for _, tt := range fitTests {
+		if result := fitWidth(tt.text, tt.width); result != tt.result {
+			t.Errorf("fitWidth(%s, %d) => %q, wanted: %q", tt.text, tt.width, result, tt.result)
+		}
+	}
+}
+
+func TestWrapText(t *testing.T) {
+	wrapTests := []struct {
+		text  string
+		width int
+		lines []string
+	}{
+		{"Name: property", 40, []string{"Name: property"}},
+		{"URL: https://github.com/akamai/cli-property", 20, []string{"URL:", "  https://github.com/akamai/cli-property"}},
+		{"Tags: one, two, three, four", 16, []string{"Tags: one, two,", "  three, four"}},
+		{"  list: List the properties", 16, []string{"  list: List the", "    properties"}},
+		{"short width", 5, []string{"short width"}},
+	}
+
+	for _, tt := range wrapTests {
+		if lines := wrapText(tt.text, tt.width); !reflect.DeepEqual(lines, tt.lines) {
+			t.Errorf("wrapText(%q, %d) => %q, wanted: %q", tt.text, tt.width, lines, tt.lines)
+		}
+	}
+}
+
+func TestFormatRequirements(t *testing.T) {
+	requirementTests := []struct {
+		requirements packageRequirements
+		result       string
+	}{
+		{packageRequirements{}, ""},
+		{packageRequirements{Go: "1.9.0"}, "go 1.9.0"},
+		{packageRequirements{Node: "*", Python: "3.6"}, "node, python 3.6"},
+	}
+
+	for _, tt := range requirementTests {
+		if result := formatRequirements(tt.requirements); result != tt.result {
+			t.Errorf("formatRequirements(%+v) => %s, wanted: %s", tt.requirements, result, tt.result)
+		}
+	}
+}
+
+func TestGetBrowseMarker(t *testing.T) {
+	markerTests := []struct {
+		status remotePackageStatus
+		marker string
+	}{
+		{remotePackageStatus{Status: packageStatusNotInstalled}, " "},
+		{remotePackageStatus{Status: packageStatusInstalled}, "*"},
+		{remotePackageStatus{Status: packageStatusUpdateAvailable, Deprecated: true}, "^"},
+		{remotePackageStatus{Status: packageStatusNotInstalled, Deprecated: true}, "!"},
+	}
+
+	for _, tt := range markerTests {
+		if marker := getBrowseMarker(tt.status); marker != tt.marker {
+			t.Errorf("getBrowseMarker(%+v) => %q, wanted: %q", tt.status, marker, tt.marker)
+		}
+	}
+}
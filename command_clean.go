@@ -0,0 +1,311 @@
+/*
+ Copyright 2018. Akamai Technologies, Inc
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	akamai "github.com/akamai/cli-common-golang"
+	"github.com/fatih/color"
+	"github.com/urfave/cli"
+	"gopkg.in/src-d/go-git.v4"
+)
+
+const (
+	usagePackage     = "package"
+	usageBuildCache  = "build cache"
+	usagePackageList = "package list"
+	usageOrphan      = "orphan"
+	usageRollback    = "rollback"
+)
+
+// pendingPackageSuffixes are those of the directories packages are installed
+// or downloaded into before becoming, or replacing, the installed one, which
+// are left behind by an interrupted install, update, or repair
+var pendingPackageSuffixes = []string{stagingSuffix, ".update", ".repair"}
+
+// diskUsage is an item of "akamai clean": an installed package, or something
+// that can be removed to free disk space
+type diskUsage struct {
+	Type string `json:"type"`
+	Name string `json:"name"`
+	Path string `json:"path"`
+	Size uint64 `json:"size"`
+	// Removed is set when the item was cleaned up
+	Removed bool `json:"removed,omitempty"`
+
+	// clean frees the space, the path is removed if not set
+	clean func() error
+}
+
+func cmdClean(c *cli.Context) error {
+	all := c.Bool("all")
+	remove := map[string]bool{
+		usageBuildCache:  all || c.Bool("caches"),
+		usagePackageList: all || c.Bool("caches"),
+		usageOrphan:      all || c.Bool("orphans"),
+		usageRollback:    all || c.Bool("rollback"),
+	}
+
+	usages := getDiskUsage()
+
+	var failed int
+	for i, usage := range usages {
+		if !remove[usage.Type] {
+			continue
+		}
+
+		var err error
+		if usage.clean != nil {
+			// Only part of the space may be freed
+			err = usage.clean()
+			if after := getDirSize(usage.Path); after < usage.Size {
+				usages[i].Size = usage.Size - after
+			} else {
+				usages[i].Size = 0
+			}
+		} else {
+			err = removeAllForce(usage.Path)
+		}
+
+		if err != nil {
+			fmt.Fprintln(akamai.App.ErrWriter, color.RedString("Unable to clean up %s: %s", usage.Path, err.Error()))
+			failed++
+			continue
+		}
+
+		logInfo("cleaned up", "type", usage.Type, "path", usage.Path, "size", usages[i].Size)
+		usages[i].Removed = true
+	}
+
+	if getOutputFormat() != formatTable {
+		if err := printStructured(usages); err != nil {
+			return err
+		}
+	} else {
+		printDiskUsage(usages)
+	}
+
+	if failed > 0 {
+		return cli.NewExitError(color.RedString("%d item(s) could not be cleaned up", failed), 1)
+	}
+
+	return nil
+}
+
+func printDiskUsage(usages []diskUsage) {
+	w := tabwriter.NewWriter(akamai.App.Writer, 0, 8, 2, ' ', 0)
+	fmt.Fprintln(w, "TYPE\tNAME\tSIZE\tPATH")
+
+	var total, reclaimable, freed uint64
+	for _, usage := range usages {
+		size := formatBytes(usage.Size)
+		switch {
+		case usage.Removed:
+			freed += usage.Size
+			size = color.GreenString("%s (removed)", size)
+		case usage.Type != usagePackage:
+			reclaimable += usage.Size
+		}
+		if usage.Type == usagePackage {
+			total += usage.Size
+		}
+
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", usage.Type, usage.Name, size, usage.Path)
+	}
+	w.Flush()
+
+	fmt.Fprintln(akamai.App.Writer)
+	fmt.Fprintf(akamai.App.Writer, "Installed packages: %s\n", color.New(color.Bold).Sprint(formatBytes(total)))
+	if freed > 0 {
+		fmt.Fprintf(akamai.App.Writer, "Freed: %s\n", color.GreenString(formatBytes(freed)))
+	}
+	if reclaimable > 0 {
+		fmt.Fprintf(akamai.App.Writer, "Reclaimable: %s, run \"%s clean --all\" to free it\n", color.YellowString(formatBytes(reclaimable)), self())
+	}
+}
+
+// getDiskUsage returns the installed packages with their size, and the caches,
+// orphaned directories, and old versions kept for rollback, which can be
+// removed. Sizes of packages include their caches and history.
+func getDiskUsage() []diskUsage {
+	cliPath, err := getAkamaiCliPath()
+	if err != nil {
+		return nil
+	}
+	srcPath, _ := getAkamaiCliSrcPath()
+
+	var usages []diskUsage
+	paths, _ := filepath.Glob(filepath.Join(srcPath, "*"))
+	for _, root := range paths {
+		name := filepath.Base(root)
+		manifest, manifestErr := readManifest(name)
+
+		if isOrphanedPackageDir(root, manifest) {
+			usages = append(usages, diskUsage{Type: usageOrphan, Name: name, Path: root, Size: getDirSize(root)})
+			continue
+		}
+
+		usages = append(usages, diskUsage{Type: usagePackage, Name: name, Path: root, Size: getDirSize(root)})
+
+		for _, cache := range findBuildCaches(root) {
+			usages = append(usages, diskUsage{Type: usageBuildCache, Name: name, Path: cache, Size: getDirSize(cache)})
+		}
+
+		if manifestErr == nil && manifest.PreviousCommit != "" {
+			usages = append(usages, getRollbackUsage(root, name, manifest))
+		}
+	}
+
+	// Go packages are built with the CLI home on their GOPATH, see getPackageArtifactPaths
+	goPkg := filepath.Join(cliPath, "pkg")
+	if _, err := os.Stat(goPkg); err == nil {
+		usages = append(usages, diskUsage{Type: usageBuildCache, Name: "go", Path: goPkg, Size: getDirSize(goPkg)})
+	}
+
+	if cachePath, err := getAkamaiCliCachePath(); err == nil {
+		lists, _ := filepath.Glob(filepath.Join(cachePath, "package-list-*.json"))
+		for _, list := range lists {
+			usages = append(usages, diskUsage{Type: usagePackageList, Name: filepath.Base(list), Path: list, Size: getDirSize(list)})
+		}
+	}
+
+	// Manifests of packages whose directory was removed by hand
+	manifests, _ := filepath.Glob(filepath.Join(cliPath, "manifests", "*.json"))
+	for _, manifest := range manifests {
+		name := strings.TrimSuffix(filepath.Base(manifest), ".json")
+		if _, err := os.Stat(filepath.Join(srcPath, name)); os.IsNotExist(err) {
+			usages = append(usages, diskUsage{Type: usageOrphan, Name: name, Path: manifest, Size: getDirSize(manifest)})
+		}
+	}
+
+	return usages
+}
+
+// isOrphanedPackageDir returns whether the directory root under src is not an
+// installed package: a download left behind, or a directory without cli.json
+func isOrphanedPackageDir(root string, manifest packageManifest) bool {
+	if isPendingPackageDir(filepath.Base(root)) {
+		return true
+	}
+
+	info, err := os.Stat(root)
+	if err != nil || !info.IsDir() {
+		return true
+	}
+
+	_, err = os.Stat(filepath.Join(root, manifest.Subpath, "cli.json"))
+	return err != nil
+}
+
+// isPendingPackageDir returns whether name is that of a directory a package
+// is downloaded into before replacing the installed one
+func isPendingPackageDir(name string) bool {
+	for _, suffix := range pendingPackageSuffixes {
+		if strings.HasSuffix(name, suffix) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// findBuildCaches returns the directories in a package that only speed up
+// building or running it, and are created again when needed
+func findBuildCaches(root string) []string {
+	var caches []string
+	filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+		if err != nil || !info.IsDir() {
+			return nil
+		}
+
+		rel, _ := filepath.Rel(root, path)
+		if rel == ".git" {
+			return filepath.SkipDir
+		}
+
+		if isBuildCacheDir(filepath.ToSlash(rel)) {
+			caches = append(caches, path)
+			return filepath.SkipDir
+		}
+
+		return nil
+	})
+	sort.Strings(caches)
+
+	return caches
+}
+
+// isBuildCacheDir returns whether the directory at rel, relative to a package,
+// is a build cache: Python bytecode, or the cache of npm build tools
+func isBuildCacheDir(rel string) bool {
+	switch path := strings.Split(rel, "/"); {
+	case path[len(path)-1] == "__pycache__":
+		return true
+	case len(path) >= 2 && path[len(path)-2] == "node_modules" && path[len(path)-1] == ".cache":
+		return true
+	}
+
+	return false
+}
+
+// getRollbackUsage returns the history of the package at root, which keeps
+// the version it was updated from, sized as a whole. Cleaning it up forgets that version, so
+// the package can no longer be rolled back, and repacks the repository
+// without it.
+func getRollbackUsage(root string, name string, manifest packageManifest) diskUsage {
+	gitDir := filepath.Join(root, ".git")
+	usage := diskUsage{Type: usageRollback, Name: name, Path: gitDir, Size: getDirSize(gitDir)}
+
+	usage.clean = func() error {
+		repo, err := git.PlainOpen(root)
+		if err != nil {
+			return err
+		}
+
+		manifest.PreviousCommit = ""
+		if err := writeManifest(name, manifest); err != nil {
+			return err
+		}
+
+		if err := repo.Prune(git.PruneOptions{Handler: repo.DeleteObject}); err != nil {
+			return err
+		}
+
+		return repo.RepackObjects(&git.RepackConfig{})
+	}
+
+	return usage
+}
+
+// getDirSize returns the size of the files in path, or of path if it is a file
+func getDirSize(path string) uint64 {
+	var size uint64
+	filepath.Walk(path, func(_ string, info os.FileInfo, err error) error {
+		if err == nil && info.Mode().IsRegular() {
+			size += uint64(info.Size())
+		}
+		return nil
+	})
+
+	return size
+}
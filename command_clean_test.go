@@ -0,0 +1,60 @@
+/*
+ Copyright 2018. Akamai Technologies, Inc
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package main
+
+import "testing"
+
+func TestIsBuildCacheDir(t *testing.T) {
+	cacheTests := []struct {
+		rel   string
+		cache bool
+	}{
+		{"__pycache__", true},
+		{"akamai/edgegrid/__pycache__", true},
+		{"node_modules/.cache", true},
+		{"node_modules/babel-loader/node_modules/.cache", true},
+		{"node_modules", false},
+		{".cache", false},
+		{"bin", false},
+		{".", false},
+	}
+
+	for _, tt := range cacheTests {
+		if isBuildCacheDir(tt.rel) != tt.cache {
+			t.Errorf("isBuildCacheDir(%s) => %t, wanted: %t", tt.rel, !tt.cache, tt.cache)
+		}
+	}
+}
+
+func TestIsPendingPackageDir(t *testing.T) {
+	pendingTests := []struct {
+		name    string
+		pending bool
+	}{
+		{"cli-property.update", true},
+		{"cli-property.repair", true},
+		{"cli-property.install", true},
+		{"cli-property", false},
+		{"cli-update", false},
+	}
+
+	for _, tt := range pendingTests {
+		if isPendingPackageDir(tt.name) != tt.pending {
+			t.Errorf("isPendingPackageDir(%s) => %t, wanted: %t", tt.name, !tt.pending, tt.pending)
+		}
+	}
+}
@@ -0,0 +1,111 @@
+/*
+ Copyright 2018. Akamai Technologies, Inc
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	akamai "github.com/akamai/cli-common-golang"
+	"github.com/fatih/color"
+	"github.com/kardianos/osext"
+	"github.com/urfave/cli"
+)
+
+// Completion scripts ask the CLI itself for candidates, by re-running the words
+// typed so far with --generate-auto-complete. That way they cover installed
+// packages, and the subcommands and flags of packages that support auto-complete.
+
+func getBashCompletionScript() string {
+	return `_akamai_cli_bash_autocomplete() {
+    local cur opts base
+    COMPREPLY=()
+    cur="${COMP_WORDS[COMP_CWORD]}"
+    opts=$( ${COMP_WORDS[@]:0:$COMP_CWORD} --generate-auto-complete )
+    COMPREPLY=( $(compgen -W "${opts}" -- ${cur}) )
+    return 0
+}
+
+complete -F _akamai_cli_bash_autocomplete ` + self()
+}
+
+func getZshCompletionScript() string {
+	return `autoload -U compinit && compinit
+autoload -U bashcompinit && bashcompinit
+` + getBashCompletionScript()
+}
+
+func getFishCompletionScript() string {
+	return `function __akamai_cli_complete
+    set -l words (commandline -opc)
+    eval (string escape -- $words) --generate-auto-complete 2>/dev/null
+end
+
+complete -c ` + self() + ` -f -a '(__akamai_cli_complete)'`
+}
+
+func getPowershellCompletionScript() string {
+	return `Register-ArgumentCompleter -Native -CommandName '` + strings.TrimSuffix(self(), ".exe") + `' -ScriptBlock {
+    param($wordToComplete, $commandAst, $cursorPosition)
+
+    $words = @($commandAst.CommandElements | ForEach-Object { $_.ToString() })
+    if ($wordToComplete -ne '') {
+        $words = $words[0..($words.Count - 2)]
+    }
+
+    $exe = $words[0]
+    $rest = @()
+    if ($words.Count -gt 1) {
+        $rest = $words[1..($words.Count - 1)]
+    }
+
+    & $exe @rest --generate-auto-complete 2>$null | Where-Object { $_ -like "$wordToComplete*" } | ForEach-Object {
+        [System.Management.Automation.CompletionResult]::new($_, $_, 'ParameterValue', $_)
+    }
+}`
+}
+
+func cmdCompletion(c *cli.Context) error {
+	cmd, err := osext.Executable()
+	if err != nil {
+		cmd = self()
+	}
+
+	shell := c.Args().First()
+	var comment, script string
+	switch shell {
+	case "bash":
+		comment = "# To enable bash auto-completion, run: eval \"$(" + cmd + " completion bash)\"\n# We recommend adding this to your .bashrc or .bash_profile file"
+		script = getBashCompletionScript()
+	case "zsh":
+		comment = "set -k\n# To enable zsh auto-completion, run: eval \"$(" + cmd + " completion zsh)\"\n# We recommend adding this to your .zshrc file"
+		script = getZshCompletionScript()
+	case "fish":
+		comment = "# To enable fish auto-completion, run: " + cmd + " completion fish | source\n# We recommend saving it to ~/.config/fish/completions/" + self() + ".fish"
+		script = getFishCompletionScript()
+	case "powershell":
+		comment = "# To enable PowerShell auto-completion, run: " + cmd + " completion powershell | Out-String | Invoke-Expression\n# We recommend adding this to your $PROFILE"
+		script = getPowershellCompletionScript()
+	default:
+		return cli.NewExitError(color.RedString("You must specify a shell: bash, zsh, fish, or powershell"), 1)
+	}
+
+	fmt.Fprintln(akamai.App.Writer, comment)
+	fmt.Fprintln(akamai.App.Writer, script)
+
+	return nil
+}
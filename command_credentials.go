@@ -0,0 +1,181 @@
+/*
+ Copyright 2018. Akamai Technologies, Inc
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package main
+
+import (
+	"fmt"
+	"os"
+
+	akamai "github.com/akamai/cli-common-golang"
+	"github.com/fatih/color"
+	"github.com/go-ini/ini"
+	"github.com/urfave/cli"
+)
+
+// cmdCredentialsStore moves the client secret of a .edgerc section into the
+// OS keychain, leaving a placeholder in its place
+func cmdCredentialsStore(c *cli.Context) error {
+	path, section := getCredentialsTarget(c)
+
+	edgerc, err := ini.Load(path)
+	if err != nil {
+		return cli.NewExitError(color.RedString("Unable to read %s: %s", path, err.Error()), 1)
+	}
+
+	key, err := getClientSecretKey(edgerc, path, section)
+	if err != nil {
+		return err
+	}
+
+	if key.String() == keychainPlaceholder {
+		fmt.Fprintf(akamai.App.Writer, "The client secret of [%s] is already stored in the keychain\n", section)
+		return nil
+	}
+
+	if err := keychainSet(keychainService, getKeychainAccount(path, section), key.String()); err != nil {
+		return cli.NewExitError(color.RedString("Unable to store the client secret in the keychain: %s", err.Error()), 1)
+	}
+
+	key.SetValue(keychainPlaceholder)
+	if err := saveEdgerc(edgerc, path); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(akamai.App.Writer, "The client secret of [%s] is now stored in the keychain, and removed from %s\n", section, path)
+	return nil
+}
+
+// cmdCredentialsRestore puts a client secret stored in the keychain back in .edgerc
+func cmdCredentialsRestore(c *cli.Context) error {
+	path, section := getCredentialsTarget(c)
+
+	edgerc, err := ini.Load(path)
+	if err != nil {
+		return cli.NewExitError(color.RedString("Unable to read %s: %s", path, err.Error()), 1)
+	}
+
+	key, err := getClientSecretKey(edgerc, path, section)
+	if err != nil {
+		return err
+	}
+
+	if key.String() != keychainPlaceholder {
+		return cli.NewExitError(color.RedString("The client secret of [%s] is not stored in the keychain", section), 1)
+	}
+
+	account := getKeychainAccount(path, section)
+	secret, err := keychainGet(keychainService, account)
+	if err != nil {
+		return cli.NewExitError(color.RedString("Unable to read the client secret from the keychain: %s", err.Error()), 1)
+	}
+
+	key.SetValue(secret)
+	if err := saveEdgerc(edgerc, path); err != nil {
+		return err
+	}
+
+	if err := keychainDelete(keychainService, account); err != nil {
+		fmt.Fprintln(akamai.App.ErrWriter, color.YellowString("Warning: unable to remove the client secret from the keychain: %s", err.Error()))
+	}
+
+	return nil
+}
+
+// cmdCredentialsList shows which sections of .edgerc keep their client secret in the keychain
+func cmdCredentialsList(c *cli.Context) error {
+	applyProfileEnv()
+	path := getEdgercPath([]string{"--edgerc", c.String("edgerc")})
+
+	edgerc, err := ini.Load(path)
+	if err != nil {
+		return cli.NewExitError(color.RedString("Unable to read %s: %s", path, err.Error()), 1)
+	}
+
+	for _, section := range edgerc.Sections() {
+		if section.Name() == ini.DEFAULT_SECTION {
+			continue
+		}
+
+		storage := "plaintext in " + path
+		switch section.Key("client_secret").String() {
+		case keychainPlaceholder:
+			storage = color.GreenString("keychain")
+			if _, err := keychainGet(keychainService, getKeychainAccount(path, section.Name())); err != nil {
+				storage = color.RedString("keychain (%s)", err.Error())
+			}
+		case "":
+			storage = "none"
+		}
+
+		fmt.Fprintf(akamai.App.Writer, "%s: %s\n", color.New(color.Bold).Sprint(section.Name()), storage)
+	}
+
+	return nil
+}
+
+func getCredentialsTarget(c *cli.Context) (string, string) {
+	// Default to the credentials of the current profile
+	applyProfileEnv()
+
+	path := getEdgercPath([]string{"--edgerc", c.String("edgerc")})
+
+	section := c.String("section")
+	if section == "" {
+		section = os.Getenv(edgercSectionEnv)
+	}
+	if section == "" {
+		section = "default"
+	}
+
+	return path, section
+}
+
+func getClientSecretKey(edgerc *ini.File, path string, section string) (*ini.Key, error) {
+	s, err := edgerc.GetSection(section)
+	if err != nil {
+		return nil, cli.NewExitError(color.RedString("Section [%s] not found in %s", section, path), 1)
+	}
+
+	if !s.HasKey("client_secret") || s.Key("client_secret").String() == "" {
+		return nil, cli.NewExitError(color.RedString("Section [%s] of %s has no client_secret", section, path), 1)
+	}
+
+	return s.Key("client_secret"), nil
+}
+
+// saveEdgerc writes .edgerc back, readable only by its owner
+func saveEdgerc(edgerc *ini.File, path string) error {
+	tmpPath := path + ".tmp"
+	file, err := os.OpenFile(tmpPath, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0600)
+	if err != nil {
+		return cli.NewExitError(color.RedString("Unable to write %s: %s", path, err.Error()), 1)
+	}
+
+	_, err = edgerc.WriteTo(file)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(tmpPath, path)
+	}
+	if err != nil {
+		os.Remove(tmpPath)
+		return cli.NewExitError(color.RedString("Unable to write %s: %s", path, err.Error()), 1)
+	}
+
+	return nil
+}
@@ -0,0 +1,419 @@
+/*
+ Copyright 2018. Akamai Technologies, Inc
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	akamai "github.com/akamai/cli-common-golang"
+	"github.com/fatih/color"
+	"github.com/urfave/cli"
+)
+
+const (
+	docsFormatMarkdown = "markdown"
+	docsFormatMan      = "man"
+)
+
+// commandDoc is the reference of a built-in or installed command
+type commandDoc struct {
+	Name        string
+	Package     string
+	Version     string
+	Description string
+	Arguments   string
+	Aliases     []string
+	Flags       []flagDoc
+	Docs        string
+	Subcommands []commandDoc
+	// Help is the help output of an installed command
+	Help string
+}
+
+type flagDoc struct {
+	Name  string
+	Usage string
+}
+
+// cmdDocs writes a markdown or man page reference of the built-in commands and
+// the installed packages, or of one package or command, to stdout or a
+// directory with a page per command
+func cmdDocs(c *cli.Context) error {
+	format := c.String("format")
+	if format != docsFormatMarkdown && format != docsFormatMan {
+		return newError(errUsage, "Invalid format \"%s\", must be %s or %s", format, docsFormatMarkdown, docsFormatMan)
+	}
+
+	docs, err := getCommandDocs(c.Args().First())
+	if err != nil {
+		return err
+	}
+
+	output := c.String("output")
+	if output == "" {
+		if format == docsFormatMan && len(docs) > 1 {
+			return newError(errUsage, "Man pages are written one per command, pass --output <dir>")
+		}
+
+		for i, doc := range docs {
+			if i > 0 {
+				fmt.Fprintln(akamai.App.Writer)
+			}
+			fmt.Fprint(akamai.App.Writer, renderCommandDoc(doc, format))
+		}
+
+		return nil
+	}
+
+	if err := os.MkdirAll(output, 0755); err != nil {
+		return cli.NewExitError(color.RedString("Unable to create %s: %s", output, err.Error()), 1)
+	}
+
+	pages := map[string]string{getDocsIndexName(format): renderDocsIndex(docs, format)}
+	for _, doc := range docs {
+		pages[getCommandDocName(doc.Name, format)] = renderCommandDoc(doc, format)
+	}
+
+	for name, page := range pages {
+		if err := ioutil.WriteFile(filepath.Join(output, name), []byte(page), 0644); err != nil {
+			return cli.NewExitError(color.RedString("Unable to write %s: %s", name, err.Error()), 1)
+		}
+	}
+
+	printInfo(akamai.App.Writer, "Wrote %d page(s) to %s", len(pages), output)
+	return nil
+}
+
+// getCommandDocs returns the reference of the built-in commands and installed
+// packages, or of the package or command name
+func getCommandDocs(name string) ([]commandDoc, error) {
+	var docs []commandDoc
+	for _, cmdPackage := range getBuiltinCommands() {
+		for _, command := range cmdPackage.Commands {
+			if name == "" || strings.EqualFold(name, command.Name) {
+				docs = append(docs, getBuiltinCommandDoc(command))
+			}
+		}
+	}
+
+	if name != "" && len(docs) > 0 {
+		return docs, nil
+	}
+
+	dirs := getPackageDirs()
+	if name != "" {
+		pkg, err := findInstalledPackage(name, getInstalledPackages())
+		if err != nil {
+			return nil, newError(errPackageNotFound, "%s", err.Error())
+		}
+
+		dirs = nil
+		for _, dir := range getAllPackageDirs() {
+			if filepath.Base(getPackageRoot(dir)) == pkg.Name {
+				dirs = append(dirs, dir)
+			}
+		}
+	}
+
+	for _, dir := range dirs {
+		cmdPackage, err := readPackage(dir)
+		if err != nil {
+			continue
+		}
+
+		for _, command := range cmdPackage.Commands {
+			docs = append(docs, getInstalledCommandDoc(command, dir))
+		}
+	}
+
+	sort.SliceStable(docs, func(i, j int) bool {
+		return docs[i].Name < docs[j].Name
+	})
+
+	return docs, nil
+}
+
+func getBuiltinCommandDoc(command Command) commandDoc {
+	doc := commandDoc{
+		Name:        command.Name,
+		Version:     VERSION,
+		Description: command.Description,
+		Arguments:   command.Arguments,
+		Aliases:     command.Aliases,
+		Flags:       getFlagDocs(command.Flags),
+		Docs:        command.Docs,
+	}
+
+	for _, subcommand := range command.Subcommands {
+		doc.Subcommands = append(doc.Subcommands, commandDoc{
+			Name:        command.Name + " " + subcommand.Name,
+			Description: subcommand.Usage,
+			Arguments:   subcommand.ArgsUsage,
+			Aliases:     subcommand.Aliases,
+			Flags:       getFlagDocs(subcommand.Flags),
+			Docs:        subcommand.Description,
+		})
+	}
+
+	return doc
+}
+
+// getInstalledCommandDoc returns the reference of an installed command, from
+// its cli.json and its help output
+func getInstalledCommandDoc(command Command, dir string) commandDoc {
+	name := strings.ToLower(command.Name)
+	help := strings.TrimSpace(stripColor(getInstalledHelpOutput(name, dir)))
+
+	doc := commandDoc{
+		Name:        name,
+		Package:     filepath.Base(getPackageRoot(dir)),
+		Version:     command.Version,
+		Description: command.Description,
+		Arguments:   command.Arguments,
+		Aliases:     command.Aliases,
+		Docs:        command.Usage,
+		Help:        help,
+	}
+
+	description, flags := parseCommandHelp(help)
+	if doc.Description == "" {
+		doc.Description = description
+	}
+	for _, flag := range flags {
+		doc.Flags = append(doc.Flags, flagDoc{Name: flag})
+	}
+
+	return doc
+}
+
+// getFlagDocs returns the names and usage of flags, as shown by --help
+func getFlagDocs(flags []cli.Flag) []flagDoc {
+	var docs []flagDoc
+	for _, flag := range flags {
+		parts := strings.SplitN(flag.String(), "\t", 2)
+		doc := flagDoc{Name: strings.TrimSpace(parts[0])}
+		if len(parts) == 2 {
+			doc.Usage = strings.TrimSpace(parts[1])
+		}
+		docs = append(docs, doc)
+	}
+
+	return docs
+}
+
+func getCommandDocName(name string, format string) string {
+	name = "akamai-" + strings.Replace(name, " ", "-", -1)
+	if format == docsFormatMan {
+		return name + ".1"
+	}
+
+	return name + ".md"
+}
+
+func getDocsIndexName(format string) string {
+	if format == docsFormatMan {
+		return "akamai.1"
+	}
+
+	return "index.md"
+}
+
+func renderCommandDoc(doc commandDoc, format string) string {
+	if format == docsFormatMan {
+		return renderManPage(doc)
+	}
+
+	return renderMarkdownDoc(doc, "#")
+}
+
+func renderDocsIndex(docs []commandDoc, format string) string {
+	var b bytes.Buffer
+	if format == docsFormatMan {
+		fmt.Fprintf(&b, ".TH AKAMAI 1 \"\" \"Akamai CLI %s\" \"Akamai CLI Manual\"\n", VERSION)
+		b.WriteString(".SH NAME\nakamai \\- Akamai CLI\n.SH SYNOPSIS\n.B akamai\n[global flags] command [command flags] [arguments...]\n.SH COMMANDS\n")
+		for _, doc := range docs {
+			fmt.Fprintf(&b, ".TP\n.BR %s (1)\n%s\n", escapeRoff(strings.TrimSuffix(getCommandDocName(doc.Name, format), ".1")), escapeRoff(doc.Description))
+		}
+		return b.String()
+	}
+
+	fmt.Fprintf(&b, "# Akamai CLI %s\n\n", VERSION)
+	for _, doc := range docs {
+		line := fmt.Sprintf("- [`akamai %s`](%s)", doc.Name, getCommandDocName(doc.Name, format))
+		if doc.Description != "" {
+			line += ": " + doc.Description
+		}
+		if doc.Package != "" {
+			line += fmt.Sprintf(" (%s)", doc.Package)
+		}
+		b.WriteString(line + "\n")
+	}
+
+	return b.String()
+}
+
+// renderMarkdownDoc renders a command, with heading as the level of its title
+func renderMarkdownDoc(doc commandDoc, heading string) string {
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "%s akamai %s\n\n", heading, doc.Name)
+
+	if doc.Description != "" {
+		b.WriteString(doc.Description + "\n\n")
+	}
+
+	if doc.Package != "" {
+		fmt.Fprintf(&b, "Package: `%s`", doc.Package)
+		if doc.Version != "" {
+			fmt.Fprintf(&b, ", version %s", doc.Version)
+		}
+		b.WriteString("\n\n")
+	}
+
+	usage := "akamai " + doc.Name
+	if doc.Arguments != "" {
+		usage += " " + doc.Arguments
+	}
+	fmt.Fprintf(&b, "```\n%s\n```\n\n", usage)
+
+	if len(doc.Aliases) > 0 {
+		fmt.Fprintf(&b, "Aliases: `%s`\n\n", strings.Join(doc.Aliases, "`, `"))
+	}
+
+	if doc.Docs != "" {
+		b.WriteString(strings.TrimSpace(doc.Docs) + "\n\n")
+	}
+
+	if len(doc.Flags) > 0 {
+		fmt.Fprintf(&b, "%s# Flags\n\n", heading)
+		for _, flag := range doc.Flags {
+			line := fmt.Sprintf("- `%s`", flag.Name)
+			if flag.Usage != "" {
+				line += ": " + flag.Usage
+			}
+			b.WriteString(line + "\n")
+		}
+		b.WriteString("\n")
+	}
+
+	for _, subcommand := range doc.Subcommands {
+		b.WriteString(renderMarkdownDoc(subcommand, heading+"#"))
+	}
+
+	if doc.Help != "" {
+		fmt.Fprintf(&b, "%s# Help\n\n```\n%s\n```\n\n", heading, doc.Help)
+	}
+
+	return b.String()
+}
+
+func renderManPage(doc commandDoc) string {
+	var b bytes.Buffer
+	title := strings.ToUpper(strings.TrimSuffix(getCommandDocName(doc.Name, docsFormatMan), ".1"))
+	fmt.Fprintf(&b, ".TH %s 1 \"\" \"Akamai CLI %s\" \"Akamai CLI Manual\"\n", title, VERSION)
+
+	fmt.Fprintf(&b, ".SH NAME\nakamai\\-%s", escapeRoff(strings.Replace(doc.Name, " ", "-", -1)))
+	if doc.Description != "" {
+		fmt.Fprintf(&b, " \\- %s", escapeRoff(doc.Description))
+	}
+	b.WriteString("\n")
+
+	renderManSynopsis(&b, doc)
+
+	if doc.Docs != "" || doc.Package != "" || len(doc.Aliases) > 0 {
+		b.WriteString(".SH DESCRIPTION\n")
+		if doc.Docs != "" {
+			b.WriteString(escapeRoff(strings.TrimSpace(doc.Docs)) + "\n")
+		}
+		if doc.Package != "" {
+			fmt.Fprintf(&b, ".PP\nProvided by the %s package", escapeRoff(doc.Package))
+			if doc.Version != "" {
+				fmt.Fprintf(&b, ", version %s", escapeRoff(doc.Version))
+			}
+			b.WriteString(".\n")
+		}
+		if len(doc.Aliases) > 0 {
+			fmt.Fprintf(&b, ".PP\nAliases: %s\n", escapeRoff(strings.Join(doc.Aliases, ", ")))
+		}
+	}
+
+	renderManFlags(&b, doc.Flags)
+
+	if len(doc.Subcommands) > 0 {
+		b.WriteString(".SH COMMANDS\n")
+		for _, subcommand := range doc.Subcommands {
+			usage := "akamai " + subcommand.Name
+			if subcommand.Arguments != "" {
+				usage += " " + subcommand.Arguments
+			}
+			fmt.Fprintf(&b, ".TP\n.B %s\n%s\n", escapeRoff(usage), escapeRoff(subcommand.Description))
+			for _, flag := range subcommand.Flags {
+				fmt.Fprintf(&b, ".RS\n.TP\n.B %s\n%s\n.RE\n", escapeRoff(flag.Name), escapeRoff(flag.Usage))
+			}
+		}
+	}
+
+	if doc.Help != "" {
+		fmt.Fprintf(&b, ".SH HELP\n.nf\n%s\n.fi\n", escapeRoff(doc.Help))
+	}
+
+	b.WriteString(".SH SEE ALSO\n.BR akamai (1)\n")
+
+	return b.String()
+}
+
+func renderManSynopsis(b *bytes.Buffer, doc commandDoc) {
+	fmt.Fprintf(b, ".SH SYNOPSIS\n.B akamai %s\n", escapeRoff(doc.Name))
+	if doc.Arguments != "" {
+		b.WriteString(escapeRoff(doc.Arguments) + "\n")
+	}
+}
+
+func renderManFlags(b *bytes.Buffer, flags []flagDoc) {
+	if len(flags) == 0 {
+		return
+	}
+
+	b.WriteString(".SH OPTIONS\n")
+	for _, flag := range flags {
+		fmt.Fprintf(b, ".TP\n.B %s\n", escapeRoff(flag.Name))
+		if flag.Usage != "" {
+			b.WriteString(escapeRoff(flag.Usage) + "\n")
+		}
+	}
+}
+
+// escapeRoff escapes text for a man page: backslashes and dashes, and dots and
+// quotes that would start a request at the start of a line
+func escapeRoff(text string) string {
+	text = strings.Replace(text, "\\", "\\e", -1)
+	text = strings.Replace(text, "-", "\\-", -1)
+
+	lines := strings.Split(text, "\n")
+	for i, line := range lines {
+		if strings.HasPrefix(line, ".") || strings.HasPrefix(line, "'") {
+			lines[i] = "\\&" + line
+		}
+	}
+
+	return strings.Join(lines, "\n")
+}
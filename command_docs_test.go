@@ -0,0 +1,84 @@
+/*
+ Copyright 2018. Akamai Technologies, Inc
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestEscapeRoff(t *testing.T) {
+	escapeTests := []struct {
+		text    string
+		escaped string
+	}{
+		{"plain text", "plain text"},
+		{"--force", "\\-\\-force"},
+		{"C:\\path", "C:\\epath"},
+		{".hidden\n'quoted", "\\&.hidden\n\\&'quoted"},
+		{"ends with a dot.", "ends with a dot."},
+	}
+
+	for _, tt := range escapeTests {
+		if escaped := escapeRoff(tt.text); escaped != tt.escaped {
+			t.Errorf("escapeRoff(%q) => %q, wanted: %q", tt.text, escaped, tt.escaped)
+		}
+	}
+}
+
+func TestGetCommandDocName(t *testing.T) {
+	docNameTests := []struct {
+		name    string
+		format  string
+		docName string
+	}{
+		{"install", docsFormatMarkdown, "akamai-install.md"},
+		{"install", docsFormatMan, "akamai-install.1"},
+		{"workspace sync", docsFormatMarkdown, "akamai-workspace-sync.md"},
+	}
+
+	for _, tt := range docNameTests {
+		if docName := getCommandDocName(tt.name, tt.format); docName != tt.docName {
+			t.Errorf("getCommandDocName(%s, %s) => %s, wanted: %s", tt.name, tt.format, docName, tt.docName)
+		}
+	}
+}
+
+func TestRenderMarkdownDoc(t *testing.T) {
+	doc := commandDoc{
+		Name:        "property",
+		Package:     "cli-property",
+		Version:     "1.2.0",
+		Description: "Manage properties",
+		Arguments:   "<action>",
+		Flags:       []flagDoc{{Name: "--section value", Usage: "Section of the credentials file"}},
+		Subcommands: []commandDoc{{Name: "property list", Description: "List properties"}},
+	}
+
+	rendered := renderMarkdownDoc(doc, "#")
+	for _, expected := range []string{
+		"# akamai property\n\nManage properties\n\n",
+		"Package: `cli-property`, version 1.2.0\n",
+		"```\nakamai property <action>\n```\n",
+		"## Flags\n\n- `--section value`: Section of the credentials file\n",
+		"## akamai property list\n\nList properties\n",
+	} {
+		if !strings.Contains(rendered, expected) {
+			t.Errorf("renderMarkdownDoc() => %q, wanted it to contain: %q", rendered, expected)
+		}
+	}
+}
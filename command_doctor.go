@@ -0,0 +1,361 @@
+/*
+ Copyright 2018. Akamai Technologies, Inc
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+	"time"
+
+	akamai "github.com/akamai/cli-common-golang"
+	"github.com/fatih/color"
+	"github.com/urfave/cli"
+)
+
+type doctorStatus int
+
+const (
+	doctorOk doctorStatus = iota
+	doctorWarn
+	doctorFail
+)
+
+// doctorCheck is the outcome of one diagnostic, with a suggested fix for problems
+type doctorCheck struct {
+	name    string
+	status  doctorStatus
+	message string
+	fix     string
+}
+
+// runtimeDownloads are where to get each language runtime from
+var runtimeDownloads = map[string]string{
+	"go":     "https://golang.org/dl/",
+	"php":    "https://php.net/downloads.php",
+	"node":   "https://nodejs.org/en/download/",
+	"ruby":   "https://www.ruby-lang.org/en/downloads/",
+	"python": "https://www.python.org/downloads/",
+}
+
+func cmdDoctor(c *cli.Context) error {
+	p := newSpinnerProgress()
+	p.Start("Running diagnostics...")
+	checks := runDoctorChecks()
+	p.Ok()
+
+	failed, warned := 0, 0
+	for _, check := range checks {
+		var label string
+		switch check.status {
+		case doctorOk:
+			label = color.GreenString("[ OK ]")
+		case doctorWarn:
+			label = color.YellowString("[WARN]")
+			warned++
+		default:
+			label = color.RedString("[FAIL]")
+			failed++
+		}
+
+		fmt.Fprintf(akamai.App.Writer, "%s %s: %s\n", label, color.New(color.Bold).Sprint(check.name), check.message)
+		if check.fix != "" && check.status != doctorOk {
+			fmt.Fprintf(akamai.App.Writer, "       %s %s\n", color.CyanString("Fix:"), check.fix)
+		}
+	}
+
+	platform := runtime.GOOS + "/" + runtime.GOARCH
+	if isRosettaTranslated() {
+		platform += " (Rosetta 2 on arm64)"
+	}
+	fmt.Fprintf(akamai.App.Writer, "\nAkamai CLI %s on %s: %d checks, %d warnings, %d failures\n", VERSION, platform, len(checks), warned, failed)
+
+	if failed > 0 {
+		return cli.NewExitError(color.RedString("%d check(s) failed", failed), 1)
+	}
+
+	return nil
+}
+
+func runDoctorChecks() []doctorCheck {
+	var checks []doctorCheck
+	checks = append(checks, checkGit())
+	checks = append(checks, checkHomeWritable()...)
+	checks = append(checks, checkPackageRuntimes()...)
+	checks = append(checks, checkProxyConfig()...)
+	checks = append(checks, checkRegistries()...)
+
+	return checks
+}
+
+// checkGit looks for the git binary. Packages are cloned without it, but
+// git's credential helper is used for private repositories.
+func checkGit() doctorCheck {
+	check := doctorCheck{name: "git"}
+
+	bin, err := exec.LookPath("git")
+	if err != nil {
+		check.status = doctorWarn
+		check.message = "not found on the PATH, credentials for private repositories can't be taken from git's credential helper"
+		check.fix = "Install git from https://git-scm.com/downloads"
+		return check
+	}
+
+	output, _ := exec.Command(bin, "--version").Output()
+	check.message = strings.TrimSpace(string(output))
+	if check.message == "" {
+		check.message = bin
+	}
+
+	return check
+}
+
+// checkHomeWritable makes sure packages and caches can be written
+func checkHomeWritable() []doctorCheck {
+	cliPath, err := getAkamaiCliPath()
+	if err != nil {
+		return []doctorCheck{{name: "home directory", status: doctorFail, message: err.Error(), fix: "Set AKAMAI_CLI_HOME to a writable directory"}}
+	}
+
+	paths := []string{cliPath, filepath.Join(cliPath, "src")}
+	if cachePath, err := getAkamaiCliCachePath(); err == nil {
+		paths = append(paths, cachePath)
+	}
+
+	var checks []doctorCheck
+	for _, path := range paths {
+		check := doctorCheck{name: "write access", message: path}
+		if _, err := os.Stat(path); os.IsNotExist(err) {
+			// Created on first use
+			check.message += " (not created yet)"
+			path = filepath.Dir(path)
+		}
+
+		file, err := ioutil.TempFile(path, ".doctor")
+		if err != nil {
+			check.status = doctorFail
+			check.message = fmt.Sprintf("%s is not writable (%s)", check.message, err.Error())
+			check.fix = fmt.Sprintf("Make %s writable by your user, or set AKAMAI_CLI_HOME to another directory", path)
+			if runtime.GOOS != "windows" {
+				check.fix = fmt.Sprintf("Run \"sudo chown -R $(whoami) %s\", or set AKAMAI_CLI_HOME to another directory", path)
+			}
+		} else {
+			file.Close()
+			os.Remove(file.Name())
+		}
+
+		checks = append(checks, check)
+	}
+
+	return checks
+}
+
+// checkPackageRuntimes checks the language runtimes required by the installed packages
+func checkPackageRuntimes() []doctorCheck {
+	required := make(map[string]string)
+	packages := make(map[string][]string)
+
+	for _, dir := range getPackageDirs() {
+		cmdPackage, err := readPackage(dir)
+		if err != nil || usesDocker(dir, "") {
+			// Packages run in Docker have their runtime in the image
+			continue
+		}
+
+		name := filepath.Base(getPackageRoot(dir))
+		for runtime, version := range cmdPackage.Requirements.runtimes() {
+			packages[runtime] = append(packages[runtime], name)
+			// Check against the highest version any package requires
+			if current, ok := required[runtime]; !ok || current == "*" || (version != "*" && versionCompare(current, version) == 1) {
+				required[runtime] = version
+			}
+		}
+	}
+
+	if len(required) == 0 {
+		return []doctorCheck{{name: "runtimes", message: "no installed packages require a language runtime"}}
+	}
+
+	runtimes := make([]string, 0, len(required))
+	for runtime := range required {
+		runtimes = append(runtimes, runtime)
+	}
+	sort.Strings(runtimes)
+
+	var checks []doctorCheck
+	for _, runtime := range runtimes {
+		bin := findRuntimeBin(runtime, required[runtime])
+		version, found := "", bin != ""
+		if found {
+			version, _ = getRuntimeVersion(runtime, bin)
+		}
+
+		sort.Strings(packages[runtime])
+		checks = append(checks, checkRuntimeRequirement(runtime, required[runtime], version, found, packages[runtime]))
+	}
+
+	return checks
+}
+
+// checkRuntimeRequirement compares the version of a runtime with the one packages require
+func checkRuntimeRequirement(runtime string, required string, version string, found bool, packages []string) doctorCheck {
+	check := doctorCheck{name: runtime}
+	requiredBy := fmt.Sprintf("required by %s", strings.Join(packages, ", "))
+
+	if found && version == "" {
+		check.status = doctorWarn
+		check.message = "unable to determine the installed version, " + requiredBy
+		return check
+	}
+
+	if err := checkRuntimeVersion(runtime, required, version, found); err != nil {
+		check.status = doctorFail
+		check.message = err.Error() + ", " + requiredBy
+
+		minimum := ""
+		if required != "*" {
+			minimum = " " + required + " or later"
+		}
+
+		check.fix = fmt.Sprintf("Install %s%s from %s", runtime, minimum, runtimeDownloads[runtime])
+		if found {
+			check.fix = fmt.Sprintf("Upgrade %s to%s from %s", runtime, minimum, runtimeDownloads[runtime])
+		}
+		return check
+	}
+
+	check.message = fmt.Sprintf("version %s, %s", version, requiredBy)
+	return check
+}
+
+// checkProxyConfig validates the proxy settings and CA bundle
+func checkProxyConfig() []doctorCheck {
+	var checks []doctorCheck
+
+	for _, name := range []string{"HTTPS_PROXY", "HTTP_PROXY"} {
+		value := os.Getenv(name)
+		if value == "" {
+			value = os.Getenv(strings.ToLower(name))
+		}
+		if value == "" {
+			continue
+		}
+
+		check := doctorCheck{name: "proxy", message: fmt.Sprintf("%s=%s", name, redactURL(value))}
+		if u, err := url.Parse(value); err != nil || u.Host == "" {
+			check.status = doctorFail
+			check.message += " is not a valid URL"
+			check.fix = fmt.Sprintf("Set %s to a URL such as http://proxy.example.com:3128", name)
+		}
+		checks = append(checks, check)
+	}
+
+	if len(checks) == 0 {
+		checks = append(checks, doctorCheck{name: "proxy", message: "not configured, connecting directly"})
+	} else if noProxy := os.Getenv("NO_PROXY") + os.Getenv("no_proxy"); noProxy != "" {
+		checks = append(checks, doctorCheck{name: "proxy", message: "NO_PROXY=" + noProxy})
+	}
+
+	if _, err := getTLSConfig(); err != nil {
+		checks = append(checks, doctorCheck{name: "CA bundle", status: doctorFail, message: err.Error(), fix: "Point cli.ca-bundle (or AKAMAI_CLI_CA_BUNDLE) at a PEM file with your CA certificates"})
+	} else if bundle := getConfigValue("cli", "ca-bundle") + os.Getenv(caBundleEnv); bundle != "" {
+		checks = append(checks, doctorCheck{name: "CA bundle", message: "loaded"})
+	}
+
+	return checks
+}
+
+// redactURL hides the password of a URL with credentials
+func redactURL(value string) string {
+	u, err := url.Parse(value)
+	if err != nil || u.User == nil {
+		return value
+	}
+
+	if _, ok := u.User.Password(); ok {
+		u.User = url.UserPassword(u.User.Username(), "xxxxx")
+	}
+
+	return u.String()
+}
+
+// checkRegistries makes sure each package registry can be reached
+func checkRegistries() []doctorCheck {
+	if isOffline() {
+		return []doctorCheck{{name: "registry", status: doctorWarn, message: "skipped, offline mode is enabled", fix: "Remove --offline or unset AKAMAI_CLI_OFFLINE to check connectivity"}}
+	}
+
+	client, err := getHTTPClient()
+	if err != nil {
+		return []doctorCheck{{name: "network", status: doctorFail, message: err.Error(), fix: "Correct the network settings in the [cli] section of .akamai-cli/config"}}
+	}
+
+	var checks []doctorCheck
+	for _, registry := range getRegistryURLs() {
+		check := doctorCheck{name: "registry", message: registry}
+
+		start := time.Now()
+		resp, err := client.Get(registry)
+		if err != nil {
+			check.status = doctorFail
+			check.message = fmt.Sprintf("%s is unreachable (%s)", registry, err.Error())
+			check.fix = getNetworkErrorFix(err)
+			checks = append(checks, check)
+			continue
+		}
+		resp.Body.Close()
+
+		if resp.StatusCode != http.StatusOK {
+			check.status = doctorFail
+			check.message = fmt.Sprintf("%s returned %s", registry, resp.Status)
+			check.fix = "Check that cli.registry (or --registry) points at a package list"
+		} else {
+			check.message = fmt.Sprintf("%s is reachable (%s)", registry, time.Since(start).Round(time.Millisecond))
+		}
+
+		checks = append(checks, check)
+	}
+
+	return checks
+}
+
+// getNetworkErrorFix suggests a fix for a failed request
+func getNetworkErrorFix(err error) string {
+	message := err.Error()
+
+	switch {
+	case strings.Contains(message, "certificate signed by unknown authority"), strings.Contains(message, "x509"):
+		return "If you are behind a TLS-intercepting proxy, set cli.ca-bundle to a PEM file with its CA certificate"
+	case strings.Contains(message, "proxyconnect"):
+		return "Check the proxy in HTTPS_PROXY/HTTP_PROXY (or --proxy) is correct and running"
+	case strings.Contains(message, "no such host"):
+		return "Check your DNS settings, or whether you need to set HTTPS_PROXY to reach the internet"
+	case strings.Contains(message, "Timeout"), strings.Contains(message, "timeout"):
+		return "Check your network or proxy settings, or raise cli.http-timeout on slow connections"
+	case strings.Contains(message, "connection refused"):
+		return "Check your firewall allows outbound HTTPS, or whether you need to set HTTPS_PROXY"
+	}
+
+	return "Check your network connection and proxy settings"
+}
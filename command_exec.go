@@ -0,0 +1,95 @@
+/*
+ Copyright 2018. Akamai Technologies, Inc
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/urfave/cli"
+)
+
+// cmdExec runs an installed command with its arguments exactly as given,
+// which the usual "akamai <command>" does not guarantee, e.g. for flags
+// that are also global flags
+func cmdExec(c *cli.Context) error {
+	args := []string(c.Args())
+	if len(args) == 0 {
+		return newError(errUsage, "You must specify a command, e.g. \"%s exec property -- --help\"", self())
+	}
+
+	packages := map[string]commandPackage{}
+	for _, dir := range getPackageDirs() {
+		if cmdPackage, err := readPackage(dir); err == nil {
+			packages[strings.TrimPrefix(filepath.Base(dir), "cli-")] = cmdPackage
+		}
+	}
+
+	cmd, err := resolveInstalledCommand(args[0], packages)
+	if err != nil {
+		return newError(errCommandNotFound, "%s", err.Error())
+	}
+
+	args = args[1:]
+	if len(args) > 0 && args[0] == "--" {
+		args = args[1:]
+	}
+
+	return runInstalledCommand(cmd, args)
+}
+
+// resolveInstalledCommand returns the installed command called name, or one of
+// its aliases, or the command of the package called name, if it has only one
+func resolveInstalledCommand(name string, packages map[string]commandPackage) (string, error) {
+	name = strings.ToLower(name)
+
+	var names []string
+	for pkgName := range packages {
+		names = append(names, pkgName)
+	}
+	sort.Strings(names)
+
+	for _, pkgName := range names {
+		for _, command := range packages[pkgName].Commands {
+			if strings.ToLower(command.Name) == name {
+				return strings.ToLower(command.Name), nil
+			}
+
+			for _, alias := range command.Aliases {
+				if strings.ToLower(alias) == name {
+					return strings.ToLower(command.Name), nil
+				}
+			}
+		}
+	}
+
+	cmdPackage, ok := packages[strings.TrimPrefix(name, "cli-")]
+	switch {
+	case !ok || len(cmdPackage.Commands) == 0:
+		return "", fmt.Errorf("Command \"%s\" is not installed", name)
+	case len(cmdPackage.Commands) > 1:
+		var commands []string
+		for _, command := range cmdPackage.Commands {
+			commands = append(commands, strings.ToLower(command.Name))
+		}
+		return "", fmt.Errorf("Package \"%s\" has several commands, specify one of: %s", name, strings.Join(commands, ", "))
+	}
+
+	return strings.ToLower(cmdPackage.Commands[0].Name), nil
+}
@@ -0,0 +1,53 @@
+/*
+ Copyright 2018. Akamai Technologies, Inc
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package main
+
+import "testing"
+
+func TestResolveInstalledCommand(t *testing.T) {
+	packages := map[string]commandPackage{
+		"property": {Commands: []Command{{Name: "property", Aliases: []string{"papi"}}}},
+		"purge":    {Commands: []Command{{Name: "Purge"}}},
+		"edgeworkers": {Commands: []Command{
+			{Name: "edgeworkers", Aliases: []string{"ew"}},
+			{Name: "edgekv"},
+		}},
+		"tools": {Commands: []Command{{Name: "netstorage"}, {Name: "sandbox"}}},
+	}
+
+	resolveTests := []struct {
+		name    string
+		command string
+		err     bool
+	}{
+		{"property", "property", false},
+		{"papi", "property", false},
+		{"PURGE", "purge", false},
+		{"cli-purge", "purge", false},
+		{"ew", "edgeworkers", false},
+		{"edgekv", "edgekv", false},
+		{"tools", "", true},
+		{"dns", "", true},
+	}
+
+	for _, tt := range resolveTests {
+		command, err := resolveInstalledCommand(tt.name, packages)
+		if command != tt.command || (err != nil) != tt.err {
+			t.Errorf("resolveInstalledCommand(%s) => %s, %v, wanted: %s (error: %t)", tt.name, command, err, tt.command, tt.err)
+		}
+	}
+}
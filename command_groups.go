@@ -0,0 +1,107 @@
+/*
+ Copyright 2018. Akamai Technologies, Inc
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	akamai "github.com/akamai/cli-common-golang"
+	"github.com/fatih/color"
+	"github.com/urfave/cli"
+)
+
+func cmdGroups(c *cli.Context) error {
+	packageList, err := fetchPackageList(fetchOptions{})
+	if err != nil {
+		return cli.NewExitError(color.RedString(err.Error()), 1)
+	}
+
+	groups := getPackageGroups(packageList)
+	if len(groups) == 0 {
+		fmt.Fprintln(akamai.App.Writer, color.YellowString("No package groups are defined"))
+		return nil
+	}
+
+	names := make([]string, 0, len(groups))
+	for name := range groups {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	bold := color.New(color.FgWhite, color.Bold)
+	for _, name := range names {
+		fmt.Fprintln(akamai.App.Writer, color.GreenString("Group: %s", name))
+		for _, member := range groups[name] {
+			fmt.Fprintln(akamai.App.Writer, bold.Sprintf("    %s", member))
+		}
+		fmt.Fprintln(akamai.App.Writer)
+	}
+
+	return nil
+}
+
+// getPackageGroups merges the groups published by the registry with those in
+// the [groups] config section, e.g. getting-started = purge, property. Groups
+// defined in config replace registry groups of the same name.
+func getPackageGroups(packageList *packageList) map[string][]string {
+	groups := make(map[string][]string)
+	for name, members := range packageList.Groups {
+		groups[name] = members
+	}
+
+	for name, value := range getConfigSectionValues("groups") {
+		var members []string
+		for _, member := range strings.Split(value, ",") {
+			if member = strings.TrimSpace(member); member != "" {
+				members = append(members, member)
+			}
+		}
+		groups[name] = members
+	}
+
+	return groups
+}
+
+type groupMember struct {
+	pkg packageListPackage
+	// version is the pinned version, if any
+	version string
+}
+
+// resolveGroup maps the members of a group to registry packages. Members may
+// pin a version with name@version.
+func resolveGroup(name string, packageList *packageList) ([]groupMember, error) {
+	members, ok := getPackageGroups(packageList)[name]
+	if !ok {
+		return nil, fmt.Errorf("Unknown package group \"%s\", run \"akamai groups\" to see available groups", name)
+	}
+
+	var resolved []groupMember
+	for _, member := range members {
+		memberName, version := parseInstallVersion(member)
+
+		pkg, ok := packageList.findPackage(memberName)
+		if !ok {
+			return nil, fmt.Errorf("Package \"%s\" in group \"%s\" was not found in the package list", memberName, name)
+		}
+		resolved = append(resolved, groupMember{pkg, version})
+	}
+
+	return resolved, nil
+}
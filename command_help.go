@@ -17,12 +17,43 @@
 package main
 
 import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
 	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
 
 	akamai "github.com/akamai/cli-common-golang"
+	"github.com/fatih/color"
 	"github.com/urfave/cli"
 )
 
+const (
+	// helpTimeout limits how long an installed command may take to print its help
+	helpTimeout = 5 * time.Second
+	// helpKillGrace is how long to wait for the output of a command whose help
+	// timed out, once it is killed
+	helpKillGrace = time.Second
+	// helpOutputLimit limits how much of its help output is kept
+	helpOutputLimit = 64 * 1024
+	helpJobs        = 4
+)
+
+// helpTemplateMarker ends the line of each command in the app help template,
+// where the help of installed commands is added
+const helpTemplateMarker = "){{end}}\n{{end}}"
+
+// installedHelp is what an installed command's own help says about it, cached
+// until the command is updated
+type installedHelp struct {
+	Key         string   `json:"key"`
+	Description string   `json:"description,omitempty"`
+	Flags       []string `json:"flags,omitempty"`
+}
+
 func cmdHelp(c *cli.Context) error {
 	if c.Args().Present() {
 		cmd := c.Args().First()
@@ -40,5 +71,296 @@ func cmdHelp(c *cli.Context) error {
 		return nil
 	}
 
+	addInstalledHelp(c.App)
+
 	return cli.ShowAppHelp(c)
 }
+
+// addInstalledHelp adds the descriptions and flags of installed commands to
+// the app help, taken from their own help output
+func addInstalledHelp(app *cli.App) {
+	helps := getInstalledHelp()
+	if len(helps) == 0 {
+		return
+	}
+
+	for _, category := range app.Categories() {
+		for i, command := range category.Commands {
+			help, ok := helps[command.Name]
+			if !ok {
+				continue
+			}
+
+			usage := help.Description
+			if len(help.Flags) > 0 {
+				usage += "\n      " + color.HiBlackString("Flags: %s", strings.Join(help.Flags, ", "))
+			}
+			category.Commands[i].Usage = strings.TrimPrefix(usage, "\n      ")
+		}
+	}
+
+	if !strings.Contains(cli.AppHelpTemplate, "{{if .Usage}}") {
+		cli.AppHelpTemplate = strings.Replace(cli.AppHelpTemplate, helpTemplateMarker, "){{end}}{{if .Usage}}\n      {{.Usage}}{{end}}\n{{end}}", 1)
+	}
+}
+
+// getInstalledHelp returns the help of each installed command, running those
+// that were installed or updated since their help was cached
+func getInstalledHelp() map[string]installedHelp {
+	cache := readHelpCache()
+
+	type helpJob struct {
+		name    string
+		command Command
+		dir     string
+		key     string
+	}
+
+	var jobs []helpJob
+	helps := map[string]installedHelp{}
+	for _, dir := range getPackageDirs() {
+		cmdPackage, err := readPackage(dir)
+		if err != nil {
+			continue
+		}
+
+		for _, command := range cmdPackage.Commands {
+			name := strings.ToLower(command.Name)
+			executable, err := findExec(name)
+			if err != nil {
+				continue
+			}
+
+			key := getHelpCacheKey(command, executable[len(executable)-1])
+			if help, ok := cache[name]; ok && help.Key == key {
+				helps[name] = help
+				continue
+			}
+
+			jobs = append(jobs, helpJob{name, command, dir, key})
+		}
+	}
+
+	if len(jobs) > 0 {
+		var lock sync.Mutex
+		queue := make(chan helpJob)
+		wg := sync.WaitGroup{}
+		for i := 0; i < helpJobs; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for job := range queue {
+					help := runInstalledHelp(job.name, job.dir)
+					help.Key = job.key
+					if help.Description == "" {
+						help.Description = job.command.Description
+					}
+
+					lock.Lock()
+					helps[job.name] = help
+					lock.Unlock()
+				}
+			}()
+		}
+
+		for _, job := range jobs {
+			queue <- job
+		}
+		close(queue)
+		wg.Wait()
+
+		writeHelpCache(helps)
+	}
+
+	return helps
+}
+
+// getHelpCacheKey identifies the version of a command its help was cached for
+func getHelpCacheKey(command Command, executable string) string {
+	key := command.Version
+	if info, err := os.Stat(executable); err == nil {
+		key += "@" + info.ModTime().UTC().Format(time.RFC3339Nano)
+	}
+
+	return key
+}
+
+// runInstalledHelp runs "<command> help" and parses what it prints
+func runInstalledHelp(name string, dir string) installedHelp {
+	description, flags := parseCommandHelp(getInstalledHelpOutput(name, dir))
+
+	return installedHelp{Description: description, Flags: flags}
+}
+
+// getInstalledHelpOutput runs "<command> help", without stdin, credentials in
+// the environment, or more than helpTimeout to finish, and returns what it
+// prints
+func getInstalledHelpOutput(name string, dir string) string {
+	executable, err := findExec(name)
+	if err != nil {
+		return ""
+	}
+
+	var env []string
+	for _, value := range os.Environ() {
+		if i := strings.Index(value, "="); i > 0 && isSecretName(value[:i]) {
+			continue
+		}
+		env = append(env, value)
+	}
+	if cmdPackage, err := readPackage(dir); err == nil && cmdPackage.Requirements.Python != "" {
+		env = append(env, "PYTHONUSERBASE="+dir)
+	}
+
+	output := &limitedBuffer{limit: helpOutputLimit}
+	cmd := newLaunchCommand(append(executable, "help"))
+	cmd.Dir = dir
+	cmd.Env = env
+	cmd.Stdout = output
+	cmd.Stderr = output
+	// Wrappers start the actual command in a child, which must be killed too
+	setNonInteractiveProcessGroup(cmd)
+
+	if err := cmd.Start(); err != nil {
+		logWarn("unable to run command help", "command", name, "error", err)
+		return ""
+	}
+
+	done := make(chan struct{})
+	go func() {
+		cmd.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(helpTimeout):
+		logWarn("command help timed out", "command", name, "timeout", helpTimeout.String())
+		killProcessGroup(cmd)
+
+		// A process that left the group can keep the output open, and Wait with it
+		select {
+		case <-done:
+		case <-time.After(helpKillGrace):
+		}
+	}
+
+	return output.String()
+}
+
+// parseCommandHelp returns the description and long flags in the help output
+// of a command. The description is the line following a "Description:"
+// heading, if there is one.
+func parseCommandHelp(output string) (string, []string) {
+	lines := strings.Split(stripColor(output), "\n")
+
+	description := ""
+	for i, line := range lines {
+		if !strings.EqualFold(strings.TrimSuffix(strings.TrimSpace(line), ":"), "description") {
+			continue
+		}
+
+		for _, next := range lines[i+1:] {
+			if next = strings.TrimSpace(next); next != "" {
+				description = next
+				break
+			}
+		}
+		break
+	}
+
+	var flags []string
+	seen := map[string]bool{}
+	for _, line := range lines {
+		line = strings.TrimSpace(line)
+		if !strings.HasPrefix(line, "-") {
+			continue
+		}
+
+		// Flags are separated from their usage by at least two spaces
+		if i := strings.Index(line, "  "); i != -1 {
+			line = line[:i]
+		}
+
+		for _, field := range strings.FieldsFunc(line, func(r rune) bool {
+			return r == ',' || r == ' ' || r == '=' || r == '[' || r == '|'
+		}) {
+			if len(field) > 2 && strings.HasPrefix(field, "--") && !strings.HasPrefix(field, "---") && !seen[field] {
+				seen[field] = true
+				flags = append(flags, field)
+			}
+		}
+	}
+
+	return description, flags
+}
+
+func getHelpCachePath() (string, error) {
+	cachePath, err := getAkamaiCliCachePath()
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(cachePath, "help.json"), nil
+}
+
+func readHelpCache() map[string]installedHelp {
+	cache := map[string]installedHelp{}
+
+	path, err := getHelpCachePath()
+	if err != nil {
+		return cache
+	}
+
+	if data, err := ioutil.ReadFile(path); err == nil {
+		json.Unmarshal(data, &cache)
+	}
+
+	return cache
+}
+
+func writeHelpCache(helps map[string]installedHelp) {
+	path, err := getHelpCachePath()
+	if err != nil {
+		return
+	}
+
+	data, err := json.MarshalIndent(helps, "", "  ")
+	if err != nil {
+		return
+	}
+
+	if err := ioutil.WriteFile(path, data, 0664); err != nil {
+		logWarn("unable to cache command help", "path", path, "error", err)
+	}
+}
+
+// limitedBuffer keeps the first limit bytes written to it, and discards the
+// rest. It can be read while it is being written to.
+type limitedBuffer struct {
+	sync.Mutex
+	buffer bytes.Buffer
+	limit  int
+}
+
+func (b *limitedBuffer) Write(p []byte) (int, error) {
+	b.Lock()
+	defer b.Unlock()
+
+	if room := b.limit - b.buffer.Len(); room > 0 {
+		if len(p) > room {
+			b.buffer.Write(p[:room])
+		} else {
+			b.buffer.Write(p)
+		}
+	}
+
+	return len(p), nil
+}
+
+func (b *limitedBuffer) String() string {
+	b.Lock()
+	defer b.Unlock()
+
+	return b.buffer.String()
+}
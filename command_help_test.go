@@ -0,0 +1,63 @@
+/*
+ Copyright 2018. Akamai Technologies, Inc
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseCommandHelp(t *testing.T) {
+	helpTests := []struct {
+		output      string
+		description string
+		flags       []string
+	}{
+		{
+			"Usage:\n   akamai purge [global flags] command\n\nDescription:\n   Purge content from the Edge\n\nGlobal Flags:\n   --edgerc value  Location of the credentials file\n   --section value, -s value  Section of the credentials file [$AKAMAI_EDGERC_SECTION]\n   --help, -h  show help\n",
+			"Purge content from the Edge",
+			[]string{"--edgerc", "--section", "--help"},
+		},
+		{
+			"Usage: akamai-property [options] <command>\n\nOptions:\n  -e, --edgerc <path>   .edgerc file\n  --section=<name>      use section\n  --verbose|--debug     more output\n  -v                    version\n",
+			"",
+			[]string{"--edgerc", "--section", "--verbose", "--debug"},
+		},
+		{"\x1b[33mDESCRIPTION:\x1b[0m\n\n  Manage DNS\n--------\n", "Manage DNS", nil},
+		{"", "", nil},
+	}
+
+	for _, tt := range helpTests {
+		description, flags := parseCommandHelp(tt.output)
+		if description != tt.description || !reflect.DeepEqual(flags, tt.flags) {
+			t.Errorf("parseCommandHelp(%q) => %s, %q, wanted: %s, %q", tt.output, description, flags, tt.description, tt.flags)
+		}
+	}
+}
+
+func TestLimitedBuffer(t *testing.T) {
+	buf := &limitedBuffer{limit: 5}
+	for _, s := range []string{"abc", "def", "ghi"} {
+		if n, err := buf.Write([]byte(s)); n != len(s) || err != nil {
+			t.Errorf("limitedBuffer.Write(%s) => %d, %v, wanted: %d, nil", s, n, err, len(s))
+		}
+	}
+
+	if buf.String() != "abcde" {
+		t.Errorf("limitedBuffer => %s, wanted: abcde", buf.String())
+	}
+}
@@ -0,0 +1,156 @@
+/*
+ Copyright 2018. Akamai Technologies, Inc
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	akamai "github.com/akamai/cli-common-golang"
+	"github.com/fatih/color"
+	"github.com/urfave/cli"
+)
+
+// historyEntry is a run of an installed package command, as shown by "akamai history"
+type historyEntry struct {
+	Time     string `json:"time"`
+	User     string `json:"user,omitempty"`
+	Dir      string `json:"dir,omitempty"`
+	Command  string `json:"command"`
+	Args     string `json:"args"`
+	ExitCode int    `json:"exitCode"`
+	Duration string `json:"duration"`
+}
+
+type historyFilter struct {
+	command string
+	since   time.Time
+	failed  bool
+	limit   int
+}
+
+func cmdHistory(c *cli.Context) error {
+	filter := historyFilter{
+		command: strings.ToLower(c.String("command")),
+		failed:  c.Bool("failed"),
+		limit:   c.Int("limit"),
+	}
+
+	if c.IsSet("since") {
+		since, err := parseHistorySince(c.String("since"), time.Now())
+		if err != nil {
+			return cli.NewExitError(color.RedString(err.Error()), 1)
+		}
+		filter.since = since
+	}
+
+	entries, err := readAuditLog()
+	if err != nil {
+		return cli.NewExitError(color.RedString("Unable to read the audit log: %s", err.Error()), 1)
+	}
+
+	history := filterHistory(entries, filter)
+
+	if getOutputFormat() != formatTable {
+		return printStructured(history)
+	}
+
+	if len(history) == 0 {
+		fmt.Fprintln(akamai.App.Writer, color.YellowString("No commands found"))
+		return nil
+	}
+
+	w := tabwriter.NewWriter(akamai.App.Writer, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "TIME\tUSER\tEXIT\tDURATION\tCOMMAND")
+	for _, entry := range history {
+		exitCode := color.GreenString("%d", entry.ExitCode)
+		if entry.ExitCode != 0 {
+			exitCode = color.RedString("%d", entry.ExitCode)
+		}
+
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", entry.Time, entry.User, exitCode, entry.Duration, strings.TrimSpace(self()+" "+entry.Command+" "+entry.Args))
+	}
+
+	return w.Flush()
+}
+
+// filterHistory returns the package command runs in the audit log that match
+// the filter, oldest first, keeping the most recent limit entries
+func filterHistory(entries []auditEntry, filter historyFilter) []historyEntry {
+	history := make([]historyEntry, 0)
+	for _, entry := range entries {
+		if entry.Event != auditExecEvent {
+			continue
+		}
+
+		if filter.command != "" && strings.ToLower(entry.Details["command"]) != filter.command {
+			continue
+		}
+
+		if !filter.since.IsZero() {
+			if t, err := time.Parse(time.RFC3339, entry.Time); err != nil || t.Before(filter.since) {
+				continue
+			}
+		}
+
+		exitCode, _ := strconv.Atoi(entry.Details["exitCode"])
+		if filter.failed && exitCode == 0 {
+			continue
+		}
+
+		history = append(history, historyEntry{
+			Time:     entry.Time,
+			User:     entry.Details["user"],
+			Dir:      entry.Details["dir"],
+			Command:  entry.Details["command"],
+			Args:     entry.Details["args"],
+			ExitCode: exitCode,
+			Duration: entry.Details["duration"],
+		})
+	}
+
+	if filter.limit > 0 && len(history) > filter.limit {
+		history = history[len(history)-filter.limit:]
+	}
+
+	return history
+}
+
+// parseHistorySince parses --since, either a duration such as 24h or 30m, a
+// number of days such as 7d, or a date (2006-01-02) or RFC 3339 time
+func parseHistorySince(value string, now time.Time) (time.Time, error) {
+	if strings.HasSuffix(value, "d") {
+		if days, err := strconv.Atoi(strings.TrimSuffix(value, "d")); err == nil && days >= 0 {
+			return now.AddDate(0, 0, -days), nil
+		}
+	}
+
+	if duration, err := time.ParseDuration(value); err == nil {
+		return now.Add(-duration), nil
+	}
+
+	for _, layout := range []string{time.RFC3339, "2006-01-02"} {
+		if t, err := time.ParseInLocation(layout, value, now.Location()); err == nil {
+			return t, nil
+		}
+	}
+
+	return time.Time{}, fmt.Errorf("Invalid value for --since \"%s\", use a duration (24h, 7d) or a date (2006-01-02)", value)
+}
@@ -0,0 +1,81 @@
+/*
+ Copyright 2018. Akamai Technologies, Inc
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestFilterHistory(t *testing.T) {
+	entries := []auditEntry{
+		{Time: "2018-06-01T10:00:00Z", Event: auditExecEvent, Details: map[string]string{"command": "property", "exitCode": "0"}},
+		{Time: "2018-06-01T11:00:00Z", Event: "license.accepted"},
+		{Time: "2018-06-02T10:00:00Z", Event: auditExecEvent, Details: map[string]string{"command": "purge", "exitCode": "1"}},
+		{Time: "2018-06-03T10:00:00Z", Event: auditExecEvent, Details: map[string]string{"command": "property", "exitCode": "2"}},
+	}
+
+	filterTests := []struct {
+		filter   historyFilter
+		commands []string
+	}{
+		{historyFilter{}, []string{"property", "purge", "property"}},
+		{historyFilter{command: "property"}, []string{"property", "property"}},
+		{historyFilter{failed: true}, []string{"purge", "property"}},
+		{historyFilter{limit: 1}, []string{"property"}},
+		{historyFilter{since: time.Date(2018, 6, 2, 0, 0, 0, 0, time.UTC)}, []string{"purge", "property"}},
+	}
+
+	for _, tt := range filterTests {
+		history := filterHistory(entries, tt.filter)
+		var commands []string
+		for _, entry := range history {
+			commands = append(commands, entry.Command)
+		}
+		if len(commands) != len(tt.commands) {
+			t.Errorf("filterHistory(%+v) => %v, wanted: %v", tt.filter, commands, tt.commands)
+			continue
+		}
+		for i := range commands {
+			if commands[i] != tt.commands[i] {
+				t.Errorf("filterHistory(%+v) => %v, wanted: %v", tt.filter, commands, tt.commands)
+				break
+			}
+		}
+	}
+}
+
+func TestParseHistorySince(t *testing.T) {
+	now := time.Date(2018, 6, 10, 12, 0, 0, 0, time.UTC)
+	sinceTests := []struct {
+		value string
+		want  time.Time
+		err   bool
+	}{
+		{"24h", time.Date(2018, 6, 9, 12, 0, 0, 0, time.UTC), false},
+		{"7d", time.Date(2018, 6, 3, 12, 0, 0, 0, time.UTC), false},
+		{"2018-06-01", time.Date(2018, 6, 1, 0, 0, 0, 0, time.UTC), false},
+		{"yesterday", time.Time{}, true},
+	}
+
+	for _, tt := range sinceTests {
+		since, err := parseHistorySince(tt.value, now)
+		if (err != nil) != tt.err || !since.Equal(tt.want) {
+			t.Errorf("parseHistorySince(%s) => %s, %v, wanted: %s", tt.value, since, err, tt.want)
+		}
+	}
+}
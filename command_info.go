@@ -0,0 +1,95 @@
+/*
+ Copyright 2018. Akamai Technologies, Inc
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	akamai "github.com/akamai/cli-common-golang"
+	"github.com/fatih/color"
+	"github.com/urfave/cli"
+)
+
+func cmdInfo(c *cli.Context) error {
+	if !c.Args().Present() {
+		return cli.NewExitError(color.RedString("You must specify a package name"), 1)
+	}
+
+	packageList, err := fetchPackageList(fetchOptions{})
+	if err != nil {
+		return cli.NewExitError(color.RedString(err.Error()), 1)
+	}
+
+	name := c.Args().First()
+	pkg, ok := packageList.findPackage(name)
+	if !ok {
+		return cli.NewExitError(color.RedString("Package \"%s\" was not found in the package list. Try \"%s search %s\".", name, self(), name), 1)
+	}
+
+	bold := color.New(color.FgWhite, color.Bold)
+	field := func(label string, value string) {
+		if value == "" {
+			value = "-"
+		}
+		fmt.Fprintf(akamai.App.Writer, "%s %s\n", bold.Sprintf("%-14s", label+":"), value)
+	}
+
+	fmt.Fprintln(akamai.App.Writer, color.GreenString("%s (%s)\n", pkg.Title, pkg.Name))
+	field("Version", pkg.Version)
+	field("URL", pkg.URL)
+	field("Issues", pkg.Issues)
+	if pkg.Path != "" {
+		field("Path", pkg.Path)
+	}
+	if pkg.Size > 0 {
+		field("Size", formatBytes(pkg.Size))
+	}
+
+	var requirements []string
+	for _, runtime := range getPackageRuntimes(pkg) {
+		requirements = append(requirements, fmt.Sprintf("%s %s", runtime, getPackageRequirement(pkg, runtime)))
+	}
+	field("Requires", strings.Join(requirements, ", "))
+
+	installed := "no"
+	if version, ok := getInstalledPackageVersion(pkg, getInstalledCommandVersions()); ok {
+		if version == "" {
+			version = "unknown version"
+		}
+		installed = "yes (" + version + ")"
+		if version != "unknown version" && pkg.Version != "" && compareVersions(pkg.Version, version) > 0 {
+			installed += ", " + color.CyanString("update available")
+		}
+	}
+	field("Installed", installed)
+
+	fmt.Fprintln(akamai.App.Writer, color.YellowString("\nCommands:\n"))
+	for _, cmd := range pkg.Commands {
+		var aliases string
+		if len(cmd.Aliases) == 1 {
+			aliases = fmt.Sprintf("(alias: %s)", cmd.Aliases[0])
+		} else if len(cmd.Aliases) > 1 {
+			aliases = fmt.Sprintf("(aliases: %s)", strings.Join(cmd.Aliases, ", "))
+		}
+
+		fmt.Fprintln(akamai.App.Writer, bold.Sprintf("  %s %s", cmd.Name, aliases))
+		fmt.Fprintf(akamai.App.Writer, "    %s\n\n", cmd.Description)
+	}
+
+	return nil
+}
@@ -0,0 +1,403 @@
+/*
+ Copyright 2018. Akamai Technologies, Inc
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"text/template"
+
+	akamai "github.com/akamai/cli-common-golang"
+	"github.com/fatih/color"
+	"github.com/urfave/cli"
+	"gopkg.in/src-d/go-git.v4"
+)
+
+var packageNamePattern = regexp.MustCompile(`^[a-z][a-z0-9]*(-[a-z0-9]+)*$`)
+
+// scaffoldData is available to the package templates
+type scaffoldData struct {
+	Name        string
+	Description string
+}
+
+// scaffoldFile is a file created by init-package. Its path is a template too.
+type scaffoldFile struct {
+	path       string
+	content    string
+	executable bool
+}
+
+func cmdInitPackage(c *cli.Context) error {
+	language := strings.ToLower(c.String("language"))
+	if language == "node" || language == "javascript" {
+		language = "js"
+	}
+
+	templates, ok := packageTemplates[language]
+	if !ok {
+		return cli.NewExitError(color.RedString("Unsupported language \"%s\", must be one of: go, python, node", c.String("language")), 1)
+	}
+
+	dir := c.Args().First()
+	name := c.String("name")
+	if name == "" {
+		if dir == "" {
+			return cli.NewExitError(color.RedString("You must specify a directory or a --name"), 1)
+		}
+		name = strings.TrimPrefix(filepath.Base(dir), "cli-")
+	}
+	name = strings.ToLower(name)
+
+	if !packageNamePattern.MatchString(name) {
+		return cli.NewExitError(color.RedString("Invalid command name \"%s\", use lowercase letters, digits, and dashes", name), 1)
+	}
+
+	if dir == "" {
+		dir = "cli-" + name
+	}
+
+	if entries, err := ioutil.ReadDir(dir); err == nil && len(entries) > 0 {
+		return cli.NewExitError(color.RedString("Directory %s already exists and is not empty", dir), 1)
+	}
+
+	description := c.String("description")
+	if description == "" {
+		description = fmt.Sprintf("Akamai CLI for %s", name)
+	}
+
+	files, err := renderPackageTemplates(templates, scaffoldData{Name: name, Description: description})
+	if err != nil {
+		return cli.NewExitError(color.RedString("Unable to render package templates: %s", err.Error()), 1)
+	}
+
+	paths := make([]string, 0, len(files))
+	for path := range files {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	for _, path := range paths {
+		file := files[path]
+		target := filepath.Join(dir, filepath.FromSlash(path))
+		if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+			return cli.NewExitError(color.RedString("Unable to create %s: %s", filepath.Dir(target), err.Error()), 1)
+		}
+
+		mode := os.FileMode(0644)
+		if file.executable {
+			mode = 0755
+		}
+
+		if err := ioutil.WriteFile(target, []byte(file.content), mode); err != nil {
+			return cli.NewExitError(color.RedString("Unable to create %s: %s", target, err.Error()), 1)
+		}
+		fmt.Fprintf(akamai.App.Writer, "  created %s\n", target)
+	}
+
+	// Packages are installed from git repositories
+	if _, err := git.PlainInit(dir, false); err != nil {
+		fmt.Fprintln(akamai.App.ErrWriter, color.YellowString("Unable to initialize a git repository in %s: %s", dir, err.Error()))
+	}
+
+	fmt.Fprintln(akamai.App.Writer, color.GreenString("\nCreated package %s in %s", color.New(color.Bold).Sprint("cli-"+name), dir))
+	fmt.Fprintf(akamai.App.Writer, "Try it out with \"%s install %s\", then run \"%s %s\".\n", self(), installablePath(dir), self(), name)
+
+	return nil
+}
+
+// installablePath returns dir in a form that "akamai install" treats as local
+func installablePath(dir string) string {
+	if isLocalInstallTarget(dir) {
+		return dir
+	}
+
+	return "." + string(os.PathSeparator) + dir
+}
+
+// renderPackageTemplates renders the paths and contents of the files of a package skeleton
+func renderPackageTemplates(templates []scaffoldFile, data scaffoldData) (map[string]scaffoldFile, error) {
+	funcs := template.FuncMap{
+		// json quotes a string for JSON, which is also a valid Python and JavaScript string literal
+		"json": func(s string) (string, error) {
+			data, err := json.Marshal(s)
+			return string(data), err
+		},
+		"snake": func(s string) string {
+			return strings.Replace(s, "-", "_", -1)
+		},
+	}
+
+	files := make(map[string]scaffoldFile)
+	for _, file := range append(append([]scaffoldFile(nil), commonTemplates...), templates...) {
+		rendered := file
+		for _, s := range []*string{&rendered.path, &rendered.content} {
+			t, err := template.New(file.path).Funcs(funcs).Parse(*s)
+			if err != nil {
+				return nil, err
+			}
+
+			buf := &bytes.Buffer{}
+			if err := t.Execute(buf, data); err != nil {
+				return nil, err
+			}
+			*s = buf.String()
+		}
+
+		files[rendered.path] = rendered
+	}
+
+	return files, nil
+}
+
+var commonTemplates = []scaffoldFile{
+	{path: "README.md", content: `# Akamai CLI: {{.Name}}
+
+{{.Description}}
+
+## Install
+
+` + "```" + `
+akamai install <repository URL>
+` + "```" + `
+
+## Usage
+
+` + "```" + `
+akamai {{.Name}} help
+akamai {{.Name}} [--edgerc <file>] [--section <name>] <command>
+` + "```" + `
+`},
+}
+
+var packageTemplates = map[string][]scaffoldFile{
+	"go": {
+		{path: "cli.json", content: `{
+  "requirements": {
+    "go": "1.9.0"
+  },
+  "commands": [
+    {
+      "name": "{{.Name}}",
+      "version": "0.1.0",
+      "description": {{json .Description}}
+    }
+  ]
+}
+`},
+		{path: ".gitignore", content: "/akamai-{{.Name}}\n/akamai-{{.Name}}.exe\n"},
+		{path: "go.mod", content: "module cli-{{.Name}}\n\ngo 1.12\n"},
+		{path: "main.go", content: `package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+)
+
+const version = "0.1.0"
+
+func main() {
+	flags := flag.NewFlagSet("akamai-{{.Name}}", flag.ContinueOnError)
+	edgerc := flags.String("edgerc", "~/.edgerc", "Location of the credentials file")
+	section := flags.String("section", "default", "Section of the credentials file")
+	if err := flags.Parse(os.Args[1:]); err != nil {
+		os.Exit(1)
+	}
+
+	if err := run(flags.Args(), *edgerc, *section); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+func run(args []string, edgerc string, section string) error {
+	if len(args) == 0 || args[0] == "help" {
+		fmt.Println(usage())
+		return nil
+	}
+
+	switch args[0] {
+	case "version":
+		fmt.Println(version)
+		return nil
+	}
+
+	return fmt.Errorf("unknown command %q, try \"akamai {{.Name}} help\"", args[0])
+}
+
+func usage() string {
+	return "Usage: akamai {{.Name}} [--edgerc <file>] [--section <name>] <command>\n\nCommands:\n  help     Show this help\n  version  Show the version"
+}
+`},
+		{path: "main_test.go", content: `package main
+
+import "testing"
+
+func TestRun(t *testing.T) {
+	if err := run([]string{"help"}, "", ""); err != nil {
+		t.Errorf("run(help) => %s, wanted no error", err)
+	}
+
+	if err := run([]string{"unknown"}, "", ""); err == nil {
+		t.Error("run(unknown) => no error, wanted an error")
+	}
+}
+`},
+	},
+	"python": {
+		{path: "cli.json", content: `{
+  "requirements": {
+    "python": "3.0.0"
+  },
+  "commands": [
+    {
+      "name": "{{.Name}}",
+      "version": "0.1.0",
+      "description": {{json .Description}}
+    }
+  ]
+}
+`},
+		{path: "requirements.txt", content: ""},
+		{path: "bin/akamai-{{.Name}}", executable: true, content: `#!/usr/bin/env python3
+import argparse
+import sys
+
+VERSION = "0.1.0"
+
+
+def parse_args(args):
+    parser = argparse.ArgumentParser(prog="akamai {{.Name}}", description={{json .Description}})
+    parser.add_argument("--edgerc", default="~/.edgerc", help="Location of the credentials file")
+    parser.add_argument("--section", default="default", help="Section of the credentials file")
+    parser.add_argument("command", nargs="?", default="help", choices=["help", "version"])
+    return parser, parser.parse_args(args)
+
+
+def main(args):
+    parser, options = parse_args(args)
+    if options.command == "version":
+        print(VERSION)
+    else:
+        parser.print_help()
+    return 0
+
+
+if __name__ == "__main__":
+    sys.exit(main(sys.argv[1:]))
+`},
+		{path: "tests/test_{{.Name | snake}}.py", content: `import importlib.machinery
+import importlib.util
+import os
+import unittest
+
+BIN = os.path.join(os.path.dirname(__file__), "..", "bin", "akamai-{{.Name}}")
+spec = importlib.util.spec_from_loader("cli", importlib.machinery.SourceFileLoader("cli", BIN))
+cli = importlib.util.module_from_spec(spec)
+spec.loader.exec_module(cli)
+
+
+class ParseArgsTest(unittest.TestCase):
+    def test_defaults(self):
+        _, options = cli.parse_args([])
+        self.assertEqual(options.command, "help")
+        self.assertEqual(options.section, "default")
+
+
+if __name__ == "__main__":
+    unittest.main()
+`},
+	},
+	"js": {
+		{path: "cli.json", content: `{
+  "requirements": {
+    "node": "8.0.0"
+  },
+  "commands": [
+    {
+      "name": "{{.Name}}",
+      "version": "0.1.0",
+      "description": {{json .Description}}
+    }
+  ]
+}
+`},
+		{path: "package.json", content: `{
+  "name": "akamai-{{.Name}}",
+  "version": "0.1.0",
+  "description": {{json .Description}},
+  "private": true,
+  "scripts": {
+    "test": "node test/{{.Name}}.test.js"
+  }
+}
+`},
+		{path: ".gitignore", content: "/node_modules\n"},
+		{path: "bin/akamai-{{.Name}}", executable: true, content: `#!/usr/bin/env node
+const VERSION = '0.1.0';
+
+function parseArgs(args) {
+  const options = { edgerc: '~/.edgerc', section: 'default', command: 'help' };
+  for (let i = 0; i < args.length; i++) {
+    if (args[i] === '--edgerc' || args[i] === '--section') {
+      options[args[i].slice(2)] = args[++i];
+    } else {
+      options.command = args[i];
+    }
+  }
+  return options;
+}
+
+function main(args) {
+  const options = parseArgs(args);
+  switch (options.command) {
+    case 'help':
+      console.log('Usage: akamai {{.Name}} [--edgerc <file>] [--section <name>] <command>');
+      return 0;
+    case 'version':
+      console.log(VERSION);
+      return 0;
+  }
+  console.error('unknown command "' + options.command + '", try "akamai {{.Name}} help"');
+  return 1;
+}
+
+if (require.main === module) {
+  process.exit(main(process.argv.slice(2)));
+}
+
+module.exports = { parseArgs, main };
+`},
+		{path: "test/{{.Name}}.test.js", content: `const assert = require('assert');
+const { parseArgs } = require('../bin/akamai-{{.Name}}');
+
+const options = parseArgs(['--section', 'ccu', 'version']);
+assert.strictEqual(options.section, 'ccu');
+assert.strictEqual(options.command, 'version');
+
+console.log('ok');
+`},
+	},
+}
@@ -0,0 +1,64 @@
+/*
+ Copyright 2018. Akamai Technologies, Inc
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package main
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestRenderPackageTemplates(t *testing.T) {
+	templateTests := []struct {
+		language string
+		expected string
+		bin      string
+	}{
+		{"go", "go", "main.go"},
+		{"python", "python", "bin/akamai-edge-dns"},
+		{"js", "javascript", "bin/akamai-edge-dns"},
+	}
+
+	data := scaffoldData{Name: "edge-dns", Description: `Manage "Edge DNS" zones`}
+	for _, tt := range templateTests {
+		files, err := renderPackageTemplates(packageTemplates[tt.language], data)
+		if err != nil {
+			t.Fatalf("renderPackageTemplates(%s) => %s", tt.language, err.Error())
+		}
+
+		var cmdPackage commandPackage
+		if err := json.Unmarshal([]byte(files["cli.json"].content), &cmdPackage); err != nil {
+			t.Errorf("renderPackageTemplates(%s) => invalid cli.json: %s", tt.language, err.Error())
+			continue
+		}
+
+		if language := determineCommandLanguage(cmdPackage); language != tt.expected {
+			t.Errorf("renderPackageTemplates(%s) => language %s, wanted: %s", tt.language, language, tt.expected)
+		}
+
+		if len(cmdPackage.Commands) != 1 || cmdPackage.Commands[0].Name != data.Name || cmdPackage.Commands[0].Description != data.Description {
+			t.Errorf("renderPackageTemplates(%s) => commands %+v, wanted %s", tt.language, cmdPackage.Commands, data.Name)
+		}
+
+		if _, ok := files["README.md"]; !ok {
+			t.Errorf("renderPackageTemplates(%s) => no README.md", tt.language)
+		}
+
+		if _, ok := files[tt.bin]; !ok {
+			t.Errorf("renderPackageTemplates(%s) => no %s", tt.language, tt.bin)
+		}
+	}
+}
@@ -17,38 +17,456 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"path/filepath"
+	"runtime"
+	"strconv"
 	"strings"
+	"sync"
+	"time"
 
 	akamai "github.com/akamai/cli-common-golang"
 	"github.com/fatih/color"
 	"github.com/mattn/go-isatty"
 	"github.com/urfave/cli"
 	"gopkg.in/src-d/go-git.v4"
+	"gopkg.in/src-d/go-git.v4/plumbing"
+	"gopkg.in/src-d/go-git.v4/plumbing/transport"
 )
 
 func cmdInstall(c *cli.Context) error {
+	if c.IsSet("from-search") {
+		return cmdInstallFromSearch(c)
+	}
+
+	if c.IsSet("group") {
+		return cmdInstallGroup(c)
+	}
+
+	if c.IsSet("from-lock") {
+		return cmdInstallFromLock(c)
+	}
+
 	if !c.Args().Present() {
-		return cli.NewExitError(color.RedString("You must specify a repository URL"), 1)
+		return newError(errUsage, "You must specify a repository URL")
 	}
 
 	oldCmds := getCommands()
 
+	opts := installOptions{
+		forceBinary:   c.Bool("force"),
+		acceptLicense: c.Bool("accept-license"),
+		version:       c.String("version"),
+		insecure:      c.Bool("insecure"),
+		fromSource:    c.Bool("from-source"),
+	}
+
+	fetch := fetchOptions{
+		skipIndexVerify: c.Bool("insecure-skip-index-verify"),
+		refresh:         c.Bool("refresh"),
+	}
+
+	var requests []installRequest
 	for _, repo := range c.Args() {
-		repo := githubize(repo)
-		err := installPackage(repo, c.Bool("force"))
-		if err != nil {
-			// Only track public github repos
-			if !strings.HasPrefix(repo, "https://github.com/") {
-				trackEvent("install.failed", repo)
+		requests = append(requests, resolveRegistryTarget(repo, opts, fetch))
+	}
+
+	err := installTargets(requests, c.Int("jobs"))
+	packageListDiff(oldCmds)
+
+	return err
+}
+
+type installRequest struct {
+	target string
+	opts   installOptions
+}
+
+// installTargets installs the requested targets, up to jobs at a time. Concurrent
+// installs report progress a line at a time, and never prompt.
+func installTargets(requests []installRequest, jobs int) error {
+	if jobs <= 1 || len(requests) <= 1 {
+		for _, request := range requests {
+			if err := installTarget(request.target, request.opts); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	}
+
+	queue := make(chan installRequest)
+	var failed []string
+	var errs []error
+	var failedLock sync.Mutex
+	var wg sync.WaitGroup
+
+	for i := 0; i < jobs && i < len(requests); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for request := range queue {
+				target := request.target
+				targetOpts := request.opts
+				targetOpts.progress = newLineProgress(target)
+
+				if err := installTarget(target, targetOpts); err != nil {
+					failedLock.Lock()
+					failed = append(failed, target)
+					errs = append(errs, err)
+					failedLock.Unlock()
+
+					if err.Error() != "" {
+						progressLock.Lock()
+						fmt.Fprintf(akamai.App.ErrWriter, "%s: %s\n", color.New(color.Bold).Sprint(target), strings.TrimSpace(err.Error()))
+						progressLock.Unlock()
+					}
+				}
 			}
+		}()
+	}
+
+	// Installing the same target twice at once would clone into the same directory
+	seen := make(map[string]bool)
+	for _, request := range requests {
+		if !seen[request.target] {
+			seen[request.target] = true
+			queue <- request
+		}
+	}
+	close(queue)
+	wg.Wait()
+
+	if len(failed) > 0 {
+		return combinedError(errs, "Unable to install: %s", strings.Join(failed, ", "))
+	}
+
+	return nil
+}
+
+// resolveRegistryTarget looks up a bare package name (e.g. "property") in the
+// configured registries, installing from the first registry that lists it, and
+// checking the install against the release the registry published. Names not
+// listed by any registry resolve to official packages on Github.
+func resolveRegistryTarget(target string, opts installOptions, fetch fetchOptions) installRequest {
+	name, version := parseInstallVersion(target)
+	if isLocalInstallTarget(target) || strings.ContainsAny(name, "/:#") || strings.HasSuffix(name, ".git") {
+		return installRequest{target, opts}
+	}
+
+	packageList, err := fetchPackageList(fetch)
+	if err != nil {
+		return installRequest{target, opts}
+	}
+
+	pkg, ok := packageList.findPackage(name)
+	if !ok {
+		return installRequest{target, opts}
+	}
+
+	if version != "" {
+		opts.version = version
+	}
+
+	return installRequest{pkg.getInstallTarget(), opts.forPackage(pkg)}
+}
+
+type installOptions struct {
+	// forceBinary installs binaries, when available, without asking if installing from source fails
+	forceBinary bool
+	// acceptLicense accepts licenses that require acceptance, without asking
+	acceptLicense bool
+	// estimatedSize is the registry's estimate of the installed package size in bytes, if known
+	estimatedSize uint64
+	// version is the tag to check out, instead of the default branch
+	version string
+	// progress reports each step of the install, a spinner by default
+	progress progress
+	// release is the registry release the install must match, if known
+	release *packageRelease
+	// insecure installs packages that do not match their release, with a warning
+	insecure bool
+	// requiredBy is the chain of packages that led to this one being installed as a dependency
+	requiredBy []string
+	// skipRequired does not install the packages declared as dependencies
+	skipRequired bool
+	// requirements are the runtime requirements the registry lists for the package, if known
+	requirements *packageRequirements
+	// fromSource builds packages from source even when their release has a binary for this platform
+	fromSource bool
+	// binary is the pre-built binary of the release for this platform, installed instead of the source
+	binary *releaseBinary
+	// commands are the registry's commands of the package, written to the cli.json of binary installs
+	commands []Command
+	// srcPath is where to install packages, instead of the src directory of the CLI home
+	srcPath string
+	// changelog shows what an update pulls in before applying it
+	changelog bool
+	// confirm asks before applying an update, after showing its changelog
+	confirm bool
+}
+
+// forPackage returns the options for installing a registry package
+func (opts installOptions) forPackage(pkg packageListPackage) installOptions {
+	opts.estimatedSize = pkg.Size
+	opts.release = nil
+	if release, ok := pkg.findRelease(opts.version); ok {
+		opts.release = &release
+	}
+	requirements := pkg.Requirements
+	opts.requirements = &requirements
+
+	opts.binary = nil
+	if opts.release != nil && !opts.fromSource && !usesDocker("cli-"+pkg.Name, "") {
+		if binary, ok := opts.release.findBinary(runtime.GOOS, getBinaryArchs(runtime.GOOS, getHostArch())); ok {
+			opts.binary = &binary
+			opts.commands = pkg.Commands
+		}
+	}
+
+	return opts
+}
+
+// getSrcPath returns the directory packages are installed in
+func (opts installOptions) getSrcPath() (string, error) {
+	if opts.srcPath != "" {
+		return opts.srcPath, nil
+	}
+
+	return getAkamaiCliSrcPath()
+}
+
+func (opts installOptions) getProgress() progress {
+	if opts.progress == nil {
+		return newSpinnerProgress()
+	}
+
+	return opts.progress
+}
+
+// defaultMinFreeSpace is the free space (in MB) required before cloning, unless cli.min-free-space is set
+const defaultMinFreeSpace = 100
+
+// checkDiskSpace ensures there is enough room to clone and build a package in dir
+func checkDiskSpace(dir string, estimatedSize uint64) error {
+	minFreeSpace := uint64(defaultMinFreeSpace)
+	if value := getConfigValue("cli", "min-free-space"); value != "" {
+		parsed, err := strconv.ParseUint(value, 10, 64)
+		if err != nil {
+			return newError(errConfig, "Invalid cli.min-free-space setting \"%s\", must be a number of megabytes", value)
+		}
+		minFreeSpace = parsed
+	}
+
+	required := minFreeSpace * 1024 * 1024
+	if estimatedSize > required {
+		required = estimatedSize
+	}
+
+	if required == 0 {
+		return nil
+	}
+
+	free, err := getFreeDiskSpace(dir)
+	if err != nil {
+		// If we can't tell, don't stand in the way of the install
+		return nil
+	}
+
+	if free < required {
+		return newError(errDiskSpace, "Not enough disk space to install package: %s available in %s, %s required. Free up space, or lower cli.min-free-space.", formatBytes(free), dir, formatBytes(required))
+	}
+
+	return nil
+}
+
+// installTarget installs a single package given a name, repository URL, or <repo>#<subpath>
+// or <repo>//<subpath>
+func installTarget(target string, opts installOptions) (err error) {
+	defer func() {
+		recordPackageResult(target, err)
+	}()
+
+	context := map[string]string{"package": target}
+	if _, err := runHooks(hookPreInstall, context); err != nil {
+		return err
+	}
+	defer func() {
+		if dir := getInstallTargetDir(target); dir != "" {
+			context["package_dir"] = dir
+		}
+		runHooks(hookPostInstall, hookStatus(context, err))
+	}()
+
+	// Local packages are installed without network access, even when offline
+	if isLocalInstallTarget(target) {
+		return installLocalPackage(target, opts)
+	}
+
+	if isOffline() {
+		return newError(errOffline, "%s", offlineError("install packages").Error())
+	}
+
+	target, version := parseInstallVersion(target)
+	if version != "" {
+		opts.version = version
+	}
+
+	repo, subpath, err := parseInstallTarget(target)
+	if err != nil {
+		return err
+	}
+
+	repo = githubize(repo)
+	if opts.binary != nil {
+		err = installBinaryPackage(repo, subpath, opts)
+	} else {
+		err = installPackage(repo, subpath, opts)
+	}
+	if err != nil {
+		// Only track public github repos
+		if !strings.HasPrefix(repo, "https://github.com/") {
+			trackEvent("install.failed", repo)
+		}
+		return err
+	}
+
+	if strings.HasPrefix(repo, "https://github.com/") {
+		trackEvent("install.success", repo)
+	}
+
+	return nil
+}
+
+// getInstallTargetDir returns the directory an install target is installed
+// in, if it exists
+func getInstallTargetDir(target string) string {
+	srcPath, err := getAkamaiCliSrcPath()
+	if err != nil {
+		return ""
+	}
+
+	var name string
+	if isLocalInstallTarget(target) {
+		name = getLocalPackageName(target)
+	} else {
+		target, _ = parseInstallVersion(target)
+		repo, subpath, err := parseInstallTarget(target)
+		if err != nil {
+			return ""
+		}
+		name = getPackageDirName(githubize(repo), subpath)
+	}
+
+	dir := filepath.Join(srcPath, name)
+	if _, err := os.Stat(dir); err != nil {
+		return ""
+	}
+
+	return dir
+}
+
+func cmdInstallFromSearch(c *cli.Context) error {
+	keywords := strings.Fields(c.String("from-search"))
+	if len(keywords) == 0 {
+		return newError(errUsage, "You must specify one or more keywords to search for")
+	}
+
+	packageList, err := fetchPackageList(fetchOptions{
+		skipIndexVerify: c.Bool("insecure-skip-index-verify"),
+		refresh:         c.Bool("refresh"),
+	})
+	if err != nil {
+		return registryError(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), defaultSearchTimeoutPerKeyword*time.Duration(len(keywords)))
+	defer cancel()
+
+	results, err := searchPackages(ctx, keywords, packageList, searchOptions{})
+	if err != nil {
+		return newError(errTimeout, "%s", err.Error())
+	}
+
+	var matches []packageListPackage
+	for _, result := range results {
+		if result.Hits >= c.Int("min-rank") {
+			matches = append(matches, result.Package)
+		}
+	}
+
+	if len(matches) == 0 {
+		return newError(errPackageNotFound, "No packages found matching \"%s\"", strings.Join(keywords, " "))
+	}
+
+	fmt.Fprintln(akamai.App.Writer, color.YellowString("The following packages will be installed:\n"))
+	for _, pkg := range matches {
+		fmt.Fprintf(akamai.App.Writer, "  %s (%s)\n", pkg.Title, pkg.Name)
+	}
+	fmt.Fprintln(akamai.App.Writer)
+
+	if !c.Bool("yes") {
+		if !isatty.IsTerminal(os.Stdout.Fd()) && !isatty.IsCygwinTerminal(os.Stdout.Fd()) {
+			return newError(errUsage, "Refusing to install multiple packages non-interactively without --yes")
+		}
+
+		fmt.Fprintf(akamai.App.Writer, "Install %d package(s)? [y/N]: ", len(matches))
+		answer := ""
+		fmt.Scanln(&answer)
+		if strings.ToLower(answer) != "y" {
+			return nil
+		}
+	}
+
+	return installRegistryPackages(matches, installOptions{
+		forceBinary:   c.Bool("force"),
+		acceptLicense: c.Bool("accept-license"),
+		insecure:      c.Bool("insecure"),
+	})
+}
+
+func cmdInstallGroup(c *cli.Context) error {
+	packageList, err := fetchPackageList(fetchOptions{
+		skipIndexVerify: c.Bool("insecure-skip-index-verify"),
+		refresh:         c.Bool("refresh"),
+	})
+	if err != nil {
+		return registryError(err)
+	}
+
+	members, err := resolveGroup(c.String("group"), packageList)
+	if err != nil {
+		return newError(errPackageNotFound, "%s", err.Error())
+	}
+
+	oldCmds := getCommands()
+
+	opts := installOptions{
+		forceBinary:   c.Bool("force"),
+		acceptLicense: c.Bool("accept-license"),
+		insecure:      c.Bool("insecure"),
+	}
+
+	for _, member := range members {
+		opts.version = member.version
+		if err := installTarget(member.pkg.getInstallTarget(), opts.forPackage(member.pkg)); err != nil {
 			return err
 		}
+	}
+
+	packageListDiff(oldCmds)
 
-		if strings.HasPrefix(repo, "https://github.com/") {
-			trackEvent("install.success", repo)
+	return nil
+}
+
+func installRegistryPackages(packages []packageListPackage, opts installOptions) error {
+	oldCmds := getCommands()
+
+	for _, pkg := range packages {
+		if err := installTarget(pkg.getInstallTarget(), opts.forPackage(pkg)); err != nil {
+			return err
 		}
 	}
 
@@ -57,83 +475,318 @@ func cmdInstall(c *cli.Context) error {
 	return nil
 }
 
-func installPackage(repo string, forceBinary bool) error {
-	srcPath, err := getAkamaiCliSrcPath()
+func installPackage(repo string, subpath string, opts installOptions) error {
+	srcPath, err := opts.getSrcPath()
 	if err != nil {
 		return err
 	}
 
 	_ = os.MkdirAll(srcPath, 0775)
 
-	akamai.StartSpinner(fmt.Sprintf("Attempting to fetch command from %s...", repo), fmt.Sprintf("Attempting to fetch command from %s...", repo)+"... ["+color.GreenString("OK")+"]\n")
+	if err := checkDiskSpace(srcPath, opts.estimatedSize); err != nil {
+		return err
+	}
+
+	dirName := getPackageDirName(repo, subpath)
+
+	// Fail before cloning when the registry says the runtime is missing
+	if opts.requirements != nil && !opts.forceBinary && !usesDocker(dirName, "") {
+		if err := checkRuntimeRequirements(*opts.requirements); err != nil {
+			return runtimeRequirementsError(dirName, err)
+		}
+	}
+
+	p := opts.getProgress()
+	p.Start(fmt.Sprintf("Attempting to fetch command from %s...", repo))
+
+	stage, err := newStagedInstall(srcPath, dirName)
+	if err != nil {
+		p.Fail()
+
+		return newError(errInstallFailed, "%s", err.Error())
+	}
+	cloneDir := stage.dir
+
+	depth := getCloneDepth(opts.version)
+	err = withRetry(getRetryPolicy(), func() error {
+		start := time.Now()
+		err := withGitAuth(repo, func(auth transport.AuthMethod) error {
+			err := clonePackageRepo(cloneDir, repo, depth, auth, p)
+			if err != nil {
+				// A failed clone can leave a partial checkout behind
+				os.RemoveAll(cloneDir)
+			}
+			return err
+		})
+		if err != nil {
+			logWarn("git clone failed", "repo", repo, "dir", cloneDir, "error", err)
+		} else {
+			logInfo("git clone", "repo", repo, "dir", cloneDir, "duration", time.Since(start).String())
+		}
+
+		return permanentGitError(err)
+	})
+
+	if err != nil {
+		stage.abort()
+
+		p.Fail()
+		return newError(getGitErrorID(err), "Unable to clone repository: %s", err.Error())
+	}
+
+	if opts.version != "" {
+		if err := checkoutVersion(cloneDir, opts.version); err != nil {
+			stage.abort()
+
+			p.Fail()
+			return newError(errVersionNotFound, "Unable to install version %s: %s", opts.version, err.Error())
+		}
+	}
+
+	var verifyWarning string
+	if opts.release != nil {
+		if opts.release.Commit != "" && depth > 0 {
+			// The release may be older than the latest commit
+			if err := ensureCommit(cloneDir, opts.release.Commit); err != nil {
+				logWarn("unable to fetch the release commit", "dir", cloneDir, "error", err)
+			}
+		}
+		if err := verifyRelease(cloneDir, *opts.release, opts.version == ""); err != nil {
+			if !opts.insecure {
+				stage.abort()
+
+				p.Fail()
+				return newError(errVerifyFailed, "Unable to verify package: %s. Use --insecure to install it anyway.", err.Error())
+			}
+			verifyWarning = err.Error()
+		}
+	}
+
+	return setupPackage(stage, subpath, packageManifest{Repo: repo, Subpath: subpath, Version: opts.version}, verifyWarning, opts, p)
+}
+
+// setupPackage completes the install of a package fetched into its staging
+// directory: it asks for license acceptance, installs the packages and
+// language dependencies it needs, and then moves the package into place and
+// records its manifest. On failure, the staged package is removed again.
+func setupPackage(stage *stagedInstall, subpath string, manifest packageManifest, verifyWarning string, opts installOptions, p progress) error {
+	dir := stage.dir
+	dirName := filepath.Base(dir)
+	source := manifest.Repo
+	if manifest.Source != "" {
+		source = manifest.Source
+	}
+
+	packageDir := filepath.Join(dir, subpath)
+	if _, err := os.Stat(filepath.Join(packageDir, "cli.json")); err != nil {
+		stage.abort()
 
-	dirName := strings.TrimSuffix(filepath.Base(repo), ".git")
-	packageDir := filepath.Join(srcPath, dirName)
-	if _, err := os.Stat(packageDir); err == nil {
-		akamai.StopSpinnerFail()
+		p.Fail()
+		return newError(errPackageInvalid, "Package does not contain a cli.json file at \"%s\".", subpath)
+	}
+
+	if !opts.forceBinary && !usesDocker(packageDir, "") {
+		if cmdPackage, err := readPackage(packageDir); err == nil {
+			if err := checkRuntimeRequirements(cmdPackage.Requirements); err != nil {
+				stage.abort()
+
+				p.Fail()
+				return runtimeRequirementsError(dirName, err)
+			}
+		}
+	}
+
+	manifest.Commit = getHeadCommit(dir)
+
+	p.Ok()
+
+	if verifyWarning != "" {
+		fmt.Fprintln(akamai.App.ErrWriter, color.YellowString("Warning: package could not be verified (%s), installing anyway because of --insecure", verifyWarning))
+	}
+
+	if strings.HasPrefix(source, "https://github.com/akamai/cli-") != true && strings.HasPrefix(source, "git@github.com:akamai/cli-") != true {
+		printInfo(akamai.App.ErrWriter, "Disclaimer: You are installing a third-party package, subject to its own terms and conditions. Akamai makes no warranty or representation with respect to the third-party package.")
+	}
+
+	if err := acceptPackageLicense(packageDir, source, opts.acceptLicense, p.Interactive()); err != nil {
+		stage.abort()
+		return err
+	}
+
+	if !opts.skipRequired {
+		if err := installRequiredPackages(packageDir, opts); err != nil {
+			stage.abort()
+			return err
+		}
+	}
 
-		return cli.NewExitError(color.RedString("Package directory already exists (%s)", packageDir), 1)
+	if !installPackageDependencies(packageDir, opts) {
+		stage.abort()
+		return newError(errBuildFailed, "")
 	}
 
-	_, err = git.PlainClone(packageDir, false, &git.CloneOptions{
-		URL:      repo,
-		Progress: nil,
+	if err := stage.promote(); err != nil {
+		return newError(errInstallFailed, "Unable to move the package into place: %s", err.Error())
+	}
+
+	if err := writeManifest(dirName, manifest); err != nil {
+		removeAllForce(stage.target)
+		return newError(errInstallFailed, "Unable to record package manifest: %s", err.Error())
+	}
+
+	warnCommandConflicts(dirName)
+
+	return nil
+}
+
+func runtimeRequirementsError(dirName string, err error) error {
+	name := strings.TrimPrefix(dirName, "cli-")
+	return newError(errRuntimeMissing, "Unable to install %s: %s. Use --force to install anyway (a prebuilt binary is used if the package provides one)", name, err.Error())
+}
+
+func checkoutVersion(dir string, version string) error {
+	repo, err := git.PlainOpen(dir)
+	if err != nil {
+		return err
+	}
+
+	hash, err := resolveVersion(repo, version)
+	if err != nil {
+		logWarn("git checkout failed", "dir", dir, "version", version, "error", err)
+		return err
+	}
+	logInfo("git checkout", "dir", dir, "version", version, "commit", hash.String())
+
+	workdir, err := repo.Worktree()
+	if err != nil {
+		return err
+	}
+
+	return workdir.Checkout(&git.CheckoutOptions{
+		Hash:  hash,
+		Force: true,
 	})
+}
 
+// getHeadCommit returns the commit checked out in the repository at dir, or
+// "" if it is not a git repository
+func getHeadCommit(dir string) string {
+	repo, err := git.PlainOpen(dir)
 	if err != nil {
-		os.RemoveAll(packageDir)
+		return ""
+	}
 
-		akamai.StopSpinnerFail()
-		return cli.NewExitError(color.RedString("Unable to clone repository: "+err.Error()), 1)
+	head, err := repo.Head()
+	if err != nil {
+		return ""
 	}
 
-	akamai.StopSpinnerOk()
+	return head.Hash().String()
+}
 
-	if strings.HasPrefix(repo, "https://github.com/akamai/cli-") != true && strings.HasPrefix(repo, "git@github.com:akamai/cli-") != true {
-		fmt.Fprintln(akamai.App.ErrWriter, color.CyanString("Disclaimer: You are installing a third-party package, subject to its own terms and conditions. Akamai makes no warranty or representation with respect to the third-party package."))
+func resolveVersion(repo *git.Repository, version string) (plumbing.Hash, error) {
+	tags := []string{version}
+	if !strings.HasPrefix(version, "v") {
+		tags = append(tags, "v"+version)
 	}
 
-	if !installPackageDependencies(packageDir, forceBinary) {
-		os.RemoveAll(packageDir)
-		return cli.NewExitError("", 1)
+	for _, tag := range tags {
+		ref, err := repo.Reference(plumbing.ReferenceName("refs/tags/"+tag), true)
+		if err != nil {
+			continue
+		}
+
+		// Annotated tags point to a tag object rather than the commit
+		if tagObject, err := repo.TagObject(ref.Hash()); err == nil {
+			commit, err := tagObject.Commit()
+			if err != nil {
+				return plumbing.ZeroHash, err
+			}
+			return commit.Hash, nil
+		}
+
+		return ref.Hash(), nil
 	}
 
+	if len(version) == 40 {
+		if commit, err := repo.CommitObject(plumbing.NewHash(version)); err == nil {
+			return commit.Hash, nil
+		}
+	}
+
+	return plumbing.ZeroHash, fmt.Errorf("no tag or commit named \"%s\" was found", version)
+}
+
+// acceptPackageLicense displays the package license and asks for it to be
+// accepted, if the package requires it
+func acceptPackageLicense(dir string, repo string, accepted bool, interactive bool) error {
+	cmdPackage, err := readPackage(dir)
+	if err != nil || !cmdPackage.License.RequireAcceptance {
+		return nil
+	}
+
+	if !accepted {
+		if !interactive {
+			return newError(errLicenseNotAccepted, "This package requires you to accept its license, use --accept-license to accept it non-interactively")
+		}
+
+		fmt.Fprintln(akamai.App.Writer, color.YellowString("\nThis package requires you to accept the following license:\n"))
+		fmt.Fprintln(akamai.App.Writer, cmdPackage.License.getLicenseText(dir))
+		fmt.Fprint(akamai.App.Writer, "\nDo you accept the license terms? [y/N]: ")
+		answer := ""
+		fmt.Scanln(&answer)
+		if strings.ToLower(answer) != "y" {
+			return newError(errLicenseNotAccepted, "License not accepted, package will not be installed")
+		}
+	}
+
+	writeAuditLog("license.accepted", map[string]string{
+		"repo":    repo,
+		"license": cmdPackage.License.Name,
+	})
+
 	return nil
 }
 
-func installPackageDependencies(dir string, forceBinary bool) bool {
-	akamai.StartSpinner("Installing...", "Installing...... ["+color.GreenString("OK")+"]\n")
+func installPackageDependencies(dir string, opts installOptions) bool {
+	p := opts.getProgress()
+	p.Start("Installing...")
 
 	cmdPackage, err := readPackage(dir)
 
 	if err != nil {
-		akamai.StopSpinnerFail()
+		p.Fail()
 		fmt.Fprintln(akamai.App.ErrWriter, err.Error())
 		return false
 	}
 
 	lang := determineCommandLanguage(cmdPackage)
+	if usesDocker(dir, "") {
+		lang = runtimeDocker
+	}
 
 	var success bool
 	switch lang {
+	case runtimeDocker:
+		success, err = installDocker(dir, cmdPackage, p)
 	case "php":
-		success, err = installPHP(dir, cmdPackage)
+		success, err = installPHP(dir, cmdPackage, p)
 	case "javascript":
-		success, err = installJavaScript(dir, cmdPackage)
+		success, err = installJavaScript(dir, cmdPackage, p)
 	case "ruby":
-		success, err = installRuby(dir, cmdPackage)
+		success, err = installRuby(dir, cmdPackage, p)
 	case "python":
-		success, err = installPython(dir, cmdPackage)
+		success, err = installPython(dir, cmdPackage, p)
 	case "go":
-		success, err = installGolang(dir, cmdPackage)
+		success, err = installGolang(dir, cmdPackage, p)
 	default:
-		akamai.StopSpinnerWarnOk()
-		fmt.Fprintln(akamai.App.Writer, color.CyanString("Package installed successfully, however package type is unknown, and may or may not function correctly."))
+		p.WarnOk()
+		printInfo(akamai.App.Writer, "Package installed successfully, however package type is unknown, and may or may not function correctly.")
 		return true
 	}
 
 	if success && err == nil {
-		akamai.StopSpinnerOk()
+		p.Ok()
 		return true
 	}
 
@@ -142,10 +795,10 @@ func installPackageDependencies(dir string, forceBinary bool) bool {
 		if cmd.Bin != "" {
 			if first {
 				first = false
-				akamai.StopSpinnerWarnOk()
+				p.WarnOk()
 				fmt.Fprintln(akamai.App.Writer, color.CyanString(err.Error()))
-				if !forceBinary {
-					if !isatty.IsTerminal(os.Stdout.Fd()) && !isatty.IsCygwinTerminal(os.Stdout.Fd()) {
+				if !opts.forceBinary {
+					if !p.Interactive() {
 						return false
 					}
 
@@ -160,19 +813,19 @@ func installPackageDependencies(dir string, forceBinary bool) bool {
 				os.MkdirAll(filepath.Join(dir, "bin"), 0775)
 			}
 
-			akamai.StartSpinner("Downloading binary...", "Downloading binary...... ["+color.GreenString("OK")+"]\n")
-			if downloadBin(filepath.Join(dir, "bin"), cmd) {
-				akamai.StopSpinnerOk()
+			p.Start("Downloading binary...")
+			if downloadBin(filepath.Join(dir, "bin"), cmd, opts) {
+				p.Ok()
 				return true
 			} else {
-				akamai.StopSpinnerFail()
+				p.Fail()
 				fmt.Fprintln(akamai.App.ErrWriter, color.RedString("Unable to download binary: "+err.Error()))
 				return false
 			}
 		} else {
 			if first {
 				first = false
-				akamai.StopSpinnerFail()
+				p.Fail()
 				fmt.Fprintln(akamai.App.ErrWriter, color.RedString(err.Error()))
 				return false
 			}
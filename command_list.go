@@ -18,6 +18,9 @@ package main
 
 import (
 	"fmt"
+	"sort"
+	"strings"
+	"text/tabwriter"
 
 	akamai "github.com/akamai/cli-common-golang"
 	"github.com/fatih/color"
@@ -25,71 +28,169 @@ import (
 )
 
 func cmdList(c *cli.Context) error {
-	bold := color.New(color.FgWhite, color.Bold)
+	if c.IsSet("remote") {
+		return listRemotePackages()
+	}
 
-	commands := listInstalledCommands(nil, nil)
+	if getOutputFormat() != formatTable {
+		return printStructured(getInstalledCommandList())
+	}
 
-	if c.IsSet("remote") {
-		packageList, err := fetchPackageList()
-		if err != nil {
-			return cli.NewExitError("Unable to fetch remote package list", 1)
-		}
+	listInstalledCommands(nil, nil)
 
-		foundCommands := true
-		for _, cmd := range packageList.Packages {
-			for _, command := range cmd.Commands {
-				if _, ok := commands[command.Name]; ok != true {
-					foundCommands = false
-					continue
-				}
+	return nil
+}
+
+type installedCommand struct {
+	Name        string   `json:"name"`
+	Version     string   `json:"version"`
+	Aliases     []string `json:"aliases"`
+	Description string   `json:"description"`
+}
+
+func getInstalledCommandList() []installedCommand {
+	commands := []installedCommand{}
+	for _, cmd := range getCommands() {
+		for _, command := range cmd.Commands {
+			aliases := command.Aliases
+			if aliases == nil {
+				aliases = []string{}
 			}
+			commands = append(commands, installedCommand{command.Name, command.Version, aliases, command.Description})
 		}
+	}
 
-		if !foundCommands {
-			fmt.Fprintln(akamai.App.Writer, color.YellowString("\nAvailable Commands:\n\n"))
-		} else {
-			return nil
+	return commands
+}
+
+// listRemotePackages shows the remote package list in a table, along with
+// which packages are installed and which have updates available
+func listRemotePackages() error {
+	packageList, err := fetchPackageList(fetchOptions{})
+	if err != nil {
+		return cli.NewExitError(color.RedString("Unable to fetch remote package list"), 1)
+	}
+
+	var installed []commandPackage
+	for _, dir := range getPackageDirs() {
+		if cmdPackage, err := readPackage(dir); err == nil {
+			installed = append(installed, cmdPackage)
 		}
+	}
 
-		for _, cmd := range packageList.Packages {
-			for _, command := range cmd.Commands {
-				if _, ok := commands[command.Name]; ok == true {
-					continue
-				}
-				bold.Printf("  %s", command.Name)
-				if len(command.Aliases) > 0 {
-					var aliases string
-
-					if len(command.Aliases) == 1 {
-						aliases = "alias"
-					} else {
-						aliases = "aliases"
-					}
+	statuses := getRemotePackageStatuses(packageList, installed)
+	if getOutputFormat() != formatTable {
+		return printStructured(statuses)
+	}
 
-					fmt.Fprintf(akamai.App.Writer, " (%s: ", aliases)
-					for i, alias := range command.Aliases {
-						bold.Print(alias)
-						if i < len(command.Aliases)-1 {
-							fmt.Fprint(akamai.App.Writer, ", ")
-						}
-					}
-					fmt.Fprint(akamai.App.Writer, ")")
-				}
+	w := tabwriter.NewWriter(akamai.App.Writer, 0, 8, 2, ' ', 0)
+	fmt.Fprintln(w, "PACKAGE\tINSTALLED\tLATEST\tSTATUS")
+	for _, status := range statuses {
+		installedVersion := status.Installed
+		if installedVersion == "" {
+			installedVersion = "-"
+		}
 
-				fmt.Fprintln(akamai.App.Writer)
+		latest := status.Latest
+		if latest == "" {
+			latest = "-"
+		}
+
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", status.Name, installedVersion, latest, status.colorStatus())
+	}
+	w.Flush()
 
-				fmt.Fprintf(akamai.App.Writer, "    %s\n", command.Description)
+	fmt.Fprintf(akamai.App.Writer, "\nInstall using \"%s\", or update using \"%s\".\n", color.BlueString("%s install [package]", self()), color.BlueString("%s update [command]", self()))
+
+	return nil
+}
+
+const (
+	packageStatusInstalled       = "installed"
+	packageStatusNotInstalled    = "not installed"
+	packageStatusUpdateAvailable = "update available"
+	packageStatusLocal           = "installed (not in package list)"
+)
+
+type remotePackageStatus struct {
+	Name       string `json:"name"`
+	Installed  string `json:"installed"`
+	Latest     string `json:"latest"`
+	Status     string `json:"status"`
+	Deprecated bool   `json:"deprecated,omitempty"`
+}
+
+func (status remotePackageStatus) colorStatus() string {
+	if status.Deprecated {
+		return color.YellowString(status.Status + " (deprecated)")
+	}
+
+	switch status.Status {
+	case packageStatusInstalled:
+		return color.GreenString(status.Status)
+	case packageStatusUpdateAvailable:
+		return color.CyanString(status.Status)
+	case packageStatusLocal:
+		return color.YellowString(status.Status)
+	}
+
+	return status.Status
+}
+
+// getRemotePackageStatuses matches the installed packages to the package list,
+// by their commands. Installed packages the list does not know about come last.
+func getRemotePackageStatuses(packageList *packageList, installed []commandPackage) []remotePackageStatus {
+	versions := make(map[string]string)
+	for _, cmdPackage := range installed {
+		for _, cmd := range cmdPackage.Commands {
+			versions[strings.ToLower(cmd.Name)] = cmd.Version
+		}
+	}
+
+	listed := make(map[string]bool)
+	var statuses []remotePackageStatus
+	for _, pkg := range packageList.Packages {
+		status := remotePackageStatus{Name: pkg.Name, Latest: pkg.Version, Status: packageStatusNotInstalled, Deprecated: pkg.Deprecated}
+
+		for _, cmd := range pkg.Commands {
+			listed[strings.ToLower(cmd.Name)] = true
+		}
+
+		if version, ok := getInstalledPackageVersion(pkg, versions); ok {
+			status.Installed = version
+			status.Status = packageStatusInstalled
+			if version != "" && pkg.Version != "" && compareVersions(pkg.Version, version) > 0 {
+				status.Status = packageStatusUpdateAvailable
 			}
 		}
 
-		fmt.Fprintf(akamai.App.Writer, "\nInstall using \"%s\".\n", color.BlueString("%s install [command]", self()))
+		statuses = append(statuses, status)
 	}
 
-	return nil
+	sort.SliceStable(statuses, func(i, j int) bool {
+		return statuses[i].Name < statuses[j].Name
+	})
+
+	for _, cmdPackage := range installed {
+		if len(cmdPackage.Commands) == 0 || listed[strings.ToLower(cmdPackage.Commands[0].Name)] {
+			continue
+		}
+
+		statuses = append(statuses, remotePackageStatus{
+			Name:      cmdPackage.Commands[0].Name,
+			Installed: cmdPackage.Commands[0].Version,
+			Status:    packageStatusLocal,
+		})
+	}
+
+	return statuses
 }
+
 func listInstalledCommands(added map[string]bool, removed map[string]bool) map[string]bool {
 	bold := color.New(color.FgWhite, color.Bold)
 
+	deprecated := getDeprecatedCommands(readCachedPackageList())
+
 	commands := make(map[string]bool)
 	fmt.Fprintln(akamai.App.Writer, color.YellowString("\nInstalled Commands:\n"))
 	for _, cmd := range getCommands() {
@@ -114,7 +215,7 @@ func listInstalledCommands(added map[string]bool, removed map[string]bool) map[s
 
 				fmt.Fprintf(akamai.App.Writer, " (%s: ", aliases)
 				for i, alias := range command.Aliases {
-					bold.Print(alias)
+					fmt.Fprint(akamai.App.Writer, bold.Sprint(alias))
 					if i < len(command.Aliases)-1 {
 						fmt.Fprint(akamai.App.Writer, ", ")
 					}
@@ -125,6 +226,9 @@ func listInstalledCommands(added map[string]bool, removed map[string]bool) map[s
 			fmt.Fprintln(akamai.App.Writer)
 
 			fmt.Fprintf(akamai.App.Writer, "    %s\n", command.Description)
+			if notice, ok := deprecated[command.Name]; ok {
+				fmt.Fprintf(akamai.App.Writer, "    %s\n", color.YellowString(notice))
+			}
 		}
 	}
 	fmt.Fprintf(akamai.App.Writer, "\nSee \"%s\" for details.\n", color.BlueString("%s help [command]", self()))
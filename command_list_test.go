@@ -0,0 +1,49 @@
+/*
+ Copyright 2018. Akamai Technologies, Inc
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package main
+
+import "testing"
+
+func TestGetRemotePackageStatuses(t *testing.T) {
+	installed := []commandPackage{
+		{Commands: []Command{{Name: "purge", Version: "1.0.0"}}},
+		{Commands: []Command{{Name: "property", Version: "v0.6"}}},
+		{Commands: []Command{{Name: "custom", Version: "0.1.0"}}},
+	}
+
+	list := testPackageList()
+	list.Packages = append(list.Packages, packageListPackage{Name: "dns", Version: "2.0.0", Commands: []Command{{Name: "dns"}}})
+	list.Packages[0].Version = "1.1.0"
+
+	expected := []remotePackageStatus{
+		{Name: "dns", Installed: "", Latest: "2.0.0", Status: packageStatusNotInstalled},
+		{Name: "property", Installed: "v0.6", Latest: "0.6.0", Status: packageStatusInstalled},
+		{Name: "purge", Installed: "1.0.0", Latest: "1.1.0", Status: packageStatusUpdateAvailable},
+		{Name: "custom", Installed: "0.1.0", Latest: "", Status: packageStatusLocal},
+	}
+
+	statuses := getRemotePackageStatuses(list, installed)
+	if len(statuses) != len(expected) {
+		t.Fatalf("getRemotePackageStatuses() => %v, wanted: %v", statuses, expected)
+	}
+
+	for i := range statuses {
+		if statuses[i] != expected[i] {
+			t.Errorf("getRemotePackageStatuses()[%d] => %v, wanted: %v", i, statuses[i], expected[i])
+		}
+	}
+}
@@ -0,0 +1,172 @@
+/*
+ Copyright 2018. Akamai Technologies, Inc
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+
+	akamai "github.com/akamai/cli-common-golang"
+	"github.com/fatih/color"
+	"github.com/urfave/cli"
+	"gopkg.in/src-d/go-git.v4"
+)
+
+const (
+	defaultLockfile = "akamai-cli.lock"
+	lockfileVersion = 1
+)
+
+// lockfile records exactly which packages are installed, so the same set can be
+// installed elsewhere with "akamai install --from-lock"
+type lockfile struct {
+	Version  int             `json:"version"`
+	Packages []lockedPackage `json:"packages"`
+}
+
+type lockedPackage struct {
+	Name    string `json:"name"`
+	Repo    string `json:"repo"`
+	Subpath string `json:"subpath,omitempty"`
+	Version string `json:"version,omitempty"`
+	Commit  string `json:"commit"`
+}
+
+func cmdLockWrite(c *cli.Context) error {
+	path := defaultLockfile
+	if c.Args().Present() {
+		path = c.Args().First()
+	}
+
+	lock, err := getInstalledLock()
+	if err != nil {
+		return cli.NewExitError(color.RedString(err.Error()), 1)
+	}
+
+	data, err := json.MarshalIndent(lock, "", "  ")
+	if err != nil {
+		return cli.NewExitError(color.RedString(err.Error()), 1)
+	}
+
+	if err := ioutil.WriteFile(path, append(data, '\n'), 0664); err != nil {
+		return cli.NewExitError(color.RedString("Unable to write lockfile: %s", err.Error()), 1)
+	}
+
+	fmt.Fprintf(akamai.App.Writer, "Wrote %d package(s) to %s\n", len(lock.Packages), path)
+	return nil
+}
+
+// getInstalledLock describes each installed package by its repository and checked out commit
+func getInstalledLock() (lockfile, error) {
+	lock := lockfile{Version: lockfileVersion, Packages: make([]lockedPackage, 0)}
+
+	srcPath, err := getAkamaiCliSrcPath()
+	if err != nil {
+		return lock, err
+	}
+
+	paths, _ := filepath.Glob(filepath.Join(srcPath, "*"))
+	for _, path := range paths {
+		name := filepath.Base(path)
+
+		repo, err := git.PlainOpen(path)
+		if err != nil {
+			// Not installed with "akamai install", it can't be reproduced
+			fmt.Fprintln(akamai.App.ErrWriter, color.CyanString("Warning: skipping %s, it is not a git repository", name))
+			continue
+		}
+
+		head, err := repo.Head()
+		if err != nil {
+			return lock, fmt.Errorf("Unable to read the checked out commit of %s (%s)", name, err.Error())
+		}
+
+		locked := lockedPackage{Name: name, Commit: head.Hash().String()}
+		if manifest, err := readManifest(name); err == nil {
+			locked.Repo = manifest.Repo
+			locked.Subpath = manifest.Subpath
+			locked.Version = manifest.Version
+		}
+
+		if locked.Repo == "" {
+			remote, err := repo.Remote(git.DefaultRemoteName)
+			if err != nil || len(remote.Config().URLs) == 0 {
+				fmt.Fprintln(akamai.App.ErrWriter, color.CyanString("Warning: skipping %s, its repository URL is unknown", name))
+				continue
+			}
+			locked.Repo = remote.Config().URLs[0]
+		}
+
+		lock.Packages = append(lock.Packages, locked)
+	}
+
+	return lock, nil
+}
+
+func readLockfile(path string) (lockfile, error) {
+	lock := lockfile{}
+
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return lock, err
+	}
+
+	if err := json.Unmarshal(data, &lock); err != nil {
+		return lock, err
+	}
+
+	if lock.Version > lockfileVersion {
+		return lock, fmt.Errorf("lockfile version %d is not supported, upgrade Akamai CLI", lock.Version)
+	}
+
+	return lock, nil
+}
+
+func cmdInstallFromLock(c *cli.Context) error {
+	lock, err := readLockfile(c.String("from-lock"))
+	if err != nil {
+		return cli.NewExitError(color.RedString("Unable to read lockfile: %s", err.Error()), 1)
+	}
+
+	oldCmds := getCommands()
+
+	// The lockfile already lists every package, dependencies included
+	opts := installOptions{
+		forceBinary:   c.Bool("force"),
+		acceptLicense: c.Bool("accept-license"),
+		skipRequired:  true,
+	}
+
+	for _, pkg := range lock.Packages {
+		target := pkg.Repo
+		if pkg.Subpath != "" {
+			target += "#" + pkg.Subpath
+		}
+
+		// The commit is what makes the install reproducible, tags can move
+		opts.version = pkg.Commit
+		if err := installTarget(target, opts); err != nil {
+			return err
+		}
+	}
+
+	packageListDiff(oldCmds)
+
+	return nil
+}
@@ -0,0 +1,111 @@
+/*
+ Copyright 2018. Akamai Technologies, Inc
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"text/tabwriter"
+
+	akamai "github.com/akamai/cli-common-golang"
+	"github.com/fatih/color"
+	"github.com/urfave/cli"
+)
+
+var matrixRuntimes = []string{"go", "php", "node", "ruby", "python"}
+
+type matrixRow struct {
+	Name         string            `json:"name"`
+	Title        string            `json:"title"`
+	Requirements map[string]string `json:"requirements"`
+}
+
+func cmdMatrix(c *cli.Context) error {
+	if c.Bool("json") && c.Bool("csv") {
+		return cli.NewExitError(color.RedString("--json and --csv cannot be used together"), 1)
+	}
+
+	packageList, err := fetchPackageList(fetchOptions{})
+	if err != nil {
+		return cli.NewExitError(color.RedString(err.Error()), 1)
+	}
+
+	rows := make([]matrixRow, 0, len(packageList.Packages))
+	for _, pkg := range packageList.Packages {
+		rows = append(rows, matrixRow{
+			Name:  pkg.Name,
+			Title: pkg.Title,
+			Requirements: map[string]string{
+				"go":     pkg.Requirements.Go,
+				"php":    pkg.Requirements.Php,
+				"node":   pkg.Requirements.Node,
+				"ruby":   pkg.Requirements.Ruby,
+				"python": pkg.Requirements.Python,
+			},
+		})
+	}
+
+	switch {
+	case c.Bool("json"):
+		for key := range rows {
+			for runtime, version := range rows[key].Requirements {
+				if version == "" {
+					delete(rows[key].Requirements, runtime)
+				}
+			}
+		}
+
+		output, err := json.MarshalIndent(rows, "", "  ")
+		if err != nil {
+			return cli.NewExitError(color.RedString(err.Error()), 1)
+		}
+		fmt.Fprintln(akamai.App.Writer, string(output))
+	case c.Bool("csv"):
+		w := csv.NewWriter(akamai.App.Writer)
+		w.Write(append([]string{"name", "title"}, matrixRuntimes...))
+		for _, row := range rows {
+			record := []string{row.Name, row.Title}
+			for _, runtime := range matrixRuntimes {
+				record = append(record, row.Requirements[runtime])
+			}
+			w.Write(record)
+		}
+		w.Flush()
+		if err := w.Error(); err != nil {
+			return cli.NewExitError(color.RedString(err.Error()), 1)
+		}
+	default:
+		w := tabwriter.NewWriter(akamai.App.Writer, 0, 8, 2, ' ', 0)
+		fmt.Fprintln(w, "PACKAGE\t"+strings.ToUpper(strings.Join(matrixRuntimes, "\t")))
+		for _, row := range rows {
+			cells := []string{row.Name}
+			for _, runtime := range matrixRuntimes {
+				version := row.Requirements[runtime]
+				if version == "" {
+					version = "-"
+				}
+				cells = append(cells, version)
+			}
+			fmt.Fprintln(w, strings.Join(cells, "\t"))
+		}
+		w.Flush()
+	}
+
+	return nil
+}
@@ -0,0 +1,252 @@
+/*
+ Copyright 2018. Akamai Technologies, Inc
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	akamai "github.com/akamai/cli-common-golang"
+	"github.com/fatih/color"
+	"github.com/go-ini/ini"
+	"github.com/mitchellh/go-homedir"
+	"github.com/urfave/cli"
+)
+
+// cmdMigrateHome moves the packages, config, and caches of the CLI to a new
+// home directory, fixes up the paths that point into the old one, and points
+// the CLI at the new one
+func cmdMigrateHome(c *cli.Context) error {
+	if !c.Args().Present() {
+		return cli.NewExitError(color.RedString("You must specify the new home directory"), 1)
+	}
+
+	newHome, err := homedir.Expand(c.Args().First())
+	if err == nil {
+		newHome, err = filepath.Abs(newHome)
+	}
+	if err != nil {
+		return cli.NewExitError(color.RedString("Invalid home directory: %s", err.Error()), 1)
+	}
+
+	oldPath, err := getAkamaiCliPath()
+	if err != nil {
+		return err
+	}
+
+	newPath := filepath.Join(newHome, ".akamai-cli")
+	if newPath == oldPath {
+		return cli.NewExitError(color.RedString("Akamai CLI already lives in %s", newHome), 1)
+	}
+
+	if _, ok := rebasePath(newPath, oldPath, oldPath); ok {
+		return cli.NewExitError(color.RedString("The new home directory can't be inside %s", oldPath), 1)
+	}
+
+	entries, _ := filepath.Glob(filepath.Join(newPath, "*"))
+	if len(entries) == 1 && entries[0] == filepath.Join(newPath, "config") && readHomeRedirect(newHome) != "" {
+		// Moving back to the user's home directory, which only points elsewhere
+		os.Remove(entries[0])
+	} else if len(entries) > 0 {
+		return cli.NewExitError(color.RedString("%s already exists and is not empty", newPath), 1)
+	}
+
+	p := newSpinnerProgress()
+	p.Start(fmt.Sprintf("Moving %s to %s...", oldPath, newPath))
+
+	if err := moveDir(oldPath, newPath); err != nil {
+		p.Fail()
+		return cli.NewExitError(color.RedString("Unable to move the home directory: %s", err.Error()), 1)
+	}
+	logInfo("moved home directory", "from", oldPath, "to", newPath)
+
+	p.Ok()
+
+	if err := rewriteConfigPaths(filepath.Join(newPath, "config"), oldPath, newPath); err != nil {
+		fmt.Fprintln(akamai.App.ErrWriter, color.YellowString("Warning: unable to update the paths in the config: %s", err.Error()))
+	}
+
+	if err := relinkDir(newPath, oldPath, newPath); err != nil {
+		fmt.Fprintln(akamai.App.ErrWriter, color.YellowString("Warning: unable to update the links in packages: %s", err.Error()))
+	}
+
+	if os.Getenv(cliHomeEnv) != "" {
+		printInfo(akamai.App.Writer, "Set %s=%s for Akamai CLI to use the new home directory", cliHomeEnv, newHome)
+		return nil
+	}
+
+	if err := writeHomeRedirect(newHome); err != nil {
+		return cli.NewExitError(color.RedString("Unable to record the new home directory: %s. Set %s=%s to use it.", err.Error(), cliHomeEnv, newHome), 1)
+	}
+	printInfo(akamai.App.Writer, "Akamai CLI now lives in %s", newHome)
+
+	return nil
+}
+
+// writeHomeRedirect sets cli.home in the config of the user's home directory,
+// creating it if the home directory was moved away
+func writeHomeRedirect(newHome string) error {
+	home, err := homedir.Dir()
+	if err != nil {
+		return err
+	}
+
+	cliPath := filepath.Join(home, ".akamai-cli")
+	if err := os.MkdirAll(cliPath, 0755); err != nil {
+		return err
+	}
+
+	configPath := filepath.Join(cliPath, "config")
+	iniFile := ini.Empty()
+	if _, err := os.Stat(configPath); err == nil {
+		if iniFile, err = ini.Load(configPath); err != nil {
+			return err
+		}
+	}
+
+	if filepath.Clean(newHome) == filepath.Clean(home) {
+		iniFile.Section("cli").DeleteKey("home")
+	} else {
+		iniFile.Section("cli").Key("home").SetValue(newHome)
+	}
+
+	return iniFile.SaveTo(configPath)
+}
+
+// moveDir moves src to dst, copying it when it is on another file system
+func moveDir(src string, dst string) error {
+	if err := os.MkdirAll(filepath.Dir(dst), 0755); err != nil {
+		return err
+	}
+
+	// An empty directory is in the way of the rename
+	os.Remove(dst)
+	if err := os.Rename(src, dst); err == nil {
+		return nil
+	}
+
+	if err := copyTree(src, dst); err != nil {
+		removeAllForce(dst)
+		return err
+	}
+
+	return removeAllForce(src)
+}
+
+// copyTree copies everything in src to dst, unlike copyPackageDir including
+// git metadata
+func copyTree(src string, dst string) error {
+	return filepath.Walk(src, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+
+		rel, err := filepath.Rel(src, path)
+		if err != nil {
+			return err
+		}
+
+		target := filepath.Join(dst, rel)
+		switch {
+		case info.IsDir():
+			return os.MkdirAll(target, info.Mode()|0700)
+		case info.Mode()&os.ModeSymlink != 0:
+			link, err := os.Readlink(path)
+			if err != nil {
+				return err
+			}
+			return createSymlink(link, target)
+		case info.Mode().IsRegular():
+			return copyFile(path, target, info.Mode())
+		}
+
+		return nil
+	})
+}
+
+// rewriteConfigPaths updates the config values that are paths in the old home
+// directory, such as cli.cache-path, to be in the new one
+func rewriteConfigPaths(configPath string, oldPath string, newPath string) error {
+	if _, err := os.Stat(configPath); os.IsNotExist(err) {
+		return nil
+	}
+
+	iniFile, err := ini.Load(configPath)
+	if err != nil {
+		return err
+	}
+
+	changed := false
+	for _, section := range iniFile.Sections() {
+		for _, key := range section.Keys() {
+			if value, ok := rebasePath(key.String(), oldPath, newPath); ok {
+				logDebug("updating config path", "key", section.Name()+"."+key.Name(), "value", value)
+				key.SetValue(value)
+				changed = true
+			}
+		}
+	}
+
+	if !changed {
+		return nil
+	}
+
+	return iniFile.SaveTo(configPath)
+}
+
+// relinkDir points the symlinks in dir to the old home directory, such as
+// those created by package managers with absolute paths, to the new one
+func relinkDir(dir string, oldPath string, newPath string) error {
+	return filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil || info.Mode()&os.ModeSymlink == 0 {
+			return nil
+		}
+
+		link, err := os.Readlink(path)
+		if err != nil || !filepath.IsAbs(link) {
+			return nil
+		}
+
+		target, ok := rebasePath(link, oldPath, newPath)
+		if !ok {
+			return nil
+		}
+
+		if err := os.Remove(path); err != nil {
+			return err
+		}
+
+		return createSymlink(target, path)
+	})
+}
+
+// rebasePath returns path moved from below oldRoot to below newRoot, and
+// whether it was below oldRoot
+func rebasePath(path string, oldRoot string, newRoot string) (string, bool) {
+	if path == "" || !filepath.IsAbs(path) {
+		return path, false
+	}
+
+	rel, err := filepath.Rel(oldRoot, path)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return path, false
+	}
+
+	return filepath.Join(newRoot, rel), true
+}
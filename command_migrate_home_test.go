@@ -0,0 +1,47 @@
+/*
+ Copyright 2018. Akamai Technologies, Inc
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package main
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestRebasePath(t *testing.T) {
+	oldRoot := filepath.FromSlash("/home/ci/.akamai-cli")
+	newRoot := filepath.FromSlash("/srv/cli/.akamai-cli")
+
+	rebaseTests := []struct {
+		path     string
+		expected string
+		ok       bool
+	}{
+		{"/home/ci/.akamai-cli/cache", "/srv/cli/.akamai-cli/cache", true},
+		{"/home/ci/.akamai-cli", "/srv/cli/.akamai-cli", true},
+		{"/home/ci/.akamai-cli-old/cache", "/home/ci/.akamai-cli-old/cache", false},
+		{"/home/ci/.edgerc", "/home/ci/.edgerc", false},
+		{"cache", "cache", false},
+		{"", "", false},
+	}
+
+	for _, tt := range rebaseTests {
+		path, ok := rebasePath(filepath.FromSlash(tt.path), oldRoot, newRoot)
+		if ok != tt.ok || path != filepath.FromSlash(tt.expected) {
+			t.Errorf("rebasePath(%s) => %s, %t, wanted: %s, %t", tt.path, path, ok, tt.expected, tt.ok)
+		}
+	}
+}
@@ -0,0 +1,163 @@
+/*
+ Copyright 2018. Akamai Technologies, Inc
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
+
+	akamai "github.com/akamai/cli-common-golang"
+	"github.com/fatih/color"
+	"github.com/urfave/cli"
+	"gopkg.in/src-d/go-git.v4"
+	"gopkg.in/src-d/go-git.v4/plumbing"
+)
+
+const (
+	outdatedSourceRegistry = "registry"
+	outdatedSourceTag      = "git tag"
+)
+
+// outdatedPackage is an installed package with a newer version available
+type outdatedPackage struct {
+	Name      string `json:"name"`
+	Current   string `json:"current"`
+	Available string `json:"available"`
+	// Source is where the available version was found: the registry, or a git tag of the package repository
+	Source string `json:"source"`
+	// Pinned is the version the package was installed at with <package>@<version>
+	Pinned string `json:"pinned,omitempty"`
+}
+
+// cmdOutdated lists the installed packages with newer versions, and exits
+// with status 2 if there are any, like "akamai update --check"
+func cmdOutdated(c *cli.Context) error {
+	list, err := fetchPackageList(fetchOptions{})
+	if err != nil {
+		fmt.Fprintln(akamai.App.ErrWriter, color.YellowString("Warning: %s, comparing with the package repositories' tags instead", err.Error()))
+		list = &packageList{}
+	}
+
+	dirs := getPackageDirs()
+	outdated := []outdatedPackage{}
+	for _, dir := range dirs {
+		pkg, ok := checkPackageOutdated(dir, list)
+		if ok {
+			outdated = append(outdated, pkg)
+		}
+	}
+
+	if getOutputFormat() != formatTable {
+		if err := printStructured(outdated); err != nil {
+			return err
+		}
+	} else if len(outdated) > 0 {
+		w := tabwriter.NewWriter(akamai.App.Writer, 0, 8, 2, ' ', 0)
+		fmt.Fprintln(w, "PACKAGE\tCURRENT\tAVAILABLE\tSOURCE")
+		for _, pkg := range outdated {
+			current := pkg.Current
+			if pkg.Pinned != "" {
+				current += " (pinned)"
+			}
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", pkg.Name, current, color.GreenString(pkg.Available), pkg.Source)
+		}
+		w.Flush()
+		fmt.Fprintln(akamai.App.Writer)
+	}
+
+	if len(outdated) > 0 {
+		return cli.NewExitError(color.YellowString("%d of %d installed package(s) are outdated, run \"%s update\" to update them", len(outdated), len(dirs), self()), 2)
+	}
+
+	printInfo(akamai.App.Writer, "All %d installed package(s) are up-to-date", len(dirs))
+	return nil
+}
+
+// checkPackageOutdated compares the version of the package in dir with the
+// registry's, or if the registry does not know it, with the newest version
+// tag of its repository
+func checkPackageOutdated(dir string, list *packageList) (outdatedPackage, bool) {
+	root := getPackageRoot(dir)
+	name := filepath.Base(root)
+	manifest, _ := readManifest(name)
+
+	cmdPackage, err := readPackage(dir)
+	if err != nil || len(cmdPackage.Commands) == 0 || cmdPackage.Commands[0].Version == "" {
+		return outdatedPackage{}, false
+	}
+
+	pkg := outdatedPackage{Name: name, Current: cmdPackage.Commands[0].Version, Pinned: manifest.Version}
+	if registryPkg, ok := list.findPackage(normalizePackageName(name)); ok && registryPkg.Version != "" {
+		pkg.Available = registryPkg.Version
+		pkg.Source = outdatedSourceRegistry
+	} else if manifest.Source == "" && !isOffline() {
+		pkg.Available = getLatestTagVersion(root)
+		pkg.Source = outdatedSourceTag
+	}
+
+	if pkg.Available == "" || compareVersions(pkg.Available, pkg.Current) <= 0 {
+		return outdatedPackage{}, false
+	}
+
+	return pkg, true
+}
+
+// getLatestTagVersion fetches the repository of the package at root, and
+// returns the newest version it has a tag for, if any
+func getLatestTagVersion(root string) string {
+	repo, err := git.PlainOpen(root)
+	if err != nil {
+		return ""
+	}
+
+	if err := fetchPackageRemote(repo, packageTagsRefSpec); err != nil {
+		logWarn("unable to fetch tags", "package", filepath.Base(root), "error", err)
+	}
+
+	iter, err := repo.Tags()
+	if err != nil {
+		return ""
+	}
+
+	var tags []string
+	iter.ForEach(func(ref *plumbing.Reference) error {
+		tags = append(tags, ref.Name().Short())
+		return nil
+	})
+
+	return latestVersionTag(tags)
+}
+
+// latestVersionTag returns the newest of the tags that are versions, without
+// any v prefix, ignoring pre-releases
+func latestVersionTag(tags []string) string {
+	latest := ""
+	for _, tag := range tags {
+		version := strings.TrimPrefix(tag, "v")
+		if _, n, ok := parseVersionParts(version); !ok || n == 0 || getPrerelease(version) != "" {
+			continue
+		}
+
+		if latest == "" || compareVersions(version, latest) > 0 {
+			latest = version
+		}
+	}
+
+	return latest
+}
@@ -0,0 +1,37 @@
+/*
+ Copyright 2018. Akamai Technologies, Inc
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package main
+
+import "testing"
+
+func TestLatestVersionTag(t *testing.T) {
+	tagTests := []struct {
+		tags   []string
+		latest string
+	}{
+		{[]string{"v1.0.0", "v1.2.0", "v1.10.0", "v1.9.3"}, "1.10.0"},
+		{[]string{"1.0.0", "v2.0.0-beta.1", "release-3"}, "1.0.0"},
+		{[]string{"latest", "x"}, ""},
+		{nil, ""},
+	}
+
+	for _, tt := range tagTests {
+		if latest := latestVersionTag(tt.tags); latest != tt.latest {
+			t.Errorf("latestVersionTag(%v) => %s, wanted: %s", tt.tags, latest, tt.latest)
+		}
+	}
+}
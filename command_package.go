@@ -0,0 +1,155 @@
+/*
+ Copyright 2018. Akamai Technologies, Inc
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	akamai "github.com/akamai/cli-common-golang"
+	"github.com/fatih/color"
+	"github.com/urfave/cli"
+)
+
+type installedPackage struct {
+	Name     string   `json:"name"`
+	Commands []string `json:"commands"`
+	Enabled  bool     `json:"enabled"`
+}
+
+func cmdPackageDisable(c *cli.Context) error {
+	return setPackagesDisabled(c, true)
+}
+
+func cmdPackageEnable(c *cli.Context) error {
+	return setPackagesDisabled(c, false)
+}
+
+func setPackagesDisabled(c *cli.Context, disabled bool) error {
+	action := "enable"
+	if disabled {
+		action = "disable"
+	}
+
+	if !c.Args().Present() {
+		return cli.NewExitError(color.RedString("You must specify a package or command to %s", action), 1)
+	}
+
+	packages := getInstalledPackages()
+	for _, name := range c.Args() {
+		pkg, err := findInstalledPackage(name, packages)
+		if err != nil {
+			return cli.NewExitError(color.RedString(err.Error()), 1)
+		}
+
+		manifest, _ := readManifest(pkg.Name)
+		if manifest.Disabled == disabled {
+			printInfo(akamai.App.Writer, "Package %s is already %sd", pkg.Name, action)
+			continue
+		}
+
+		manifest.Disabled = disabled
+		if err := writeManifest(pkg.Name, manifest); err != nil {
+			return cli.NewExitError(color.RedString("Unable to %s %s: %s", action, pkg.Name, err.Error()), 1)
+		}
+		logInfo("package "+action+"d", "package", pkg.Name)
+
+		fmt.Fprintf(akamai.App.Writer, "Package %s %sd (commands: %s)\n", color.New(color.Bold).Sprint(pkg.Name), action, strings.Join(pkg.Commands, ", "))
+	}
+
+	return nil
+}
+
+func cmdPackageList(c *cli.Context) error {
+	packages := getInstalledPackages()
+
+	if getOutputFormat() != formatTable {
+		return printStructured(packages)
+	}
+
+	if len(packages) == 0 {
+		fmt.Fprintln(akamai.App.Writer, color.YellowString("No packages are installed"))
+		return nil
+	}
+
+	for _, pkg := range packages {
+		status := color.GreenString("enabled")
+		if !pkg.Enabled {
+			status = color.YellowString("disabled")
+		}
+		fmt.Fprintf(akamai.App.Writer, "%s (%s): %s\n", color.New(color.Bold).Sprint(pkg.Name), strings.Join(pkg.Commands, ", "), status)
+	}
+
+	return nil
+}
+
+// getInstalledPackages returns every installed package, including disabled ones
+func getInstalledPackages() []installedPackage {
+	packages := []installedPackage{}
+	for _, dir := range getAllPackageDirs() {
+		name := filepath.Base(getPackageRoot(dir))
+		pkg := installedPackage{Name: name, Commands: []string{}, Enabled: true}
+
+		if cmdPackage, err := readPackage(dir); err == nil {
+			for _, command := range cmdPackage.Commands {
+				pkg.Commands = append(pkg.Commands, strings.ToLower(command.Name))
+			}
+		}
+
+		if manifest, err := readManifest(name); err == nil {
+			pkg.Enabled = !manifest.Disabled
+		}
+
+		packages = append(packages, pkg)
+	}
+
+	return packages
+}
+
+// findInstalledPackage finds a package by its name, with or without the cli-
+// prefix, or by the name of one of its commands, as long as only one package
+// provides it
+func findInstalledPackage(name string, packages []installedPackage) (installedPackage, error) {
+	name = strings.ToLower(name)
+	for _, pkg := range packages {
+		if pkgName := strings.ToLower(pkg.Name); pkgName == name || pkgName == "cli-"+name {
+			return pkg, nil
+		}
+	}
+
+	var found []installedPackage
+	var names []string
+	for _, pkg := range packages {
+		for _, command := range pkg.Commands {
+			if command == name {
+				found = append(found, pkg)
+				names = append(names, pkg.Name)
+				break
+			}
+		}
+	}
+
+	switch len(found) {
+	case 0:
+		return installedPackage{}, fmt.Errorf("Package \"%s\" is not installed. Try \"%s package list\".", name, self())
+	case 1:
+		return found[0], nil
+	}
+
+	return installedPackage{}, fmt.Errorf("Command \"%s\" is provided by several packages, specify one of: %s", name, strings.Join(names, ", "))
+}
@@ -0,0 +1,54 @@
+/*
+ Copyright 2018. Akamai Technologies, Inc
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package main
+
+import "testing"
+
+func TestFindInstalledPackage(t *testing.T) {
+	packages := []installedPackage{
+		{Name: "cli-property", Commands: []string{"property"}},
+		{Name: "cli-property-manager", Commands: []string{"property-manager", "snippets"}},
+		{Name: "cli-snippets", Commands: []string{"snippets"}},
+		{Name: "my-tools", Commands: []string{"purge"}},
+	}
+
+	findTests := []struct {
+		name string
+		pkg  string
+		err  bool
+	}{
+		{"cli-property", "cli-property", false},
+		{"property", "cli-property", false},
+		{"Property-Manager", "cli-property-manager", false},
+		{"my-tools", "my-tools", false},
+		{"purge", "my-tools", false},
+		{"snippets", "cli-snippets", false},
+		{"dns", "", true},
+	}
+
+	for _, tt := range findTests {
+		pkg, err := findInstalledPackage(tt.name, packages)
+		if pkg.Name != tt.pkg || (err != nil) != tt.err {
+			t.Errorf("findInstalledPackage(%s) => %s, %v, wanted: %s (error: %t)", tt.name, pkg.Name, err, tt.pkg, tt.err)
+		}
+	}
+
+	packages = append(packages, installedPackage{Name: "cli-purge", Commands: []string{"purge-v2"}}, installedPackage{Name: "cli-purge-old", Commands: []string{"purge-v2"}})
+	if pkg, err := findInstalledPackage("purge-v2", packages); err == nil {
+		t.Errorf("findInstalledPackage(purge-v2) => %s, wanted an error for a command of several packages", pkg.Name)
+	}
+}
@@ -0,0 +1,275 @@
+/*
+ Copyright 2018. Akamai Technologies, Inc
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package main
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+
+	akamai "github.com/akamai/cli-common-golang"
+	"github.com/fatih/color"
+	"github.com/go-ini/ini"
+	"github.com/mitchellh/go-homedir"
+	"github.com/urfave/cli"
+)
+
+const (
+	// profileEnv names the profile in use, set by --profile or "akamai profile use"
+	profileEnv = "AKAMAI_CLI_PROFILE"
+	// profileSection stores each profile as <name>-edgerc and <name>-section keys
+	profileSection = "profiles"
+
+	// edgercEnv and edgercSectionEnv are read by packages in place of --edgerc and --section
+	edgercEnv        = "AKAMAI_EDGERC"
+	edgercSectionEnv = "AKAMAI_EDGERC_SECTION"
+
+	defaultEdgerc = "~/.edgerc"
+)
+
+var profileName = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9_.-]*$`)
+
+// credentialProfile is a named .edgerc file and section
+type credentialProfile struct {
+	Name    string `json:"name"`
+	Edgerc  string `json:"edgerc"`
+	Section string `json:"section"`
+	Current bool   `json:"current"`
+}
+
+// edgercRequiredKeys are the credentials every .edgerc section needs
+var edgercRequiredKeys = []string{"client_secret", "host", "access_token", "client_token"}
+
+func cmdProfileAdd(c *cli.Context) error {
+	name := c.Args().First()
+	if !profileName.MatchString(name) {
+		return cli.NewExitError(color.RedString("Invalid profile name \"%s\", use letters, numbers, \".\", \"_\", and \"-\"", name), 1)
+	}
+
+	profile := credentialProfile{Name: name, Edgerc: c.String("edgerc"), Section: c.String("section")}
+	if profile.Section == "" {
+		profile.Section = name
+	}
+
+	setConfigValue(profileSection, name+"-edgerc", profile.Edgerc)
+	setConfigValue(profileSection, name+"-section", profile.Section)
+	saveConfig()
+
+	if err := validateProfile(profile); err != nil {
+		fmt.Fprintln(akamai.App.ErrWriter, color.YellowString("Warning: profile \"%s\" was added, but %s", name, err.Error()))
+	}
+
+	return nil
+}
+
+func cmdProfileList(c *cli.Context) error {
+	profiles := getProfiles()
+
+	if getOutputFormat() != formatTable {
+		return printStructured(profiles)
+	}
+
+	if len(profiles) == 0 {
+		fmt.Fprintln(akamai.App.Writer, color.YellowString("No profiles are defined, add one with \"%s profile add <name>\"", self()))
+		return nil
+	}
+
+	for _, profile := range profiles {
+		marker := "  "
+		if profile.Current {
+			marker = color.GreenString("* ")
+		}
+		fmt.Fprintf(akamai.App.Writer, "%s%s (%s, section %s)\n", marker, color.New(color.Bold).Sprint(profile.Name), profile.Edgerc, profile.Section)
+	}
+
+	return nil
+}
+
+func cmdProfileUse(c *cli.Context) error {
+	if c.Bool("none") {
+		unsetConfigValue("cli", "profile")
+		saveConfig()
+		return nil
+	}
+
+	name := c.Args().First()
+	if _, ok := findProfile(name); !ok {
+		return cli.NewExitError(color.RedString("Profile \"%s\" not found. Try \"%s profile list\".", name, self()), 1)
+	}
+
+	setConfigValue("cli", "profile", name)
+	saveConfig()
+
+	fmt.Fprintf(akamai.App.Writer, "Now using profile %s\n", color.New(color.Bold).Sprint(name))
+	return nil
+}
+
+func cmdProfileRemove(c *cli.Context) error {
+	name := c.Args().First()
+	if _, ok := findProfile(name); !ok {
+		return cli.NewExitError(color.RedString("Profile \"%s\" not found", name), 1)
+	}
+
+	unsetConfigValue(profileSection, name+"-edgerc")
+	unsetConfigValue(profileSection, name+"-section")
+	if getConfigValue("cli", "profile") == name {
+		unsetConfigValue("cli", "profile")
+	}
+	saveConfig()
+
+	return nil
+}
+
+func cmdProfileValidate(c *cli.Context) error {
+	var profiles []credentialProfile
+	if c.Args().Present() {
+		for _, name := range c.Args() {
+			profile, ok := findProfile(name)
+			if !ok {
+				return cli.NewExitError(color.RedString("Profile \"%s\" not found", name), 1)
+			}
+			profiles = append(profiles, profile)
+		}
+	} else {
+		profiles = getProfiles()
+	}
+
+	failed := 0
+	for _, profile := range profiles {
+		if err := validateProfile(profile); err != nil {
+			failed++
+			fmt.Fprintf(akamai.App.Writer, "[%s] %s: %s\n", color.RedString("FAIL"), profile.Name, err.Error())
+			continue
+		}
+		fmt.Fprintf(akamai.App.Writer, "[%s] %s\n", color.GreenString(" OK "), profile.Name)
+	}
+
+	if failed > 0 {
+		return cli.NewExitError("", 1)
+	}
+
+	return nil
+}
+
+// getProfiles returns the profiles defined in config, sorted by name
+func getProfiles() []credentialProfile {
+	values := getConfigSectionValues(profileSection)
+	current := getCurrentProfileName()
+
+	profiles := make([]credentialProfile, 0)
+	for key := range values {
+		if !strings.HasSuffix(key, "-section") {
+			continue
+		}
+
+		name := strings.TrimSuffix(key, "-section")
+		edgerc := values[name+"-edgerc"]
+		if edgerc == "" {
+			edgerc = defaultEdgerc
+		}
+		profiles = append(profiles, credentialProfile{Name: name, Edgerc: edgerc, Section: values[key], Current: name == current})
+	}
+
+	sort.Slice(profiles, func(i, j int) bool { return profiles[i].Name < profiles[j].Name })
+
+	return profiles
+}
+
+func findProfile(name string) (credentialProfile, bool) {
+	for _, profile := range getProfiles() {
+		if profile.Name == name {
+			return profile, true
+		}
+	}
+
+	return credentialProfile{}, false
+}
+
+// getCurrentProfileName returns the profile given with --profile, or else the one chosen with "akamai profile use"
+func getCurrentProfileName() string {
+	if name := os.Getenv(profileEnv); name != "" {
+		return name
+	}
+
+	return getConfigValue("cli", "profile")
+}
+
+// applyProfileEnv points packages at the credentials of the current profile,
+// unless they are given --edgerc or --section themselves
+func applyProfileEnv() error {
+	name := getCurrentProfileName()
+	if name == "" {
+		return nil
+	}
+
+	profile, ok := findProfile(name)
+	if !ok {
+		return cli.NewExitError(color.RedString("Profile \"%s\" not found. Try \"%s profile list\".", name, self()), 1)
+	}
+
+	edgerc, err := homedir.Expand(profile.Edgerc)
+	if err != nil {
+		edgerc = profile.Edgerc
+	}
+
+	os.Setenv(edgercEnv, edgerc)
+	os.Setenv(edgercSectionEnv, profile.Section)
+
+	return nil
+}
+
+// validateProfile checks that the .edgerc file of a profile has its section,
+// with all of the credentials it needs
+func validateProfile(profile credentialProfile) error {
+	path, err := homedir.Expand(profile.Edgerc)
+	if err != nil {
+		return err
+	}
+
+	edgerc, err := ini.Load(path)
+	if err != nil {
+		return fmt.Errorf("unable to read %s: %s", profile.Edgerc, err.Error())
+	}
+
+	section, err := edgerc.GetSection(profile.Section)
+	if err != nil {
+		return fmt.Errorf("section [%s] not found in %s", profile.Section, profile.Edgerc)
+	}
+
+	return validateEdgercSection(section.KeysHash())
+}
+
+func validateEdgercSection(keys map[string]string) error {
+	var missing []string
+	for _, key := range edgercRequiredKeys {
+		if strings.TrimSpace(keys[key]) == "" {
+			missing = append(missing, key)
+		}
+	}
+
+	if len(missing) > 0 {
+		return fmt.Errorf("missing %s", strings.Join(missing, ", "))
+	}
+
+	if host := keys["host"]; strings.Contains(host, "://") || strings.HasSuffix(host, "/") {
+		return fmt.Errorf("host must be a hostname, without a scheme or trailing \"/\", e.g. akab-xxx.luna.akamaiapis.net")
+	}
+
+	return nil
+}
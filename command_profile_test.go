@@ -0,0 +1,54 @@
+/*
+ Copyright 2018. Akamai Technologies, Inc
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package main
+
+import "testing"
+
+func TestValidateEdgercSection(t *testing.T) {
+	valid := map[string]string{
+		"client_secret": "secret",
+		"host":          "akab-xxx.luna.akamaiapis.net",
+		"access_token":  "akab-access",
+		"client_token":  "akab-client",
+	}
+
+	with := func(key string, value string) map[string]string {
+		keys := make(map[string]string)
+		for k, v := range valid {
+			keys[k] = v
+		}
+		keys[key] = value
+		return keys
+	}
+
+	sectionTests := []struct {
+		keys  map[string]string
+		valid bool
+	}{
+		{valid, true},
+		{with("client_secret", ""), false},
+		{with("host", "https://akab-xxx.luna.akamaiapis.net"), false},
+		{with("host", "akab-xxx.luna.akamaiapis.net/"), false},
+		{map[string]string{}, false},
+	}
+
+	for _, tt := range sectionTests {
+		if err := validateEdgercSection(tt.keys); (err == nil) != tt.valid {
+			t.Errorf("validateEdgercSection(%v) => %v, wanted valid: %t", tt.keys, err, tt.valid)
+		}
+	}
+}
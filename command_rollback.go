@@ -0,0 +1,91 @@
+/*
+ Copyright 2018. Akamai Technologies, Inc
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/fatih/color"
+	"github.com/urfave/cli"
+	"gopkg.in/src-d/go-git.v4"
+)
+
+func cmdRollback(c *cli.Context) error {
+	if !c.Args().Present() {
+		return cli.NewExitError(color.RedString("You must specify a command"), 1)
+	}
+
+	for _, cmd := range c.Args() {
+		if err := rollbackPackage(cmd, c.Bool("force")); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// rollbackPackage restores the commit a package was at before its last update,
+// and runs the build step again. The commit rolled back from is recorded in its
+// place, so rolling back twice returns to the update.
+func rollbackPackage(cmd string, forceBinary bool) error {
+	repoDir, err := findCommandPackageDir(cmd, "roll back")
+	if err != nil {
+		return err
+	}
+
+	name := filepath.Base(getPackageRoot(repoDir))
+	manifest, _ := readManifest(name)
+	if manifest.PreviousCommit == "" {
+		return cli.NewExitError(color.RedString("No previous version of \"%s\" is recorded, it has not been updated since it was installed", cmd), 1)
+	}
+
+	p := newSpinnerProgress()
+	p.Start(fmt.Sprintf("Attempting to roll back \"%s\" command...", cmd))
+
+	repo, err := git.PlainOpen(getPackageRoot(repoDir))
+	if err != nil {
+		p.Fail()
+		return cli.NewExitError(color.RedString("Unable to open package repository: %s", err.Error()), 1)
+	}
+
+	head, err := repo.Head()
+	if err != nil {
+		p.Fail()
+		return cli.NewExitError(color.RedString("Unable to roll back command: %s", err.Error()), 1)
+	}
+
+	if err := checkoutVersion(getPackageRoot(repoDir), manifest.PreviousCommit); err != nil {
+		p.Fail()
+		return cli.NewExitError(color.RedString("Unable to roll back command: %s", err.Error()), 1)
+	}
+
+	manifest.Commit = manifest.PreviousCommit
+	manifest.PreviousCommit = head.Hash().String()
+	if err := writeManifest(name, manifest); err != nil {
+		p.Fail()
+		return cli.NewExitError(color.RedString("Unable to record package manifest: %s", err.Error()), 1)
+	}
+
+	p.Ok()
+
+	if !installPackageDependencies(repoDir, installOptions{forceBinary: forceBinary}) {
+		return cli.NewExitError("Unable to roll back command", 1)
+	}
+
+	return nil
+}
@@ -0,0 +1,459 @@
+/*
+ Copyright 2018. Akamai Technologies, Inc
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	akamai "github.com/akamai/cli-common-golang"
+	"github.com/fatih/color"
+	"github.com/tuvistavie/securerandom"
+	"github.com/urfave/cli"
+	"gopkg.in/src-d/go-git.v4"
+)
+
+const (
+	sbomFormatCycloneDX = "cyclonedx"
+	sbomFormatSPDX      = "spdx"
+)
+
+// sbomPackage is an installed package, as described in a bill of materials
+type sbomPackage struct {
+	Name         string
+	Version      string
+	Commit       string
+	Repo         string
+	Language     string
+	Dependencies []sbomDependency
+}
+
+// sbomDependency is a language dependency a package declares, its version
+// may be a range when that is all the package records
+type sbomDependency struct {
+	Name      string
+	Version   string
+	Ecosystem string
+}
+
+func cmdSBOM(c *cli.Context) error {
+	format := strings.ToLower(c.String("format"))
+	if format != sbomFormatCycloneDX && format != sbomFormatSPDX {
+		return cli.NewExitError(color.RedString("Unknown format \"%s\", must be one of: cyclonedx, spdx", c.String("format")), 1)
+	}
+
+	serial, err := securerandom.Uuid()
+	if err != nil {
+		return cli.NewExitError(color.RedString(err.Error()), 1)
+	}
+
+	packages := getSBOMPackages()
+
+	var document interface{}
+	if format == sbomFormatSPDX {
+		document = buildSPDXDocument(packages, serial, time.Now())
+	} else {
+		document = buildCycloneDXDocument(packages, serial, time.Now())
+	}
+
+	data, err := json.MarshalIndent(document, "", "  ")
+	if err != nil {
+		return cli.NewExitError(color.RedString(err.Error()), 1)
+	}
+
+	if output := c.String("output"); output != "" {
+		if err := ioutil.WriteFile(output, append(data, '\n'), 0664); err != nil {
+			return cli.NewExitError(color.RedString("Unable to write the bill of materials: %s", err.Error()), 1)
+		}
+		printInfo(akamai.App.ErrWriter, "Wrote the bill of materials of %d package(s) to %s", len(packages), output)
+		return nil
+	}
+
+	fmt.Fprintln(akamai.App.Writer, string(data))
+	return nil
+}
+
+// getSBOMPackages describes every installed package, including disabled ones
+func getSBOMPackages() []sbomPackage {
+	var packages []sbomPackage
+	for _, dir := range getAllPackageDirs() {
+		root := getPackageRoot(dir)
+		pkg := sbomPackage{Name: filepath.Base(root)}
+
+		if cmdPackage, err := readPackage(dir); err == nil {
+			if len(cmdPackage.Commands) > 0 {
+				pkg.Version = cmdPackage.Commands[0].Version
+			}
+			pkg.Language = determineCommandLanguage(cmdPackage)
+		}
+
+		if manifest, err := readManifest(pkg.Name); err == nil {
+			pkg.Repo = manifest.Repo
+			if manifest.Source != "" {
+				pkg.Repo = manifest.Source
+			}
+		}
+
+		if repo, err := git.PlainOpen(root); err == nil {
+			if head, err := repo.Head(); err == nil {
+				pkg.Commit = head.Hash().String()
+			}
+		}
+
+		pkg.Dependencies = readPackageDependencies(dir)
+		packages = append(packages, pkg)
+	}
+
+	return packages
+}
+
+// readPackageDependencies discovers the language dependencies a package
+// declares, from the manifests of the languages' package managers
+func readPackageDependencies(dir string) []sbomDependency {
+	var dependencies []sbomDependency
+	addMap := func(ecosystem string, deps map[string]string) {
+		for name, version := range deps {
+			if ecosystem == "composer" && !strings.Contains(name, "/") {
+				// php and ext-* are platform requirements, not packages
+				continue
+			}
+			dependencies = append(dependencies, sbomDependency{name, version, ecosystem})
+		}
+	}
+
+	if data, err := ioutil.ReadFile(filepath.Join(dir, "package.json")); err == nil {
+		var manifest struct {
+			Dependencies map[string]string `json:"dependencies"`
+		}
+		if json.Unmarshal(data, &manifest) == nil {
+			addMap("npm", manifest.Dependencies)
+		}
+	}
+
+	if data, err := ioutil.ReadFile(filepath.Join(dir, "composer.json")); err == nil {
+		var manifest struct {
+			Require map[string]string `json:"require"`
+		}
+		if json.Unmarshal(data, &manifest) == nil {
+			addMap("composer", manifest.Require)
+		}
+	}
+
+	readLines := func(name string, parse func(line string) (sbomDependency, bool)) {
+		file, err := os.Open(filepath.Join(dir, name))
+		if err != nil {
+			return
+		}
+		defer file.Close()
+
+		scanner := bufio.NewScanner(file)
+		for scanner.Scan() {
+			if dependency, ok := parse(scanner.Text()); ok {
+				dependencies = append(dependencies, dependency)
+			}
+		}
+	}
+
+	readLines("requirements.txt", parseRequirementsLine)
+	readLines("go.mod", parseGoModLine)
+	readLines("Gemfile.lock", parseGemfileLockLine)
+
+	sort.SliceStable(dependencies, func(i, j int) bool {
+		if dependencies[i].Ecosystem != dependencies[j].Ecosystem {
+			return dependencies[i].Ecosystem < dependencies[j].Ecosystem
+		}
+		return dependencies[i].Name < dependencies[j].Name
+	})
+
+	return dependencies
+}
+
+var requirementsLinePattern = regexp.MustCompile(`^([A-Za-z0-9][A-Za-z0-9._-]*)\s*(?:\[[^\]]*\])?\s*((?:==|>=|<=|~=|!=|>|<)[^;#\s]*)?`)
+
+// parseRequirementsLine parses a line of a pip requirements file, e.g. requests==2.18.4
+func parseRequirementsLine(line string) (sbomDependency, bool) {
+	line = strings.TrimSpace(line)
+	if line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, "-") {
+		return sbomDependency{}, false
+	}
+
+	match := requirementsLinePattern.FindStringSubmatch(line)
+	if match == nil {
+		return sbomDependency{}, false
+	}
+
+	return sbomDependency{Name: match[1], Version: strings.TrimPrefix(match[2], "=="), Ecosystem: "pypi"}, true
+}
+
+var goModRequirePattern = regexp.MustCompile(`^(?:require\s+)?([^\s()]+\.[^\s()]+)\s+(v[^\s]+)`)
+
+// parseGoModLine parses a requirement of a go.mod file, on its own or in a require block
+func parseGoModLine(line string) (sbomDependency, bool) {
+	line = strings.TrimSpace(line)
+	if strings.HasPrefix(line, "module ") || strings.HasPrefix(line, "go ") || strings.HasPrefix(line, "//") {
+		return sbomDependency{}, false
+	}
+
+	match := goModRequirePattern.FindStringSubmatch(line)
+	if match == nil {
+		return sbomDependency{}, false
+	}
+
+	return sbomDependency{Name: match[1], Version: match[2], Ecosystem: "golang"}, true
+}
+
+var gemfileLockSpecPattern = regexp.MustCompile(`^    ([A-Za-z0-9_.-]+) \(([^)]+)\)$`)
+
+// parseGemfileLockLine parses a resolved gem of a Gemfile.lock, e.g. "    rake (12.3.0)"
+func parseGemfileLockLine(line string) (sbomDependency, bool) {
+	match := gemfileLockSpecPattern.FindStringSubmatch(line)
+	if match == nil {
+		return sbomDependency{}, false
+	}
+
+	return sbomDependency{Name: match[1], Version: match[2], Ecosystem: "gem"}, true
+}
+
+// purl returns the package URL of a dependency, see https://github.com/package-url/purl-spec
+func (dependency sbomDependency) purl() string {
+	name := dependency.Name
+	if dependency.Ecosystem == "npm" && strings.HasPrefix(name, "@") {
+		name = "%40" + name[1:]
+	}
+
+	if dependency.Version == "" {
+		return fmt.Sprintf("pkg:%s/%s", dependency.Ecosystem, name)
+	}
+
+	return fmt.Sprintf("pkg:%s/%s@%s", dependency.Ecosystem, name, dependency.Version)
+}
+
+// purl returns the package URL of a package hosted on GitHub, or ""
+func (pkg sbomPackage) purl() string {
+	repo := strings.TrimSuffix(githubize(pkg.Repo), ".git")
+	for _, prefix := range []string{"https://github.com/", "git@github.com:"} {
+		if strings.HasPrefix(repo, prefix) {
+			purl := "pkg:github/" + strings.ToLower(strings.TrimPrefix(repo, prefix))
+			if pkg.Commit != "" {
+				purl += "@" + pkg.Commit
+			}
+			return purl
+		}
+	}
+
+	return ""
+}
+
+type cycloneDXDocument struct {
+	BOMFormat    string               `json:"bomFormat"`
+	SpecVersion  string               `json:"specVersion"`
+	SerialNumber string               `json:"serialNumber"`
+	Version      int                  `json:"version"`
+	Metadata     cycloneDXMetadata    `json:"metadata"`
+	Components   []cycloneDXComponent `json:"components"`
+}
+
+type cycloneDXMetadata struct {
+	Timestamp string             `json:"timestamp"`
+	Tools     []cycloneDXTool    `json:"tools"`
+	Component cycloneDXComponent `json:"component"`
+}
+
+type cycloneDXTool struct {
+	Vendor  string `json:"vendor"`
+	Name    string `json:"name"`
+	Version string `json:"version"`
+}
+
+type cycloneDXComponent struct {
+	Type               string               `json:"type"`
+	BOMRef             string               `json:"bom-ref,omitempty"`
+	Name               string               `json:"name"`
+	Version            string               `json:"version,omitempty"`
+	PURL               string               `json:"purl,omitempty"`
+	ExternalReferences []cycloneDXReference `json:"externalReferences,omitempty"`
+	Properties         []cycloneDXProperty  `json:"properties,omitempty"`
+	Components         []cycloneDXComponent `json:"components,omitempty"`
+}
+
+type cycloneDXReference struct {
+	Type string `json:"type"`
+	URL  string `json:"url"`
+}
+
+type cycloneDXProperty struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+func buildCycloneDXDocument(packages []sbomPackage, serial string, now time.Time) cycloneDXDocument {
+	document := cycloneDXDocument{
+		BOMFormat:    "CycloneDX",
+		SpecVersion:  "1.4",
+		SerialNumber: "urn:uuid:" + serial,
+		Version:      1,
+		Metadata: cycloneDXMetadata{
+			Timestamp: now.UTC().Format(time.RFC3339),
+			Tools:     []cycloneDXTool{{Vendor: "Akamai", Name: "akamai-cli", Version: VERSION}},
+			Component: cycloneDXComponent{Type: "application", BOMRef: "akamai-cli", Name: "akamai-cli", Version: VERSION},
+		},
+		Components: []cycloneDXComponent{},
+	}
+
+	for _, pkg := range packages {
+		component := cycloneDXComponent{
+			Type:    "application",
+			BOMRef:  pkg.Name,
+			Name:    pkg.Name,
+			Version: pkg.Version,
+			PURL:    pkg.purl(),
+		}
+		if pkg.Repo != "" {
+			component.ExternalReferences = []cycloneDXReference{{Type: "vcs", URL: pkg.Repo}}
+		}
+		if pkg.Commit != "" {
+			component.Properties = append(component.Properties, cycloneDXProperty{"akamai-cli:commit", pkg.Commit})
+		}
+		if pkg.Language != "" {
+			component.Properties = append(component.Properties, cycloneDXProperty{"akamai-cli:language", pkg.Language})
+		}
+
+		for _, dependency := range pkg.Dependencies {
+			component.Components = append(component.Components, cycloneDXComponent{
+				Type:    "library",
+				BOMRef:  pkg.Name + "/" + dependency.purl(),
+				Name:    dependency.Name,
+				Version: dependency.Version,
+				PURL:    dependency.purl(),
+			})
+		}
+
+		document.Components = append(document.Components, component)
+	}
+
+	return document
+}
+
+type spdxDocument struct {
+	SPDXVersion       string             `json:"spdxVersion"`
+	DataLicense       string             `json:"dataLicense"`
+	SPDXID            string             `json:"SPDXID"`
+	Name              string             `json:"name"`
+	DocumentNamespace string             `json:"documentNamespace"`
+	CreationInfo      spdxCreationInfo   `json:"creationInfo"`
+	Packages          []spdxPackage      `json:"packages"`
+	Relationships     []spdxRelationship `json:"relationships"`
+}
+
+type spdxCreationInfo struct {
+	Created  string   `json:"created"`
+	Creators []string `json:"creators"`
+}
+
+type spdxPackage struct {
+	SPDXID           string            `json:"SPDXID"`
+	Name             string            `json:"name"`
+	VersionInfo      string            `json:"versionInfo,omitempty"`
+	DownloadLocation string            `json:"downloadLocation"`
+	FilesAnalyzed    bool              `json:"filesAnalyzed"`
+	SourceInfo       string            `json:"sourceInfo,omitempty"`
+	ExternalRefs     []spdxExternalRef `json:"externalRefs,omitempty"`
+}
+
+type spdxExternalRef struct {
+	ReferenceCategory string `json:"referenceCategory"`
+	ReferenceType     string `json:"referenceType"`
+	ReferenceLocator  string `json:"referenceLocator"`
+}
+
+type spdxRelationship struct {
+	SPDXElementID      string `json:"spdxElementId"`
+	RelationshipType   string `json:"relationshipType"`
+	RelatedSPDXElement string `json:"relatedSpdxElement"`
+}
+
+var spdxIDInvalidChars = regexp.MustCompile(`[^A-Za-z0-9.-]+`)
+
+// spdxID makes an SPDX identifier, which may only contain letters, digits, . and -
+func spdxID(parts ...string) string {
+	return "SPDXRef-" + spdxIDInvalidChars.ReplaceAllString(strings.Join(parts, "-"), "-")
+}
+
+func buildSPDXDocument(packages []sbomPackage, serial string, now time.Time) spdxDocument {
+	cliID := spdxID("Package", "akamai-cli")
+	document := spdxDocument{
+		SPDXVersion:       "SPDX-2.3",
+		DataLicense:       "CC0-1.0",
+		SPDXID:            "SPDXRef-DOCUMENT",
+		Name:              "akamai-cli",
+		DocumentNamespace: "https://developer.akamai.com/cli/spdx/" + serial,
+		CreationInfo: spdxCreationInfo{
+			Created:  now.UTC().Format(time.RFC3339),
+			Creators: []string{"Tool: akamai-cli-" + VERSION},
+		},
+		Packages: []spdxPackage{{
+			SPDXID:           cliID,
+			Name:             "akamai-cli",
+			VersionInfo:      VERSION,
+			DownloadLocation: "https://github.com/akamai/cli",
+		}},
+		Relationships: []spdxRelationship{{"SPDXRef-DOCUMENT", "DESCRIBES", cliID}},
+	}
+
+	for _, pkg := range packages {
+		pkgID := spdxID("Package", pkg.Name)
+		spdxPkg := spdxPackage{
+			SPDXID:           pkgID,
+			Name:             pkg.Name,
+			VersionInfo:      pkg.Version,
+			DownloadLocation: "NOASSERTION",
+		}
+		if pkg.Repo != "" {
+			spdxPkg.DownloadLocation = pkg.Repo
+		}
+		if pkg.Commit != "" {
+			spdxPkg.SourceInfo = "git commit " + pkg.Commit
+		}
+		if purl := pkg.purl(); purl != "" {
+			spdxPkg.ExternalRefs = []spdxExternalRef{{"PACKAGE-MANAGER", "purl", purl}}
+		}
+		document.Packages = append(document.Packages, spdxPkg)
+		document.Relationships = append(document.Relationships, spdxRelationship{cliID, "CONTAINS", pkgID})
+
+		for _, dependency := range pkg.Dependencies {
+			dependencyID := spdxID("Package", pkg.Name, dependency.Ecosystem, dependency.Name)
+			document.Packages = append(document.Packages, spdxPackage{
+				SPDXID:           dependencyID,
+				Name:             dependency.Name,
+				VersionInfo:      dependency.Version,
+				DownloadLocation: "NOASSERTION",
+				ExternalRefs:     []spdxExternalRef{{"PACKAGE-MANAGER", "purl", dependency.purl()}},
+			})
+			document.Relationships = append(document.Relationships, spdxRelationship{pkgID, "DEPENDS_ON", dependencyID})
+		}
+	}
+
+	return document
+}
@@ -0,0 +1,71 @@
+/*
+ Copyright 2018. Akamai Technologies, Inc
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package main
+
+import "testing"
+
+func TestParseDependencyLines(t *testing.T) {
+	lineTests := []struct {
+		parse      func(string) (sbomDependency, bool)
+		line       string
+		dependency sbomDependency
+		ok         bool
+	}{
+		{parseRequirementsLine, "requests==2.18.4", sbomDependency{"requests", "2.18.4", "pypi"}, true},
+		{parseRequirementsLine, "edgegrid-python>=1.0.10 # auth", sbomDependency{"edgegrid-python", ">=1.0.10", "pypi"}, true},
+		{parseRequirementsLine, "urllib3[secure]", sbomDependency{"urllib3", "", "pypi"}, true},
+		{parseRequirementsLine, "# comment", sbomDependency{}, false},
+		{parseRequirementsLine, "-r base.txt", sbomDependency{}, false},
+		{parseGoModLine, "require github.com/urfave/cli v1.20.0", sbomDependency{"github.com/urfave/cli", "v1.20.0", "golang"}, true},
+		{parseGoModLine, "\tgithub.com/fatih/color v1.7.0 // indirect", sbomDependency{"github.com/fatih/color", "v1.7.0", "golang"}, true},
+		{parseGoModLine, "module github.com/akamai/cli-example", sbomDependency{}, false},
+		{parseGoModLine, "go 1.12", sbomDependency{}, false},
+		{parseGemfileLockLine, "    rake (12.3.0)", sbomDependency{"rake", "12.3.0", "gem"}, true},
+		{parseGemfileLockLine, "      rake (>= 10.0)", sbomDependency{}, false},
+		{parseGemfileLockLine, "GEM", sbomDependency{}, false},
+	}
+
+	for _, tt := range lineTests {
+		dependency, ok := tt.parse(tt.line)
+		if ok != tt.ok || dependency != tt.dependency {
+			t.Errorf("parsing %q => %v, %t, wanted: %v, %t", tt.line, dependency, ok, tt.dependency, tt.ok)
+		}
+	}
+}
+
+func TestDependencyPurl(t *testing.T) {
+	purlTests := []struct {
+		dependency sbomDependency
+		purl       string
+	}{
+		{sbomDependency{"lodash", "4.17.11", "npm"}, "pkg:npm/lodash@4.17.11"},
+		{sbomDependency{"@akamai/edgegrid", "3.0.0", "npm"}, "pkg:npm/%40akamai/edgegrid@3.0.0"},
+		{sbomDependency{"akamai/open", "", "composer"}, "pkg:composer/akamai/open"},
+	}
+
+	for _, tt := range purlTests {
+		if purl := tt.dependency.purl(); purl != tt.purl {
+			t.Errorf("purl of %v => %s, wanted: %s", tt.dependency, purl, tt.purl)
+		}
+	}
+}
+
+func TestSPDXID(t *testing.T) {
+	if id := spdxID("Package", "cli-purge", "golang", "github.com/urfave/cli"); id != "SPDXRef-Package-cli-purge-golang-github.com-urfave-cli" {
+		t.Errorf("spdxID => %s", id)
+	}
+}
@@ -17,125 +17,731 @@
 package main
 
 import (
+	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io/ioutil"
 	"net/http"
+	"os"
+	"regexp"
 	"sort"
+	"strconv"
 	"strings"
+	"text/template"
+	"time"
+	"unicode"
 
 	akamai "github.com/akamai/cli-common-golang"
 	"github.com/fatih/color"
+	"github.com/mattn/go-isatty"
 	"github.com/urfave/cli"
 )
 
 type packageList struct {
 	Version  float64              `json:"version"`
 	Packages []packageListPackage `json:"packages"`
+	// Groups are curated bundles of package names, see "akamai groups"
+	Groups map[string][]string `json:"groups,omitempty"`
 }
 
 type packageListPackage struct {
-	Title        string    `json:"title"`
-	Name         string    `json:"name"`
-	Version      string    `json:"version"`
-	URL          string    `json:"url"`
-	Issues       string    `json:"issues"`
-	Commands     []Command `json:"commands"`
-	Requirements struct {
-		Go     string `json:"go"`
-		Php    string `json:"php"`
-		Node   string `json:"node"`
-		Ruby   string `json:"ruby"`
-		Python string `json:"python"`
-	} `json:"requirements"`
+	Title        string              `json:"title"`
+	Name         string              `json:"name"`
+	Version      string              `json:"version"`
+	URL          string              `json:"url"`
+	Path         string              `json:"path"`
+	Size         uint64              `json:"size"`
+	Issues       string              `json:"issues"`
+	Commands     []Command           `json:"commands"`
+	Requirements packageRequirements `json:"requirements"`
+	Releases     []packageRelease    `json:"releases"`
+	// Updated is when the package was last released, as an RFC 3339 date or time
+	Updated string `json:"updated,omitempty"`
+	// Tags and Categories classify the package, see "akamai tags"
+	Tags       []string `json:"tags,omitempty"`
+	Categories []string `json:"categories,omitempty"`
+	// Deprecated packages should no longer be installed, Replacement names the package to use instead
+	Deprecated  bool   `json:"deprecated,omitempty"`
+	Replacement string `json:"replacement,omitempty"`
+
+	// Source is the URL of the registry the package was listed by
+	Source string `json:"-"`
+
+	// raw holds the package record exactly as it was received from the registry
+	raw json.RawMessage
+}
+
+func (pkg *packageListPackage) UnmarshalJSON(data []byte) error {
+	type registryPackage packageListPackage
+	if err := json.Unmarshal(data, (*registryPackage)(pkg)); err != nil {
+		return err
+	}
+
+	pkg.raw = append(json.RawMessage(nil), data...)
+	return nil
+}
+
+// findPackage looks up a package by name, with or without the "cli-" prefix
+func (list *packageList) findPackage(name string) (packageListPackage, bool) {
+	for _, pkg := range list.Packages {
+		if pkg.Name == name || pkg.Name == strings.TrimPrefix(name, "cli-") {
+			return pkg, true
+		}
+	}
+
+	return packageListPackage{}, false
+}
+
+// getInstallTarget returns the argument "akamai install" takes for a registry package
+func (pkg packageListPackage) getInstallTarget() string {
+	if pkg.Path != "" {
+		return pkg.URL + "#" + pkg.Path
+	}
+
+	return pkg.URL
 }
 
 func cmdSearch(c *cli.Context) error {
-	if !c.Args().Present() {
-		return cli.NewExitError(color.RedString("You must specify one or more keywords"), 1)
+	tags := parseTagFilter(c.StringSlice("tag"))
+	if !c.Args().Present() && !c.Bool("watch") && !c.Bool("top-commands") && len(tags) == 0 {
+		return newError(errUsage, "You must specify one or more keywords, or --tag")
+	}
+
+	opts := searchOptions{
+		noBanner:      c.Bool("no-banner") || isQuiet(),
+		caseSensitive: c.Bool("case-sensitive"),
+		runtimeCount:  c.Bool("runtime-count"),
+		showSource:    len(getRegistryURLs()) > 1 && !c.Bool("installed"),
+		exact:         c.Bool("exact"),
+		explain:       c.Bool("explain"),
+		highlight:     c.Args(),
 	}
 
-	packageList, err := fetchPackageList()
+	var err error
+	headerFormat := c.String("header-format")
+	if headerFormat == "" {
+		headerFormat = defaultSearchHeaderFormat
+	}
+
+	opts.headerTemplate, err = template.New("header").Parse(headerFormat)
 	if err != nil {
-		return cli.NewExitError(color.RedString(err.Error()), 1)
+		return newError(errUsage, "Invalid header format: %s", err.Error())
+	}
+
+	if opts.palette = c.String("palette"); opts.palette != "" {
+		if err := validatePalette(opts.palette); err != nil {
+			return newError(errUsage, "%s", err.Error())
+		}
 	}
 
-	err = searchPackages(c.Args(), packageList)
+	sortKeys, err := parseSearchSortKeys(c.String("sort"))
 	if err != nil {
-		return cli.NewExitError(color.RedString(err.Error()), 1)
+		return newError(errUsage, "%s", err.Error())
+	}
+
+	format := c.String("format")
+	if format != "text" && format != "markdown" {
+		return newError(errUsage, "Unknown format \"%s\", must be one of: text, markdown", format)
+	}
+
+	countBy := c.String("count-by")
+	if countBy != "" {
+		if err := validateSearchCountField(countBy); err != nil {
+			return newError(errUsage, "%s", err.Error())
+		}
+		if c.Bool("top-commands") {
+			return newError(errUsage, "--count-by and --top-commands cannot be used together")
+		}
+	}
+
+	if c.Int("limit") < 0 {
+		return newError(errUsage, "--limit must be a positive number")
+	}
+
+	if c.IsSet("page") && (c.Int("limit") == 0 || c.Int("page") < 1) {
+		return newError(errUsage, "--page must be 1 or more, and can only be used with --limit")
+	}
+
+	var runtimes map[string]bool
+	if len(c.StringSlice("lang")) > 0 {
+		runtimes = make(map[string]bool)
+		for _, lang := range c.StringSlice("lang") {
+			for _, runtime := range strings.Split(lang, ",") {
+				runtime = strings.ToLower(strings.TrimSpace(runtime))
+				if _, ok := runtimeBinaries[runtime]; !ok {
+					return newError(errUsage, "Unknown language \"%s\", must be one of: %s", runtime, strings.Join(matrixRuntimes, ", "))
+				}
+				runtimes[runtime] = true
+			}
+		}
+	} else if c.Bool("available") {
+		runtimes = detectRuntimes()
+	}
+
+	threshold := c.Int("relevance-threshold")
+	if threshold < 0 || threshold > 100 {
+		return newError(errUsage, "--relevance-threshold must be between 0 and 100")
+	}
+
+	if c.Bool("installed") && (c.Bool("install-first") || c.Bool("watch")) {
+		return newError(errUsage, "--installed cannot be used with --install-first or --watch")
+	}
+
+	packageList, err := getSearchPackageList(c)
+	if err != nil {
+		return registryError(err)
+	}
+
+	if c.Bool("watch") {
+		return watchSearch(c.Args(), packageList, opts)
+	}
+
+	if name := c.String("in"); name != "" {
+		return searchWithinPackage(name, c.Args(), packageList, opts)
+	}
+
+	// Without keywords, --top-commands considers every package
+	if c.Bool("top-commands") && !c.Args().Present() {
+		results := make([]searchResult, 0, len(packageList.Packages))
+		for _, pkg := range packageList.Packages {
+			results = append(results, searchResult{Package: pkg})
+		}
+		if len(tags) > 0 {
+			results = filterSearchResultsByTag(results, tags)
+		}
+		return printTopCommands(results, c.Int("n"), c.Bool("json"))
+	}
+
+	var results []searchResult
+	if c.Args().Present() {
+		timeout := c.Duration("timeout-per-keyword") * time.Duration(len(c.Args()))
+		ctx, cancel := context.WithTimeout(context.Background(), timeout)
+		defer cancel()
+
+		results, err = searchPackages(ctx, c.Args(), packageList, opts)
+		if err != nil {
+			return cli.NewExitError(color.RedString(err.Error()), 1)
+		}
+	} else {
+		// Without keywords, --tag lists every package with the tags
+		for _, pkg := range packageList.Packages {
+			results = append(results, searchResult{Package: pkg})
+		}
+	}
+	if len(tags) > 0 {
+		results = filterSearchResultsByTag(results, tags)
+	}
+	sortSearchResults(results, sortKeys)
+	results = filterSearchResultsByRelevance(results, threshold)
+	if runtimes != nil {
+		results = filterSearchResultsByRuntime(results, runtimes)
+	}
+
+	if countBy != "" {
+		return printSearchCounts(results, countBy, c.Bool("json"))
+	}
+
+	opts.total = len(results)
+	results = paginateSearchResults(results, c.Int("limit"), c.Int("page"))
+
+	if c.Bool("top-commands") {
+		return printTopCommands(results, c.Int("n"), c.Bool("json"))
+	}
+
+	switch {
+	case c.Bool("raw"):
+		err = printRawSearchResults(results)
+	case c.Bool("json"):
+		err = printJSONSearchResults(c.Args(), results, opts)
+	case format == "markdown":
+		printMarkdownSearchResults(results, opts)
+	default:
+		if c.Bool("diff-installed-version") {
+			opts.installed = getInstalledCommandVersions()
+		}
+
+		// Number the results, so that one can be picked to install
+		opts.numbered = !c.Bool("install-first") && !c.Bool("no-prompt") && !c.Bool("installed") && len(results) > 0 &&
+			isatty.IsTerminal(os.Stdin.Fd()) && isatty.IsTerminal(os.Stdout.Fd())
+
+		printResults := func() error {
+			return printSearchResults(results, opts)
+		}
+
+		if !c.Bool("no-pager") && c.Int("limit") == 0 {
+			err = withPager(printResults)
+		} else {
+			err = printResults()
+		}
+
+		if err != nil {
+			err = cli.NewExitError(color.RedString(err.Error()), 1)
+		}
+	}
+
+	if err != nil {
+		return err
+	}
+
+	if c.Bool("install-first") {
+		if len(results) == 0 {
+			return newError(errPackageNotFound, "No packages found to install")
+		}
+
+		return installRegistryPackages([]packageListPackage{results[0].Package}, installOptions{})
+	}
+
+	if opts.numbered {
+		return promptInstallSearchResult(results)
 	}
 
 	return nil
 }
 
-func fetchPackageList() (*packageList, error) {
-	repo := "https://developer.akamai.com/cli/package-list"
-	resp, err := http.Get(repo)
+// getSearchPackageList returns the packages to search: the registry package
+// list, or with --installed the installed packages
+func getSearchPackageList(c *cli.Context) (*packageList, error) {
+	if c.Bool("installed") {
+		return getInstalledPackageList(), nil
+	}
+
+	return fetchPackageList(fetchOptions{
+		allowPartial:    c.Bool("allow-partial"),
+		skipIndexVerify: c.Bool("insecure-skip-index-verify"),
+		refresh:         c.Bool("refresh"),
+	})
+}
+
+// promptInstallSearchResult asks for the number of a result to install
+func promptInstallSearchResult(results []searchResult) error {
+	fmt.Fprintf(akamai.App.Writer, "Enter a number to install that package, or press enter to skip: ")
+	answer := ""
+	fmt.Scanln(&answer)
+	if answer == "" {
+		return nil
+	}
+
+	choice, err := strconv.Atoi(answer)
+	if err != nil || choice < 1 || choice > len(results) {
+		return cli.NewExitError(color.RedString("Invalid choice \"%s\", must be between 1 and %d", answer, len(results)), 1)
+	}
+
+	return installRegistryPackages([]packageListPackage{results[choice-1].Package}, installOptions{})
+}
+
+// paginateSearchResults returns the page-th (starting at 1) set of limit results,
+// or all results when limit is 0
+func paginateSearchResults(results []searchResult, limit int, page int) []searchResult {
+	if limit <= 0 {
+		return results
+	}
+
+	if page < 1 {
+		page = 1
+	}
+
+	start := (page - 1) * limit
+	if start >= len(results) {
+		return []searchResult{}
+	}
+
+	end := start + limit
+	if end > len(results) {
+		end = len(results)
+	}
+
+	return results[start:end]
+}
+
+const (
+	defaultRegistryURL = "https://developer.akamai.com/cli/package-list"
+	registryEnv        = "AKAMAI_CLI_REGISTRY"
+)
+
+// getRegistryURLs returns the package list URLs to use, highest priority first.
+// They are set as a comma-separated list with --registry, the cli.registry config
+// value, or AKAMAI_CLI_REGISTRY, in that order.
+func getRegistryURLs() []string {
+	// cli.registry is exported as AKAMAI_CLI_REGISTRY on startup, and --registry overrides it
+	value := os.Getenv(registryEnv)
+	if strings.TrimSpace(value) == "" {
+		value = getConfigValue("cli", "registry")
+	}
+
+	var registries []string
+	for _, registry := range strings.Split(value, ",") {
+		if registry = strings.TrimSpace(registry); registry != "" {
+			registries = append(registries, registry)
+		}
+	}
+
+	if len(registries) == 0 {
+		return []string{defaultRegistryURL}
+	}
+
+	return registries
+}
+
+type fetchOptions struct {
+	// allowPartial salvages the complete package entries of a truncated response
+	allowPartial bool
+	// skipIndexVerify trusts the package list even if its signature cannot be verified
+	skipIndexVerify bool
+	// refresh ignores the cached package list, even if it has not expired
+	refresh bool
+}
+
+// fetchPackageList merges the package lists of all configured registries. When
+// several registries list a package, or group, of the same name the one with the
+// highest priority wins.
+func fetchPackageList(opts fetchOptions) (*packageList, error) {
+	registries := getRegistryURLs()
+	if len(registries) == 1 {
+		return fetchRegistryPackageList(registries[0], opts)
+	}
+
+	merged := &packageList{Groups: make(map[string][]string)}
+	seen := make(map[string]bool)
+	var lastErr error
+	fetched := 0
+	for _, registry := range registries {
+		list, err := fetchRegistryPackageList(registry, opts)
+		if err != nil {
+			fmt.Fprintln(akamai.App.ErrWriter, color.CyanString("Warning: skipping registry %s (%s)", registry, err.Error()))
+			lastErr = err
+			continue
+		}
+		fetched++
+
+		if list.Version > merged.Version {
+			merged.Version = list.Version
+		}
+
+		for _, pkg := range list.Packages {
+			if !seen[pkg.Name] {
+				seen[pkg.Name] = true
+				merged.Packages = append(merged.Packages, pkg)
+			}
+		}
+
+		for name, members := range list.Groups {
+			if _, ok := merged.Groups[name]; !ok {
+				merged.Groups[name] = members
+			}
+		}
+	}
+
+	if fetched == 0 {
+		return nil, lastErr
+	}
+
+	return merged, nil
+}
+
+func fetchRegistryPackageList(registry string, opts fetchOptions) (*packageList, error) {
+	list, err := readRegistryPackageList(registry, opts)
 	if err != nil {
-		return nil, fmt.Errorf("Unable to fetch remote Package List (%s)", err.Error())
+		return nil, err
 	}
 
-	defer resp.Body.Close()
+	for key := range list.Packages {
+		list.Packages[key].Source = registry
+	}
+
+	return list, nil
+}
+
+func readRegistryPackageList(registry string, opts fetchOptions) (*packageList, error) {
+	cached, age, cacheErr := readPackageListCache(registry)
+	if cacheErr == nil && !opts.refresh && age < getPackageListTTL() {
+		result := &packageList{}
+		if err := json.Unmarshal(cached, result); err == nil {
+			return result, nil
+		}
+	}
+
+	// Offline, any cached list is better than none
+	if isOffline() {
+		if cacheErr != nil {
+			return nil, fmt.Errorf("No cached Package List is available, and network access is disabled by --offline or %s", offlineEnv)
+		}
+
+		result := &packageList{}
+		if err := json.Unmarshal(cached, result); err != nil {
+			return nil, fmt.Errorf("Unable to read the cached Package List (%s)", err.Error())
+		}
+
+		return result, nil
+	}
+
+	body, verified, err := downloadPackageList(registry, opts)
+	if err != nil {
+		if cacheErr != nil {
+			return nil, err
+		}
+
+		// Prefer a stale list to no list at all
+		result := &packageList{}
+		if json.Unmarshal(cached, result) != nil {
+			return nil, err
+		}
+
+		fmt.Fprintln(akamai.App.ErrWriter, color.CyanString("Warning: %s, using the cached package list from %s ago", err.Error(), age.Round(time.Minute)))
+		return result, nil
+	}
 
 	result := &packageList{}
-	body, err := ioutil.ReadAll(resp.Body)
 	err = json.Unmarshal(body, result)
 	if err != nil {
-		return nil, fmt.Errorf("Unable to fetch remote Package List (%s)", err.Error())
+		if !opts.allowPartial {
+			return nil, fmt.Errorf("Unable to fetch remote Package List (%s)", err.Error())
+		}
+
+		result, err = decodePartialPackageList(body)
+		if err != nil {
+			return nil, fmt.Errorf("Unable to fetch remote Package List (%s)", err.Error())
+		}
+
+		fmt.Fprintln(akamai.App.ErrWriter, color.CyanString("Warning: only a partial package list was received (%d packages), results may be incomplete", len(result.Packages)))
+		return result, nil
+	}
+
+	// Later runs trust the cache without verifying it again, so a list only
+	// accepted because of --insecure-skip-index-verify is not cached
+	if verified {
+		writePackageListCache(registry, body)
 	}
 
 	return result, nil
 }
 
-func searchPackages(keywords []string, packageList *packageList) error {
-	results := make(map[int]map[string]packageListPackage)
+// downloadPackageList fetches the package list from the registry, verifying its
+// signature when configured. It returns whether the list was verified, or
+// needed no verification.
+func downloadPackageList(repo string, opts fetchOptions) ([]byte, bool, error) {
+	client, err := getHTTPClient()
+	if err != nil {
+		return nil, false, err
+	}
+
+	var body []byte
+	err = withRetry(getRetryPolicy(), func() error {
+		resp, err := client.Get(repo)
+		if err != nil {
+			return fmt.Errorf("Unable to fetch remote Package List (%s)", err.Error())
+		}
 
-	var hits int
-	for key, pkg := range packageList.Packages {
-		hits = 0
-		for _, keyword := range keywords {
-			keyword = strings.ToLower(keyword)
-			if strings.Contains(strings.ToLower(pkg.Name), keyword) {
-				hits += 100
+		defer resp.Body.Close()
+
+		if resp.StatusCode != http.StatusOK {
+			err := fmt.Errorf("Unable to fetch remote Package List (%s)", resp.Status)
+			if !isRetryableStatus(resp.StatusCode) {
+				return permanent(err)
 			}
+			return err
+		}
 
-			if strings.Contains(strings.ToLower(pkg.Title), keyword) {
-				hits += 50
+		body, err = ioutil.ReadAll(resp.Body)
+		if err != nil {
+			return fmt.Errorf("Unable to fetch remote Package List (%s)", err.Error())
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, false, err
+	}
+
+	if err := verifyPackageList(client, repo, body); err != nil {
+		if !opts.skipIndexVerify {
+			return nil, false, fmt.Errorf("Unable to verify remote Package List (%s)", err.Error())
+		}
+
+		fmt.Fprintln(akamai.App.ErrWriter, color.CyanString("Warning: using unverified package list (%s)", err.Error()))
+		return body, false, nil
+	}
+
+	return body, true, nil
+}
+
+// verifyPackageList checks the detached signature published alongside the
+// package list at <url>.sig, when cli.index-public-key is configured
+func verifyPackageList(client *http.Client, url string, body []byte) error {
+	key, err := getIndexPublicKey()
+	if err != nil || key == nil {
+		return err
+	}
+
+	resp, err := client.Get(url + ".sig")
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unable to fetch signature: %s", resp.Status)
+	}
+
+	signature, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+
+	return verifyIndexSignature(key, body, signature)
+}
+
+// decodePartialPackageList streams through a (possibly truncated) package list,
+// keeping every package entry that was received in full
+func decodePartialPackageList(data []byte) (*packageList, error) {
+	result := &packageList{}
+
+	dec := json.NewDecoder(bytes.NewReader(data))
+	if tok, err := dec.Token(); err != nil || tok != json.Delim('{') {
+		return nil, errors.New("package list is not a JSON object")
+	}
+
+decode:
+	for dec.More() {
+		tok, err := dec.Token()
+		if err != nil {
+			break
+		}
+
+		switch key, _ := tok.(string); key {
+		case "version":
+			if err := dec.Decode(&result.Version); err != nil {
+				break decode
+			}
+		case "packages":
+			if tok, err := dec.Token(); err != nil || tok != json.Delim('[') {
+				break decode
 			}
 
-			validCmds := make([]Command, 0)
-			for _, cmd := range pkg.Commands {
-				cmdMatches := false
-				if strings.Contains(strings.ToLower(cmd.Name), keyword) {
-					hits += 30
-					cmdMatches = true
+			for dec.More() {
+				var pkg packageListPackage
+				if err := dec.Decode(&pkg); err != nil {
+					break decode
 				}
+				result.Packages = append(result.Packages, pkg)
+			}
 
-				for _, alias := range cmd.Aliases {
-					if strings.Contains(strings.ToLower(alias), keyword) {
-						hits += 20
-						cmdMatches = true
-					}
-				}
+			if _, err := dec.Token(); err != nil {
+				break decode
+			}
+		default:
+			var skip json.RawMessage
+			if err := dec.Decode(&skip); err != nil {
+				break decode
+			}
+		}
+	}
 
-				if strings.Contains(strings.ToLower(cmd.Description), keyword) {
-					hits += 1
-					cmdMatches = true
-				}
+	if len(result.Packages) == 0 {
+		return nil, errors.New("no complete package entries were received")
+	}
 
-				if cmdMatches {
-					validCmds = append(validCmds, cmd)
-				}
+	return result, nil
+}
+
+const defaultSearchHeaderFormat = "Package: {{.Title}} ({{.Name}}) (rank: {{.Rank}})"
+
+type searchResult struct {
+	Package packageListPackage
+	Hits    int
+	// Details break Hits down by the fields that matched
+	Details []scoreDetail
+}
+
+type searchOptions struct {
+	// installed maps installed command names to their versions, and enables version annotations
+	installed map[string]string
+	// headerTemplate renders the per-package header line
+	headerTemplate *template.Template
+	// noBanner suppresses the "Results Found" line
+	noBanner bool
+	// caseSensitive disables lower-casing of keywords and package data before matching
+	caseSensitive bool
+	// palette, when set, colors each package header by hashing the package name
+	palette string
+	// runtimeCount adds a footer counting the runtimes required by the results
+	runtimeCount bool
+	// showSource includes the registry each result was listed by
+	showSource bool
+	// exact disables fuzzy matching, so keywords must appear verbatim
+	exact bool
+	// total is the number of results before pagination
+	total int
+	// numbered prefixes each result with its position, for picking one to install
+	numbered bool
+	// explain shows how the rank of each result was scored
+	explain bool
+	// highlight are the keywords to highlight in text output
+	highlight []string
+}
+
+// searchHeader is the data made available to the --header-format template
+type searchHeader struct {
+	packageListPackage
+	Rank int
+}
+
+const defaultSearchTimeoutPerKeyword = 10 * time.Second
+
+func searchPackages(ctx context.Context, keywords []string, packageList *packageList, opts searchOptions) ([]searchResult, error) {
+	results := make(map[int]map[string]searchResult)
+
+	normalize := strings.ToLower
+	if opts.caseSensitive {
+		normalize = func(s string) string { return s }
+	}
+
+	normalized := make([]string, 0, len(keywords))
+	for _, keyword := range keywords {
+		normalized = append(normalized, normalize(keyword))
+	}
+
+	for key, pkg := range packageList.Packages {
+		select {
+		case <-ctx.Done():
+			return nil, fmt.Errorf("Search timed out while scoring packages (%s)", ctx.Err().Error())
+		default:
+		}
+
+		var details []scoreDetail
+		addDetail := func(field string, value string, weight int, fuzzy bool) int {
+			score, matched := scoreField(normalize(value), normalized, weight, fuzzy)
+			if score > 0 {
+				details = append(details, scoreDetail{Field: field, Weight: weight, Keywords: matched, Score: score})
+			}
+			return score
+		}
+
+		hits := addDetail("name", pkg.Name, 100, !opts.exact)
+		hits += addDetail("title", pkg.Title, 50, !opts.exact)
+
+		validCmds := make([]Command, 0)
+		for _, cmd := range pkg.Commands {
+			cmdHits := addDetail("command "+cmd.Name, cmd.Name, 30, !opts.exact)
+			for _, alias := range cmd.Aliases {
+				cmdHits += addDetail("alias "+alias, alias, 20, !opts.exact)
 			}
+			cmdHits += addDetail("description of "+cmd.Name, cmd.Description, 1, false)
 
-			packageList.Packages[key].Commands = validCmds
+			if cmdHits > 0 {
+				hits += cmdHits
+				validCmds = append(validCmds, cmd)
+			}
 		}
 
+		packageList.Packages[key].Commands = validCmds
+
 		if hits > 0 {
 			if _, ok := results[hits]; !ok {
-				results[hits] = make(map[string]packageListPackage)
+				results[hits] = make(map[string]searchResult)
 			}
-			results[hits][pkg.Name] = pkg
+			results[hits][pkg.Name] = searchResult{Package: pkg, Hits: hits, Details: details}
 		}
 	}
 
@@ -143,36 +749,631 @@ func searchPackages(keywords []string, packageList *packageList) error {
 	resultPkgs := make([]string, 0)
 	for hits := range results {
 		resultHits = append(resultHits, hits)
-		for _, pkg := range results[hits] {
-			resultPkgs = append(resultPkgs, pkg.Name)
+		for _, result := range results[hits] {
+			resultPkgs = append(resultPkgs, result.Package.Name)
 		}
 	}
 
 	sort.Sort(sort.Reverse(sort.IntSlice(resultHits)))
 	sort.Strings(resultPkgs)
-	bold := color.New(color.FgWhite, color.Bold)
-
-	fmt.Fprintln(akamai.App.Writer, color.YellowString("Results Found: %d\n\n", len(resultPkgs)))
 
+	sorted := make([]searchResult, 0, len(resultPkgs))
 	for _, hits := range resultHits {
 		for _, pkgName := range resultPkgs {
-			if _, ok := results[hits][pkgName]; ok {
-				pkg := results[hits][pkgName]
-				fmt.Fprintln(akamai.App.Writer, color.GreenString("Package: %s (%s) (rank: %d)\n", pkg.Title, pkg.Name, hits))
-				for _, cmd := range results[hits][pkgName].Commands {
-					var aliases string
-					if len(cmd.Aliases) == 1 {
-						aliases = fmt.Sprintf("(alias: %s)", cmd.Aliases[0])
-					} else if len(cmd.Aliases) > 1 {
-						aliases = fmt.Sprintf("(aliases: %s)", strings.Join(cmd.Aliases, ", "))
-					}
-
-					fmt.Fprintf(akamai.App.Writer, bold.Sprintf("    Command: %s %s\n", cmd.Name, aliases))
-					fmt.Fprintf(akamai.App.Writer, "        %s\n\n", cmd.Description)
+			if result, ok := results[hits][pkgName]; ok {
+				sorted = append(sorted, result)
+			}
+		}
+	}
+
+	return sorted, nil
+}
+
+// scoreDetail is the part of a result's rank earned by one field, for --explain
+type scoreDetail struct {
+	Field    string   `json:"field"`
+	Weight   int      `json:"weight"`
+	Keywords []string `json:"keywords"`
+	Score    int      `json:"score"`
+}
+
+// scoreField scores a field against all keywords. The field earns its full
+// weight when every keyword matches it, and a share of it when only some do,
+// so a field matching several keywords is not counted several times over.
+func scoreField(field string, keywords []string, weight int, fuzzy bool) (int, []string) {
+	if len(keywords) == 0 {
+		return 0, nil
+	}
+
+	total := 0
+	var matched []string
+	for _, keyword := range keywords {
+		if score := scoreMatch(field, keyword, weight, fuzzy); score > 0 {
+			total += score
+			matched = append(matched, keyword)
+		}
+	}
+
+	// Round up, so a single match of a low weight field still counts
+	return (total + len(keywords) - 1) / len(keywords), matched
+}
+
+// formatScoreDetails explains a rank, e.g. "name 100 (purge) + command purge 30 (purge) = 130"
+func formatScoreDetails(details []scoreDetail, hits int) string {
+	parts := make([]string, 0, len(details))
+	for _, detail := range details {
+		parts = append(parts, fmt.Sprintf("%s %d (%s)", detail.Field, detail.Score, strings.Join(detail.Keywords, ", ")))
+	}
+
+	return fmt.Sprintf("%s = %d", strings.Join(parts, " + "), hits)
+}
+
+// scoreMatch scores how well keyword matches a field. A substring match earns
+// the full weight. With fuzzy matching, a word of the field within a small edit
+// distance of the keyword earns a share of it: half for one typo, a quarter for two.
+func scoreMatch(field string, keyword string, weight int, fuzzy bool) int {
+	if strings.Contains(field, keyword) {
+		return weight
+	}
+
+	maxDistance := maxTypos(keyword)
+	if !fuzzy || maxDistance == 0 {
+		return 0
+	}
+
+	best := maxDistance + 1
+	for _, word := range strings.FieldsFunc(field, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	}) {
+		if distance := levenshtein(word, keyword); distance < best {
+			best = distance
+		}
+	}
+
+	if best > maxDistance {
+		return 0
+	}
+
+	return weight / (2 * best)
+}
+
+// maxTypos is the edit distance tolerated for a keyword, short keywords must match exactly
+func maxTypos(keyword string) int {
+	switch length := len([]rune(keyword)); {
+	case length < 4:
+		return 0
+	case length < 7:
+		return 1
+	default:
+		return 2
+	}
+}
+
+type commandResult struct {
+	Command Command
+	Hits    int
+}
+
+// searchPackageCommands scores the commands of a single package, using the same
+// weights as searchPackages does for commands
+func searchPackageCommands(keywords []string, pkg packageListPackage, opts searchOptions) []commandResult {
+	normalize := strings.ToLower
+	if opts.caseSensitive {
+		normalize = func(s string) string { return s }
+	}
+
+	normalized := make([]string, 0, len(keywords))
+	for _, keyword := range keywords {
+		normalized = append(normalized, normalize(keyword))
+	}
+
+	results := make([]commandResult, 0)
+	for _, cmd := range pkg.Commands {
+		hits, _ := scoreField(normalize(cmd.Name), normalized, 30, !opts.exact)
+		for _, alias := range cmd.Aliases {
+			aliasHits, _ := scoreField(normalize(alias), normalized, 20, !opts.exact)
+			hits += aliasHits
+		}
+		descriptionHits, _ := scoreField(normalize(cmd.Description), normalized, 1, false)
+		hits += descriptionHits
+
+		if hits > 0 {
+			results = append(results, commandResult{Command: cmd, Hits: hits})
+		}
+	}
+
+	sort.SliceStable(results, func(i, j int) bool {
+		return results[i].Hits > results[j].Hits
+	})
+
+	return results
+}
+
+func searchWithinPackage(name string, keywords []string, packageList *packageList, opts searchOptions) error {
+	var pkg *packageListPackage
+	for key := range packageList.Packages {
+		if strings.ToLower(packageList.Packages[key].Name) == strings.ToLower(name) {
+			pkg = &packageList.Packages[key]
+			break
+		}
+	}
+
+	if pkg == nil {
+		return newError(errPackageNotFound, "Package \"%s\" not found in the package repository", name)
+	}
+
+	results := searchPackageCommands(keywords, *pkg, opts)
+
+	if !opts.noBanner {
+		fmt.Fprintln(akamai.App.Writer, color.YellowString("Commands Found in %s: %d\n\n", pkg.Name, len(results)))
+	}
+
+	bold := color.New(color.FgWhite, color.Bold)
+	for _, result := range results {
+		fmt.Fprintf(akamai.App.Writer, bold.Sprintf("    Command: %s (rank: %d)\n", highlightKeywords(result.Command.Name, keywords, opts.caseSensitive), result.Hits))
+		fmt.Fprintf(akamai.App.Writer, "        %s\n\n", highlightKeywords(result.Command.Description, keywords, opts.caseSensitive))
+	}
+
+	return nil
+}
+
+const defaultSearchSort = "rank:desc,name:asc"
+
+type searchSortKey struct {
+	name       string
+	descending bool
+}
+
+// searchSortFields compares two results by a single field, returning <0, 0, or >0
+var searchSortFields = map[string]func(a, b searchResult) int{
+	"rank": func(a, b searchResult) int {
+		return a.Hits - b.Hits
+	},
+	"relevance": func(a, b searchResult) int {
+		return a.Hits - b.Hits
+	},
+	"name": func(a, b searchResult) int {
+		return strings.Compare(strings.ToLower(a.Package.Name), strings.ToLower(b.Package.Name))
+	},
+	"title": func(a, b searchResult) int {
+		return strings.Compare(strings.ToLower(a.Package.Title), strings.ToLower(b.Package.Title))
+	},
+	"version": func(a, b searchResult) int {
+		// versionCompare returns 1 when the right side is greater
+		return -versionCompare(a.Package.Version, b.Package.Version)
+	},
+	"updated": func(a, b searchResult) int {
+		updatedA, updatedB := parseUpdated(a.Package.Updated), parseUpdated(b.Package.Updated)
+		switch {
+		case updatedA.Before(updatedB):
+			return -1
+		case updatedA.After(updatedB):
+			return 1
+		}
+		return 0
+	},
+}
+
+// parseUpdated parses the updated date of a registry package, returning the
+// zero time, which sorts as oldest, if it is missing or invalid
+func parseUpdated(updated string) time.Time {
+	for _, layout := range []string{time.RFC3339, "2006-01-02"} {
+		if t, err := time.Parse(layout, updated); err == nil {
+			return t
+		}
+	}
+
+	return time.Time{}
+}
+
+// parseSearchSortKeys parses a comma-separated list of <key>[:asc|desc]
+func parseSearchSortKeys(spec string) ([]searchSortKey, error) {
+	if strings.TrimSpace(spec) == "" {
+		spec = defaultSearchSort
+	}
+
+	var keys []searchSortKey
+	for _, part := range strings.Split(spec, ",") {
+		fields := strings.SplitN(strings.TrimSpace(part), ":", 2)
+		key := searchSortKey{name: strings.ToLower(fields[0])}
+		if _, ok := searchSortFields[key.name]; !ok {
+			return nil, fmt.Errorf("Unknown sort key \"%s\", must be one of: rank (or relevance), name, title, version, updated", fields[0])
+		}
+
+		// Rank sorts best first and updated newest first unless told otherwise, everything else ascending
+		key.descending = key.name == "rank" || key.name == "relevance" || key.name == "updated"
+		if len(fields) == 2 {
+			switch strings.ToLower(fields[1]) {
+			case "asc":
+				key.descending = false
+			case "desc":
+				key.descending = true
+			default:
+				return nil, fmt.Errorf("Unknown sort direction \"%s\", must be asc or desc", fields[1])
+			}
+		}
+
+		keys = append(keys, key)
+	}
+
+	return keys, nil
+}
+
+func sortSearchResults(results []searchResult, keys []searchSortKey) {
+	sort.SliceStable(results, func(i, j int) bool {
+		for _, key := range keys {
+			cmp := searchSortFields[key.name](results[i], results[j])
+			if cmp == 0 {
+				continue
+			}
+
+			if key.descending {
+				return cmp > 0
+			}
+			return cmp < 0
+		}
+
+		return false
+	})
+}
+
+// filterSearchResultsByRelevance keeps only results scoring at least threshold
+// percent of the top result
+func filterSearchResultsByRelevance(results []searchResult, threshold int) []searchResult {
+	if threshold <= 0 || len(results) == 0 {
+		return results
+	}
+
+	topHits := 0
+	for _, result := range results {
+		if result.Hits > topHits {
+			topHits = result.Hits
+		}
+	}
+
+	minHits := topHits * threshold
+	filtered := make([]searchResult, 0, len(results))
+	for _, result := range results {
+		if result.Hits*100 >= minHits {
+			filtered = append(filtered, result)
+		}
+	}
+
+	return filtered
+}
+
+// filterSearchResultsByRuntime keeps only results whose required runtimes are all in runtimes
+func filterSearchResultsByRuntime(results []searchResult, runtimes map[string]bool) []searchResult {
+	filtered := make([]searchResult, 0, len(results))
+	for _, result := range results {
+		usable := true
+		for _, runtime := range getPackageRuntimes(result.Package) {
+			if !runtimes[runtime] {
+				usable = false
+				break
+			}
+		}
+
+		if usable {
+			filtered = append(filtered, result)
+		}
+	}
+
+	return filtered
+}
+
+func printSearchResults(results []searchResult, opts searchOptions) error {
+	bold := color.New(color.FgWhite, color.Bold)
+
+	if !opts.noBanner {
+		if opts.total > len(results) {
+			fmt.Fprintln(akamai.App.Writer, color.YellowString("Results Found: %d (showing %d)\n\n", opts.total, len(results)))
+		} else {
+			fmt.Fprintln(akamai.App.Writer, color.YellowString("Results Found: %d\n\n", len(results)))
+		}
+	}
+
+	for i, result := range results {
+		pkg := result.Package
+
+		header := &bytes.Buffer{}
+		if opts.numbered {
+			fmt.Fprintf(header, "[%d] ", i+1)
+		}
+		headerPkg := pkg
+		headerPkg.Name = highlightKeywords(pkg.Name, opts.highlight, opts.caseSensitive)
+		headerPkg.Title = highlightKeywords(pkg.Title, opts.highlight, opts.caseSensitive)
+		if err := opts.headerTemplate.Execute(header, searchHeader{headerPkg, result.Hits}); err != nil {
+			return fmt.Errorf("Unable to render header format (%s)", err.Error())
+		}
+		headerColor := color.New(color.FgGreen)
+		if opts.palette != "" {
+			headerColor = getPackageColor(opts.palette, pkg.Name)
+		}
+		fmt.Fprintln(akamai.App.Writer, headerColor.Sprintf("%s\n", header.String()))
+
+		if opts.explain {
+			fmt.Fprintf(akamai.App.Writer, "    Rank: %s\n\n", formatScoreDetails(result.Details, result.Hits))
+		}
+
+		if notice := pkg.deprecationNotice(); notice != "" {
+			fmt.Fprintf(akamai.App.Writer, "    %s\n\n", color.YellowString(notice))
+		}
+
+		if opts.showSource {
+			fmt.Fprintf(akamai.App.Writer, "    Source: %s\n\n", pkg.Source)
+		}
+
+		if opts.installed != nil {
+			if version, ok := getInstalledPackageVersion(pkg, opts.installed); ok {
+				if version == "" {
+					version = "unknown"
+				}
+
+				status := color.GreenString("up-to-date")
+				if version != "unknown" && pkg.Version != "" && compareVersions(pkg.Version, version) > 0 {
+					status = color.CyanString("update available")
 				}
+				fmt.Fprintf(akamai.App.Writer, "    Installed: %s, Registry: %s (%s)\n\n", version, pkg.Version, status)
+			}
+		}
+
+		for _, cmd := range pkg.Commands {
+			var aliases string
+			if len(cmd.Aliases) == 1 {
+				aliases = fmt.Sprintf("(alias: %s)", cmd.Aliases[0])
+			} else if len(cmd.Aliases) > 1 {
+				aliases = fmt.Sprintf("(aliases: %s)", strings.Join(cmd.Aliases, ", "))
+			}
+
+			fmt.Fprintf(akamai.App.Writer, bold.Sprintf("    Command: %s %s\n", highlightKeywords(cmd.Name, opts.highlight, opts.caseSensitive), highlightKeywords(aliases, opts.highlight, opts.caseSensitive)))
+			fmt.Fprintf(akamai.App.Writer, "        %s\n\n", highlightKeywords(cmd.Description, opts.highlight, opts.caseSensitive))
+		}
+	}
+
+	if footer := getRuntimeCountFooter(results, opts); footer != "" {
+		fmt.Fprintln(akamai.App.Writer, color.YellowString(footer))
+	}
+
+	return nil
+}
+
+// Highlighting uses reverse video, which is switched on and off on its own,
+// so the colors and bold of the surrounding text carry on after a match
+const (
+	highlightOn  = "\x1b[7m"
+	highlightOff = "\x1b[27m"
+)
+
+// highlightKeywords highlights each occurrence of the keywords in s, unless
+// colors are disabled. Matches with typos are not highlighted.
+func highlightKeywords(s string, keywords []string, caseSensitive bool) string {
+	if color.NoColor || s == "" {
+		return s
+	}
+
+	var patterns []string
+	for _, keyword := range keywords {
+		if keyword != "" {
+			patterns = append(patterns, regexp.QuoteMeta(keyword))
+		}
+	}
+	if len(patterns) == 0 {
+		return s
+	}
+
+	// Longest first, so that the longest of overlapping keywords is highlighted
+	sort.SliceStable(patterns, func(i, j int) bool {
+		return len(patterns[i]) > len(patterns[j])
+	})
+
+	expr := strings.Join(patterns, "|")
+	if !caseSensitive {
+		expr = "(?i)" + expr
+	}
+
+	return regexp.MustCompile(expr).ReplaceAllStringFunc(s, func(match string) string {
+		return highlightOn + match + highlightOff
+	})
+}
+
+// getPackageRequirement returns the version of runtime a registry package requires, if any
+func getPackageRequirement(pkg packageListPackage, runtime string) string {
+	switch runtime {
+	case "go":
+		return pkg.Requirements.Go
+	case "php":
+		return pkg.Requirements.Php
+	case "node":
+		return pkg.Requirements.Node
+	case "ruby":
+		return pkg.Requirements.Ruby
+	case "python":
+		return pkg.Requirements.Python
+	}
+
+	return ""
+}
+
+// getPackageRuntimes lists the language runtimes a registry package requires
+func getPackageRuntimes(pkg packageListPackage) []string {
+	var runtimes []string
+	for _, runtime := range matrixRuntimes {
+		if getPackageRequirement(pkg, runtime) != "" {
+			runtimes = append(runtimes, runtime)
+		}
+	}
+
+	return runtimes
+}
+
+// getRuntimeCountFooter returns the footer counting the runtimes required by
+// the results, or "" if there is none to print. It is informational, so
+// --quiet leaves it out.
+func getRuntimeCountFooter(results []searchResult, opts searchOptions) string {
+	if !opts.runtimeCount || isQuiet() || len(results) == 0 {
+		return ""
+	}
+
+	return fmt.Sprintf("Runtimes matched: %s", formatRuntimeCounts(results))
+}
+
+// formatRuntimeCounts summarizes runtimes across results, e.g. "5 node, 3 go, 1 python"
+func formatRuntimeCounts(results []searchResult) string {
+	counts := countSearchResults(results, getPackageRuntimes)
+
+	parts := make([]string, 0, len(counts))
+	for _, count := range counts {
+		parts = append(parts, fmt.Sprintf("%d %s", count.Count, count.Value))
+	}
+
+	if len(parts) == 0 {
+		return "none"
+	}
+
+	return strings.Join(parts, ", ")
+}
+
+type jsonSearchResult struct {
+	Name     string              `json:"name"`
+	Title    string              `json:"title"`
+	Version  string              `json:"version"`
+	URL      string              `json:"url"`
+	Source   string              `json:"source"`
+	Rank     int                 `json:"rank"`
+	Commands []jsonSearchCommand `json:"commands"`
+	// Explain breaks the rank down by field, with --explain
+	Explain     []scoreDetail `json:"explain,omitempty"`
+	Deprecated  bool          `json:"deprecated,omitempty"`
+	Replacement string        `json:"replacement,omitempty"`
+}
+
+type jsonSearchCommand struct {
+	Name        string   `json:"name"`
+	Aliases     []string `json:"aliases"`
+	Description string   `json:"description"`
+	Rank        int      `json:"rank"`
+}
+
+// printJSONSearchResults outputs results, and the commands that matched the
+// keywords within each package, as JSON
+func printJSONSearchResults(keywords []string, results []searchResult, opts searchOptions) error {
+	records := make([]jsonSearchResult, 0, len(results))
+	for _, result := range results {
+		pkg := result.Package
+		record := jsonSearchResult{
+			Name:     pkg.Name,
+			Title:    pkg.Title,
+			Version:  pkg.Version,
+			URL:      pkg.URL,
+			Source:   pkg.Source,
+			Rank:     result.Hits,
+			Commands: make([]jsonSearchCommand, 0),
+
+			Deprecated:  pkg.Deprecated,
+			Replacement: pkg.Replacement,
+		}
+		if opts.explain {
+			record.Explain = result.Details
+		}
+
+		for _, match := range searchPackageCommands(keywords, pkg, opts) {
+			aliases := match.Command.Aliases
+			if aliases == nil {
+				aliases = make([]string, 0)
 			}
+
+			record.Commands = append(record.Commands, jsonSearchCommand{
+				Name:        match.Command.Name,
+				Aliases:     aliases,
+				Description: match.Command.Description,
+				Rank:        match.Hits,
+			})
 		}
+
+		records = append(records, record)
+	}
+
+	output, err := json.MarshalIndent(records, "", "  ")
+	if err != nil {
+		return cli.NewExitError(color.RedString(err.Error()), 1)
 	}
 
+	fmt.Fprintln(akamai.App.Writer, string(output))
 	return nil
 }
+
+// printRawSearchResults outputs the registry records of matching packages, untouched
+func printRawSearchResults(results []searchResult) error {
+	records := make([]json.RawMessage, 0, len(results))
+	for _, result := range results {
+		records = append(records, result.Package.raw)
+	}
+
+	output, err := json.Marshal(records)
+	if err != nil {
+		return cli.NewExitError(color.RedString(err.Error()), 1)
+	}
+
+	indented := &bytes.Buffer{}
+	if err := json.Indent(indented, output, "", "  "); err != nil {
+		return cli.NewExitError(color.RedString(err.Error()), 1)
+	}
+
+	fmt.Fprintln(akamai.App.Writer, indented.String())
+	return nil
+}
+
+var markdownEscaper = strings.NewReplacer(
+	`\`, `\\`,
+	"`", "\\`",
+	"*", `\*`,
+	"_", `\_`,
+	"[", `\[`,
+	"]", `\]`,
+	"<", `\<`,
+	">", `\>`,
+	"#", `\#`,
+	"|", `\|`,
+	"\n", " ",
+)
+
+// escapeMarkdown escapes characters that would otherwise be rendered as
+// Markdown formatting or break a table row
+func escapeMarkdown(s string) string {
+	return markdownEscaper.Replace(s)
+}
+
+// printMarkdownSearchResults renders results as a Markdown table, suitable for
+// pasting into documentation
+func printMarkdownSearchResults(results []searchResult, opts searchOptions) {
+	if opts.showSource {
+		fmt.Fprintln(akamai.App.Writer, "| Package | Name | Version | Source | Commands |")
+		fmt.Fprintln(akamai.App.Writer, "| --- | --- | --- | --- | --- |")
+	} else {
+		fmt.Fprintln(akamai.App.Writer, "| Package | Name | Version | Commands |")
+		fmt.Fprintln(akamai.App.Writer, "| --- | --- | --- | --- |")
+	}
+
+	for _, result := range results {
+		pkg := result.Package
+
+		title := escapeMarkdown(pkg.Title)
+		if pkg.URL != "" {
+			title = fmt.Sprintf("[%s](%s)", title, strings.Replace(pkg.URL, ")", "%29", -1))
+		}
+
+		commands := make([]string, 0, len(pkg.Commands))
+		for _, cmd := range pkg.Commands {
+			commands = append(commands, fmt.Sprintf("**%s**: %s", escapeMarkdown(cmd.Name), escapeMarkdown(cmd.Description)))
+		}
+
+		cells := []string{title, escapeMarkdown(pkg.Name), escapeMarkdown(pkg.Version)}
+		if opts.showSource {
+			cells = append(cells, escapeMarkdown(pkg.Source))
+		}
+		cells = append(cells, strings.Join(commands, "<br>"))
+
+		fmt.Fprintf(akamai.App.Writer, "| %s |\n", strings.Join(cells, " | "))
+	}
+}
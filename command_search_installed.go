@@ -0,0 +1,61 @@
+/*
+ Copyright 2018. Akamai Technologies, Inc
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package main
+
+import "path/filepath"
+
+// getInstalledPackageList builds a package list from the cli.json of each
+// installed package, so that search can rank them without the registry
+func getInstalledPackageList() *packageList {
+	list := &packageList{Version: 1}
+	for _, dir := range getAllPackageDirs() {
+		cmdPackage, err := readPackage(dir)
+		if err != nil {
+			continue
+		}
+
+		dirName := filepath.Base(getPackageRoot(dir))
+		manifest, _ := readManifest(dirName)
+		list.Packages = append(list.Packages, newInstalledListPackage(dirName, cmdPackage, manifest))
+	}
+
+	return list
+}
+
+// newInstalledListPackage describes an installed package like a registry
+// package. Packages have no title in their cli.json, so their directory name
+// is used instead.
+func newInstalledListPackage(dirName string, cmdPackage commandPackage, manifest packageManifest) packageListPackage {
+	pkg := packageListPackage{
+		Title:        dirName,
+		Name:         normalizePackageName(dirName),
+		URL:          manifest.Repo,
+		Path:         manifest.Subpath,
+		Commands:     cmdPackage.Commands,
+		Requirements: cmdPackage.Requirements,
+		Source:       "installed",
+	}
+	if manifest.Source != "" {
+		pkg.URL = manifest.Source
+	}
+
+	if len(cmdPackage.Commands) > 0 {
+		pkg.Version = cmdPackage.Commands[0].Version
+	}
+
+	return pkg
+}
@@ -0,0 +1,171 @@
+/*
+ Copyright 2018. Akamai Technologies, Inc
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	akamai "github.com/akamai/cli-common-golang"
+	"github.com/fatih/color"
+	"github.com/urfave/cli"
+)
+
+type searchCount struct {
+	Value string `json:"value"`
+	Count int    `json:"count"`
+}
+
+// searchCountFields map a --count-by field to the values a package has for it
+var searchCountFields = map[string]func(pkg packageListPackage) []string{
+	"runtime": func(pkg packageListPackage) []string {
+		if runtimes := getPackageRuntimes(pkg); len(runtimes) > 0 {
+			return runtimes
+		}
+		return []string{"none"}
+	},
+	"namespace": func(pkg packageListPackage) []string {
+		return []string{strings.SplitN(pkg.Name, "/", 2)[0]}
+	},
+	"tag": getPackageTags,
+}
+
+func validateSearchCountField(field string) error {
+	if _, ok := searchCountFields[field]; !ok {
+		return fmt.Errorf("Unknown --count-by field \"%s\", must be one of: namespace, runtime, tag", field)
+	}
+
+	return nil
+}
+
+// countSearchResults tallies the values of results, most common first
+func countSearchResults(results []searchResult, values func(pkg packageListPackage) []string) []searchCount {
+	counts := make(map[string]int)
+	for _, result := range results {
+		for _, value := range values(result.Package) {
+			counts[value]++
+		}
+	}
+
+	tally := make([]searchCount, 0, len(counts))
+	for value, count := range counts {
+		tally = append(tally, searchCount{value, count})
+	}
+	sort.Slice(tally, func(i, j int) bool {
+		if tally[i].Count != tally[j].Count {
+			return tally[i].Count > tally[j].Count
+		}
+		return tally[i].Value < tally[j].Value
+	})
+
+	return tally
+}
+
+func printSearchCounts(results []searchResult, field string, asJSON bool) error {
+	counts := countSearchResults(results, searchCountFields[field])
+
+	if asJSON {
+		output, err := json.MarshalIndent(struct {
+			Field  string        `json:"field"`
+			Counts []searchCount `json:"counts"`
+		}{field, counts}, "", "  ")
+		if err != nil {
+			return cli.NewExitError(color.RedString(err.Error()), 1)
+		}
+
+		fmt.Fprintln(akamai.App.Writer, string(output))
+		return nil
+	}
+
+	w := tabwriter.NewWriter(akamai.App.Writer, 0, 8, 2, ' ', 0)
+	fmt.Fprintf(w, "%s\tPACKAGES\n", strings.ToUpper(field))
+	for _, count := range counts {
+		fmt.Fprintf(w, "%s\t%d\n", count.Value, count.Count)
+	}
+	w.Flush()
+
+	return nil
+}
+
+type topCommand struct {
+	Command  string   `json:"command"`
+	Count    int      `json:"count"`
+	Packages []string `json:"packages"`
+}
+
+// getTopCommands counts how many packages provide each command name or alias,
+// most common first, keeping at most n entries when n > 0
+func getTopCommands(results []searchResult, n int) []topCommand {
+	providers := make(map[string][]string)
+	for _, result := range results {
+		seen := make(map[string]bool)
+		for _, cmd := range result.Package.Commands {
+			for _, name := range append([]string{cmd.Name}, cmd.Aliases...) {
+				name = strings.ToLower(name)
+				if name == "" || seen[name] {
+					continue
+				}
+				seen[name] = true
+				providers[name] = append(providers[name], result.Package.Name)
+			}
+		}
+	}
+
+	top := make([]topCommand, 0, len(providers))
+	for name, packages := range providers {
+		sort.Strings(packages)
+		top = append(top, topCommand{name, len(packages), packages})
+	}
+	sort.Slice(top, func(i, j int) bool {
+		if top[i].Count != top[j].Count {
+			return top[i].Count > top[j].Count
+		}
+		return top[i].Command < top[j].Command
+	})
+
+	if n > 0 && len(top) > n {
+		top = top[:n]
+	}
+
+	return top
+}
+
+func printTopCommands(results []searchResult, n int, asJSON bool) error {
+	top := getTopCommands(results, n)
+
+	if asJSON {
+		output, err := json.MarshalIndent(top, "", "  ")
+		if err != nil {
+			return cli.NewExitError(color.RedString(err.Error()), 1)
+		}
+
+		fmt.Fprintln(akamai.App.Writer, string(output))
+		return nil
+	}
+
+	w := tabwriter.NewWriter(akamai.App.Writer, 0, 8, 2, ' ', 0)
+	fmt.Fprintln(w, "COMMAND\tPACKAGES\tPROVIDED BY")
+	for _, cmd := range top {
+		fmt.Fprintf(w, "%s\t%d\t%s\n", cmd.Command, cmd.Count, strings.Join(cmd.Packages, ", "))
+	}
+	w.Flush()
+
+	return nil
+}
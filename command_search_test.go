@@ -0,0 +1,503 @@
+/*
+ Copyright 2018. Akamai Technologies, Inc
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package main
+
+import (
+	"context"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/fatih/color"
+)
+
+func testPackageList() *packageList {
+	return &packageList{
+		Version: 1,
+		Packages: []packageListPackage{
+			{
+				Name:    "purge",
+				Title:   "Fast Purge",
+				Version: "1.0.0",
+				Commands: []Command{
+					{Name: "purge", Description: "Purge content from the edge"},
+				},
+			},
+			{
+				Name:    "property",
+				Title:   "Property Manager API",
+				Version: "0.6.0",
+				Commands: []Command{
+					{Name: "property", Aliases: []string{"prop"}, Description: "Manage properties"},
+				},
+			},
+		},
+	}
+}
+
+func TestSearchPackages(t *testing.T) {
+	searchTests := []struct {
+		keywords      []string
+		caseSensitive bool
+		exact         bool
+		results       []string
+	}{
+		{[]string{"purge"}, false, false, []string{"purge"}},
+		{[]string{"PROP"}, false, false, []string{"property"}},
+		{[]string{"p"}, false, false, []string{"property", "purge"}},
+		{[]string{"API"}, true, false, []string{"property"}},
+		{[]string{"api"}, true, false, []string{}},
+		{[]string{"proprety"}, false, false, []string{"property"}},
+		{[]string{"proprety"}, false, true, []string{}},
+		{[]string{"prge"}, false, false, []string{"purge"}},
+	}
+
+	for _, tt := range searchTests {
+		results, err := searchPackages(context.Background(), tt.keywords, testPackageList(), searchOptions{caseSensitive: tt.caseSensitive, exact: tt.exact})
+		if err != nil {
+			t.Errorf("searchPackages(%v) => error: %s", tt.keywords, err.Error())
+			continue
+		}
+
+		names := make([]string, 0)
+		for _, result := range results {
+			names = append(names, result.Package.Name)
+		}
+
+		if len(names) != len(tt.results) {
+			t.Errorf("searchPackages(%v) => %v, wanted: %v", tt.keywords, names, tt.results)
+			continue
+		}
+
+		for i := range names {
+			if names[i] != tt.results[i] {
+				t.Errorf("searchPackages(%v) => %v, wanted: %v", tt.keywords, names, tt.results)
+				break
+			}
+		}
+	}
+}
+
+func TestFilterSearchResultsByRelevance(t *testing.T) {
+	results := []searchResult{{Hits: 200}, {Hits: 150}, {Hits: 100}, {Hits: 20}}
+
+	relevanceTests := []struct {
+		threshold int
+		count     int
+	}{
+		{0, 4},
+		{50, 3},
+		{75, 2},
+		{100, 1},
+	}
+
+	for _, tt := range relevanceTests {
+		if filtered := filterSearchResultsByRelevance(results, tt.threshold); len(filtered) != tt.count {
+			t.Errorf("filterSearchResultsByRelevance(%d) => %d results, wanted: %d", tt.threshold, len(filtered), tt.count)
+		}
+	}
+}
+
+func TestSortSearchResults(t *testing.T) {
+	results := func() []searchResult {
+		return []searchResult{
+			{Package: packageListPackage{Name: "b", Version: "1.0.0", Updated: "2018-03-01"}, Hits: 100},
+			{Package: packageListPackage{Name: "a", Version: "2.0.0", Updated: "2018-01-15T10:00:00Z"}, Hits: 100},
+			{Package: packageListPackage{Name: "c", Version: "1.5.0"}, Hits: 150},
+		}
+	}
+
+	sortTests := []struct {
+		spec  string
+		order string
+	}{
+		{"", "cab"},
+		{"rank:desc,name:asc", "cab"},
+		{"rank,version:desc", "cab"},
+		{"rank:asc,name:desc", "bac"},
+		{"version", "bca"},
+		{"name:desc", "cba"},
+		{"relevance,name", "cab"},
+		{"updated", "bac"},
+		{"updated:asc", "cab"},
+	}
+
+	for _, tt := range sortTests {
+		keys, err := parseSearchSortKeys(tt.spec)
+		if err != nil {
+			t.Errorf("parseSearchSortKeys(%s) => error: %s", tt.spec, err.Error())
+			continue
+		}
+
+		sorted := results()
+		sortSearchResults(sorted, keys)
+
+		order := ""
+		for _, result := range sorted {
+			order += result.Package.Name
+		}
+
+		if order != tt.order {
+			t.Errorf("sortSearchResults(%s) => %s, wanted: %s", tt.spec, order, tt.order)
+		}
+	}
+
+	for _, spec := range []string{"popularity", "rank:up"} {
+		if _, err := parseSearchSortKeys(spec); err == nil {
+			t.Errorf("parseSearchSortKeys(%s) => no error, wanted an error", spec)
+		}
+	}
+}
+
+func TestScoreField(t *testing.T) {
+	scoreTests := []struct {
+		field    string
+		keywords []string
+		weight   int
+		score    int
+		matched  int
+	}{
+		{"property", []string{"property"}, 100, 100, 1},
+		{"property manager", []string{"property", "manager"}, 100, 100, 2},
+		{"property", []string{"property", "manager"}, 100, 50, 1},
+		{"purge content", []string{"purge", "dns", "edge"}, 1, 1, 1},
+		{"purge", []string{"dns"}, 100, 0, 0},
+		{"purge", nil, 100, 0, 0},
+	}
+
+	for _, tt := range scoreTests {
+		if score, matched := scoreField(tt.field, tt.keywords, tt.weight, false); score != tt.score || len(matched) != tt.matched {
+			t.Errorf("scoreField(%s, %v) => %d (%v), wanted: %d (%d keywords)", tt.field, tt.keywords, score, matched, tt.score, tt.matched)
+		}
+	}
+}
+
+func TestSearchPackagesExplain(t *testing.T) {
+	results, err := searchPackages(context.Background(), []string{"purge"}, testPackageList(), searchOptions{})
+	if err != nil || len(results) != 1 {
+		t.Fatalf("searchPackages(purge) => %v, %v", results, err)
+	}
+
+	total := 0
+	for _, detail := range results[0].Details {
+		total += detail.Score
+	}
+	if total != results[0].Hits {
+		t.Errorf("searchPackages(purge) details add up to %d, wanted: %d", total, results[0].Hits)
+	}
+
+	if explained := formatScoreDetails(results[0].Details, results[0].Hits); !strings.HasPrefix(explained, "name 100 (purge) + ") || !strings.HasSuffix(explained, "= 181") {
+		t.Errorf("formatScoreDetails() => %s", explained)
+	}
+}
+
+func TestDecodePartialPackageList(t *testing.T) {
+	complete := `{"version": 1, "packages": [{"name": "purge", "commands": [{"name": "purge"}]}, {"name": "property", "commands": [{"name": "property"}]}]}`
+
+	partialTests := []struct {
+		data     string
+		packages int
+		err      bool
+	}{
+		{complete, 2, false},
+		{complete[:len(complete)-40], 1, false},
+		{`{"version": 1, "packages": [{"name": "pur`, 0, true},
+		{`[]`, 0, true},
+	}
+
+	for _, tt := range partialTests {
+		result, err := decodePartialPackageList([]byte(tt.data))
+		if tt.err {
+			if err == nil {
+				t.Errorf("decodePartialPackageList(%s) => no error, wanted an error", tt.data)
+			}
+			continue
+		}
+
+		if err != nil {
+			t.Errorf("decodePartialPackageList(%s) => error: %s", tt.data, err.Error())
+			continue
+		}
+
+		if len(result.Packages) != tt.packages {
+			t.Errorf("decodePartialPackageList(%s) => %d packages, wanted: %d", tt.data, len(result.Packages), tt.packages)
+		}
+	}
+}
+
+func TestEscapeMarkdown(t *testing.T) {
+	escapeTests := []struct {
+		in  string
+		out string
+	}{
+		{"Property Manager", "Property Manager"},
+		{"a|b", `a\|b`},
+		{"*bold* _em_", `\*bold\* \_em\_`},
+		{"[link](url)", `\[link\](url)`},
+		{"line\nbreak", "line break"},
+		{`back\slash`, `back\\slash`},
+	}
+
+	for _, tt := range escapeTests {
+		if out := escapeMarkdown(tt.in); out != tt.out {
+			t.Errorf("escapeMarkdown(%q) => %q, wanted: %q", tt.in, out, tt.out)
+		}
+	}
+}
+
+func TestCountSearchResults(t *testing.T) {
+	results := []searchResult{
+		{Package: packageListPackage{Name: "acme/purge"}},
+		{Package: packageListPackage{Name: "acme/property"}},
+		{Package: packageListPackage{Name: "dns"}},
+	}
+	results[0].Package.Requirements.Node = "7.0.0"
+	results[1].Package.Requirements.Node = "7.0.0"
+	results[1].Package.Requirements.Go = "1.9.0"
+
+	countTests := []struct {
+		field  string
+		counts []searchCount
+	}{
+		{"runtime", []searchCount{{"node", 2}, {"go", 1}, {"none", 1}}},
+		{"namespace", []searchCount{{"acme", 2}, {"dns", 1}}},
+	}
+
+	for _, tt := range countTests {
+		counts := countSearchResults(results, searchCountFields[tt.field])
+		if len(counts) != len(tt.counts) {
+			t.Errorf("countSearchResults(%s) => %v, wanted: %v", tt.field, counts, tt.counts)
+			continue
+		}
+
+		for i := range counts {
+			if counts[i] != tt.counts[i] {
+				t.Errorf("countSearchResults(%s) => %v, wanted: %v", tt.field, counts, tt.counts)
+				break
+			}
+		}
+	}
+}
+
+func TestGetTopCommands(t *testing.T) {
+	results := []searchResult{
+		{Package: packageListPackage{Name: "purge", Commands: []Command{{Name: "purge"}, {Name: "list", Aliases: []string{"ls"}}}}},
+		{Package: packageListPackage{Name: "property", Commands: []Command{{Name: "property", Aliases: []string{"ls"}}, {Name: "List"}}}},
+		{Package: packageListPackage{Name: "dns", Commands: []Command{{Name: "dns", Aliases: []string{"dns"}}}}},
+	}
+
+	top := getTopCommands(results, 2)
+	if len(top) != 2 {
+		t.Fatalf("getTopCommands() => %d commands, wanted: 2", len(top))
+	}
+
+	if top[0].Command != "list" || top[0].Count != 2 || top[0].Packages[0] != "property" {
+		t.Errorf("getTopCommands()[0] => %v, wanted list provided by property and purge", top[0])
+	}
+
+	if top[1].Command != "ls" || top[1].Count != 2 {
+		t.Errorf("getTopCommands()[1] => %v, wanted ls provided by 2 packages", top[1])
+	}
+
+	if all := getTopCommands(results, 0); len(all) != 5 {
+		t.Errorf("getTopCommands(0) => %d commands, wanted: 5", len(all))
+	}
+}
+
+func TestPaginateSearchResults(t *testing.T) {
+	results := make([]searchResult, 5)
+	for i := range results {
+		results[i].Hits = i
+	}
+
+	pageTests := []struct {
+		limit int
+		page  int
+		first int
+		count int
+	}{
+		{0, 1, 0, 5},
+		{2, 1, 0, 2},
+		{2, 2, 2, 2},
+		{2, 3, 4, 1},
+		{2, 4, 0, 0},
+		{10, 1, 0, 5},
+	}
+
+	for _, tt := range pageTests {
+		page := paginateSearchResults(results, tt.limit, tt.page)
+		if len(page) != tt.count {
+			t.Errorf("paginateSearchResults(%d, %d) => %d results, wanted: %d", tt.limit, tt.page, len(page), tt.count)
+			continue
+		}
+
+		if len(page) > 0 && page[0].Hits != tt.first {
+			t.Errorf("paginateSearchResults(%d, %d) => starts at %d, wanted: %d", tt.limit, tt.page, page[0].Hits, tt.first)
+		}
+	}
+}
+
+func TestFilterSearchResultsByRuntime(t *testing.T) {
+	results := []searchResult{
+		{Package: packageListPackage{Name: "purge"}},
+		{Package: packageListPackage{Name: "property"}},
+		{Package: packageListPackage{Name: "dns"}},
+	}
+	results[0].Package.Requirements.Node = "7.0.0"
+	results[1].Package.Requirements.Node = "7.0.0"
+	results[1].Package.Requirements.Go = "1.9.0"
+
+	runtimeTests := []struct {
+		runtimes map[string]bool
+		results  []string
+	}{
+		{map[string]bool{"node": true}, []string{"purge", "dns"}},
+		{map[string]bool{"node": true, "go": true}, []string{"purge", "property", "dns"}},
+		{map[string]bool{}, []string{"dns"}},
+	}
+
+	for _, tt := range runtimeTests {
+		filtered := filterSearchResultsByRuntime(results, tt.runtimes)
+		names := make([]string, 0)
+		for _, result := range filtered {
+			names = append(names, result.Package.Name)
+		}
+
+		if strings.Join(names, ",") != strings.Join(tt.results, ",") {
+			t.Errorf("filterSearchResultsByRuntime(%v) => %v, wanted: %v", tt.runtimes, names, tt.results)
+		}
+	}
+}
+
+func TestNewInstalledListPackage(t *testing.T) {
+	cmdPackage := commandPackage{
+		Commands: []Command{
+			{Name: "purge", Version: "1.2.0", Description: "Purge content from the edge"},
+			{Name: "purge-queue", Version: "1.2.0"},
+		},
+	}
+	cmdPackage.Requirements.Go = "1.9.0"
+
+	pkg := newInstalledListPackage("cli-purge", cmdPackage, packageManifest{Repo: "https://github.com/akamai/cli-purge.git"})
+	if pkg.Name != "purge" || pkg.Title != "cli-purge" || pkg.Version != "1.2.0" || pkg.URL != "https://github.com/akamai/cli-purge.git" || len(pkg.Commands) != 2 || pkg.Requirements.Go != "1.9.0" {
+		t.Errorf("newInstalledListPackage(cli-purge) => %+v", pkg)
+	}
+
+	pkg = newInstalledListPackage("my-tools", commandPackage{}, packageManifest{Repo: "https://example.org/my-tools.git", Source: "/opt/my-tools"})
+	if pkg.Name != "my-tools" || pkg.Version != "" || pkg.URL != "/opt/my-tools" {
+		t.Errorf("newInstalledListPackage(my-tools) => %+v", pkg)
+	}
+
+	list := &packageList{Packages: []packageListPackage{newInstalledListPackage("cli-purge", cmdPackage, packageManifest{})}}
+	results, err := searchPackages(context.Background(), []string{"edge"}, list, searchOptions{})
+	if err != nil || len(results) != 1 || results[0].Package.Name != "purge" {
+		t.Errorf("searchPackages(edge) on installed packages => %v, %v, wanted: purge", results, err)
+	}
+}
+
+func TestHighlightKeywords(t *testing.T) {
+	noColor := color.NoColor
+	defer func() { color.NoColor = noColor }()
+	color.NoColor = false
+
+	highlightTests := []struct {
+		s             string
+		keywords      []string
+		caseSensitive bool
+		expected      string
+	}{
+		{"Fast Purge", []string{"purge"}, false, "Fast \x1b[7mPurge\x1b[27m"},
+		{"Fast Purge", []string{"purge"}, true, "Fast Purge"},
+		{"property-manager", []string{"prop", "property"}, false, "\x1b[7mproperty\x1b[27m-manager"},
+		{"a.b ab", []string{"a.b"}, false, "\x1b[7ma.b\x1b[27m ab"},
+		{"purge", nil, false, "purge"},
+	}
+
+	for _, tt := range highlightTests {
+		if highlighted := highlightKeywords(tt.s, tt.keywords, tt.caseSensitive); highlighted != tt.expected {
+			t.Errorf("highlightKeywords(%s, %v) => %q, wanted: %q", tt.s, tt.keywords, highlighted, tt.expected)
+		}
+	}
+
+	color.NoColor = true
+	if highlighted := highlightKeywords("Fast Purge", []string{"purge"}, false); highlighted != "Fast Purge" {
+		t.Errorf("highlightKeywords() with colors disabled => %q, wanted no highlighting", highlighted)
+	}
+}
+
+func TestFilterSearchResultsByTag(t *testing.T) {
+	results := []searchResult{
+		{Package: packageListPackage{Name: "purge", Tags: []string{"Caching", "purge"}}},
+		{Package: packageListPackage{Name: "firewall", Tags: []string{"security"}, Categories: []string{"Caching"}}},
+		{Package: packageListPackage{Name: "dns"}},
+	}
+
+	tagTests := []struct {
+		tags    []string
+		results []string
+	}{
+		{parseTagFilter([]string{"caching"}), []string{"purge", "firewall"}},
+		{parseTagFilter([]string{"caching,security"}), []string{"firewall"}},
+		{parseTagFilter([]string{"Caching", "purge"}), []string{"purge"}},
+		{parseTagFilter([]string{"dns"}), []string{}},
+	}
+
+	for _, tt := range tagTests {
+		filtered := filterSearchResultsByTag(results, tt.tags)
+		names := make([]string, 0)
+		for _, result := range filtered {
+			names = append(names, result.Package.Name)
+		}
+
+		if strings.Join(names, ",") != strings.Join(tt.results, ",") {
+			t.Errorf("filterSearchResultsByTag(%v) => %v, wanted: %v", tt.tags, names, tt.results)
+		}
+	}
+
+	counts := countSearchResults(results, getPackageTags)
+	if len(counts) != 3 || counts[0].Value != "caching" || counts[0].Count != 2 {
+		t.Errorf("countSearchResults(getPackageTags) => %v, wanted caching first with 2 packages", counts)
+	}
+}
+
+func TestGetRuntimeCountFooter(t *testing.T) {
+	results := []searchResult{
+		{Package: packageListPackage{Name: "purge"}},
+		{Package: packageListPackage{Name: "dns"}},
+	}
+	results[0].Package.Requirements.Node = "7.0.0"
+	defer os.Unsetenv(quietEnv)
+
+	footerTests := []struct {
+		results      []searchResult
+		runtimeCount bool
+		quiet        string
+		footer       string
+	}{
+		{results, true, "", "Runtimes matched: 1 node, 1 none"},
+		{results, false, "", ""},
+		{results, true, "1", ""},
+		{nil, true, "", ""},
+	}
+
+	for _, tt := range footerTests {
+		os.Setenv(quietEnv, tt.quiet)
+		if footer := getRuntimeCountFooter(tt.results, searchOptions{runtimeCount: tt.runtimeCount}); footer != tt.footer {
+			t.Errorf("getRuntimeCountFooter(%d results, runtimeCount: %t, quiet: %q) => %q, wanted: %q", len(tt.results), tt.runtimeCount, tt.quiet, footer, tt.footer)
+		}
+	}
+}
@@ -0,0 +1,165 @@
+/*
+ Copyright 2018. Akamai Technologies, Inc
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	akamai "github.com/akamai/cli-common-golang"
+	"github.com/fatih/color"
+	"github.com/mattn/go-isatty"
+	"github.com/urfave/cli"
+)
+
+const (
+	keyCtrlC     = 3
+	keyBackspace = 8
+	keyEnter     = 13
+	keyEscape    = 27
+	keyDelete    = 127
+)
+
+// watchSearch re-runs the search against the already fetched package list as
+// the user types, like a fuzzy finder. No network requests are made while typing.
+func watchSearch(keywords []string, list *packageList, opts searchOptions) error {
+	if !isatty.IsTerminal(os.Stdin.Fd()) || !isatty.IsTerminal(os.Stdout.Fd()) {
+		return cli.NewExitError(color.RedString("--watch requires an interactive terminal"), 1)
+	}
+
+	restore, err := makeRawTerminal()
+	if err != nil {
+		return cli.NewExitError(color.RedString(err.Error()), 1)
+	}
+	// Restoring twice is harmless, it is restored early for what follows
+	defer restore()
+
+	query := strings.Join(keywords, " ")
+	selected := 0
+	var results []searchResult
+
+	buf := make([]byte, 3)
+	for {
+		results = watchSearchResults(query, list, opts)
+		if selected >= len(results) {
+			selected = len(results) - 1
+		}
+		if selected < 0 {
+			selected = 0
+		}
+
+		renderWatchSearch(query, results, selected)
+
+		n, err := os.Stdin.Read(buf)
+		if err != nil {
+			return nil
+		}
+
+		switch {
+		case n == 3 && buf[0] == keyEscape && buf[1] == '[' && buf[2] == 'A':
+			selected--
+		case n == 3 && buf[0] == keyEscape && buf[1] == '[' && buf[2] == 'B':
+			selected++
+		case buf[0] == keyEscape || buf[0] == keyCtrlC:
+			restore()
+			fmt.Fprint(akamai.App.Writer, "\033[H\033[2J")
+			return nil
+		case buf[0] == keyEnter:
+			restore()
+			fmt.Fprint(akamai.App.Writer, "\033[H\033[2J")
+			if len(results) == 0 {
+				return nil
+			}
+			return offerInstall(results[selected].Package)
+		case buf[0] == keyBackspace || buf[0] == keyDelete:
+			if len(query) > 0 {
+				query = query[:len(query)-1]
+			}
+		case n == 1 && buf[0] >= ' ' && buf[0] < keyDelete:
+			query += string(buf[0])
+			selected = 0
+		}
+	}
+}
+
+func watchSearchResults(query string, list *packageList, opts searchOptions) []searchResult {
+	keywords := strings.Fields(query)
+	if len(keywords) == 0 {
+		return nil
+	}
+
+	// searchPackages trims the command lists of the packages it is given, so
+	// each query must start from a fresh copy of the list
+	fresh := &packageList{
+		Version:  list.Version,
+		Packages: append([]packageListPackage(nil), list.Packages...),
+	}
+
+	results, err := searchPackages(context.Background(), keywords, fresh, opts)
+	if err != nil {
+		return nil
+	}
+
+	return results
+}
+
+func renderWatchSearch(query string, results []searchResult, selected int) {
+	rows, _, err := getTerminalSize()
+	if err != nil || rows < 4 {
+		rows = 24
+	}
+
+	// In raw mode, output needs explicit carriage returns
+	fmt.Fprint(akamai.App.Writer, "\033[H\033[2J")
+	fmt.Fprintf(akamai.App.Writer, "%s %s\r\n", color.YellowString("Search:"), query)
+	fmt.Fprintf(akamai.App.Writer, "%s\r\n\r\n", color.New(color.Faint).Sprint("(up/down to select, enter to install, esc to exit)"))
+
+	for i, result := range results {
+		if i >= rows-4 {
+			break
+		}
+
+		line := fmt.Sprintf("%s (%s) (rank: %d)", result.Package.Title, result.Package.Name, result.Hits)
+		if i == selected {
+			fmt.Fprintf(akamai.App.Writer, "%s\r\n", color.New(color.FgBlack, color.BgGreen).Sprint("> "+line))
+		} else {
+			fmt.Fprintf(akamai.App.Writer, "  %s\r\n", line)
+		}
+	}
+}
+
+// offerInstall asks whether to install the chosen package
+func offerInstall(pkg packageListPackage) error {
+	fmt.Fprintf(akamai.App.Writer, "Install %s (%s)? [Y/n]: ", pkg.Title, pkg.Name)
+	answer := ""
+	fmt.Scanln(&answer)
+	if answer != "" && strings.ToLower(answer) != "y" {
+		return nil
+	}
+
+	oldCmds := getCommands()
+
+	if err := installTarget(pkg.getInstallTarget(), installOptions{}.forPackage(pkg)); err != nil {
+		return err
+	}
+
+	packageListDiff(oldCmds)
+
+	return nil
+}
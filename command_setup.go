@@ -0,0 +1,396 @@
+/*
+ Copyright 2018. Akamai Technologies, Inc
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"net/url"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	akamai "github.com/akamai/cli-common-golang"
+	"github.com/fatih/color"
+	"github.com/go-ini/ini"
+	"github.com/mattn/go-isatty"
+	"github.com/mitchellh/go-homedir"
+	"github.com/urfave/cli"
+)
+
+const (
+	// nonInteractiveEnv makes akamai setup take every answer from its flags, for provisioning
+	nonInteractiveEnv = "AKAMAI_CLI_NON_INTERACTIVE"
+	// setupEnvPrefix is the prefix of the environment variables setting akamai setup flags
+	setupEnvPrefix = "AKAMAI_CLI_SETUP_"
+)
+
+// setupInput is shared by all prompts, so that answers buffered by one are not lost to the next
+var setupInput = bufio.NewReader(os.Stdin)
+
+// setupOptions are the answers to the setup steps given with flags, an empty
+// value is asked for, or left as it is when not interactive
+type setupOptions struct {
+	interactive bool
+	edgerc      string
+	section     string
+	proxy       string
+	noProxy     bool
+	caBundle    string
+	autoUpgrade string
+	channel     string
+	telemetry   string
+}
+
+func cmdSetup(c *cli.Context) error {
+	opts := setupOptions{
+		interactive: !c.Bool("non-interactive") && isatty.IsTerminal(os.Stdin.Fd()) && isatty.IsTerminal(os.Stdout.Fd()),
+		edgerc:      c.String("edgerc"),
+		section:     c.String("section"),
+		proxy:       c.String("proxy"),
+		noProxy:     c.Bool("no-proxy"),
+		caBundle:    c.String("ca-bundle"),
+		autoUpgrade: strings.ToLower(c.String("auto-upgrade")),
+		channel:     strings.ToLower(c.String("upgrade-channel")),
+		telemetry:   strings.ToLower(c.String("telemetry")),
+	}
+
+	for _, value := range []string{opts.autoUpgrade, opts.telemetry} {
+		if _, err := parseOnOff(value); value != "" && err != nil {
+			return cli.NewExitError(color.RedString(err.Error()), 1)
+		}
+	}
+
+	if opts.interactive {
+		showBanner()
+	}
+
+	steps := []func(setupOptions) error{setupCredentials, setupProxy, setupUpgrade, setupTelemetry}
+	for _, step := range steps {
+		if err := step(opts); err != nil {
+			return cli.NewExitError(color.RedString(err.Error()), 1)
+		}
+	}
+
+	if err := saveConfig(); err != nil {
+		return cli.NewExitError(color.RedString("Unable to save the configuration: %s", err.Error()), 1)
+	}
+
+	printInfo(akamai.App.Writer, "Setup complete, run \"%s setup\" again at any time to change these settings", self())
+	return nil
+}
+
+// setupCredentials finds the sections of the .edgerc file, and makes the one
+// chosen the credentials profile in use
+func setupCredentials(opts setupOptions) error {
+	edgerc := opts.edgerc
+	if edgerc == "" {
+		edgerc = defaultEdgerc
+	}
+
+	sections, err := getEdgercSections(edgerc)
+	if err != nil {
+		if opts.section != "" {
+			return err
+		}
+
+		printInfo(akamai.App.Writer, "No credentials were found in %s, see https://developer.akamai.com/introduction/Conf_Client.html to create them", edgerc)
+		return nil
+	}
+
+	section := opts.section
+	if section == "" && opts.interactive {
+		section = promptChoice(fmt.Sprintf("Found %d credential section(s) in %s, which should be used by default?", len(sections), edgerc), sections, getProfileSection(getCurrentProfileName()))
+	}
+	if section == "" {
+		return nil
+	}
+
+	profile := credentialProfile{Name: section, Edgerc: edgerc, Section: section}
+	if !profileName.MatchString(profile.Name) {
+		profile.Name = "default"
+	}
+
+	if err := validateProfile(profile); err != nil {
+		return err
+	}
+
+	setConfigValue(profileSection, profile.Name+"-edgerc", profile.Edgerc)
+	setConfigValue(profileSection, profile.Name+"-section", profile.Section)
+	setConfigValue("cli", "profile", profile.Name)
+	printInfo(akamai.App.Writer, "Using section [%s] of %s, as profile %s", profile.Section, profile.Edgerc, profile.Name)
+
+	return nil
+}
+
+// getEdgercSections returns the sections of an .edgerc file, sorted
+func getEdgercSections(edgerc string) ([]string, error) {
+	path, err := homedir.Expand(edgerc)
+	if err != nil {
+		return nil, err
+	}
+
+	file, err := ini.Load(path)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read %s: %s", edgerc, err.Error())
+	}
+
+	var sections []string
+	for _, section := range file.SectionStrings() {
+		if section != ini.DEFAULT_SECTION {
+			sections = append(sections, section)
+		}
+	}
+
+	if len(sections) == 0 {
+		return nil, fmt.Errorf("no sections were found in %s", edgerc)
+	}
+	sort.Strings(sections)
+
+	return sections, nil
+}
+
+// getProfileSection returns the section of a profile, or "" if it doesn't exist
+func getProfileSection(name string) string {
+	if profile, ok := findProfile(name); ok {
+		return profile.Section
+	}
+
+	return ""
+}
+
+// setupProxy saves the proxy, and the CA bundle to trust for TLS-intercepting ones
+func setupProxy(opts setupOptions) error {
+	proxy := opts.proxy
+	if !opts.noProxy && proxy == "" && opts.interactive {
+		proxy = promptValue("HTTP proxy URL, if you need one", getConfigValue("cli", "proxy"))
+	}
+
+	if opts.noProxy || proxy == "none" {
+		unsetConfigValue("cli", "proxy")
+		unsetConfigValue("cli", "ca-bundle")
+		return nil
+	}
+
+	if proxy == "" {
+		return nil
+	}
+
+	if u, err := url.Parse(proxy); err != nil || u.Scheme == "" || u.Host == "" {
+		return fmt.Errorf("Invalid proxy URL \"%s\", it must include the scheme, e.g. http://proxy.example.com:3128", proxy)
+	}
+	setConfigValue("cli", "proxy", proxy)
+
+	caBundle := opts.caBundle
+	if caBundle == "" && opts.interactive {
+		caBundle = promptValue("PEM file of CA certificates to trust, if the proxy intercepts TLS", getConfigValue("cli", "ca-bundle"))
+	}
+
+	if caBundle == "none" {
+		unsetConfigValue("cli", "ca-bundle")
+	} else if caBundle != "" {
+		path, err := homedir.Expand(caBundle)
+		if err == nil {
+			_, err = os.Stat(path)
+		}
+		if err != nil {
+			return fmt.Errorf("Unable to find the CA bundle %s", caBundle)
+		}
+		setConfigValue("cli", "ca-bundle", caBundle)
+	}
+
+	return nil
+}
+
+// setupUpgrade turns the daily upgrade check on or off, and sets its channel
+func setupUpgrade(opts setupOptions) error {
+	enabled := getConfigValue("cli", "last-upgrade-check") != "ignore"
+	switch {
+	case opts.autoUpgrade != "":
+		enabled, _ = parseOnOff(opts.autoUpgrade)
+	case opts.interactive:
+		enabled = promptYesNo("Akamai CLI can auto-update itself, would you like to enable daily checks?", enabled)
+	case getConfigValue("cli", "last-upgrade-check") != "":
+		return setupUpgradeChannel(opts)
+	default:
+		// Build agents are upgraded by whatever provisions them
+		enabled = false
+	}
+
+	if !enabled {
+		setConfigValue("cli", "last-upgrade-check", "ignore")
+	} else if last := getConfigValue("cli", "last-upgrade-check"); last == "" || last == "ignore" {
+		setConfigValue("cli", "last-upgrade-check", "never")
+	}
+
+	return setupUpgradeChannel(opts)
+}
+
+func setupUpgradeChannel(opts setupOptions) error {
+	if opts.channel == "" {
+		return nil
+	}
+
+	if err := validateUpgradeChannel(opts.channel); err != nil {
+		return err
+	}
+	setConfigValue("cli", "upgrade-channel", opts.channel)
+
+	return nil
+}
+
+// setupTelemetry turns the anonymous usage statistics on or off
+func setupTelemetry(opts setupOptions) error {
+	var enabled bool
+	switch {
+	case opts.telemetry != "":
+		enabled, _ = parseOnOff(opts.telemetry)
+	case opts.interactive:
+		anonymous := color.New(color.FgWhite, color.Bold).Sprint("anonymous")
+		fmt.Fprintf(akamai.App.Writer, "Help Akamai improve Akamai CLI by sending %s diagnostics and usage data, such as upgrades, and packages installed and updated.\n", anonymous)
+		enabled = promptYesNo(fmt.Sprintf("Send %s diagnostics and usage data to Akamai?", anonymous), isTelemetryEnabled())
+	case getConfigValue("cli", "enable-cli-statistics") != "":
+		return nil
+	}
+
+	setTelemetry(enabled)
+	return nil
+}
+
+// setTelemetry turns both the usage reports and the legacy statistics on or off
+func setTelemetry(enabled bool) {
+	if !enabled {
+		setConfigValue("cli", "enable-cli-statistics", "false")
+		setConfigValue("cli", "telemetry", "off")
+		return
+	}
+
+	setConfigValue("cli", "enable-cli-statistics", "true")
+	setConfigValue("cli", "telemetry", "on")
+	if getConfigValue("cli", "last-ping") == "" {
+		setConfigValue("cli", "last-ping", "never")
+	}
+	setupUuid()
+}
+
+// parseOnOff parses the value of an on/off setting
+func parseOnOff(value string) (bool, error) {
+	switch strings.ToLower(strings.TrimSpace(value)) {
+	case "on", "true", "yes", "y", "1":
+		return true, nil
+	case "off", "false", "no", "n", "0":
+		return false, nil
+	}
+
+	return false, fmt.Errorf("Invalid value \"%s\", must be on or off", value)
+}
+
+// readAnswer reads a line of input, trimmed
+func readAnswer() string {
+	answer, _ := setupInput.ReadString('\n')
+	return strings.TrimSpace(answer)
+}
+
+func promptYesNo(question string, def bool) bool {
+	choices := "[y/N]"
+	if def {
+		choices = "[Y/n]"
+	}
+
+	fmt.Fprintf(akamai.App.Writer, "%s %s: ", question, choices)
+	answer, err := parseOnOff(readAnswer())
+	if err != nil {
+		return def
+	}
+
+	return answer
+}
+
+// promptValue asks for a value, keeping current if nothing is entered, "none" clears it
+func promptValue(question string, current string) string {
+	if current != "" {
+		fmt.Fprintf(akamai.App.Writer, "%s [%s, \"none\" to remove]: ", question, current)
+	} else {
+		fmt.Fprintf(akamai.App.Writer, "%s [none]: ", question)
+	}
+
+	if answer := readAnswer(); answer != "" {
+		return answer
+	}
+
+	return current
+}
+
+// promptChoice asks to choose one of choices by number, current is chosen if nothing is entered
+func promptChoice(question string, choices []string, current string) string {
+	fmt.Fprintln(akamai.App.Writer, color.YellowString(question))
+	for i, choice := range choices {
+		marker := ""
+		if choice == current {
+			marker = " (current)"
+		}
+		fmt.Fprintf(akamai.App.Writer, "(%d) %s%s\n", i+1, choice, marker)
+	}
+
+	for {
+		fmt.Fprint(akamai.App.Writer, "Enter a number: ")
+		answer := readAnswer()
+		if answer == "" {
+			return current
+		}
+
+		if index, err := strconv.Atoi(answer); err == nil && index >= 1 && index <= len(choices) {
+			return choices[index-1]
+		}
+		fmt.Fprintln(akamai.App.Writer, color.RedString("Invalid choice, try again"))
+	}
+}
+
+// firstRunSetup asks the setup questions that have never been answered, when
+// run in a terminal: the daily upgrade check, unless upgrades are left to a
+// package manager, and the usage statistics
+func firstRunSetup(bannerShown bool, upgrades bool) {
+	if !isatty.IsTerminal(os.Stdin.Fd()) || !isatty.IsTerminal(os.Stdout.Fd()) {
+		return
+	}
+
+	var steps []func(setupOptions) error
+	if upgrades && getConfigValue("cli", "last-upgrade-check") == "" {
+		steps = append(steps, setupUpgrade)
+	}
+	if getConfigValue("cli", "enable-cli-statistics") == "" {
+		steps = append(steps, setupTelemetry)
+	}
+	if len(steps) == 0 {
+		return
+	}
+
+	if !bannerShown {
+		showBanner()
+	}
+
+	for _, step := range steps {
+		step(setupOptions{interactive: true})
+	}
+	saveConfig()
+
+	if isTelemetryEnabled() {
+		trackEvent("first-run", "true")
+	}
+	printInfo(akamai.App.Writer, "Run \"%s setup\" to choose your credentials and a proxy\n", self())
+}
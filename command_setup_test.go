@@ -0,0 +1,43 @@
+/*
+ Copyright 2018. Akamai Technologies, Inc
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package main
+
+import "testing"
+
+func TestParseOnOff(t *testing.T) {
+	onOffTests := []struct {
+		value   string
+		enabled bool
+		valid   bool
+	}{
+		{"on", true, true},
+		{"YES", true, true},
+		{" 1 ", true, true},
+		{"off", false, true},
+		{"n", false, true},
+		{"false", false, true},
+		{"", false, false},
+		{"maybe", false, false},
+	}
+
+	for _, tt := range onOffTests {
+		enabled, err := parseOnOff(tt.value)
+		if enabled != tt.enabled || (err == nil) != tt.valid {
+			t.Errorf("parseOnOff(%q) => %t, %v, wanted: %t, valid: %t", tt.value, enabled, err, tt.enabled, tt.valid)
+		}
+	}
+}
@@ -0,0 +1,261 @@
+/*
+ Copyright 2018. Akamai Technologies, Inc
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+
+	akamai "github.com/akamai/cli-common-golang"
+	"github.com/fatih/color"
+	"github.com/go-ini/ini"
+	"github.com/urfave/cli"
+)
+
+const (
+	defaultStateFile = "akamai-cli-state.json"
+	stateVersion     = 1
+)
+
+// machineConfigKeys are the settings of the cli section that only make sense
+// on the machine they were made on
+var machineConfigKeys = map[string]bool{
+	"cache-path":         true,
+	"client-id":          true,
+	"config-version":     true,
+	"home":               true,
+	"last-upgrade-check": true,
+	// Trusting a path on another machine would trust whatever is checked out there
+	trustedWorkspacesKey: true,
+}
+
+// cliState is everything needed to set up Akamai CLI the same way on another
+// machine: its packages, config, and aliases. Credentials are not included.
+type cliState struct {
+	Version    int    `json:"version"`
+	CLIVersion string `json:"cliVersion"`
+	// Packages are recorded like in a lockfile, at their commits
+	Packages []lockedPackage              `json:"packages"`
+	Config   map[string]map[string]string `json:"config"`
+	Aliases  map[string]string            `json:"aliases"`
+}
+
+func cmdExportState(c *cli.Context) error {
+	path := defaultStateFile
+	if c.Args().Present() {
+		path = c.Args().First()
+	}
+
+	lock, err := getInstalledLock()
+	if err != nil {
+		return cli.NewExitError(color.RedString(err.Error()), 1)
+	}
+
+	config, err := openConfig()
+	if err != nil {
+		return cli.NewExitError(color.RedString("Unable to read config: %s", err.Error()), 1)
+	}
+
+	values := make(map[string]map[string]string)
+	for _, section := range config.Sections() {
+		if keys := section.KeysHash(); len(keys) > 0 {
+			values[section.Name()] = keys
+		}
+	}
+
+	state := cliState{
+		Version:    stateVersion,
+		CLIVersion: VERSION,
+		Packages:   lock.Packages,
+		Config:     getExportableConfig(values),
+		Aliases:    getExportableAliases(values[aliasSection]),
+	}
+
+	data, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return cli.NewExitError(color.RedString(err.Error()), 1)
+	}
+
+	// The config may still hold paths and hostnames that are not for everyone's eyes
+	if err := ioutil.WriteFile(path, append(data, '\n'), 0600); err != nil {
+		return cli.NewExitError(color.RedString("Unable to write state: %s", err.Error()), 1)
+	}
+
+	fmt.Fprintf(akamai.App.Writer, "Wrote %d package(s), %d setting(s), and %d alias(es) to %s\n", len(state.Packages), countConfigValues(state.Config), len(state.Aliases), path)
+	return nil
+}
+
+// getExportableConfig returns the config sections without aliases, which are
+// exported on their own, machine specific settings, and anything that looks
+// like a secret: keys named like one, and URLs with credentials
+func getExportableConfig(values map[string]map[string]string) map[string]map[string]string {
+	exportable := make(map[string]map[string]string)
+	for section, keys := range values {
+		if section == aliasSection || section == ini.DEFAULT_SECTION {
+			continue
+		}
+
+		for key, value := range keys {
+			if section == "cli" && machineConfigKeys[key] {
+				continue
+			}
+
+			if isSecretName(key) || redactURL(value) != value {
+				logDebug("not exporting secret setting", "key", section+"."+key)
+				continue
+			}
+
+			if exportable[section] == nil {
+				exportable[section] = make(map[string]string)
+			}
+			exportable[section][key] = value
+		}
+	}
+
+	return exportable
+}
+
+// getExportableAliases returns the aliases that do not pass secrets
+func getExportableAliases(aliases map[string]string) map[string]string {
+	exportable := make(map[string]string)
+	for name, expansion := range aliases {
+		args, err := splitAliasArgs(expansion)
+		if err != nil || !reflect.DeepEqual(redactArgs(args), args) {
+			fmt.Fprintln(akamai.App.ErrWriter, color.YellowString("Warning: not exporting alias \"%s\", it contains a secret", name))
+			continue
+		}
+		exportable[name] = expansion
+	}
+
+	return exportable
+}
+
+func countConfigValues(config map[string]map[string]string) int {
+	count := 0
+	for _, keys := range config {
+		count += len(keys)
+	}
+
+	return count
+}
+
+func readStateFile(path string) (cliState, error) {
+	state := cliState{}
+
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return state, err
+	}
+
+	if err := json.Unmarshal(data, &state); err != nil {
+		return state, err
+	}
+
+	if state.Version > stateVersion {
+		return state, fmt.Errorf("state version %d is not supported, upgrade Akamai CLI", state.Version)
+	}
+
+	return state, nil
+}
+
+// cmdImportState applies the config and aliases of a state file, and installs
+// the packages it lists that are not installed yet, at the same commits
+func cmdImportState(c *cli.Context) error {
+	path := defaultStateFile
+	if c.Args().Present() {
+		path = c.Args().First()
+	}
+
+	state, err := readStateFile(path)
+	if err != nil {
+		return cli.NewExitError(color.RedString("Unable to read state: %s", err.Error()), 1)
+	}
+
+	sections := make([]string, 0, len(state.Config))
+	for section := range state.Config {
+		sections = append(sections, section)
+	}
+	sort.Strings(sections)
+
+	for _, section := range sections {
+		for key, value := range state.Config[section] {
+			if section == "cli" && machineConfigKeys[key] {
+				continue
+			}
+			setConfigValue(section, key, value)
+		}
+	}
+
+	for name, expansion := range state.Aliases {
+		if err := validateAliasName(name); err != nil {
+			fmt.Fprintln(akamai.App.ErrWriter, color.YellowString("Warning: skipping alias \"%s\": %s", name, err.Error()))
+			continue
+		}
+		setConfigValue(aliasSection, name, expansion)
+	}
+
+	if err := saveConfig(); err != nil {
+		return cli.NewExitError(color.RedString("Unable to save config: %s", err.Error()), 1)
+	}
+	printInfo(akamai.App.Writer, "Imported %d setting(s) and %d alias(es)", countConfigValues(state.Config), len(state.Aliases))
+
+	srcPath, err := getAkamaiCliSrcPath()
+	if err != nil {
+		return err
+	}
+
+	oldCmds := getCommands()
+
+	// The state lists every package, dependencies included
+	opts := installOptions{
+		forceBinary:   c.Bool("force"),
+		acceptLicense: c.Bool("accept-license"),
+		skipRequired:  true,
+	}
+
+	for _, pkg := range state.Packages {
+		if _, err := os.Stat(filepath.Join(srcPath, pkg.Name)); err == nil {
+			printInfo(akamai.App.Writer, "%s is already installed, skipping it", pkg.Name)
+			continue
+		}
+
+		target := pkg.Repo
+		if pkg.Subpath != "" {
+			target += "#" + pkg.Subpath
+		}
+
+		opts.version = pkg.Commit
+		if err := installTarget(target, opts); err != nil {
+			return err
+		}
+
+		// Installed at the commit, but only pinned if it was
+		if manifest, err := readManifest(pkg.Name); err == nil && manifest.Version != pkg.Version {
+			manifest.Version = pkg.Version
+			writeManifest(pkg.Name, manifest)
+		}
+	}
+
+	packageListDiff(oldCmds)
+
+	return nil
+}
@@ -18,29 +18,43 @@ package main
 
 import (
 	"os"
+	"path/filepath"
+	"time"
 
 	"github.com/fatih/color"
 	"github.com/urfave/cli"
 )
 
 func cmdSubcommand(c *cli.Context) error {
-	cmd := c.Command.Name
+	// Flags are not parsed, so these are all the arguments after the command
+	return runInstalledCommand(c.Command.Name, []string(c.Args()))
+}
 
+// runInstalledCommand runs an installed command, passing it args
+func runInstalledCommand(cmd string, args []string) error {
+	var packageDir string
 	executable, err := findExec(cmd)
-	if err != nil {
-		return cli.NewExitError(color.RedString("Executable \"%s\" not found.", cmd), 1)
+	if err == nil {
+		if len(executable) == 1 {
+			packageDir = findPackageDir(executable[0])
+		} else if len(executable) > 1 {
+			packageDir = findPackageDir(executable[1])
+		}
+	} else if providers := findCommandProviders(cmd, getPackageDirs()); len(providers) > 0 && usesDocker(providers[0].Directory, cmd) {
+		// Packages run in Docker don't need their language runtime installed
+		packageDir = providers[0].Directory
+	} else {
+		return newError(errCommandNotFound, "Executable \"%s\" not found.", cmd)
 	}
 
-	var packageDir string
-	if len(executable) == 1 {
-		packageDir = findPackageDir(executable[0])
-	} else if len(executable) > 1 {
-		packageDir = findPackageDir(executable[1])
+	runtime, err := getPackageRuntime(packageDir, cmd)
+	if err != nil {
+		return cli.NewExitError(color.RedString(err.Error()), 1)
 	}
 
 	cmdPackage, _ := readPackage(packageDir)
 
-	if cmdPackage.Requirements.Python != "" {
+	if cmdPackage.Requirements.Python != "" && runtime == runtimeLocal {
 		if err := migratePythonPackage(cmd, packageDir); err != nil {
 			return err
 		}
@@ -51,6 +65,56 @@ func cmdSubcommand(c *cli.Context) error {
 		}
 	}
 
-	executable = append(executable, os.Args[2:]...)
-	return passthruCommand(executable)
+	warnIfDeprecated(packageDir)
+
+	applyPackageEnv(packageDir, cmd)
+
+	if err := applyProfileEnv(); err != nil {
+		return err
+	}
+
+	if err := applyKeychainEnv(args); err != nil {
+		return err
+	}
+
+	context := map[string]string{"command": cmd, "args": quoteArgs(args)}
+	if packageDir != "" {
+		context["package"] = filepath.Base(getPackageRoot(packageDir))
+		context["package_dir"] = getPackageRoot(packageDir)
+	}
+	env, err := runHooks(hookPreExec, context)
+	if err != nil {
+		return err
+	}
+	for name, value := range env {
+		os.Setenv(name, value)
+	}
+
+	if runtime == runtimeDocker {
+		executable, err = getDockerExec(cmd, packageDir, cmdPackage, getPassedEnvNames(packageDir, cmd, env))
+		if err != nil {
+			return cli.NewExitError(color.RedString(err.Error()), 1)
+		}
+	}
+
+	timeout, err := getExecTimeout(packageDir, cmd)
+	if err != nil {
+		return newError(errConfig, "%s", err.Error())
+	}
+
+	start := time.Now()
+	err = passthruCommandTimeout(append(executable, args...), timeout)
+
+	exitCode := 0
+	if err != nil {
+		exitCode = getExitCode(err)
+		if getErrorID(err) != errTimeout {
+			err = commandFailedError(exitCode)
+		}
+	}
+	writeExecAuditLog(cmd, args, exitCode, time.Since(start))
+
+	runHooks(hookPostExec, hookStatus(context, err))
+
+	return err
 }
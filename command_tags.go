@@ -0,0 +1,113 @@
+/*
+ Copyright 2018. Akamai Technologies, Inc
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package main
+
+import (
+	"fmt"
+	"strings"
+	"text/tabwriter"
+
+	akamai "github.com/akamai/cli-common-golang"
+	"github.com/fatih/color"
+	"github.com/urfave/cli"
+)
+
+func cmdTags(c *cli.Context) error {
+	packageList, err := fetchPackageList(fetchOptions{})
+	if err != nil {
+		return cli.NewExitError(color.RedString(err.Error()), 1)
+	}
+
+	results := make([]searchResult, 0, len(packageList.Packages))
+	for _, pkg := range packageList.Packages {
+		results = append(results, searchResult{Package: pkg})
+	}
+	counts := countSearchResults(results, getPackageTags)
+
+	if getOutputFormat() != formatTable {
+		return printStructured(counts)
+	}
+
+	if len(counts) == 0 {
+		fmt.Fprintln(akamai.App.Writer, color.YellowString("No packages in the package list are tagged"))
+		return nil
+	}
+
+	w := tabwriter.NewWriter(akamai.App.Writer, 0, 8, 2, ' ', 0)
+	fmt.Fprintln(w, "TAG\tPACKAGES")
+	for _, count := range counts {
+		fmt.Fprintf(w, "%s\t%d\n", count.Value, count.Count)
+	}
+	w.Flush()
+
+	return nil
+}
+
+// getPackageTags returns the lower-cased tags and categories of a registry
+// package, without duplicates
+func getPackageTags(pkg packageListPackage) []string {
+	var tags []string
+	seen := make(map[string]bool)
+	for _, tag := range append(append([]string{}, pkg.Tags...), pkg.Categories...) {
+		tag = strings.ToLower(strings.TrimSpace(tag))
+		if tag != "" && !seen[tag] {
+			seen[tag] = true
+			tags = append(tags, tag)
+		}
+	}
+
+	return tags
+}
+
+// parseTagFilter splits the values of --tag, which may also be comma-separated
+func parseTagFilter(values []string) []string {
+	var tags []string
+	for _, value := range values {
+		for _, tag := range strings.Split(value, ",") {
+			if tag = strings.ToLower(strings.TrimSpace(tag)); tag != "" {
+				tags = append(tags, tag)
+			}
+		}
+	}
+
+	return tags
+}
+
+// filterSearchResultsByTag keeps only results that have all of tags
+func filterSearchResultsByTag(results []searchResult, tags []string) []searchResult {
+	filtered := make([]searchResult, 0, len(results))
+	for _, result := range results {
+		pkgTags := make(map[string]bool)
+		for _, tag := range getPackageTags(result.Package) {
+			pkgTags[tag] = true
+		}
+
+		tagged := true
+		for _, tag := range tags {
+			if !pkgTags[tag] {
+				tagged = false
+				break
+			}
+		}
+
+		if tagged {
+			filtered = append(filtered, result)
+		}
+	}
+
+	return filtered
+}
@@ -20,15 +20,21 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"regexp"
+	"runtime"
+	"strings"
 
-	akamai "github.com/akamai/cli-common-golang"
 	"github.com/fatih/color"
 	"github.com/urfave/cli"
 )
 
+// commandNamePattern is what the name of a command in cli.json must look like
+// before it is used to find the files and config of the command to remove
+var commandNamePattern = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]*$`)
+
 func cmdUninstall(c *cli.Context) error {
 	for _, cmd := range c.Args() {
-		if err := uninstallPackage(cmd); err != nil {
+		if err := recordPackageResult(cmd, uninstallPackage(cmd, c.Bool("purge"))); err != nil {
 			trackEvent("uninstall.failed", cmd)
 			return err
 		}
@@ -38,13 +44,14 @@ func cmdUninstall(c *cli.Context) error {
 	return nil
 }
 
-func uninstallPackage(cmd string) error {
+func uninstallPackage(cmd string, purge bool) error {
 	exec, err := findExec(cmd)
 	if err != nil {
 		return cli.NewExitError(color.RedString("Command \"%s\" not found. Try \"%s help\".\n", cmd, self()), 1)
 	}
 
-	akamai.StartSpinner(fmt.Sprintf("Attempting to uninstall \"%s\" command...", cmd), fmt.Sprintf("Attempting to uninstall \"%s\" command...", cmd)+"... ["+color.GreenString("OK")+"]\n")
+	p := newSpinnerProgress()
+	p.Start(fmt.Sprintf("Attempting to uninstall \"%s\" command...", cmd))
 
 	var repoDir string
 	if len(exec) == 1 {
@@ -54,16 +61,180 @@ func uninstallPackage(cmd string) error {
 	}
 
 	if repoDir == "" {
-		akamai.StopSpinnerFail()
+		p.Fail()
 		return cli.NewExitError(color.RedString("unable to uninstall, was it installed using "+color.CyanString("\"akamai install\"")+"?"), 1)
 	}
 
-	if err := os.RemoveAll(repoDir); err != nil {
-		akamai.StopSpinnerFail()
+	cmdPackage, _ := readPackage(repoDir)
+
+	repoDir = getPackageRoot(repoDir)
+	name := filepath.Base(repoDir)
+
+	context := map[string]string{"command": cmd, "package": name, "package_dir": repoDir}
+	if _, err := runHooks(hookPreUninstall, context); err != nil {
+		p.Fail()
+		return err
+	}
+
+	for _, artifact := range findPackageArtifacts(name, cmdPackage) {
+		logInfo("removing package artifact", "package", name, "path", artifact)
+		if err := removeAllForce(artifact); err != nil {
+			logWarn("unable to remove package artifact", "package", name, "path", artifact, "error", err)
+		}
+	}
+
+	if err := removeAllForce(repoDir); err != nil {
+		p.Fail()
 		return cli.NewExitError(color.RedString("unable to remove directory: %s", repoDir), 1)
 	}
+	removeManifest(name)
+
+	if purge {
+		purgePackageData(name, cmdPackage)
+	}
+
+	p.Ok()
 
-	akamai.StopSpinnerOk()
+	runHooks(hookPostUninstall, hookStatus(context, nil))
 
 	return nil
 }
+
+// findPackageArtifacts returns the files the CLI created outside of a package's
+// directory when building it, which uninstalling the directory leaves behind
+func findPackageArtifacts(name string, cmdPackage commandPackage) []string {
+	cliPath, err := getAkamaiCliPath()
+	if err != nil {
+		return nil
+	}
+
+	var artifacts []string
+	for _, path := range getPackageArtifactPaths(cliPath, name, cmdPackage, runtime.GOOS, runtime.GOARCH) {
+		if _, err := os.Stat(path); err == nil {
+			artifacts = append(artifacts, path)
+		}
+	}
+
+	return artifacts
+}
+
+// getPackageArtifactPaths lists where build artifacts for a package may be found
+// outside of its directory. Go packages are built with the CLI home on their
+// GOPATH, so compiled archives and installed binaries can end up in its pkg and
+// bin directories. Other runtimes keep their dependencies (node_modules, vendor,
+// Python user site) in the package directory, which is removed anyway.
+func getPackageArtifactPaths(cliPath string, name string, cmdPackage commandPackage, goos string, goarch string) []string {
+	var paths []string
+
+	if determineCommandLanguage(cmdPackage) == "go" {
+		pkgPath := filepath.Join(cliPath, "pkg", goos+"_"+goarch)
+		for _, path := range []string{filepath.Join(pkgPath, name), filepath.Join(pkgPath, name+".a")} {
+			if isPathInside(pkgPath, path) {
+				paths = append(paths, path)
+			}
+		}
+
+		binPath := filepath.Join(cliPath, "bin")
+		for _, command := range cmdPackage.Commands {
+			commandName := strings.ToLower(command.Name)
+			if !commandNamePattern.MatchString(commandName) {
+				logWarn("ignoring invalid command name", "package", name, "command", command.Name)
+				continue
+			}
+
+			bin := "akamai-" + commandName
+			if goos == "windows" {
+				bin += ".exe"
+			}
+			if path := filepath.Join(binPath, bin); isPathInside(binPath, path) {
+				paths = append(paths, path)
+			}
+		}
+	}
+
+	return paths
+}
+
+// purgePackageData removes the config sections and cache directories of a
+// package and its commands, for uninstall --purge
+func purgePackageData(name string, cmdPackage commandPackage) {
+	names := getPurgeNames(name, cmdPackage, getInstalledCommandPackages())
+
+	if config, err := openConfig(); err == nil {
+		for _, section := range names {
+			logInfo("removing package config", "package", name, "section", section)
+			config.DeleteSection(section)
+		}
+		saveConfig()
+	}
+
+	if cachePath, err := getAkamaiCliCachePath(); err == nil {
+		for _, dir := range names {
+			path := filepath.Join(cachePath, dir)
+			if !isPathInside(cachePath, path) {
+				continue
+			}
+			if _, err := os.Stat(path); err == nil {
+				logInfo("removing package cache", "package", name, "path", path)
+				removeAllForce(path)
+			}
+		}
+	}
+}
+
+// getPurgeNames returns the names of the config sections and cache
+// directories of a package and its commands. Names that are not valid command
+// names, or that other installed packages use too, are left out, so that a
+// cli.json cannot have the data of the CLI or of another package removed.
+func getPurgeNames(name string, cmdPackage commandPackage, installed map[string]commandPackage) []string {
+	taken := map[string]bool{"cli": true}
+	for dirName, other := range installed {
+		if dirName == name {
+			continue
+		}
+		taken[strings.ToLower(strings.TrimPrefix(dirName, "cli-"))] = true
+		for _, command := range other.Commands {
+			taken[strings.ToLower(command.Name)] = true
+		}
+	}
+
+	candidates := []string{strings.TrimPrefix(name, "cli-")}
+	for _, command := range cmdPackage.Commands {
+		candidates = append(candidates, command.Name)
+	}
+
+	var names []string
+	seen := make(map[string]bool)
+	for _, candidate := range candidates {
+		candidate = strings.ToLower(candidate)
+		if seen[candidate] {
+			continue
+		}
+		seen[candidate] = true
+
+		if !commandNamePattern.MatchString(candidate) || taken[candidate] {
+			logWarn("not purging package data", "package", name, "name", candidate)
+			continue
+		}
+		names = append(names, candidate)
+	}
+
+	return names
+}
+
+// removeAllForce is os.RemoveAll, but also removes read-only files such as
+// git objects and module caches, which os.RemoveAll can't delete on Windows
+func removeAllForce(path string) error {
+	if err := os.RemoveAll(path); err == nil {
+		return nil
+	}
+
+	filepath.Walk(path, func(file string, info os.FileInfo, err error) error {
+		if err == nil && info.Mode()&0200 == 0 {
+			os.Chmod(file, info.Mode()|0200)
+		}
+		return nil
+	})
+
+	return os.RemoveAll(path)
+}
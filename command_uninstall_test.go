@@ -0,0 +1,112 @@
+/*
+ Copyright 2018. Akamai Technologies, Inc
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package main
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestGetPackageArtifactPaths(t *testing.T) {
+	goPackage := commandPackage{Commands: []Command{{Name: "Purge"}, {Name: "purge-legacy"}}}
+	goPackage.Requirements.Go = "1.9.0"
+
+	nodePackage := commandPackage{Commands: []Command{{Name: "property"}}}
+	nodePackage.Requirements.Node = "7.0.0"
+
+	unsafePackage := commandPackage{Commands: []Command{{Name: "purge"}, {Name: "../../.."}, {Name: ""}}}
+	unsafePackage.Requirements.Go = "1.9.0"
+
+	home := filepath.Join("home", ".akamai-cli")
+	tests := []struct {
+		pkg      commandPackage
+		goos     string
+		expected []string
+	}{
+		{goPackage, "linux", []string{
+			filepath.Join(home, "pkg", "linux_amd64", "cli-purge"),
+			filepath.Join(home, "pkg", "linux_amd64", "cli-purge.a"),
+			filepath.Join(home, "bin", "akamai-purge"),
+			filepath.Join(home, "bin", "akamai-purge-legacy"),
+		}},
+		{goPackage, "windows", []string{
+			filepath.Join(home, "pkg", "windows_amd64", "cli-purge"),
+			filepath.Join(home, "pkg", "windows_amd64", "cli-purge.a"),
+			filepath.Join(home, "bin", "akamai-purge.exe"),
+			filepath.Join(home, "bin", "akamai-purge-legacy.exe"),
+		}},
+		{nodePackage, "linux", nil},
+		{unsafePackage, "linux", []string{
+			filepath.Join(home, "pkg", "linux_amd64", "cli-purge"),
+			filepath.Join(home, "pkg", "linux_amd64", "cli-purge.a"),
+			filepath.Join(home, "bin", "akamai-purge"),
+		}},
+	}
+
+	for _, test := range tests {
+		actual := getPackageArtifactPaths(home, "cli-purge", test.pkg, test.goos, "amd64")
+		if !reflect.DeepEqual(actual, test.expected) {
+			t.Errorf("getPackageArtifactPaths(%s) = %v, expected %v", test.goos, actual, test.expected)
+		}
+	}
+}
+
+func TestGetPurgeNames(t *testing.T) {
+	installed := map[string]commandPackage{
+		"cli-purge":    {Commands: []Command{{Name: "purge"}}},
+		"cli-property": {Commands: []Command{{Name: "property"}, {Name: "papi"}}},
+	}
+
+	purgeTests := []struct {
+		commands []Command
+		names    []string
+	}{
+		{[]Command{{Name: "purge"}, {Name: "Purge-Legacy"}}, []string{"purge", "purge-legacy"}},
+		{[]Command{{Name: ".."}, {Name: "../.."}, {Name: ""}}, []string{"purge"}},
+		{[]Command{{Name: "papi"}, {Name: "cli"}}, []string{"purge"}},
+	}
+
+	for _, tt := range purgeTests {
+		names := getPurgeNames("cli-purge", commandPackage{Commands: tt.commands}, installed)
+		if !reflect.DeepEqual(names, tt.names) {
+			t.Errorf("getPurgeNames(%v) => %v, wanted: %v", tt.commands, names, tt.names)
+		}
+	}
+}
+
+func TestIsPathInside(t *testing.T) {
+	cache := filepath.Join("home", ".akamai-cli", "cache")
+
+	insideTests := []struct {
+		path   string
+		inside bool
+	}{
+		{filepath.Join(cache, "purge"), true},
+		{filepath.Join(cache, "purge", "tokens"), true},
+		{filepath.Join(cache, ""), false},
+		{filepath.Join(cache, ".."), false},
+		{filepath.Join(cache, "..", ".."), false},
+		{filepath.Join(cache, "..", "cache-old"), false},
+	}
+
+	for _, tt := range insideTests {
+		if inside := isPathInside(cache, tt.path); inside != tt.inside {
+			t.Errorf("isPathInside(%s) => %t, wanted: %t", tt.path, inside, tt.inside)
+		}
+	}
+}
@@ -18,90 +18,281 @@ package main
 
 import (
 	"fmt"
+	"os"
 	"path/filepath"
 	"strings"
+	"sync"
 
 	akamai "github.com/akamai/cli-common-golang"
 	"github.com/fatih/color"
+	"github.com/mattn/go-isatty"
 	"github.com/urfave/cli"
 	"gopkg.in/src-d/go-git.v4"
+	gitconfig "gopkg.in/src-d/go-git.v4/config"
+	"gopkg.in/src-d/go-git.v4/plumbing/transport"
+)
+
+// updateStatus is what updating a package did, for the summary of "akamai update"
+type updateStatus string
+
+const (
+	updateUpdated     updateStatus = "updated"
+	updateCurrent     updateStatus = "up-to-date"
+	updateSkipped     updateStatus = "skipped"
+	updateFailed      updateStatus = "failed"
+	defaultUpdateJobs              = 4
 )
 
 func cmdUpdate(c *cli.Context) error {
+	if c.Bool("check") {
+		return cmdUpdateCheck(c)
+	}
+
+	if c.Bool("migrate") {
+		return cmdUpdateMigrate(c)
+	}
+
+	opts := installOptions{forceBinary: c.Bool("force")}
+
 	if !c.Args().Present() {
-		var builtinCmds map[string]bool = make(map[string]bool)
-		for _, cmd := range getBuiltinCommands() {
-			builtinCmds[strings.ToLower(cmd.Commands[0].Name)] = true
+		return updateAllPackages(opts, c.Int("jobs"))
+	}
+
+	// Updates of named packages show what they pull in, and ask first
+	opts.changelog = true
+	opts.confirm = !c.Bool("yes") && isatty.IsTerminal(os.Stdin.Fd()) && isatty.IsTerminal(os.Stdout.Fd())
+
+	for _, cmd := range c.Args() {
+		_, err := updatePackage(cmd, opts)
+		if err := recordPackageResult(cmd, err); err != nil {
+			return err
 		}
+	}
 
-		for _, cmd := range getCommands() {
-			for _, command := range cmd.Commands {
-				if _, ok := builtinCmds[command.Name]; !ok {
-					if err := updatePackage(command.Name, c.Bool("force")); err != nil {
-						return err
-					}
+	return nil
+}
+
+type updateResult struct {
+	name   string
+	status updateStatus
+	err    error
+}
+
+// updateAllPackages updates every installed package, up to jobs at a time. A
+// failure does not stop the other updates, instead every package is listed in
+// a summary at the end.
+func updateAllPackages(opts installOptions, jobs int) error {
+	if isOffline() {
+		return newError(errOffline, "%s", offlineError("update packages").Error())
+	}
+
+	// Update each package once, by way of its first command
+	var names, cmds []string
+	for _, dir := range getPackageDirs() {
+		cmdPackage, err := readPackage(dir)
+		if err != nil || len(cmdPackage.Commands) == 0 {
+			continue
+		}
+
+		names = append(names, strings.TrimPrefix(filepath.Base(dir), "cli-"))
+		cmds = append(cmds, cmdPackage.Commands[0].Name)
+	}
+
+	if jobs < 1 {
+		jobs = 1
+	}
+
+	results := make([]updateResult, len(cmds))
+	queue := make(chan int)
+	var wg sync.WaitGroup
+
+	for i := 0; i < jobs && i < len(cmds); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range queue {
+				packageOpts := opts
+				if jobs > 1 {
+					packageOpts.progress = newLineProgress(names[i])
 				}
+
+				status, err := updatePackage(cmds[i], packageOpts)
+				if err != nil {
+					status = updateFailed
+				}
+				results[i] = updateResult{name: names[i], status: status, err: recordPackageResult(names[i], err)}
 			}
-		}
+		}()
+	}
 
-		return nil
+	for i := range cmds {
+		queue <- i
 	}
+	close(queue)
+	wg.Wait()
 
-	for _, cmd := range c.Args() {
-		if err := updatePackage(cmd, c.Bool("force")); err != nil {
-			return err
+	printUpdateSummary(results)
+
+	var failed []string
+	var errs []error
+	for _, result := range results {
+		if result.status == updateFailed {
+			failed = append(failed, result.name)
+			errs = append(errs, result.err)
 		}
 	}
 
+	if len(failed) > 0 {
+		return combinedError(errs, "Unable to update: %s", strings.Join(failed, ", "))
+	}
+
 	return nil
 }
 
-func updatePackage(cmd string, forceBinary bool) error {
-	exec, err := findExec(cmd)
+func printUpdateSummary(results []updateResult) {
+	if len(results) == 0 {
+		return
+	}
+
+	fmt.Fprintln(akamai.App.Writer, color.New(color.Bold).Sprint("\nSummary:"))
+	for _, result := range results {
+		status := string(result.status)
+		switch result.status {
+		case updateUpdated:
+			status = color.GreenString(status)
+		case updateFailed:
+			status = color.RedString(status)
+		default:
+			status = color.CyanString(status)
+		}
+
+		line := fmt.Sprintf("  %s: %s", color.New(color.Bold).Sprint(result.name), status)
+		if result.err != nil {
+			if message := strings.TrimSpace(stripColor(result.err.Error())); message != "" {
+				line += " (" + message + ")"
+			}
+		}
+		fmt.Fprintln(akamai.App.Writer, line)
+	}
+}
+
+// updatePackage updates the package of a command, running the update hooks around it
+func updatePackage(cmd string, opts installOptions) (updateStatus, error) {
+	context := map[string]string{"command": cmd}
+	if dir, err := findCommandPackageDir(cmd, "update"); err == nil {
+		context["package"] = filepath.Base(getPackageRoot(dir))
+		context["package_dir"] = getPackageRoot(dir)
+	}
+
+	if _, err := runHooks(hookPreUpdate, context); err != nil {
+		return updateFailed, err
+	}
+
+	status, err := updatePackageRepo(cmd, opts)
+	if status == updateUpdated {
+		clearPackageUpdateState(context["package"])
+	}
+
+	context = hookStatus(context, err)
+	context["update_status"] = string(status)
+	runHooks(hookPostUpdate, context)
+
+	return status, err
+}
+
+func updatePackageRepo(cmd string, opts installOptions) (updateStatus, error) {
+	if isOffline() {
+		return updateFailed, newError(errOffline, "%s", offlineError("update packages").Error())
+	}
+
+	repoDir, err := findCommandPackageDir(cmd, "update")
 	if err != nil {
-		return cli.NewExitError(color.RedString("Command \"%s\" not found. Try \"%s help\".\n", cmd, self()), 1)
+		return updateFailed, err
 	}
 
-	akamai.StartSpinner(fmt.Sprintf("Attempting to update \"%s\" command...", cmd), fmt.Sprintf("Attempting to update \"%s\" command...", cmd)+"... ["+color.CyanString("OK")+"]\n")
+	p := opts.getProgress()
+	p.Start(fmt.Sprintf("Attempting to update \"%s\" command...", cmd))
 
-	var repoDir string
-	if len(exec) == 1 {
-		repoDir = findPackageDir(filepath.Dir(exec[0]))
-	} else if len(exec) > 1 {
-		repoDir = findPackageDir(filepath.Dir(exec[len(exec)-1]))
+	name := filepath.Base(getPackageRoot(repoDir))
+	manifest, _ := readManifest(name)
+	if manifest.Version != "" {
+		p.WarnOk()
+		printUpdateMessage(opts, "command \"%s\" is pinned to version %s, reinstall it to change version", cmd, manifest.Version)
+		return updateSkipped, nil
 	}
 
-	if repoDir == "" {
-		akamai.StopSpinnerFail()
-		return cli.NewExitError(color.RedString("unable to update, was it installed using "+color.CyanString("\"akamai install\"")+"?"), 1)
+	if manifest.Source != "" {
+		p.WarnOk()
+		printUpdateMessage(opts, "command \"%s\" was installed from %s, reinstall it to update", cmd, manifest.Source)
+		return updateSkipped, nil
+	}
+
+	if manifest.Binary != "" {
+		return updateBinaryPackage(cmd, repoDir, manifest, opts, p)
 	}
 
-	repo, err := git.PlainOpen(repoDir)
+	repo, err := git.PlainOpen(getPackageRoot(repoDir))
 	if err != nil {
-		return err
+		p.Fail()
+		return updateFailed, err
 	}
 
-	err = repo.Fetch(&git.FetchOptions{
-		RemoteName: git.DefaultRemoteName,
-	})
+	branch := getPackageBranch(repo)
+	err = fetchPackageRemote(repo, getPackageBranchRefSpec(branch))
 
-	if err != nil && err.Error() != "already up-to-date" {
-		akamai.StopSpinnerFail()
-		return cli.NewExitError("Unable to fetch updates", 1)
+	if err != nil {
+		p.Fail()
+		return updateFailed, newError(getGitErrorID(err), "Unable to fetch updates")
 	}
 
 	workdir, _ := repo.Worktree()
-	ref, err := repo.Reference("refs/remotes/"+git.DefaultRemoteName+"/master", true)
+	ref, err := repo.Reference(getPackageRemoteRef(branch), true)
 	if err != nil {
-		akamai.StopSpinnerFail()
-		return cli.NewExitError("Unable to update command", 1)
+		p.Fail()
+		return updateFailed, newError(errGitFailed, "Unable to update command")
 	}
 
 	head, _ := repo.Head()
 	if head.Hash() == ref.Hash() {
-		akamai.StopSpinnerWarnOk()
-		fmt.Fprintln(akamai.App.Writer, color.CyanString("command \"%s\" already up-to-date", cmd))
-		return nil
+		p.WarnOk()
+		printUpdateMessage(opts, "command \"%s\" already up-to-date", cmd)
+		return updateCurrent, nil
+	}
+
+	// Only other packages of its monorepo changed, catch up without rebuilding
+	if !subpathChanged(repo, head.Hash(), ref.Hash(), manifest.Subpath) {
+		if err := workdir.Checkout(&git.CheckoutOptions{Branch: ref.Name(), Force: true}); err != nil {
+			p.Fail()
+			return updateFailed, newError(errGitFailed, "Unable to update command")
+		}
+
+		manifest.Commit = ref.Hash().String()
+		if err := writeManifest(name, manifest); err != nil {
+			logWarn("unable to record package commit", "package", name, "error", err)
+		}
+
+		p.WarnOk()
+		printUpdateMessage(opts, "command \"%s\" already up-to-date, only other packages in its repository changed", cmd)
+		return updateCurrent, nil
+	}
+
+	if opts.changelog {
+		p.Ok()
+		if isShallowRepository(repo) {
+			if err := fetchPackageRefs(repo, []gitconfig.RefSpec{getPackageBranchRefSpec(branch)}, maxChangelogCommits); err != nil {
+				logWarn("unable to fetch the changelog", "package", name, "error", err)
+			}
+		}
+
+		pkg, _ := findCachedPackage(name)
+		changes, complete := getCommitChangelog(repo, head.Hash(), ref.Hash(), manifest.Subpath)
+		printChangelog(name, getNewerReleases(pkg, head.Hash().String()), changes, complete)
+
+		if !confirmUpdate(name, opts) {
+			printInfo(akamai.App.Writer, "Skipped updating \"%s\"", cmd)
+			return updateSkipped, nil
+		}
+		p.Start(fmt.Sprintf("Updating \"%s\" command...", cmd))
 	}
 
 	err = workdir.Checkout(&git.CheckoutOptions{
@@ -110,15 +301,114 @@ func updatePackage(cmd string, forceBinary bool) error {
 	})
 
 	if err != nil {
-		akamai.StopSpinnerFail()
-		return cli.NewExitError("Unable to update command", 1)
+		p.Fail()
+		return updateFailed, newError(errGitFailed, "Unable to update command")
+	}
+
+	// Remember where we came from, so a bad release can be rolled back
+	manifest.PreviousCommit = head.Hash().String()
+	manifest.Commit = ref.Hash().String()
+	if err := writeManifest(name, manifest); err != nil {
+		fmt.Fprintln(akamai.App.ErrWriter, color.YellowString("Unable to record the previous version, rollback will not be possible: %s", err.Error()))
 	}
 
-	akamai.StopSpinnerOk()
+	p.Ok()
 
-	if !installPackageDependencies(repoDir, forceBinary) {
-		return cli.NewExitError("Unable to update command", 1)
+	if err := installRequiredPackages(repoDir, opts); err != nil {
+		return updateFailed, err
 	}
 
-	return nil
+	if !installPackageDependencies(repoDir, opts) {
+		return updateFailed, newError(errBuildFailed, "Unable to update command, run \"%s rollback %s\" to restore the previous version", self(), cmd)
+	}
+
+	return updateUpdated, nil
+}
+
+// printUpdateMessage prints a note about an update, unless updates are running
+// concurrently, when the summary says the same
+func printUpdateMessage(opts installOptions, format string, a ...interface{}) {
+	if opts.progress == nil {
+		printInfo(akamai.App.Writer, format, a...)
+	}
+}
+
+// findCommandPackageDir returns the directory of the installed package providing
+// cmd, action is used in the error when the package was not installed by us
+func findCommandPackageDir(cmd string, action string) (string, error) {
+	exec, err := findExec(cmd)
+	if err != nil {
+		return "", newError(errCommandNotFound, "Command \"%s\" not found. Try \"%s help\".\n", cmd, self())
+	}
+
+	var repoDir string
+	if len(exec) == 1 {
+		repoDir = findPackageDir(filepath.Dir(exec[0]))
+	} else if len(exec) > 1 {
+		repoDir = findPackageDir(filepath.Dir(exec[len(exec)-1]))
+	}
+
+	if repoDir == "" {
+		return "", newError(errPackageInvalid, "unable to %s, was it installed using %s?", action, color.CyanString("\"akamai install\""))
+	}
+
+	return repoDir, nil
+}
+
+// getRemoteURL returns the URL of the remote a package is updated from
+func getRemoteURL(repo *git.Repository) string {
+	remote, err := repo.Remote(git.DefaultRemoteName)
+	if err != nil || len(remote.Config().URLs) == 0 {
+		return ""
+	}
+
+	return remote.Config().URLs[0]
+}
+
+// fetchPackageRemote fetches refSpecs, by default the branch packages are
+// updated from, from the origin remote of a package repository. Shallow clones
+// only fetch the latest commit, or everything if the remote refuses to.
+func fetchPackageRemote(repo *git.Repository, refSpecs ...gitconfig.RefSpec) error {
+	if len(refSpecs) == 0 {
+		refSpecs = []gitconfig.RefSpec{getPackageBranchRefSpec(getPackageBranch(repo))}
+	}
+
+	if !isShallowRepository(repo) {
+		return fetchPackageRefs(repo, refSpecs, 0)
+	}
+
+	err := fetchPackageRefs(repo, refSpecs, 1)
+	if err != nil && !isPermanentGitError(err) {
+		logWarn("shallow git fetch failed, fetching in full", "remote", git.DefaultRemoteName, "error", err)
+		err = fetchPackageRefs(repo, refSpecs, unshallowDepth)
+	}
+
+	return err
+}
+
+// fetchPackageRefs fetches refSpecs from the origin remote, with depth commits
+// if not 0, retrying transient failures. Being up-to-date already is not an
+// error.
+func fetchPackageRefs(repo *git.Repository, refSpecs []gitconfig.RefSpec, depth int) error {
+	return withRetry(getRetryPolicy(), func() error {
+		err := withGitAuth(getRemoteURL(repo), func(auth transport.AuthMethod) error {
+			return repo.Fetch(&git.FetchOptions{
+				RemoteName: git.DefaultRemoteName,
+				RefSpecs:   refSpecs,
+				Depth:      depth,
+				Auth:       auth,
+			})
+		})
+		if err == git.NoErrAlreadyUpToDate {
+			err = nil
+		}
+
+		if err != nil {
+			logWarn("git fetch failed", "remote", git.DefaultRemoteName, "error", err)
+		} else {
+			logInfo("git fetch", "remote", git.DefaultRemoteName, "depth", depth)
+		}
+
+		return permanentGitError(err)
+	})
 }
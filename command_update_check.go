@@ -0,0 +1,170 @@
+/*
+ Copyright 2018. Akamai Technologies, Inc
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"path"
+	"path/filepath"
+
+	akamai "github.com/akamai/cli-common-golang"
+	"github.com/fatih/color"
+	"github.com/urfave/cli"
+	"gopkg.in/src-d/go-git.v4"
+	"gopkg.in/src-d/go-git.v4/plumbing"
+)
+
+// packageUpdate describes the update available for an installed package
+type packageUpdate struct {
+	name       string
+	pinned     string
+	source     string
+	oldVersion string
+	newVersion string
+	oldCommit  plumbing.Hash
+	newCommit  plumbing.Hash
+	// unchanged is set when the new commit only changes other packages of a monorepo
+	unchanged bool
+}
+
+func (update packageUpdate) available() bool {
+	return update.pinned == "" && update.source == "" && update.oldCommit != update.newCommit && !update.unchanged
+}
+
+// cmdUpdateCheck reports the packages that "akamai update" would update,
+// without changing any checkout. It exits with status 2 if there are updates,
+// so that CI jobs can gate on it.
+func cmdUpdateCheck(c *cli.Context) error {
+	if isOffline() {
+		return cli.NewExitError(color.RedString(offlineError("check for package updates").Error()), 1)
+	}
+
+	var dirs []string
+	if c.Args().Present() {
+		for _, cmd := range c.Args() {
+			dir, err := findCommandPackageDir(cmd, "check for updates")
+			if err != nil {
+				return err
+			}
+			dirs = append(dirs, dir)
+		}
+	} else {
+		dirs = getPackageDirs()
+	}
+
+	available := 0
+	for _, dir := range dirs {
+		update, err := checkPackageUpdate(dir)
+		if err != nil {
+			fmt.Fprintln(akamai.App.ErrWriter, color.RedString("%s: unable to check for updates: %s", filepath.Base(getPackageRoot(dir)), err.Error()))
+			continue
+		}
+
+		switch {
+		case update.pinned != "":
+			fmt.Fprintf(akamai.App.Writer, "%s: pinned to version %s\n", update.name, update.pinned)
+		case update.source != "":
+			fmt.Fprintf(akamai.App.Writer, "%s: installed from %s\n", update.name, update.source)
+		case update.available():
+			available++
+			fmt.Fprintf(akamai.App.Writer, "%s: %s -> %s (%s -> %s)\n", color.New(color.Bold).Sprint(update.name), update.oldVersion, color.GreenString(update.newVersion), update.oldCommit.String()[:7], update.newCommit.String()[:7])
+		default:
+			fmt.Fprintf(akamai.App.Writer, "%s: up-to-date\n", update.name)
+		}
+	}
+
+	if available > 0 {
+		return cli.NewExitError(color.YellowString("%d package(s) have updates available, run \"%s update\" to install them", available, self()), 2)
+	}
+
+	return nil
+}
+
+// checkPackageUpdate fetches the remote of the package in dir, and compares
+// the checked out commit with the head of the remote branch it follows. Fetching
+// only updates the remote tracking branch, the checkout is left as is.
+func checkPackageUpdate(dir string) (packageUpdate, error) {
+	root := getPackageRoot(dir)
+	update := packageUpdate{name: filepath.Base(root)}
+
+	manifest, _ := readManifest(update.name)
+	if manifest.Version != "" {
+		update.pinned = manifest.Version
+		return update, nil
+	}
+
+	if manifest.Source != "" {
+		update.source = manifest.Source
+		return update, nil
+	}
+
+	repo, err := git.PlainOpen(root)
+	if err != nil {
+		return update, err
+	}
+
+	branch := getPackageBranch(repo)
+	err = fetchPackageRemote(repo, getPackageBranchRefSpec(branch))
+	if err != nil {
+		return update, err
+	}
+
+	head, err := repo.Head()
+	if err != nil {
+		return update, err
+	}
+
+	ref, err := repo.Reference(getPackageRemoteRef(branch), true)
+	if err != nil {
+		return update, err
+	}
+
+	update.oldCommit = head.Hash()
+	update.newCommit = ref.Hash()
+	update.oldVersion = getCommitPackageVersion(repo, update.oldCommit, manifest.Subpath)
+	update.newVersion = getCommitPackageVersion(repo, update.newCommit, manifest.Subpath)
+	update.unchanged = !subpathChanged(repo, update.oldCommit, update.newCommit, manifest.Subpath)
+
+	return update, nil
+}
+
+// getCommitPackageVersion reads the package version from the cli.json at a
+// commit, or returns "unknown"
+func getCommitPackageVersion(repo *git.Repository, hash plumbing.Hash, subpath string) string {
+	commit, err := repo.CommitObject(hash)
+	if err != nil {
+		return "unknown"
+	}
+
+	file, err := commit.File(path.Join(filepath.ToSlash(subpath), "cli.json"))
+	if err != nil {
+		return "unknown"
+	}
+
+	contents, err := file.Contents()
+	if err != nil {
+		return "unknown"
+	}
+
+	var cmdPackage commandPackage
+	if err := json.Unmarshal([]byte(contents), &cmdPackage); err != nil || len(cmdPackage.Commands) == 0 || cmdPackage.Commands[0].Version == "" {
+		return "unknown"
+	}
+
+	return cmdPackage.Commands[0].Version
+}
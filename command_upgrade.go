@@ -19,6 +19,7 @@ package main
 import (
 	"fmt"
 	"os"
+	"strings"
 
 	akamai "github.com/akamai/cli-common-golang"
 	"github.com/fatih/color"
@@ -26,10 +27,36 @@ import (
 )
 
 func cmdUpgrade(c *cli.Context) error {
-	akamai.StartSpinner("Checking for upgrades...", "Checking for upgrades...... ["+color.GreenString("OK")+"]\n")
+	// The previous version is kept locally
+	if c.Bool("undo") {
+		return undoUpgrade()
+	}
+
+	if isOffline() {
+		return cli.NewExitError(color.RedString(offlineError("upgrade").Error()), 1)
+	}
+
+	if c.IsSet("channel") {
+		channel := strings.ToLower(c.String("channel"))
+		if err := validateUpgradeChannel(channel); err != nil {
+			return cli.NewExitError(color.RedString(err.Error()), 1)
+		}
+
+		setConfigValue("cli", "upgrade-channel", channel)
+		if err := saveConfig(); err != nil {
+			return cli.NewExitError(color.RedString("Unable to save upgrade channel: %s", err.Error()), 1)
+		}
+	}
+
+	if c.IsSet("to") {
+		return upgradeToVersion(strings.TrimPrefix(c.String("to"), "v"))
+	}
+
+	p := newSpinnerProgress()
+	p.Start("Checking for upgrades...")
 
 	if latestVersion := checkForUpgrade(true); latestVersion != "" {
-		akamai.StopSpinnerOk()
+		p.Ok()
 		fmt.Fprintf(akamai.App.Writer, "Found new version: %s (current version: %s)\n", color.BlueString("v"+latestVersion), color.BlueString("v"+VERSION))
 		os.Args = []string{os.Args[0], "--version"}
 		success := upgradeCli(latestVersion)
@@ -39,8 +66,32 @@ func cmdUpgrade(c *cli.Context) error {
 			trackEvent("upgrade.failed", "to: "+latestVersion+" from:"+VERSION)
 		}
 	} else {
-		akamai.StopSpinnerWarnOk()
-		fmt.Fprintf(akamai.App.Writer, "Akamai CLI (%s) is already up-to-date", color.CyanString("v"+VERSION))
+		p.WarnOk()
+		fmt.Fprintf(akamai.App.Writer, "Akamai CLI (%s) is already up-to-date on the %s channel\n", color.CyanString("v"+VERSION), getUpgradeChannel())
+		if getReleaseChannel(VERSION) != channelStable && getUpgradeChannel() == channelStable {
+			fmt.Fprintln(akamai.App.Writer, color.YellowString("You are running a pre-release, which will be upgraded once a newer stable release is available"))
+		}
+	}
+
+	return nil
+}
+
+// upgradeToVersion replaces Akamai CLI with the release version, which may be
+// older than the current one
+func upgradeToVersion(version string) error {
+	switch compareVersions(version, VERSION) {
+	case 0:
+		fmt.Fprintf(akamai.App.Writer, "Akamai CLI (%s) is already installed\n", color.CyanString("v"+VERSION))
+		return nil
+	case -1:
+		fmt.Fprintln(akamai.App.ErrWriter, color.YellowString("Warning: downgrading Akamai CLI from v%s to v%s, run \"%s upgrade --undo\" to go back", VERSION, version, self()))
+	default:
+		fmt.Fprintf(akamai.App.Writer, "Upgrading to version: %s (current version: %s)\n", color.BlueString("v"+version), color.BlueString("v"+VERSION))
+	}
+
+	os.Args = []string{os.Args[0], "--version"}
+	if !upgradeCli(version) {
+		return cli.NewExitError(color.RedString("Unable to install Akamai CLI v%s, check that it is a published release", version), 1)
 	}
 
 	return nil
@@ -0,0 +1,368 @@
+/*
+ Copyright 2018. Akamai Technologies, Inc
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package main
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+
+	akamai "github.com/akamai/cli-common-golang"
+	"github.com/fatih/color"
+	"github.com/urfave/cli"
+	"gopkg.in/src-d/go-git.v4"
+)
+
+const (
+	integrityOk         = "ok"
+	integrityModified   = "modified"
+	integrityCorrupted  = "corrupted"
+	integrityUnverified = "unverified"
+)
+
+// packageIntegrity is the outcome of verifying an installed package's working
+// tree against the commit it was installed at
+type packageIntegrity struct {
+	Name   string `json:"name"`
+	Status string `json:"status"`
+	// Commit is the commit the package should be at, the recorded one if any
+	Commit string `json:"commit,omitempty"`
+	// Modified are the tracked files that were changed or deleted
+	Modified []string `json:"modified,omitempty"`
+	Problem  string   `json:"problem,omitempty"`
+
+	dir string
+}
+
+// cmdVerifyLocked is verify, holding the operation lock when it repairs
+// packages. Verifying alone changes nothing, so it runs alongside other commands.
+func cmdVerifyLocked(c *cli.Context) error {
+	if c.Bool("repair") {
+		return withOperationLock("verify --repair", cmdVerify)(c)
+	}
+
+	return cmdVerify(c)
+}
+
+func cmdVerify(c *cli.Context) error {
+	dirs := getAllPackageDirs()
+	if c.Args().Present() {
+		dirs = nil
+		for _, cmd := range c.Args() {
+			dir, err := findCommandPackageDir(cmd, "verify")
+			if err != nil {
+				return err
+			}
+			dirs = append(dirs, dir)
+		}
+	}
+
+	results := make([]packageIntegrity, 0, len(dirs))
+	for _, dir := range dirs {
+		results = append(results, verifyPackage(dir))
+	}
+
+	if getOutputFormat() != formatTable {
+		if err := printStructured(results); err != nil {
+			return err
+		}
+	} else {
+		for _, result := range results {
+			printPackageIntegrity(result)
+		}
+	}
+
+	failed := 0
+	for _, result := range results {
+		if result.Status != integrityModified && result.Status != integrityCorrupted {
+			continue
+		}
+
+		if !c.Bool("repair") {
+			failed++
+			continue
+		}
+
+		if err := repairPackage(result, installOptions{forceBinary: c.Bool("force")}); err != nil {
+			fmt.Fprintln(akamai.App.ErrWriter, color.RedString("Unable to repair %s: %s", result.Name, err.Error()))
+			failed++
+		}
+	}
+
+	if failed > 0 {
+		if c.Bool("repair") {
+			return cli.NewExitError(color.RedString("%d package(s) could not be repaired", failed), 1)
+		}
+		return cli.NewExitError(color.RedString("%d package(s) failed verification, run \"%s verify --repair\" to restore them", failed, self()), 1)
+	}
+
+	return nil
+}
+
+func printPackageIntegrity(result packageIntegrity) {
+	var label string
+	switch result.Status {
+	case integrityOk:
+		label = color.GreenString("[ OK ]")
+	case integrityUnverified:
+		label = color.YellowString("[SKIP]")
+	default:
+		label = color.RedString("[FAIL]")
+	}
+
+	message := result.Status
+	if result.Problem != "" {
+		message = result.Problem
+	}
+	fmt.Fprintf(akamai.App.Writer, "%s %s: %s\n", label, color.New(color.Bold).Sprint(result.Name), message)
+
+	for _, file := range result.Modified {
+		fmt.Fprintf(akamai.App.Writer, "       %s\n", file)
+	}
+}
+
+// verifyPackage checks that the package in dir is checked out at its recorded
+// commit, and that none of its tracked files were changed. Untracked files,
+// like the output of the build step, are not checked.
+func verifyPackage(dir string) packageIntegrity {
+	root := getPackageRoot(dir)
+	result := packageIntegrity{Name: filepath.Base(root), Status: integrityOk, dir: dir}
+	manifest, _ := readManifest(result.Name)
+
+	if manifest.Binary != "" {
+		return verifyBinaryPackage(result, manifest)
+	}
+
+	repo, err := git.PlainOpen(root)
+	if err != nil {
+		if manifest.Source != "" {
+			result.Status = integrityUnverified
+			result.Problem = fmt.Sprintf("installed from %s, which is not a git repository", manifest.Source)
+			return result
+		}
+		return corruptedIntegrity(result, err)
+	}
+
+	head, err := repo.Head()
+	if err != nil {
+		return corruptedIntegrity(result, err)
+	}
+
+	if _, err := repo.CommitObject(head.Hash()); err != nil {
+		return corruptedIntegrity(result, err)
+	}
+
+	result.Commit = head.Hash().String()
+	if manifest.Commit != "" && manifest.Commit != result.Commit {
+		result.Status = integrityModified
+		result.Problem = fmt.Sprintf("checked out at %s instead of %s", shortCommit(result.Commit), shortCommit(manifest.Commit))
+		result.Commit = manifest.Commit
+	}
+
+	worktree, err := repo.Worktree()
+	if err != nil {
+		return corruptedIntegrity(result, err)
+	}
+
+	status, err := worktree.Status()
+	if err != nil {
+		return corruptedIntegrity(result, err)
+	}
+
+	result.Modified = getModifiedFiles(status)
+	if len(result.Modified) > 0 && result.Status == integrityOk {
+		result.Status = integrityModified
+		result.Problem = fmt.Sprintf("%d tracked file(s) changed", len(result.Modified))
+	}
+
+	if _, err := readPackage(dir); err != nil && result.Status == integrityOk {
+		return corruptedIntegrity(result, err)
+	}
+
+	return result
+}
+
+// verifyBinaryPackage checks the executable of a package installed from a
+// binary against the checksum it was installed with. The executables
+// extracted from an archive can't be checked.
+func verifyBinaryPackage(result packageIntegrity, manifest packageManifest) packageIntegrity {
+	cmdPackage, err := readPackage(result.dir)
+	if err != nil {
+		return corruptedIntegrity(result, err)
+	}
+
+	if getArchiveSuffix(manifest.Binary) != "" || manifest.BinarySHA256 == "" || len(cmdPackage.Commands) == 0 {
+		result.Status = integrityUnverified
+		result.Problem = fmt.Sprintf("installed from %s, which has no checksum for its executables", manifest.Binary)
+		return result
+	}
+
+	name := getBinaryName(cmdPackage.Commands[0])
+	data, err := ioutil.ReadFile(filepath.Join(result.dir, "bin", name))
+	if err != nil {
+		return corruptedIntegrity(result, err)
+	}
+
+	if sum := sha256.Sum256(data); !checksumMatches(manifest.BinarySHA256, sum[:]) {
+		result.Status = integrityCorrupted
+		result.Modified = []string{filepath.ToSlash(filepath.Join("bin", name))}
+		result.Problem = "the executable does not match the checksum of the binary it was installed from"
+	}
+
+	return result
+}
+
+func corruptedIntegrity(result packageIntegrity, err error) packageIntegrity {
+	result.Status = integrityCorrupted
+	result.Problem = err.Error()
+	return result
+}
+
+// getModifiedFiles returns the tracked files of a worktree status that differ
+// from the commit, sorted
+func getModifiedFiles(status git.Status) []string {
+	var files []string
+	for file, fileStatus := range status {
+		if fileStatus.Worktree == git.Untracked && fileStatus.Staging == git.Untracked {
+			continue
+		}
+		if fileStatus.Worktree == git.Unmodified && fileStatus.Staging == git.Unmodified {
+			continue
+		}
+		files = append(files, file)
+	}
+	sort.Strings(files)
+
+	return files
+}
+
+func shortCommit(commit string) string {
+	if len(commit) > 7 {
+		return commit[:7]
+	}
+
+	return commit
+}
+
+// repairPackage restores a package that failed verification: the working tree
+// is checked out again at the commit, or if the repository is corrupted, the
+// package is cloned again. The build step is then run again.
+func repairPackage(result packageIntegrity, opts installOptions) error {
+	manifest, _ := readManifest(result.Name)
+	commit := result.Commit
+	if commit == "" {
+		commit = manifest.Commit
+	}
+
+	if result.Status == integrityModified {
+		p := newSpinnerProgress()
+		p.Start(fmt.Sprintf("Attempting to restore %s at %s...", result.Name, shortCommit(commit)))
+		if err := checkoutVersion(getPackageRoot(result.dir), commit); err != nil {
+			p.Fail()
+			return err
+		}
+		p.Ok()
+
+		if !installPackageDependencies(result.dir, opts) {
+			return fmt.Errorf("the build step failed")
+		}
+
+		manifest.Commit = commit
+		return writeManifest(result.Name, manifest)
+	}
+
+	if manifest.Binary != "" {
+		return repairBinaryPackage(result, manifest, opts)
+	}
+
+	if manifest.Repo == "" {
+		return fmt.Errorf("its repository is unknown, uninstall and install it again")
+	}
+
+	// The corrupted package is kept aside until the new one is in place, so
+	// that a failed clone or build doesn't leave no package at all
+	root := getPackageRoot(result.dir)
+	old := root + ".repair"
+	removeAllForce(old)
+	if err := os.Rename(root, old); err != nil {
+		return err
+	}
+
+	// Reinstall at the recorded commit, or the pinned version, keeping the rest of the manifest
+	opts.version = manifest.Version
+	if commit != "" {
+		opts.version = commit
+	}
+	// The license was accepted for the recorded commit, not whatever else is cloned
+	if commit != "" && commit == manifest.Commit {
+		opts.acceptLicense = true
+	}
+	opts.skipRequired = true
+	if err := installPackage(manifest.Repo, manifest.Subpath, opts); err != nil {
+		if _, statErr := os.Stat(root); os.IsNotExist(statErr) {
+			if renameErr := os.Rename(old, root); renameErr != nil {
+				logWarn("unable to restore the package", "dir", root, "error", renameErr)
+			}
+		}
+		return err
+	}
+
+	if err := removeAllForce(old); err != nil {
+		logWarn("unable to remove the corrupted package", "dir", old, "error", err)
+	}
+
+	manifest.Commit = getHeadCommit(root)
+	return writeManifest(result.Name, manifest)
+}
+
+// repairBinaryPackage downloads the binary a package was installed from again
+func repairBinaryPackage(result packageIntegrity, manifest packageManifest, opts installOptions) error {
+	cmdPackage, err := readPackage(result.dir)
+	if err != nil {
+		return fmt.Errorf("its cli.json is unreadable, uninstall and install it again")
+	}
+
+	p := newSpinnerProgress()
+	p.Start(fmt.Sprintf("Attempting to download %s again...", manifest.Binary))
+
+	root := getPackageRoot(result.dir)
+	tmp := root + ".repair"
+	os.RemoveAll(tmp)
+	binary := releaseBinary{URL: manifest.Binary, SHA256: manifest.BinarySHA256}
+	if err := writeBinaryPackage(tmp, binary, cmdPackage.Commands, opts.insecure); err != nil {
+		os.RemoveAll(tmp)
+		p.Fail()
+		return err
+	}
+
+	if err := os.RemoveAll(root); err != nil {
+		os.RemoveAll(tmp)
+		p.Fail()
+		return err
+	}
+
+	if err := os.Rename(tmp, root); err != nil {
+		p.Fail()
+		return err
+	}
+	p.Ok()
+
+	return nil
+}
@@ -0,0 +1,44 @@
+/*
+ Copyright 2018. Akamai Technologies, Inc
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package main
+
+import (
+	"reflect"
+	"testing"
+
+	"gopkg.in/src-d/go-git.v4"
+)
+
+func TestGetModifiedFiles(t *testing.T) {
+	status := git.Status{
+		"cli.json":        &git.FileStatus{Staging: git.Unmodified, Worktree: git.Modified},
+		"bin/akamai-foo":  &git.FileStatus{Staging: git.Untracked, Worktree: git.Untracked},
+		"README.md":       &git.FileStatus{Staging: git.Unmodified, Worktree: git.Unmodified},
+		"main.go":         &git.FileStatus{Staging: git.Unmodified, Worktree: git.Deleted},
+		"lib/util.py":     &git.FileStatus{Staging: git.Modified, Worktree: git.Unmodified},
+		"node_modules/.x": &git.FileStatus{Staging: git.Untracked, Worktree: git.Untracked},
+	}
+
+	expected := []string{"cli.json", "lib/util.py", "main.go"}
+	if files := getModifiedFiles(status); !reflect.DeepEqual(files, expected) {
+		t.Errorf("getModifiedFiles() => %v, wanted: %v", files, expected)
+	}
+
+	if files := getModifiedFiles(git.Status{}); len(files) != 0 {
+		t.Errorf("getModifiedFiles() of a clean worktree => %v, wanted none", files)
+	}
+}
@@ -0,0 +1,165 @@
+/*
+ Copyright 2018. Akamai Technologies, Inc
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	akamai "github.com/akamai/cli-common-golang"
+	"github.com/fatih/color"
+	"github.com/urfave/cli"
+)
+
+// commandProvider is an installed package providing a command
+type commandProvider struct {
+	Command     string   `json:"command"`
+	Package     string   `json:"package"`
+	Directory   string   `json:"directory"`
+	Version     string   `json:"version"`
+	Language    string   `json:"language"`
+	Requirement string   `json:"requirement,omitempty"`
+	Executable  []string `json:"executable"`
+	Error       string   `json:"error,omitempty"`
+	// Active is set for the provider that "akamai <command>" runs
+	Active bool `json:"active"`
+}
+
+func cmdWhich(c *cli.Context) error {
+	if !c.Args().Present() {
+		return cli.NewExitError(color.RedString("You must specify a command"), 1)
+	}
+
+	name := strings.ToLower(c.Args().First())
+	if alias := getConfigValue(aliasSection, name); alias != "" && validateAliasName(name) == nil {
+		printInfo(akamai.App.ErrWriter, "\"%s\" is an alias of \"%s\"", name, alias)
+		if args, err := splitAliasArgs(alias); err == nil && len(args) > 0 {
+			name = strings.ToLower(args[0])
+		}
+	}
+
+	for _, cmd := range getBuiltinCommands() {
+		command := cmd.Commands[0]
+		if command.Name == name || containsString(command.Aliases, name) {
+			if getOutputFormat() != formatTable {
+				return printStructured([]commandProvider{{Command: command.Name, Package: "built-in", Version: VERSION, Active: true}})
+			}
+			fmt.Fprintf(akamai.App.Writer, "%s is a built-in command of Akamai CLI %s\n", color.New(color.Bold).Sprint(command.Name), VERSION)
+			return nil
+		}
+	}
+
+	providers := findCommandProviders(name, getPackageDirs())
+	if len(providers) == 0 {
+		return cli.NewExitError(color.RedString("Command \"%s\" is not provided by any enabled package. Try \"%s package list\".", name, self()), 1)
+	}
+
+	if getOutputFormat() != formatTable {
+		return printStructured(providers)
+	}
+
+	bold := color.New(color.Bold)
+	field := func(label string, value string) {
+		if value == "" {
+			value = "-"
+		}
+		fmt.Fprintf(akamai.App.Writer, "  %s %s\n", bold.Sprintf("%-12s", label+":"), value)
+	}
+
+	for i, provider := range providers {
+		if i > 0 {
+			fmt.Fprintln(akamai.App.Writer)
+		}
+
+		status := color.GreenString("runs with \"%s %s\"", self(), provider.Command)
+		if !provider.Active {
+			status = color.YellowString("shadowed, run with \"%s %s/%s\"", self(), normalizePackageName(provider.Package), provider.Command)
+		}
+		fmt.Fprintf(akamai.App.Writer, "%s from %s (%s)\n", bold.Sprint(provider.Command), bold.Sprint(provider.Package), status)
+
+		field("Directory", provider.Directory)
+		field("Version", provider.Version)
+		language := provider.Language
+		if provider.Requirement != "" {
+			language += " " + provider.Requirement
+		}
+		field("Language", language)
+		if provider.Error != "" {
+			field("Executable", color.RedString(provider.Error))
+		} else {
+			field("Executable", strings.Join(provider.Executable, " "))
+		}
+	}
+
+	return nil
+}
+
+// findCommandProviders returns the packages in dirs that provide the command
+// name, or have it as an alias, in the order they are looked for in
+func findCommandProviders(name string, dirs []string) []commandProvider {
+	var providers []commandProvider
+	for _, dir := range dirs {
+		cmdPackage, err := readPackage(dir)
+		if err != nil {
+			continue
+		}
+
+		for _, command := range cmdPackage.Commands {
+			if command.Name != name && !containsString(command.Aliases, name) {
+				continue
+			}
+
+			provider := commandProvider{
+				Command:    command.Name,
+				Package:    filepath.Base(getPackageRoot(dir)),
+				Directory:  dir,
+				Version:    command.Version,
+				Language:   determineCommandLanguage(cmdPackage),
+				Executable: []string{},
+				Active:     len(providers) == 0,
+			}
+			if runtime := provider.Language; runtime != "" {
+				if runtime == "javascript" {
+					runtime = "node"
+				}
+				provider.Requirement = cmdPackage.Requirements.runtimes()[runtime]
+			}
+
+			if executable, err := findExecIn(command.Name, filepath.SplitList(getBinPaths([]string{dir}))); err != nil {
+				provider.Error = err.Error()
+			} else {
+				provider.Executable = executable
+			}
+
+			providers = append(providers, provider)
+			break
+		}
+	}
+
+	return providers
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if strings.ToLower(v) == value {
+			return true
+		}
+	}
+
+	return false
+}
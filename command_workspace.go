@@ -0,0 +1,154 @@
+/*
+ Copyright 2018. Akamai Technologies, Inc
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	akamai "github.com/akamai/cli-common-golang"
+	"github.com/fatih/color"
+	"github.com/urfave/cli"
+)
+
+// workspacePackage is a package declared in the workspace file
+type workspacePackage struct {
+	// target is what is installed: a name, repository URL, or local path
+	target string
+	// name is the directory the package is installed in
+	name    string
+	version string
+}
+
+// cmdWorkspaceSync installs the packages of the workspace file at their pinned
+// versions, and removes the workspace packages it no longer lists
+func cmdWorkspaceSync(c *cli.Context) error {
+	root := findWorkspace()
+	if root == "" {
+		return cli.NewExitError(color.RedString("No %s found in this directory or its parents", workspaceFile), 1)
+	}
+
+	workspace, err := readWorkspaceConfig(filepath.Join(root, workspaceFile))
+	if err != nil {
+		return cli.NewExitError(color.RedString("Unable to read %s: %s", workspaceFile, err.Error()), 1)
+	}
+
+	// Syncing is what makes the user trust the workspace
+	if err := trustWorkspace(root); err != nil {
+		return newError(errConfig, "Unable to trust the workspace at %s: %s", root, err.Error())
+	}
+
+	srcPath, _ := getWorkspaceSrcPath()
+	if err := os.MkdirAll(srcPath, 0775); err != nil {
+		return cli.NewExitError(color.RedString("Unable to create %s: %s", srcPath, err.Error()), 1)
+	}
+
+	packages := make([]workspacePackage, 0, len(workspace.Packages))
+	declared := make(map[string]bool)
+	for _, target := range workspace.Packages {
+		pkg, err := parseWorkspacePackage(root, target)
+		if err != nil {
+			return err
+		}
+		packages = append(packages, pkg)
+		declared[pkg.name] = true
+	}
+
+	oldCmds := getCommands()
+
+	// Remove what is no longer declared first, its commands may be taken over
+	paths, _ := filepath.Glob(filepath.Join(srcPath, "*"))
+	for _, path := range paths {
+		name := filepath.Base(path)
+		if declared[name] {
+			continue
+		}
+
+		if err := removeWorkspacePackage(name); err != nil {
+			return cli.NewExitError(color.RedString("Unable to remove %s: %s", name, err.Error()), 1)
+		}
+		printInfo(akamai.App.Writer, "Removed %s, which %s no longer lists", name, workspaceFile)
+	}
+
+	opts := installOptions{
+		forceBinary:   c.Bool("force"),
+		acceptLicense: c.Bool("accept-license"),
+		insecure:      c.Bool("insecure"),
+		srcPath:       srcPath,
+	}
+
+	for _, pkg := range packages {
+		if isWorkspacePackage(pkg.name) {
+			// Local packages are snapshots, they are copied again
+			manifest, _ := readManifest(pkg.name)
+			if manifest.Version == pkg.version && manifest.Source == "" {
+				logDebug("workspace package up to date", "package", pkg.name, "version", pkg.version)
+				continue
+			}
+
+			if err := removeWorkspacePackage(pkg.name); err != nil {
+				return cli.NewExitError(color.RedString("Unable to remove %s: %s", pkg.name, err.Error()), 1)
+			}
+		}
+
+		opts.version = pkg.version
+		if err := installTarget(pkg.target, opts); err != nil {
+			return err
+		}
+	}
+
+	packageListDiff(oldCmds)
+
+	return nil
+}
+
+// parseWorkspacePackage returns what to install for a target of the workspace
+// file in root. Local paths are relative to root.
+func parseWorkspacePackage(root string, target string) (workspacePackage, error) {
+	if isLocalInstallTarget(target) {
+		path := target
+		if !filepath.IsAbs(path) && !strings.HasPrefix(path, "~") {
+			path = filepath.Join(root, path)
+		}
+
+		return workspacePackage{target: path, name: getLocalPackageName(path)}, nil
+	}
+
+	target, version := parseInstallVersion(target)
+	repo, subpath, err := parseInstallTarget(target)
+	if err != nil {
+		return workspacePackage{}, err
+	}
+
+	return workspacePackage{target: target, name: getPackageDirName(githubize(repo), subpath), version: version}, nil
+}
+
+// removeWorkspacePackage uninstalls the workspace package installed in name
+func removeWorkspacePackage(name string) error {
+	srcPath, ok := getWorkspaceSrcPath()
+	if !ok {
+		return fmt.Errorf("not in a workspace")
+	}
+
+	// The manifest is found while the package is still there
+	removeManifest(name)
+	logInfo("removing workspace package", "package", name)
+
+	return removeAllForce(filepath.Join(srcPath, name))
+}
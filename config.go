@@ -160,6 +160,15 @@ func getConfigValue(sectionName string, keyName string) string {
 	return ""
 }
 
+func getConfigSectionValues(sectionName string) map[string]string {
+	config, err := openConfig()
+	if err != nil {
+		return nil
+	}
+
+	return config.Section(sectionName).KeysHash()
+}
+
 func setConfigValue(sectionName string, key string, value string) {
 	config, err := openConfig()
 	if err != nil {
@@ -0,0 +1,197 @@
+/*
+ Copyright 2018. Akamai Technologies, Inc
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	akamai "github.com/akamai/cli-common-golang"
+	"github.com/fatih/color"
+)
+
+// packageEnv restricts installed commands to those of one package, set by
+// "akamai <package>/<command>" and --package
+const packageEnv = "AKAMAI_CLI_PACKAGE"
+
+// packagePriorityKey is the [cli] setting listing, in order, the packages
+// whose commands win when several packages provide the same command
+const packagePriorityKey = "package-priority"
+
+// normalizePackageName lower-cases a package name and removes its cli- prefix
+func normalizePackageName(name string) string {
+	return strings.TrimPrefix(strings.ToLower(strings.TrimSpace(name)), "cli-")
+}
+
+func getPackagePriority() []string {
+	var priority []string
+	for _, name := range strings.Split(getConfigValue("cli", packagePriorityKey), ",") {
+		if name = normalizePackageName(name); name != "" {
+			priority = append(priority, name)
+		}
+	}
+
+	return priority
+}
+
+// prioritizePackageDirs orders package directories so that the packages in
+// priority come first, in that order, followed by the rest as they were. If
+// only is set, just the directories of that package are returned.
+func prioritizePackageDirs(dirs []string, priority []string, only string) []string {
+	rank := func(dir string) int {
+		name := normalizePackageName(filepath.Base(getPackageRoot(dir)))
+		for i, pkg := range priority {
+			if pkg == name {
+				return i
+			}
+		}
+		return len(priority)
+	}
+
+	var ordered []string
+	for _, dir := range dirs {
+		if only == "" || normalizePackageName(filepath.Base(getPackageRoot(dir))) == normalizePackageName(only) {
+			ordered = append(ordered, dir)
+		}
+	}
+
+	sort.SliceStable(ordered, func(i, j int) bool {
+		return rank(ordered[i]) < rank(ordered[j])
+	})
+
+	return ordered
+}
+
+// findCommandConflicts maps each command provided by more than one package to
+// the names of those packages
+func findCommandConflicts(packages map[string]commandPackage) map[string][]string {
+	providers := make(map[string][]string)
+	for name, cmdPackage := range packages {
+		for _, command := range cmdPackage.Commands {
+			command := strings.ToLower(command.Name)
+			providers[command] = append(providers[command], name)
+		}
+	}
+
+	conflicts := make(map[string][]string)
+	for command, names := range providers {
+		if len(names) > 1 {
+			sort.Strings(names)
+			conflicts[command] = names
+		}
+	}
+
+	return conflicts
+}
+
+// getInstalledCommandPackages reads the cli.json of every enabled package,
+// keyed by package directory name
+func getInstalledCommandPackages() map[string]commandPackage {
+	packages := make(map[string]commandPackage)
+	for _, dir := range getPackageDirs() {
+		if cmdPackage, err := readPackage(dir); err == nil {
+			packages[filepath.Base(getPackageRoot(dir))] = cmdPackage
+		}
+	}
+
+	return packages
+}
+
+// warnCommandConflicts warns about the commands of a newly installed package
+// that other packages already provide, and how to run each of them
+func warnCommandConflicts(dirName string) {
+	conflicts := findCommandConflicts(getInstalledCommandPackages())
+
+	var commands []string
+	for command, names := range conflicts {
+		for _, name := range names {
+			if name == dirName {
+				commands = append(commands, command)
+				break
+			}
+		}
+	}
+	sort.Strings(commands)
+
+	for _, command := range commands {
+		var others []string
+		for _, name := range conflicts[command] {
+			if name != dirName {
+				others = append(others, name)
+			}
+		}
+
+		fmt.Fprintln(akamai.App.ErrWriter, color.YellowString(
+			"Warning: command \"%s\" is also provided by %s. Run a specific one with \"%s %s/%s\", or choose which runs by default with \"%s config set cli.%s %s\"",
+			command, strings.Join(others, ", "), self(), normalizePackageName(dirName), command, self(), packagePriorityKey, normalizePackageName(dirName),
+		))
+	}
+}
+
+// expandNamespace rewrites "<package>/<command>" and "--package <package> <command>"
+// in args (os.Args without the program name) to run the command from that
+// package with "exec", passing the remaining arguments as they are. Arguments
+// that do not name an installed package are returned unchanged.
+func expandNamespace(args []string, packages []string) []string {
+	isPackage := func(name string) bool {
+		for _, pkg := range packages {
+			if normalizePackageName(pkg) == normalizePackageName(name) {
+				return true
+			}
+		}
+		return false
+	}
+
+	var globals []string
+	pkg := ""
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		if strings.HasPrefix(arg, "-") {
+			switch {
+			case strings.TrimLeft(arg, "-") == "package" && i+1 < len(args):
+				pkg = args[i+1]
+				i++
+			case strings.HasPrefix(strings.TrimLeft(arg, "-"), "package="):
+				pkg = strings.SplitN(arg, "=", 2)[1]
+			case globalFlagsWithValues[strings.TrimLeft(arg, "-")] && i+1 < len(args):
+				globals = append(globals, arg, args[i+1])
+				i++
+			default:
+				globals = append(globals, arg)
+			}
+			continue
+		}
+
+		command := arg
+		if parts := strings.SplitN(arg, "/", 2); len(parts) == 2 && parts[1] != "" && isPackage(parts[0]) {
+			pkg, command = parts[0], parts[1]
+		}
+
+		if pkg == "" {
+			return args
+		}
+
+		os.Setenv(packageEnv, normalizePackageName(pkg))
+		result := append(globals, "exec", command, "--")
+		return append(result, args[i+1:]...)
+	}
+
+	return args
+}
@@ -0,0 +1,86 @@
+/*
+ Copyright 2018. Akamai Technologies, Inc
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package main
+
+import (
+	"os"
+	"reflect"
+	"testing"
+)
+
+func TestFindCommandConflicts(t *testing.T) {
+	packages := map[string]commandPackage{
+		"cli-property":  {Commands: []Command{{Name: "property"}, {Name: "list"}}},
+		"cli-purge":     {Commands: []Command{{Name: "purge"}}},
+		"my-properties": {Commands: []Command{{Name: "List"}}},
+	}
+
+	conflicts := findCommandConflicts(packages)
+	expected := map[string][]string{"list": {"cli-property", "my-properties"}}
+	if !reflect.DeepEqual(conflicts, expected) {
+		t.Errorf("findCommandConflicts() => %v, wanted: %v", conflicts, expected)
+	}
+}
+
+func TestPrioritizePackageDirs(t *testing.T) {
+	dirs := []string{"/tmp/src/cli-a", "/tmp/src/cli-b", "/tmp/src/c", "/tmp/src/cli-d"}
+
+	prioritizeTests := []struct {
+		priority []string
+		only     string
+		expected []string
+	}{
+		{nil, "", dirs},
+		{[]string{"d", "b"}, "", []string{"/tmp/src/cli-d", "/tmp/src/cli-b", "/tmp/src/cli-a", "/tmp/src/c"}},
+		{[]string{"missing"}, "", dirs},
+		{nil, "cli-b", []string{"/tmp/src/cli-b"}},
+		{nil, "C", []string{"/tmp/src/c"}},
+	}
+
+	for _, tt := range prioritizeTests {
+		if ordered := prioritizePackageDirs(dirs, tt.priority, tt.only); !reflect.DeepEqual(ordered, tt.expected) {
+			t.Errorf("prioritizePackageDirs(%v, %s) => %v, wanted: %v", tt.priority, tt.only, ordered, tt.expected)
+		}
+	}
+}
+
+func TestExpandNamespace(t *testing.T) {
+	defer os.Unsetenv(packageEnv)
+	packages := []string{"cli-property", "my-tools"}
+
+	namespaceTests := []struct {
+		args     []string
+		expected []string
+		pkg      string
+	}{
+		{[]string{"property", "list"}, []string{"property", "list"}, ""},
+		{[]string{"property/list", "--json"}, []string{"exec", "list", "--", "--json"}, "property"},
+		{[]string{"--format", "json", "my-tools/purge"}, []string{"--format", "json", "exec", "purge", "--"}, "my-tools"},
+		{[]string{"--package", "cli-property", "list", "-v"}, []string{"exec", "list", "--", "-v"}, "property"},
+		{[]string{"--package=property", "list"}, []string{"exec", "list", "--"}, "property"},
+		{[]string{"install", "akamai/cli-property"}, []string{"install", "akamai/cli-property"}, ""},
+		{[]string{"unknown/list"}, []string{"unknown/list"}, ""},
+	}
+
+	for _, tt := range namespaceTests {
+		os.Unsetenv(packageEnv)
+		args := expandNamespace(tt.args, packages)
+		if !reflect.DeepEqual(args, tt.expected) || os.Getenv(packageEnv) != tt.pkg {
+			t.Errorf("expandNamespace(%v) => %v (package: %s), wanted: %v (package: %s)", tt.args, args, os.Getenv(packageEnv), tt.expected, tt.pkg)
+		}
+	}
+}
@@ -0,0 +1,143 @@
+/*
+ Copyright 2018. Akamai Technologies, Inc
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package main
+
+import (
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	akamai "github.com/akamai/cli-common-golang"
+	"github.com/fatih/color"
+	"github.com/urfave/cli"
+)
+
+// requiredPackagesLock serializes installing the packages other packages depend on,
+// so that concurrent installs don't both try to install a shared dependency
+var requiredPackagesLock sync.Mutex
+
+// installRequiredPackages installs the Akamai CLI packages that the package
+// in dir declares in the dependencies of its cli.json, and theirs in turn.
+// Dependencies map package names to semver ranges, e.g. "property": "^1.2.0".
+func installRequiredPackages(dir string, opts installOptions) error {
+	cmdPackage, err := readPackage(dir)
+	if err != nil || len(cmdPackage.Dependencies) == 0 {
+		return nil
+	}
+
+	name := strings.TrimPrefix(filepath.Base(dir), "cli-")
+	if len(opts.requiredBy) == 0 {
+		requiredPackagesLock.Lock()
+		defer requiredPackagesLock.Unlock()
+	}
+	chain := append(append([]string(nil), opts.requiredBy...), name)
+
+	list, err := fetchPackageList(fetchOptions{})
+	if err != nil {
+		// Without a package list, dependencies can still be installed from Github
+		list = &packageList{}
+	}
+
+	names := make([]string, 0, len(cmdPackage.Dependencies))
+	for dependency := range cmdPackage.Dependencies {
+		names = append(names, dependency)
+	}
+	sort.Strings(names)
+
+	for _, dependency := range names {
+		constraint := cmdPackage.Dependencies[dependency]
+		dependency = strings.TrimPrefix(dependency, "cli-")
+
+		for _, required := range chain {
+			if required == dependency {
+				return cli.NewExitError(color.RedString("Dependency cycle detected: %s -> %s", strings.Join(chain, " -> "), dependency), 1)
+			}
+		}
+
+		pkg, listed := list.findPackage(dependency)
+		if version, installed := getInstalledDependencyVersion(dependency, pkg, listed); installed {
+			if !versionSatisfies(version, constraint) {
+				return cli.NewExitError(color.RedString("Version conflict: %s requires %s %s, but version %s is installed", name, dependency, constraint, version), 1)
+			}
+			continue
+		}
+
+		depOpts := opts
+		depOpts.version = ""
+		depOpts.release = nil
+		depOpts.requiredBy = chain
+		target := dependency
+
+		if listed {
+			version, ok := findSatisfyingVersion(pkg, constraint)
+			if !ok {
+				return cli.NewExitError(color.RedString("Version conflict: %s requires %s %s, but no such version is available", name, dependency, constraint), 1)
+			}
+
+			// The latest version is installed from the default branch, like any other install
+			if version != pkg.Version {
+				depOpts.version = version
+			}
+			depOpts = depOpts.forPackage(pkg)
+			target = pkg.getInstallTarget()
+		}
+
+		printInfo(akamai.App.ErrWriter, "Installing %s %s, required by %s", dependency, constraint, name)
+		if err := installTarget(target, depOpts); err != nil {
+			return err
+		}
+
+		if version, installed := getInstalledDependencyVersion(dependency, pkg, listed); installed && !versionSatisfies(version, constraint) {
+			return cli.NewExitError(color.RedString("Version conflict: %s requires %s %s, but version %s was installed", name, dependency, constraint, version), 1)
+		}
+	}
+
+	return nil
+}
+
+func getInstalledDependencyVersion(dependency string, pkg packageListPackage, listed bool) (string, bool) {
+	installed := getInstalledCommandVersions()
+	if listed {
+		return getInstalledPackageVersion(pkg, installed)
+	}
+
+	version, ok := installed[strings.ToLower(dependency)]
+	return version, ok
+}
+
+// findSatisfyingVersion returns the newest version of a registry package,
+// out of its latest version and published releases, that satisfies constraint
+func findSatisfyingVersion(pkg packageListPackage, constraint string) (string, bool) {
+	candidates := []string{pkg.Version}
+	for _, release := range pkg.Releases {
+		candidates = append(candidates, release.Version)
+	}
+
+	best := ""
+	for _, candidate := range candidates {
+		if !versionSatisfies(candidate, constraint) {
+			continue
+		}
+
+		if best == "" || versionCompare(strings.TrimPrefix(best, "v"), strings.TrimPrefix(candidate, "v")) == 1 {
+			best = candidate
+		}
+	}
+
+	return best, best != ""
+}
@@ -0,0 +1,50 @@
+/*
+ Copyright 2018. Akamai Technologies, Inc
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package main
+
+import "testing"
+
+func TestFindSatisfyingVersion(t *testing.T) {
+	pkg := packageListPackage{
+		Version: "2.1.0",
+		Releases: []packageRelease{
+			{Version: "1.0.0"},
+			{Version: "v1.4.2"},
+			{Version: "1.3.0"},
+			{Version: "2.1.0"},
+		},
+	}
+
+	versionTests := []struct {
+		constraint string
+		version    string
+		found      bool
+	}{
+		{"", "2.1.0", true},
+		{"^1.0.0", "v1.4.2", true},
+		{"~1.3", "1.3.0", true},
+		{">=2", "2.1.0", true},
+		{"^3.0.0", "", false},
+	}
+
+	for _, tt := range versionTests {
+		version, ok := findSatisfyingVersion(pkg, tt.constraint)
+		if version != tt.version || ok != tt.found {
+			t.Errorf("findSatisfyingVersion(%s) => %s, %t, wanted: %s, %t", tt.constraint, version, ok, tt.version, tt.found)
+		}
+	}
+}
@@ -0,0 +1,178 @@
+/*
+ Copyright 2018. Akamai Technologies, Inc
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	akamai "github.com/akamai/cli-common-golang"
+	"github.com/fatih/color"
+	"github.com/mattn/go-isatty"
+	"github.com/urfave/cli"
+)
+
+// deprecationNotice describes a deprecated registry package and what to use
+// instead, or returns "" if it is not deprecated
+func (pkg packageListPackage) deprecationNotice() string {
+	if !pkg.Deprecated {
+		return ""
+	}
+
+	if pkg.Replacement != "" {
+		return fmt.Sprintf("Package %s is deprecated, use %s instead", pkg.Name, pkg.Replacement)
+	}
+
+	return fmt.Sprintf("Package %s is deprecated", pkg.Name)
+}
+
+// readCachedPackageList merges the cached package lists of all registries,
+// however old, without any network access. It returns nil if none are cached.
+func readCachedPackageList() *packageList {
+	var merged *packageList
+	seen := make(map[string]bool)
+	for _, registry := range getRegistryURLs() {
+		data, _, err := readPackageListCache(registry)
+		if err != nil {
+			continue
+		}
+
+		list := &packageList{}
+		if err := json.Unmarshal(data, list); err != nil {
+			continue
+		}
+
+		if merged == nil {
+			merged = &packageList{}
+		}
+		for _, pkg := range list.Packages {
+			if !seen[pkg.Name] {
+				seen[pkg.Name] = true
+				merged.Packages = append(merged.Packages, pkg)
+			}
+		}
+	}
+
+	return merged
+}
+
+// getDeprecatedCommands maps the commands of deprecated packages to their
+// deprecation notices
+func getDeprecatedCommands(list *packageList) map[string]string {
+	deprecated := make(map[string]string)
+	if list == nil {
+		return deprecated
+	}
+
+	for _, pkg := range list.Packages {
+		if notice := pkg.deprecationNotice(); notice != "" {
+			for _, cmd := range pkg.Commands {
+				deprecated[strings.ToLower(cmd.Name)] = notice
+			}
+		}
+	}
+
+	return deprecated
+}
+
+// warnIfDeprecated warns before running a command of a package the cached
+// package list says is deprecated
+func warnIfDeprecated(packageDir string) {
+	if packageDir == "" {
+		return
+	}
+
+	list := readCachedPackageList()
+	if list == nil {
+		return
+	}
+
+	if pkg, ok := list.findPackage(normalizePackageName(filepath.Base(getPackageRoot(packageDir)))); ok {
+		if notice := pkg.deprecationNotice(); notice != "" {
+			fmt.Fprintln(akamai.App.ErrWriter, color.YellowString("Warning: %s. See \"%s update --migrate\".", notice, self()))
+		}
+	}
+}
+
+// cmdUpdateMigrate offers to install the replacements of installed packages
+// that are deprecated
+func cmdUpdateMigrate(c *cli.Context) error {
+	packageList, err := fetchPackageList(fetchOptions{})
+	if err != nil {
+		return cli.NewExitError(color.RedString(err.Error()), 1)
+	}
+
+	interactive := isatty.IsTerminal(os.Stdin.Fd()) && isatty.IsTerminal(os.Stdout.Fd())
+
+	found := 0
+	for _, dir := range getPackageDirs() {
+		dirName := filepath.Base(getPackageRoot(dir))
+		pkg, ok := packageList.findPackage(normalizePackageName(dirName))
+		if !ok || !pkg.Deprecated {
+			continue
+		}
+		found++
+
+		// "akamai uninstall" takes a command of the package
+		uninstallName := normalizePackageName(dirName)
+		if cmdPackage, err := readPackage(dir); err == nil && len(cmdPackage.Commands) > 0 {
+			uninstallName = cmdPackage.Commands[0].Name
+		}
+
+		fmt.Fprintln(akamai.App.Writer, color.YellowString(pkg.deprecationNotice()))
+		if pkg.Replacement == "" {
+			continue
+		}
+
+		replacement, ok := packageList.findPackage(pkg.Replacement)
+		if !ok {
+			fmt.Fprintln(akamai.App.ErrWriter, color.RedString("Replacement package \"%s\" was not found in the package list", pkg.Replacement))
+			continue
+		}
+
+		if isPackageInstalled("cli-"+replacement.Name) || isPackageInstalled(replacement.Name) {
+			fmt.Fprintf(akamai.App.Writer, "  %s is already installed, uninstall %s with \"%s uninstall %s\"\n", replacement.Name, dirName, self(), uninstallName)
+			continue
+		}
+
+		if !interactive {
+			fmt.Fprintf(akamai.App.Writer, "  Install it with \"%s install %s\"\n", self(), replacement.Name)
+			continue
+		}
+
+		fmt.Fprintf(akamai.App.Writer, "Install %s? [y/N]: ", replacement.Name)
+		answer := ""
+		fmt.Scanln(&answer)
+		if strings.ToLower(answer) != "y" {
+			continue
+		}
+
+		if err := installRegistryPackages([]packageListPackage{replacement}, installOptions{}); err != nil {
+			return err
+		}
+		fmt.Fprintf(akamai.App.Writer, "Uninstall %s when you no longer need it, with \"%s uninstall %s\"\n", dirName, self(), uninstallName)
+	}
+
+	if found == 0 {
+		printInfo(akamai.App.Writer, "No installed packages are deprecated")
+	}
+
+	return nil
+}
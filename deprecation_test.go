@@ -0,0 +1,55 @@
+/*
+ Copyright 2018. Akamai Technologies, Inc
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package main
+
+import "testing"
+
+func TestDeprecationNotice(t *testing.T) {
+	noticeTests := []struct {
+		pkg    packageListPackage
+		notice string
+	}{
+		{packageListPackage{Name: "purge"}, ""},
+		{packageListPackage{Name: "purge", Replacement: "fast-purge"}, ""},
+		{packageListPackage{Name: "purge", Deprecated: true}, "Package purge is deprecated"},
+		{packageListPackage{Name: "purge", Deprecated: true, Replacement: "fast-purge"}, "Package purge is deprecated, use fast-purge instead"},
+	}
+
+	for _, tt := range noticeTests {
+		if notice := tt.pkg.deprecationNotice(); notice != tt.notice {
+			t.Errorf("deprecationNotice(%+v) => %q, wanted: %q", tt.pkg, notice, tt.notice)
+		}
+	}
+}
+
+func TestGetDeprecatedCommands(t *testing.T) {
+	list := &packageList{
+		Packages: []packageListPackage{
+			{Name: "purge", Deprecated: true, Replacement: "fast-purge", Commands: []Command{{Name: "Purge"}}},
+			{Name: "fast-purge", Commands: []Command{{Name: "fast-purge"}}},
+		},
+	}
+
+	deprecated := getDeprecatedCommands(list)
+	if len(deprecated) != 1 || deprecated["purge"] != "Package purge is deprecated, use fast-purge instead" {
+		t.Errorf("getDeprecatedCommands() => %v, wanted only purge", deprecated)
+	}
+
+	if deprecated := getDeprecatedCommands(nil); len(deprecated) != 0 {
+		t.Errorf("getDeprecatedCommands(nil) => %v, wanted none", deprecated)
+	}
+}
@@ -0,0 +1,300 @@
+/*
+ Copyright 2018. Akamai Technologies, Inc
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/mattn/go-isatty"
+	"github.com/mitchellh/go-homedir"
+	"github.com/urfave/cli"
+)
+
+// Packages run locally by default, with the language runtimes installed on
+// the machine. With "akamai config set <package>.runtime docker" (or
+// cli.runtime, for all packages) they are built and run in a container
+// instead, so only Docker needs to be installed.
+
+const (
+	runtimeLocal  = "local"
+	runtimeDocker = "docker"
+
+	// defaultDockerImage is the official Akamai CLI image, which has the runtimes of every package language
+	defaultDockerImage = "akamaiopen/cli"
+
+	// dockerPackagePath is where the package checkout is mounted in the container
+	dockerPackagePath = "/cli/package"
+	// dockerWorkdir is where the current directory is mounted, and commands run from
+	dockerWorkdir = "/workdir"
+	// dockerEdgerc is where the credentials file is mounted, read-only
+	dockerEdgerc = "/root/.edgerc"
+)
+
+// packageDocker is the "docker" object of cli.json
+type packageDocker struct {
+	// Image is the image to run the package in, instead of defaultDockerImage
+	Image string `json:"image"`
+}
+
+// dockerEnvPrefixes are the environment variables passed into the container,
+// along with those set for the package and by hooks
+var dockerEnvPrefixes = []string{"AKAMAI_", "HTTP_PROXY", "HTTPS_PROXY", "NO_PROXY", "http_proxy", "https_proxy", "no_proxy"}
+
+// getPackageRuntime returns where the commands of the package in packageDir
+// run: locally, or in Docker
+func getPackageRuntime(packageDir string, cmd string) (string, error) {
+	runtime := strings.ToLower(getConfigValue("cli", "runtime"))
+	for _, section := range getPackageConfigSections(packageDir, cmd) {
+		if value := getConfigValue(section, "runtime"); value != "" {
+			runtime = strings.ToLower(value)
+		}
+	}
+
+	switch runtime {
+	case "", runtimeLocal:
+		return runtimeLocal, nil
+	case runtimeDocker:
+		return runtimeDocker, nil
+	}
+
+	return runtimeLocal, fmt.Errorf("Invalid runtime \"%s\", must be local or docker", runtime)
+}
+
+// usesDocker returns whether the package in packageDir, or its command cmd if
+// given, is built and run in Docker
+func usesDocker(packageDir string, cmd string) bool {
+	runtime, _ := getPackageRuntime(packageDir, cmd)
+	return runtime == runtimeDocker
+}
+
+// getDockerImage returns the image the package runs in
+func getDockerImage(cmdPackage commandPackage) string {
+	if cmdPackage.Docker.Image != "" {
+		return cmdPackage.Docker.Image
+	}
+
+	if image := getConfigValue("cli", "docker-image"); image != "" {
+		return image
+	}
+
+	return defaultDockerImage
+}
+
+// getDockerExec returns the docker command line that runs cmd of the package
+// in packageDir in a container, env names extra variables to pass into it
+func getDockerExec(cmd string, packageDir string, cmdPackage commandPackage, env []string) ([]string, error) {
+	docker, err := exec.LookPath("docker")
+	if err != nil {
+		return nil, fmt.Errorf("Docker is required to run packages with runtime docker, but it was not found")
+	}
+
+	cmdFile, compiled, err := findCommandFile(cmd, filepath.SplitList(getBinPaths([]string{packageDir})))
+	if err != nil {
+		return nil, err
+	}
+
+	root := getPackageRoot(packageDir)
+	executable := []string{toContainerPath(root, cmdFile)}
+	if interpreter := getContainerInterpreter(cmdPackage); !compiled && interpreter != "" {
+		executable = append([]string{interpreter}, executable...)
+	}
+
+	args := newDockerRunArgs(root, packageDir, cmdPackage, env)
+	args = append(args, "--entrypoint", executable[0], getDockerImage(cmdPackage))
+
+	return append(append([]string{docker}, args...), executable[1:]...), nil
+}
+
+// newDockerRunArgs returns the arguments of docker run for a package: its
+// checkout, the current directory, and the credentials file are mounted, and
+// the environment passed on
+func newDockerRunArgs(root string, packageDir string, cmdPackage commandPackage, env []string) []string {
+	args := []string{"run", "--rm", "-i"}
+	if isatty.IsTerminal(os.Stdin.Fd()) && isatty.IsTerminal(os.Stdout.Fd()) {
+		args = append(args, "-t")
+	}
+
+	args = append(args, "-v", root+":"+dockerPackagePath)
+	if cwd, err := os.Getwd(); err == nil {
+		args = append(args, "-v", cwd+":"+dockerWorkdir, "-w", dockerWorkdir)
+	}
+
+	edgerc := os.Getenv(edgercEnv)
+	if edgerc == "" {
+		edgerc = defaultEdgerc
+	}
+	if edgercPath, err := homedir.Expand(edgerc); err == nil {
+		if _, err := os.Stat(edgercPath); err == nil {
+			args = append(args, "-v", edgercPath+":"+dockerEdgerc+":ro", "-e", edgercEnv+"="+dockerEdgerc)
+		}
+	}
+
+	if cmdPackage.Requirements.Python != "" {
+		args = append(args, "-e", "PYTHONUSERBASE="+toContainerPath(root, packageDir))
+	}
+
+	for _, name := range getDockerEnvNames(os.Environ(), env) {
+		args = append(args, "-e", name)
+	}
+
+	return args
+}
+
+// getDockerEnvNames returns the names of the variables in environ to pass into
+// a container: those with dockerEnvPrefixes, and extra, sorted
+func getDockerEnvNames(environ []string, extra []string) []string {
+	seen := make(map[string]bool)
+	for _, name := range extra {
+		seen[name] = true
+	}
+
+	for _, variable := range environ {
+		name := strings.SplitN(variable, "=", 2)[0]
+		for _, prefix := range dockerEnvPrefixes {
+			if strings.HasPrefix(name, prefix) {
+				seen[name] = true
+			}
+		}
+	}
+
+	// Set to the path of the credentials file in the container
+	delete(seen, edgercEnv)
+
+	names := make([]string, 0, len(seen))
+	for name := range seen {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+// getPassedEnvNames returns the names of the variables set for a package's
+// commands in the config, and by hooks, to pass into its container
+func getPassedEnvNames(packageDir string, cmd string, hookEnv map[string]string) []string {
+	var names []string
+	for _, section := range getPackageConfigSections(packageDir, cmd) {
+		for name := range getPackageEnv(getConfigSectionValues(section)) {
+			names = append(names, name)
+		}
+	}
+
+	for name := range hookEnv {
+		names = append(names, name)
+	}
+
+	return names
+}
+
+// toContainerPath returns where path, inside the package checkout at root, is in the container
+func toContainerPath(root string, hostPath string) string {
+	rel, err := filepath.Rel(root, hostPath)
+	if err != nil {
+		return dockerPackagePath
+	}
+
+	return path.Join(dockerPackagePath, filepath.ToSlash(rel))
+}
+
+// getContainerInterpreter returns the interpreter of the package's scripts in
+// the container, or "" for compiled packages
+func getContainerInterpreter(cmdPackage commandPackage) string {
+	switch language := determineCommandLanguage(cmdPackage); language {
+	case "go", "c#", "csharp":
+		return ""
+	case "javascript":
+		return "node"
+	case "python":
+		if strings.HasPrefix(cmdPackage.Requirements.Python, "2") {
+			return "python2"
+		}
+		return "python3"
+	default:
+		return language
+	}
+}
+
+// getContainerBuildCommand returns the shell command that installs the
+// package's dependencies in the container, or "" if it has none
+func getContainerBuildCommand(packageDir string, cmdPackage commandPackage) string {
+	exists := func(name string) bool {
+		_, err := os.Stat(filepath.Join(packageDir, name))
+		return err == nil
+	}
+
+	switch determineCommandLanguage(cmdPackage) {
+	case "javascript":
+		if exists("package.json") {
+			return "npm install"
+		}
+	case "python":
+		if exists("requirements.txt") {
+			pip := "pip3"
+			if strings.HasPrefix(cmdPackage.Requirements.Python, "2") {
+				pip = "pip2"
+			}
+			return pip + " install --user --ignore-installed -r requirements.txt"
+		}
+	case "php":
+		if exists("composer.json") {
+			return "composer install"
+		}
+	case "ruby":
+		if exists("Gemfile") {
+			return "bundle install --path vendor/bundle"
+		}
+	case "go":
+		execName := "akamai-" + strings.ToLower(strings.TrimPrefix(strings.TrimPrefix(filepath.Base(packageDir), "akamai-"), "cli-"))
+		return "go build -o " + execName + " ."
+	}
+
+	return ""
+}
+
+// installDocker installs the dependencies of the package in dir in a container,
+// so that the language runtime is not needed locally
+func installDocker(dir string, cmdPackage commandPackage, p progress) (bool, error) {
+	docker, err := exec.LookPath("docker")
+	if err != nil {
+		return false, cli.NewExitError("Unable to locate Docker, which packages with runtime docker need", 1)
+	}
+
+	build := getContainerBuildCommand(dir, cmdPackage)
+	if build == "" {
+		return true, nil
+	}
+
+	root := getPackageRoot(dir)
+	args := []string{"run", "--rm", "-v", root + ":" + dockerPackagePath, "-w", toContainerPath(root, dir)}
+	if cmdPackage.Requirements.Python != "" {
+		args = append(args, "-e", "PYTHONUSERBASE="+toContainerPath(root, dir))
+	}
+	args = append(args, "--entrypoint", "sh", getDockerImage(cmdPackage), "-c", build)
+
+	cmd := exec.Command(docker, args...)
+	if err := runBuildCommand(cmd, p); err != nil {
+		return false, cli.NewExitError(err.Error(), 1)
+	}
+
+	return true, nil
+}
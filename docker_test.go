@@ -0,0 +1,76 @@
+/*
+ Copyright 2018. Akamai Technologies, Inc
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package main
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestGetDockerEnvNames(t *testing.T) {
+	environ := []string{
+		"AKAMAI_EDGERC=/home/user/.edgerc",
+		"AKAMAI_EDGERC_SECTION=papi",
+		"AKAMAI_CLI_PROFILE=prod",
+		"HTTPS_PROXY=http://proxy:3128",
+		"HOME=/home/user",
+		"PATH=/usr/bin",
+	}
+
+	expected := []string{"AKAMAI_CLI_PROFILE", "AKAMAI_EDGERC_SECTION", "HTTPS_PROXY", "PAPI_DEBUG"}
+	if names := getDockerEnvNames(environ, []string{"PAPI_DEBUG"}); !reflect.DeepEqual(names, expected) {
+		t.Errorf("getDockerEnvNames() => %v, wanted: %v", names, expected)
+	}
+}
+
+func TestToContainerPath(t *testing.T) {
+	root := filepath.Join("home", "user", ".akamai-cli", "src", "cli-purge")
+	pathTests := []struct {
+		path      string
+		container string
+	}{
+		{root, "/cli/package"},
+		{filepath.Join(root, "bin", "akamai-purge"), "/cli/package/bin/akamai-purge"},
+		{filepath.Join(root, "tools", "akamai-purge.py"), "/cli/package/tools/akamai-purge.py"},
+	}
+
+	for _, tt := range pathTests {
+		if container := toContainerPath(root, tt.path); container != tt.container {
+			t.Errorf("toContainerPath(%s) => %s, wanted: %s", tt.path, container, tt.container)
+		}
+	}
+}
+
+func TestGetContainerInterpreter(t *testing.T) {
+	interpreterTests := []struct {
+		requirements packageRequirements
+		interpreter  string
+	}{
+		{packageRequirements{Go: "1.8.0"}, ""},
+		{packageRequirements{Node: "7.0.0"}, "node"},
+		{packageRequirements{Python: "3.0.0"}, "python3"},
+		{packageRequirements{Python: "2.7.10"}, "python2"},
+		{packageRequirements{Ruby: "2.0.0"}, "ruby"},
+	}
+
+	for _, tt := range interpreterTests {
+		if interpreter := getContainerInterpreter(commandPackage{Requirements: tt.requirements}); interpreter != tt.interpreter {
+			t.Errorf("getContainerInterpreter(%+v) => %s, wanted: %s", tt.requirements, interpreter, tt.interpreter)
+		}
+	}
+}
@@ -0,0 +1,162 @@
+/*
+ Copyright 2018. Akamai Technologies, Inc
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package main
+
+import (
+	"github.com/fatih/color"
+	"github.com/urfave/cli"
+	"gopkg.in/src-d/go-git.v4/plumbing/transport"
+)
+
+// Error identifiers tell scripts why a command failed. They, and the exit
+// codes that go with them, are part of the CLI's interface: never change or
+// reuse one, only add new ones.
+const (
+	errGeneral             = "E_GENERAL"
+	errUpdatesAvailable    = "E_UPDATES_AVAILABLE"
+	errUsage               = "E_USAGE"
+	errPackageNotFound     = "E_PACKAGE_NOT_FOUND"
+	errVersionNotFound     = "E_VERSION_NOT_FOUND"
+	errRegistryUnreachable = "E_REGISTRY_UNREACHABLE"
+	errBuildFailed         = "E_BUILD_FAILED"
+	errRuntimeMissing      = "E_RUNTIME_MISSING"
+	errDiskSpace           = "E_DISK_SPACE"
+	errGitFailed           = "E_GIT_FAILED"
+	errOffline             = "E_OFFLINE"
+	errVerifyFailed        = "E_VERIFY_FAILED"
+	errLicenseNotAccepted  = "E_LICENSE_NOT_ACCEPTED"
+	errConfig              = "E_CONFIG"
+	errCommandNotFound     = "E_COMMAND_NOT_FOUND"
+	errTimeout             = "E_TIMEOUT"
+	errLocked              = "E_LOCKED"
+	errPackageInvalid      = "E_PACKAGE_INVALID"
+	errInstallFailed       = "E_INSTALL_FAILED"
+	// errCommandFailed is a package command that failed, with its own exit code
+	errCommandFailed = "E_COMMAND_FAILED"
+)
+
+// errorExitCodes are the exit codes of the error identifiers, following
+// sysexits.h where it has one that fits
+var errorExitCodes = map[string]int{
+	errGeneral:             1,
+	errUpdatesAvailable:    2,
+	errUsage:               64,
+	errPackageNotFound:     65,
+	errVersionNotFound:     66,
+	errRegistryUnreachable: 69,
+	errBuildFailed:         70,
+	errRuntimeMissing:      71,
+	errLocked:              72,
+	errDiskSpace:           73,
+	errGitFailed:           74,
+	errOffline:             75,
+	errVerifyFailed:        76,
+	errLicenseNotAccepted:  77,
+	errConfig:              78,
+	errPackageInvalid:      79,
+	errInstallFailed:       80,
+	errTimeout:             124,
+	errCommandNotFound:     127,
+}
+
+// cliError is an error with an identifier. Its exit code is that of the
+// identifier, except for errCommandFailed.
+type cliError struct {
+	id       string
+	exitCode int
+	message  string
+}
+
+func (e *cliError) Error() string {
+	return e.message
+}
+
+func (e *cliError) ExitCode() int {
+	return e.exitCode
+}
+
+// newError returns an error identified by id, with its message in red like
+// other errors. An empty format is an error that was already reported.
+func newError(id string, format string, a ...interface{}) error {
+	message := ""
+	if format != "" {
+		message = color.RedString(format, a...)
+	}
+
+	return &cliError{id: id, exitCode: getErrorExitCode(id), message: message}
+}
+
+// commandFailedError returns the error of a package command that exited with exitCode
+func commandFailedError(exitCode int) error {
+	return &cliError{id: errCommandFailed, exitCode: exitCode}
+}
+
+// combinedError returns an error for several failures, identified like them
+// if they all failed for the same reason
+func combinedError(errs []error, format string, a ...interface{}) error {
+	id := errGeneral
+	for i, err := range errs {
+		if i == 0 {
+			id = getErrorID(err)
+		} else if getErrorID(err) != id {
+			id = errGeneral
+			break
+		}
+	}
+
+	return newError(id, format, a...)
+}
+
+// registryError returns the error for a Package List that could not be fetched
+func registryError(err error) error {
+	if isOffline() {
+		return newError(errOffline, "%s", err.Error())
+	}
+
+	return newError(errRegistryUnreachable, "%s", err.Error())
+}
+
+// getGitErrorID returns the identifier for a failure to fetch a repository
+func getGitErrorID(err error) string {
+	if err == transport.ErrRepositoryNotFound {
+		return errPackageNotFound
+	}
+
+	return errGitFailed
+}
+
+func getErrorExitCode(id string) int {
+	if exitCode, ok := errorExitCodes[id]; ok {
+		return exitCode
+	}
+
+	return 1
+}
+
+// getErrorID returns the identifier of err, errors without one are identified
+// by their exit code
+func getErrorID(err error) string {
+	if cliErr, ok := err.(*cliError); ok {
+		return cliErr.id
+	}
+
+	if exitErr, ok := err.(cli.ExitCoder); ok && exitErr.ExitCode() == errorExitCodes[errUpdatesAvailable] {
+		return errUpdatesAvailable
+	}
+
+	return errGeneral
+}
@@ -0,0 +1,65 @@
+/*
+ Copyright 2018. Akamai Technologies, Inc
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package main
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/urfave/cli"
+	"gopkg.in/src-d/go-git.v4/plumbing/transport"
+)
+
+func TestGetErrorID(t *testing.T) {
+	tests := []struct {
+		err      error
+		id       string
+		exitCode int
+	}{
+		{errors.New("failed"), errGeneral, 1},
+		{cli.NewExitError("failed", 1), errGeneral, 1},
+		{cli.NewExitError("2 package(s) are outdated", 2), errUpdatesAvailable, 2},
+		{newError(errBuildFailed, ""), errBuildFailed, 70},
+		{newError(errRegistryUnreachable, "Unable to fetch the Package List: %s", "timeout"), errRegistryUnreachable, 69},
+		{newError(errCommandNotFound, "Command \"purge\" not found"), errCommandNotFound, 127},
+		{newError(getGitErrorID(transport.ErrRepositoryNotFound), "Unable to clone repository"), errPackageNotFound, 65},
+		{newError(getGitErrorID(errors.New("connection reset")), "Unable to clone repository"), errGitFailed, 74},
+		{commandFailedError(3), errCommandFailed, 3},
+		{combinedError([]error{newError(errBuildFailed, ""), newError(errBuildFailed, "")}, "Unable to install"), errBuildFailed, 70},
+		{combinedError([]error{newError(errBuildFailed, ""), newError(errGitFailed, "")}, "Unable to install"), errGeneral, 1},
+	}
+
+	for _, tt := range tests {
+		if id := getErrorID(tt.err); id != tt.id {
+			t.Errorf("getErrorID(%q) => %s, wanted %s", tt.err, id, tt.id)
+		}
+
+		if exitCode := getExitCode(tt.err); exitCode != tt.exitCode {
+			t.Errorf("getExitCode(%q) => %d, wanted %d", tt.err, exitCode, tt.exitCode)
+		}
+	}
+}
+
+func TestErrorExitCodesAreUnique(t *testing.T) {
+	seen := make(map[int]string)
+	for id, exitCode := range errorExitCodes {
+		if other, ok := seen[exitCode]; ok {
+			t.Errorf("%s and %s both exit with %d", id, other, exitCode)
+		}
+		seen[exitCode] = id
+	}
+}
@@ -0,0 +1,159 @@
+/*
+ Copyright 2018. Akamai Technologies, Inc
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// execTimeoutKey is the config key for how long package commands may run,
+// cli.exec-timeout for all of them, or <package>.exec-timeout for one
+const execTimeoutKey = "exec-timeout"
+
+// defaultExecGracePeriod is how long a command has to exit after being asked
+// to, before it is killed, unless cli.exec-grace-period is set
+const defaultExecGracePeriod = 5 * time.Second
+
+// errExecTimeout is returned by runCommandSupervised for a command that was
+// stopped for running too long
+type errExecTimeout struct {
+	timeout time.Duration
+}
+
+func (e errExecTimeout) Error() string {
+	return fmt.Sprintf("timed out after %s", e.timeout)
+}
+
+// getExecTimeout returns how long the command cmd of the package in packageDir
+// may run, 0 for no limit. Settings for the package or command win over
+// cli.exec-timeout.
+func getExecTimeout(packageDir string, cmd string) (time.Duration, error) {
+	value := getConfigValue("cli", execTimeoutKey)
+	for _, section := range getPackageConfigSections(packageDir, cmd) {
+		if sectionValue := getConfigValue(section, execTimeoutKey); sectionValue != "" {
+			value = sectionValue
+		}
+	}
+
+	return parseExecTimeout(value)
+}
+
+func parseExecTimeout(value string) (time.Duration, error) {
+	switch value = strings.TrimSpace(value); value {
+	case "", "0", "off":
+		return 0, nil
+	}
+
+	timeout, err := time.ParseDuration(value)
+	if err != nil || timeout < 0 {
+		return 0, fmt.Errorf("Invalid %s \"%s\", must be a duration such as 30s or 2h, or 0 for none", execTimeoutKey, value)
+	}
+
+	return timeout, nil
+}
+
+// getExecGracePeriod returns how long a command that is stopped has to exit
+// before it is killed
+func getExecGracePeriod() time.Duration {
+	if value := getConfigValue("cli", "exec-grace-period"); value != "" {
+		if grace, err := time.ParseDuration(value); err == nil && grace >= 0 {
+			return grace
+		}
+	}
+
+	return defaultExecGracePeriod
+}
+
+// runCommandSupervised runs cmd like runCommand, and stops it, and everything
+// it started, once it has run for timeout, if not 0, or when the CLI is
+// interrupted or terminated. It is asked to exit first, and killed if it has
+// not by the end of the grace period.
+func runCommandSupervised(cmd *exec.Cmd, timeout time.Duration) error {
+	setProcessGroup(cmd)
+
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	defer signal.Stop(signals)
+
+	start := time.Now()
+	logDebug("exec start", "cmd", strings.Join(cmd.Args, " "), "dir", cmd.Dir, "timeout", timeout.String())
+	if err := cmd.Start(); err != nil {
+		logWarn("exec failed", "cmd", strings.Join(cmd.Args, " "), "dir", cmd.Dir, "error", err)
+		return err
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		done <- cmd.Wait()
+	}()
+
+	var expired <-chan time.Time
+	if timeout > 0 {
+		timer := time.NewTimer(timeout)
+		defer timer.Stop()
+		expired = timer.C
+	}
+
+	for {
+		select {
+		case err := <-done:
+			if err != nil {
+				logWarn("exec failed", "cmd", strings.Join(cmd.Args, " "), "dir", cmd.Dir, "duration", time.Since(start).String(), "error", err)
+				return err
+			}
+			logDebug("exec done", "cmd", strings.Join(cmd.Args, " "), "dir", cmd.Dir, "duration", time.Since(start).String())
+			return nil
+		case sig := <-signals:
+			if !forwardSignal(cmd, sig) {
+				// It got the interrupt from the terminal too, and may well carry on
+				continue
+			}
+			logInfo("exec stopped", "cmd", strings.Join(cmd.Args, " "), "signal", sig.String())
+			return waitGracePeriod(cmd, done, signals)
+		case <-expired:
+			logWarn("exec timed out", "cmd", strings.Join(cmd.Args, " "), "dir", cmd.Dir, "timeout", timeout.String())
+			terminateProcessGroup(cmd)
+			waitGracePeriod(cmd, done, signals)
+			return errExecTimeout{timeout}
+		}
+	}
+}
+
+// waitGracePeriod waits for a command that was asked to exit, and kills it at
+// the end of the grace period, or straight away if the CLI gets another signal
+func waitGracePeriod(cmd *exec.Cmd, done <-chan error, signals <-chan os.Signal) error {
+	grace := time.NewTimer(getExecGracePeriod())
+	defer grace.Stop()
+
+	select {
+	case err := <-done:
+		return err
+	case <-grace.C:
+	case <-signals:
+	}
+
+	logWarn("exec killed", "cmd", strings.Join(cmd.Args, " "), "pid", cmd.Process.Pid)
+	killProcessGroup(cmd)
+
+	return <-done
+}
@@ -0,0 +1,47 @@
+/*
+ Copyright 2018. Akamai Technologies, Inc
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseExecTimeout(t *testing.T) {
+	tests := []struct {
+		value   string
+		timeout time.Duration
+		valid   bool
+	}{
+		{"", 0, true},
+		{"0", 0, true},
+		{"off", 0, true},
+		{"30s", 30 * time.Second, true},
+		{" 2h ", 2 * time.Hour, true},
+		{"1h30m", 90 * time.Minute, true},
+		{"-5m", 0, false},
+		{"30", 0, false},
+		{"forever", 0, false},
+	}
+
+	for _, tt := range tests {
+		timeout, err := parseExecTimeout(tt.value)
+		if (err == nil) != tt.valid || timeout != tt.timeout {
+			t.Errorf("parseExecTimeout(%q) => %s, %v, wanted %s, valid %t", tt.value, timeout, err, tt.timeout, tt.valid)
+		}
+	}
+}
@@ -42,8 +42,7 @@ func firstRun() error {
 		return err
 	}
 
-	bannerShown = checkUpdate(bannerShown)
-	checkStats(bannerShown)
+	firstRunSetup(bannerShown, true)
 
 	return nil
 }
@@ -65,15 +64,8 @@ func checkPath() (bool, error) {
 	inPath := false
 	writablePaths := []string{}
 
-	var bannerShown bool
-	if getConfigValue("cli", "install-in-path") == "no" {
+	if getConfigValue("cli", "install-in-path") == "no" || len(paths) == 0 {
 		inPath = true
-		bannerShown = checkUpdate(!inPath)
-	}
-
-	if len(paths) == 0 {
-		inPath = true
-		bannerShown = checkUpdate(!inPath)
 	}
 
 	for _, path := range paths {
@@ -90,28 +82,26 @@ func checkPath() (bool, error) {
 		}
 
 		if path == dirPath {
-			bannerShown = checkUpdate(false)
+			inPath = true
 		}
 	}
 
 	if !inPath && len(writablePaths) > 0 {
-		if !bannerShown {
-			showBanner()
-		}
+		showBanner()
 		fmt.Fprint(akamai.App.Writer, "Akamai CLI is not installed in your PATH, would you like to install it? [Y/n]: ")
 		answer := ""
 		fmt.Scanln(&answer)
 		if answer != "" && strings.ToLower(answer) != "y" {
 			setConfigValue("cli", "install-in-path", "no")
 			saveConfig()
-			checkUpdate(true)
 			return true, nil
 		}
 
 		choosePath(writablePaths, answer, selfPath)
+		return true, nil
 	}
 
-	return !inPath, nil
+	return false, nil
 }
 
 func choosePath(writablePaths []string, answer string, selfPath string) {
@@ -148,55 +138,3 @@ func choosePath(writablePaths []string, answer string, selfPath string) {
 	}
 	akamai.StopSpinnerOk()
 }
-
-func checkUpdate(bannerShown bool) bool {
-	if getConfigValue("cli", "last-upgrade-check") == "" {
-		if !bannerShown {
-			bannerShown = true
-			showBanner()
-		}
-		fmt.Fprint(akamai.App.Writer, "Akamai CLI can auto-update itself, would you like to enable daily checks? [Y/n]: ")
-
-		answer := ""
-		fmt.Scanln(&answer)
-		if answer != "" && strings.ToLower(answer) != "y" {
-			setConfigValue("cli", "last-upgrade-check", "ignore")
-			saveConfig()
-			return bannerShown
-		}
-
-		setConfigValue("cli", "last-upgrade-check", "never")
-		saveConfig()
-	}
-
-	return bannerShown
-}
-
-func checkStats(bannerShown bool) bool {
-	if getConfigValue("cli", "client-id") == "" && getConfigValue("cli", "enable-cli-statistics") != "false" {
-		if !bannerShown {
-			bannerShown = true
-			showBanner()
-		}
-		anonymous := color.New(color.FgWhite, color.Bold).Sprint("anonymous")
-		fmt.Fprintf(akamai.App.Writer, "Help Akamai improve Akamai CLI by automatically sending %s diagnotics and usage data.\n", anonymous)
-		fmt.Fprintln(akamai.App.Writer, "Examples of data being send include upgrade statistics, and packages installed and updated.\n")
-		fmt.Fprintf(akamai.App.Writer, "Send %s diagnostics and usage data to Akamai? [Y/n]: ", anonymous)
-
-		answer := ""
-		fmt.Scanln(&answer)
-		if answer != "" && strings.ToLower(answer) != "y" {
-			setConfigValue("cli", "enable-cli-statistics", "false")
-			saveConfig()
-			return bannerShown
-		}
-
-		setConfigValue("cli", "enable-cli-statistics", "true")
-		setConfigValue("cli", "last-ping", "never")
-		setupUuid()
-		saveConfig()
-		trackEvent("first-run", "true")
-	}
-
-	return bannerShown
-}
@@ -19,6 +19,7 @@
 package main
 
 func firstRun() error {
-	checkStats(false)
+	// Upgrades of these builds are left to the package manager they came from
+	firstRunSetup(false, false)
 	return nil
 }
@@ -0,0 +1,204 @@
+/*
+ Copyright 2018. Akamai Technologies, Inc
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package main
+
+import (
+	"bytes"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/mitchellh/go-homedir"
+	"gopkg.in/src-d/go-git.v4/plumbing/transport"
+	githttp "gopkg.in/src-d/go-git.v4/plumbing/transport/http"
+	"gopkg.in/src-d/go-git.v4/plumbing/transport/ssh"
+)
+
+// sshPassphraseEnv holds the passphrase of the key set with cli.ssh-key
+const sshPassphraseEnv = "AKAMAI_CLI_SSH_PASSPHRASE"
+
+// scpLikeURL matches the short form of SSH remotes, e.g. git@github.example.com:team/cli-foo.git
+var scpLikeURL = regexp.MustCompile(`^([^@/:]+)@([^/:]+):(.+)$`)
+
+// gitRemote is the parts of a remote URL needed to find credentials for it
+type gitRemote struct {
+	ssh  bool
+	user string
+	host string
+	path string
+}
+
+func parseGitRemote(remote string) (gitRemote, bool) {
+	if matches := scpLikeURL.FindStringSubmatch(remote); matches != nil {
+		return gitRemote{ssh: true, user: matches[1], host: matches[2], path: matches[3]}, true
+	}
+
+	u, err := url.Parse(remote)
+	if err != nil || u.Host == "" {
+		return gitRemote{}, false
+	}
+
+	parsed := gitRemote{ssh: u.Scheme == "ssh", host: u.Hostname(), path: strings.TrimPrefix(u.Path, "/")}
+	if u.User != nil {
+		parsed.user = u.User.Username()
+	}
+
+	return parsed, u.Scheme == "ssh" || u.Scheme == "http" || u.Scheme == "https"
+}
+
+// getGitAuth returns the credentials to use for a remote. SSH remotes use the
+// key set with cli.ssh-key, the SSH agent, or the default keys in ~/.ssh.
+// HTTPS remotes use the token set with "akamai config set git.<host>.token",
+// if any, and otherwise start out anonymous, see withGitAuth.
+func getGitAuth(remote string) (transport.AuthMethod, error) {
+	parsed, ok := parseGitRemote(remote)
+	if !ok {
+		return nil, nil
+	}
+
+	if !parsed.ssh {
+		if token := getConfigValue("git", getGitConfigKey(parsed.host, "token")); token != "" {
+			username := getConfigValue("git", getGitConfigKey(parsed.host, "username"))
+			if username == "" {
+				username = parsed.user
+			}
+			if username == "" {
+				// Token authentication ignores the user name, but it can't be empty
+				username = "akamai-cli"
+			}
+			logDebug("using configured git token", "host", parsed.host)
+			return &githttp.BasicAuth{Username: username, Password: token}, nil
+		}
+
+		return nil, nil
+	}
+
+	user := parsed.user
+	if user == "" {
+		user = ssh.DefaultUsername
+	}
+
+	if key := getConfigValue("cli", "ssh-key"); key != "" {
+		if path, err := homedir.Expand(key); err == nil {
+			key = path
+		}
+		logDebug("using ssh key", "host", parsed.host, "key", key)
+		return ssh.NewPublicKeysFromFile(user, key, os.Getenv(sshPassphraseEnv))
+	}
+
+	if auth, err := ssh.NewSSHAgentAuth(user); err == nil {
+		logDebug("using ssh agent", "host", parsed.host)
+		return auth, nil
+	}
+
+	if home, err := homedir.Dir(); err == nil {
+		for _, name := range []string{"id_ed25519", "id_ecdsa", "id_rsa"} {
+			key := filepath.Join(home, ".ssh", name)
+			if _, err := os.Stat(key); err != nil {
+				continue
+			}
+
+			if auth, err := ssh.NewPublicKeysFromFile(user, key, os.Getenv(sshPassphraseEnv)); err == nil {
+				logDebug("using ssh key", "host", parsed.host, "key", key)
+				return auth, nil
+			}
+		}
+	}
+
+	// Let go-git report that no SSH credentials are available
+	return nil, nil
+}
+
+// getGitConfigKey returns the config key of a setting for a git host in the
+// git section, e.g. git.github.example.com.token is github-example-com-token
+func getGitConfigKey(host string, setting string) string {
+	return strings.Replace(host, ".", "-", -1) + "-" + setting
+}
+
+// withGitAuth runs a git operation against remote with its credentials. When an
+// HTTPS remote requires authentication that has not been configured, the
+// operation is tried again with the credentials of git's credential helper.
+func withGitAuth(remote string, fn func(auth transport.AuthMethod) error) error {
+	auth, err := getGitAuth(remote)
+	if err != nil {
+		return permanent(err)
+	}
+
+	err = fn(auth)
+	if err != transport.ErrAuthenticationRequired || auth != nil {
+		return err
+	}
+
+	if parsed, ok := parseGitRemote(remote); ok && !parsed.ssh {
+		if helperAuth := getCredentialHelperAuth(parsed, remote); helperAuth != nil {
+			return fn(helperAuth)
+		}
+	}
+
+	return err
+}
+
+// getCredentialHelperAuth asks "git credential fill" for the credentials of an
+// HTTPS remote, without prompting, or returns nil if git has none
+func getCredentialHelperAuth(parsed gitRemote, remote string) transport.AuthMethod {
+	bin, err := exec.LookPath("git")
+	if err != nil {
+		return nil
+	}
+
+	protocol := "https"
+	if strings.HasPrefix(remote, "http://") {
+		protocol = "http"
+	}
+
+	input := "protocol=" + protocol + "\nhost=" + parsed.host + "\npath=" + parsed.path + "\n"
+	if parsed.user != "" {
+		input += "username=" + parsed.user + "\n"
+	}
+
+	cmd := exec.Command(bin, "credential", "fill")
+	cmd.Stdin = strings.NewReader(input + "\n")
+	cmd.Env = append(os.Environ(), "GIT_TERMINAL_PROMPT=0")
+	output := &bytes.Buffer{}
+	cmd.Stdout = output
+	if err := runCommand(cmd); err != nil {
+		return nil
+	}
+
+	credentials := parseCredentialOutput(output.String())
+	if credentials["password"] == "" {
+		return nil
+	}
+
+	logDebug("using git credential helper", "host", parsed.host)
+	return &githttp.BasicAuth{Username: credentials["username"], Password: credentials["password"]}
+}
+
+func parseCredentialOutput(output string) map[string]string {
+	credentials := make(map[string]string)
+	for _, line := range strings.Split(output, "\n") {
+		parts := strings.SplitN(strings.TrimRight(line, "\r"), "=", 2)
+		if len(parts) == 2 {
+			credentials[parts[0]] = parts[1]
+		}
+	}
+
+	return credentials
+}
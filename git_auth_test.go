@@ -0,0 +1,49 @@
+/*
+ Copyright 2018. Akamai Technologies, Inc
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package main
+
+import "testing"
+
+func TestParseGitRemote(t *testing.T) {
+	remoteTests := []struct {
+		remote string
+		result gitRemote
+		ok     bool
+	}{
+		{"git@github.example.com:team/cli-foo.git", gitRemote{ssh: true, user: "git", host: "github.example.com", path: "team/cli-foo.git"}, true},
+		{"ssh://deploy@git.example.com:2222/team/cli-foo.git", gitRemote{ssh: true, user: "deploy", host: "git.example.com", path: "team/cli-foo.git"}, true},
+		{"https://github.example.com/team/cli-foo.git", gitRemote{host: "github.example.com", path: "team/cli-foo.git"}, true},
+		{"https://bot@gitlab.example.com/team/cli-foo.git", gitRemote{user: "bot", host: "gitlab.example.com", path: "team/cli-foo.git"}, true},
+		{"file:///local/repo/path", gitRemote{}, false},
+		{"/local/repo/path", gitRemote{}, false},
+	}
+
+	for _, tt := range remoteTests {
+		result, ok := parseGitRemote(tt.remote)
+		if ok != tt.ok || (ok && result != tt.result) {
+			t.Errorf("parseGitRemote(%s) => %+v, %t, wanted: %+v, %t", tt.remote, result, ok, tt.result, tt.ok)
+		}
+	}
+}
+
+func TestParseCredentialOutput(t *testing.T) {
+	credentials := parseCredentialOutput("protocol=https\nhost=github.example.com\nusername=bot\r\npassword=s3cr=t\n")
+
+	if credentials["username"] != "bot" || credentials["password"] != "s3cr=t" {
+		t.Errorf("parseCredentialOutput() => %v, wanted username bot and password s3cr=t", credentials)
+	}
+}
@@ -0,0 +1,181 @@
+/*
+ Copyright 2018. Akamai Technologies, Inc
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package main
+
+import (
+	"os"
+	"strings"
+
+	"gopkg.in/src-d/go-git.v4"
+	gitconfig "gopkg.in/src-d/go-git.v4/config"
+	"gopkg.in/src-d/go-git.v4/plumbing"
+	"gopkg.in/src-d/go-git.v4/plumbing/transport"
+)
+
+// Packages are cloned with only their latest commit, and updates fetch only
+// the latest commit of the branch they follow, which keeps installs of
+// packages with a long history fast and small. go-git can't do partial
+// clones (--filter=blob:none), so a depth of 1 is the closest it gets.
+// Shallow clones are turned off with "akamai config set cli.shallow-clone off".
+
+const (
+	// defaultPackageBranch is the branch packages are updated from when it
+	// can't be told from the checkout or the remote
+	defaultPackageBranch = "master"
+	// packageTagsRefSpec is the version tags of a package
+	packageTagsRefSpec = gitconfig.RefSpec("+refs/tags/*:refs/tags/*")
+
+	// unshallowDepth fetches the full history of a shallow clone, like git fetch --unshallow
+	unshallowDepth = 2147483647
+)
+
+// getCloneDepth returns how many commits to clone of a package: only the
+// latest, unless shallow clones are turned off, or a version is installed,
+// whose tag or commit may be anywhere in the history
+func getCloneDepth(version string) int {
+	if version != "" {
+		return 0
+	}
+
+	if shallow, err := parseOnOff(getConfigValue("cli", "shallow-clone")); err == nil && !shallow {
+		return 0
+	}
+
+	return 1
+}
+
+// clonePackageRepo clones repo into dir, with depth commits if not 0. A
+// shallow clone the remote refuses is done again in full.
+func clonePackageRepo(dir string, repo string, depth int, auth transport.AuthMethod, p progress) error {
+	options := &git.CloneOptions{
+		URL:      repo,
+		Auth:     auth,
+		Progress: newProgressWriter(p),
+	}
+	if depth > 0 {
+		options.Depth = depth
+		options.SingleBranch = true
+	}
+
+	_, err := git.PlainClone(dir, false, options)
+	if err != nil && depth > 0 && !isPermanentGitError(err) {
+		logWarn("shallow git clone failed, cloning in full", "repo", repo, "error", err)
+		os.RemoveAll(dir)
+
+		options.Depth = 0
+		options.SingleBranch = false
+		_, err = git.PlainClone(dir, false, options)
+	}
+
+	return err
+}
+
+// isShallowRepository returns whether repo was cloned without its full history
+func isShallowRepository(repo *git.Repository) bool {
+	shallow, err := repo.Storer.Shallow()
+	return err == nil && len(shallow) > 0
+}
+
+// ensureCommit fetches the full history of the repository at dir if it is a
+// shallow clone that doesn't have commit
+func ensureCommit(dir string, commit string) error {
+	repo, err := git.PlainOpen(dir)
+	if err != nil {
+		return err
+	}
+
+	if _, err := repo.CommitObject(plumbing.NewHash(commit)); err == nil || !isShallowRepository(repo) {
+		return nil
+	}
+
+	logInfo("commit not in shallow clone, fetching full history", "dir", dir, "commit", commit)
+	return fetchPackageRefs(repo, []gitconfig.RefSpec{getPackageBranchRefSpec(getPackageBranch(repo)), packageTagsRefSpec}, unshallowDepth)
+}
+
+// getPackageBranch returns the branch a package is updated from: the branch
+// checked out when it was cloned, or the remote branch an update checked
+// out. When a commit is checked out instead, e.g. a release, it is the
+// default branch of the remote.
+func getPackageBranch(repo *git.Repository) string {
+	remotePrefix := "refs/remotes/" + git.DefaultRemoteName + "/"
+	if head, err := repo.Storer.Reference(plumbing.HEAD); err == nil && head.Type() == plumbing.SymbolicReference {
+		switch target := head.Target(); {
+		case target.IsBranch():
+			return target.Short()
+		case strings.HasPrefix(target.String(), remotePrefix):
+			return strings.TrimPrefix(target.String(), remotePrefix)
+		}
+	}
+
+	if branch := getRemoteDefaultBranch(repo); branch != "" {
+		return branch
+	}
+
+	return defaultPackageBranch
+}
+
+// getRemoteDefaultBranch asks the origin remote which branch its HEAD is
+func getRemoteDefaultBranch(repo *git.Repository) string {
+	remote, err := repo.Remote(git.DefaultRemoteName)
+	if err != nil {
+		return ""
+	}
+
+	var refs []*plumbing.Reference
+	err = withGitAuth(getRemoteURL(repo), func(auth transport.AuthMethod) error {
+		refs, err = remote.List(&git.ListOptions{Auth: auth})
+		return err
+	})
+	if err != nil {
+		logWarn("unable to find the default branch", "remote", git.DefaultRemoteName, "error", err)
+		return ""
+	}
+
+	var head *plumbing.Reference
+	for _, ref := range refs {
+		if ref.Name() == plumbing.HEAD {
+			head = ref
+		}
+	}
+
+	switch {
+	case head == nil:
+		return ""
+	case head.Type() == plumbing.SymbolicReference:
+		return head.Target().Short()
+	}
+
+	// Without the symref capability, HEAD is the branch at the same commit
+	for _, ref := range refs {
+		if ref.Name().IsBranch() && ref.Hash() == head.Hash() {
+			return ref.Name().Short()
+		}
+	}
+
+	return ""
+}
+
+// getPackageBranchRefSpec returns the refspec that fetches branch to its
+// remote tracking branch
+func getPackageBranchRefSpec(branch string) gitconfig.RefSpec {
+	return gitconfig.RefSpec("+refs/heads/" + branch + ":" + getPackageRemoteRef(branch).String())
+}
+
+// getPackageRemoteRef returns the remote tracking branch of branch
+func getPackageRemoteRef(branch string) plumbing.ReferenceName {
+	return plumbing.ReferenceName("refs/remotes/" + git.DefaultRemoteName + "/" + branch)
+}
@@ -0,0 +1,197 @@
+/*
+ Copyright 2018. Akamai Technologies, Inc
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	akamai "github.com/akamai/cli-common-golang"
+	"github.com/fatih/color"
+	"github.com/urfave/cli"
+)
+
+// hookEnv is set to the event while a hook runs, and hooks are not run for
+// akamai commands that hooks run themselves
+const hookEnv = "AKAMAI_CLI_HOOK"
+
+const (
+	hookPreInstall    = "pre-install"
+	hookPostInstall   = "post-install"
+	hookPreUpdate     = "pre-update"
+	hookPostUpdate    = "post-update"
+	hookPreUninstall  = "pre-uninstall"
+	hookPostUninstall = "post-uninstall"
+	hookPreExec       = "pre-exec"
+	hookPostExec      = "post-exec"
+)
+
+// hookEnvAssignment matches the NAME=value lines pre-exec hooks print to set
+// environment variables for the command
+var hookEnvAssignment = regexp.MustCompile(`^([A-Za-z_][A-Za-z0-9_]*)=(.*)$`)
+
+// hook is a command run for an event, either a command line from the [hooks]
+// config section, or an executable in the hooks/<event> directory
+type hook struct {
+	name  string
+	line  string
+	path  string
+	event string
+}
+
+// getHooks returns the hooks for an event: the config one first, then those
+// in the hooks directory, in name order
+func getHooks(event string) []hook {
+	var hooks []hook
+	if line := strings.TrimSpace(getConfigValue("hooks", event)); line != "" {
+		hooks = append(hooks, hook{name: "hooks." + event, line: line, event: event})
+	}
+
+	cliPath, err := getAkamaiCliPath()
+	if err != nil {
+		return hooks
+	}
+
+	dir := filepath.Join(cliPath, "hooks", event)
+	files, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return hooks
+	}
+
+	sort.Slice(files, func(i, j int) bool { return files[i].Name() < files[j].Name() })
+	for _, file := range files {
+		path := filepath.Join(dir, file.Name())
+		if file.IsDir() || strings.HasPrefix(file.Name(), ".") || !isExecutableFile(path, file) {
+			continue
+		}
+		hooks = append(hooks, hook{name: filepath.Join("hooks", event, file.Name()), path: path, event: event})
+	}
+
+	return hooks
+}
+
+// runHooks runs the hooks for an event, with its context in AKAMAI_CLI_HOOK_*
+// environment variables, e.g. AKAMAI_CLI_HOOK_PACKAGE. A failing pre- hook
+// stops the operation, and a failing post- hook only prints a warning. The
+// environment variables set by pre-exec hooks are returned.
+func runHooks(event string, context map[string]string) (map[string]string, error) {
+	if os.Getenv(hookEnv) != "" {
+		return nil, nil
+	}
+
+	hooks := getHooks(event)
+	if len(hooks) == 0 {
+		return nil, nil
+	}
+
+	env := append(os.Environ(), getHookEnv(event, context)...)
+	setEnv := map[string]string{}
+	for _, h := range hooks {
+		logInfo("running hook", "event", event, "hook", h.name)
+
+		cmd := newShellCommand(h.line)
+		if h.path != "" {
+			cmd = newLaunchCommand([]string{h.path})
+		}
+		cmd.Env = env
+		cmd.Stderr = os.Stderr
+
+		stdout := &bytes.Buffer{}
+		if event == hookPreExec {
+			cmd.Stdout = stdout
+		} else {
+			cmd.Stdout = os.Stderr
+		}
+
+		err := runCommand(cmd)
+
+		assignments, output := parseHookOutput(stdout.String())
+		fmt.Fprint(os.Stderr, output)
+		for name, value := range assignments {
+			setEnv[name] = value
+		}
+
+		if err == nil {
+			continue
+		}
+
+		if strings.HasPrefix(event, "pre-") {
+			return nil, cli.NewExitError(color.RedString("The %s hook %s failed: %s", event, h.name, err.Error()), 1)
+		}
+		fmt.Fprintln(akamai.App.ErrWriter, color.YellowString("Warning: the %s hook %s failed: %s", event, h.name, err.Error()))
+	}
+
+	return setEnv, nil
+}
+
+// getHookEnv returns the environment variables with the context of an event
+func getHookEnv(event string, context map[string]string) []string {
+	env := []string{hookEnv + "=" + event}
+
+	names := make([]string, 0, len(context))
+	for name := range context {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		env = append(env, hookEnv+"_"+strings.ToUpper(name)+"="+context[name])
+	}
+
+	return env
+}
+
+// parseHookOutput splits the output of a pre-exec hook into the NAME=value
+// lines that set environment variables, and everything else
+func parseHookOutput(output string) (map[string]string, string) {
+	assignments := map[string]string{}
+	rest := &bytes.Buffer{}
+
+	scanner := bufio.NewScanner(strings.NewReader(output))
+	for scanner.Scan() {
+		line := scanner.Text()
+		if match := hookEnvAssignment.FindStringSubmatch(strings.TrimPrefix(strings.TrimSpace(line), "export ")); match != nil {
+			assignments[match[1]] = match[2]
+			continue
+		}
+		fmt.Fprintln(rest, line)
+	}
+
+	return assignments, rest.String()
+}
+
+// hookStatus returns the status and exit code context of a post- hook
+func hookStatus(context map[string]string, err error) map[string]string {
+	status := map[string]string{"status": "ok", "exit_code": "0"}
+	if err != nil {
+		status["status"] = "failed"
+		status["exit_code"] = fmt.Sprint(getExitCode(err))
+	}
+
+	for name, value := range context {
+		status[name] = value
+	}
+
+	return status
+}
@@ -0,0 +1,76 @@
+/*
+ Copyright 2018. Akamai Technologies, Inc
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package main
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/urfave/cli"
+)
+
+func TestGetHookEnv(t *testing.T) {
+	env := getHookEnv(hookPostInstall, map[string]string{"package_dir": "/home/user/.akamai-cli/src/cli-purge", "package": "purge"})
+	want := []string{
+		"AKAMAI_CLI_HOOK=post-install",
+		"AKAMAI_CLI_HOOK_PACKAGE=purge",
+		"AKAMAI_CLI_HOOK_PACKAGE_DIR=/home/user/.akamai-cli/src/cli-purge",
+	}
+
+	if !reflect.DeepEqual(env, want) {
+		t.Errorf("getHookEnv() => %q, wanted: %q", env, want)
+	}
+}
+
+func TestParseHookOutput(t *testing.T) {
+	outputTests := []struct {
+		output      string
+		assignments map[string]string
+		rest        string
+	}{
+		{"AKAMAI_TOKEN=abc123\nexport PAPI_DEBUG=1\nfetched a token\n", map[string]string{"AKAMAI_TOKEN": "abc123", "PAPI_DEBUG": "1"}, "fetched a token\n"},
+		{"EMPTY=\nnot an=assignment\n1X=2", map[string]string{"EMPTY": ""}, "not an=assignment\n1X=2\n"},
+		{"", map[string]string{}, ""},
+	}
+
+	for _, tt := range outputTests {
+		assignments, rest := parseHookOutput(tt.output)
+		if !reflect.DeepEqual(assignments, tt.assignments) || rest != tt.rest {
+			t.Errorf("parseHookOutput(%q) => %v, %q, wanted: %v, %q", tt.output, assignments, rest, tt.assignments, tt.rest)
+		}
+	}
+}
+
+func TestHookStatus(t *testing.T) {
+	statusTests := []struct {
+		err      error
+		status   string
+		exitCode string
+	}{
+		{nil, "ok", "0"},
+		{errors.New("failed"), "failed", "1"},
+		{cli.NewExitError("", 3), "failed", "3"},
+	}
+
+	for _, tt := range statusTests {
+		status := hookStatus(map[string]string{"package": "purge"}, tt.err)
+		if status["status"] != tt.status || status["exit_code"] != tt.exitCode || status["package"] != "purge" {
+			t.Errorf("hookStatus(%v) => %v, wanted status %s and exit code %s", tt.err, status, tt.status, tt.exitCode)
+		}
+	}
+}
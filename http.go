@@ -0,0 +1,236 @@
+/*
+ Copyright 2018. Akamai Technologies, Inc
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package main
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+
+	gitclient "gopkg.in/src-d/go-git.v4/plumbing/transport/client"
+	githttp "gopkg.in/src-d/go-git.v4/plumbing/transport/http"
+)
+
+// defaultHTTPTimeout bounds each request, unless cli.http-timeout is set
+const defaultHTTPTimeout = 30 * time.Second
+
+// caBundleEnv names a PEM file of additional CA certificates, like cli.ca-bundle
+const caBundleEnv = "AKAMAI_CLI_CA_BUNDLE"
+
+var (
+	httpClient     *http.Client
+	httpClientErr  error
+	httpClientOnce sync.Once
+)
+
+// getHTTPClient returns the client shared by all outbound requests. Proxies are
+// taken from HTTP_PROXY, HTTPS_PROXY and NO_PROXY (which --proxy sets).
+//
+// Outbound connections can be bound to a local address with cli.bind-address, or
+// to the first address of a network interface with cli.bind-interface. Servers
+// signed by a corporate CA are trusted by adding it to a PEM bundle named by
+// cli.ca-bundle or $AKAMAI_CLI_CA_BUNDLE. Each request must complete within
+// cli.http-timeout (e.g. 1m), use getDownloadHTTPClient for large downloads.
+func getHTTPClient() (*http.Client, error) {
+	httpClientOnce.Do(func() {
+		httpClient, httpClientErr = newHTTPClient()
+	})
+
+	return httpClient, httpClientErr
+}
+
+// getDownloadHTTPClient returns the shared client without the overall request
+// timeout, so that large downloads on slow connections can complete. Connecting
+// and waiting for the response headers are still bounded.
+func getDownloadHTTPClient() (*http.Client, error) {
+	client, err := getHTTPClient()
+	if err != nil {
+		return nil, err
+	}
+
+	download := *client
+	download.Timeout = 0
+
+	return &download, nil
+}
+
+func newHTTPClient() (*http.Client, error) {
+	localAddr, err := getBindAddress()
+	if err != nil {
+		return nil, err
+	}
+
+	timeout := defaultHTTPTimeout
+	if value := getConfigValue("cli", "http-timeout"); value != "" {
+		timeout, err = time.ParseDuration(value)
+		if err != nil || timeout <= 0 {
+			return nil, fmt.Errorf("Invalid http-timeout \"%s\", must be a duration such as 30s or 2m", value)
+		}
+	}
+
+	tlsConfig, err := getTLSConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	dialer := &net.Dialer{
+		Timeout:   30 * time.Second,
+		KeepAlive: 30 * time.Second,
+	}
+
+	transport := &http.Transport{
+		Proxy:                 http.ProxyFromEnvironment,
+		DialContext:           dialer.DialContext,
+		TLSClientConfig:       tlsConfig,
+		TLSHandshakeTimeout:   10 * time.Second,
+		ResponseHeaderTimeout: timeout,
+	}
+
+	if localAddr != nil {
+		dialer.LocalAddr = &net.TCPAddr{IP: localAddr}
+		transport.DialContext = func(ctx context.Context, network, addr string) (net.Conn, error) {
+			conn, err := dialer.DialContext(ctx, network, addr)
+			if err != nil {
+				return nil, fmt.Errorf("unable to connect from local address %s (%s)", localAddr, err.Error())
+			}
+
+			return conn, nil
+		}
+	}
+
+	return &http.Client{Transport: loggingTransport{transport}, Timeout: timeout}, nil
+}
+
+// loggingTransport logs each request made through the shared client
+type loggingTransport struct {
+	next http.RoundTripper
+}
+
+func (t loggingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	start := time.Now()
+	logDebug("http request", "method", req.Method, "url", req.URL.String())
+
+	resp, err := t.next.RoundTrip(req)
+	if err != nil {
+		logWarn("http request failed", "method", req.Method, "url", req.URL.String(), "duration", time.Since(start).String(), "error", err)
+		return nil, err
+	}
+
+	logInfo("http request", "method", req.Method, "url", req.URL.String(), "status", resp.StatusCode, "duration", time.Since(start).String())
+
+	return resp, nil
+}
+
+// useHTTPClientForGit makes git clones and fetches over HTTP(S) go through the
+// shared client, so they get the same proxy, CA and local address settings
+func useHTTPClientForGit() error {
+	client, err := getDownloadHTTPClient()
+	if err != nil {
+		return err
+	}
+
+	transport := githttp.NewClient(client)
+	gitclient.InstallProtocol("https", transport)
+	gitclient.InstallProtocol("http", transport)
+
+	return nil
+}
+
+// getTLSConfig trusts the system CAs, plus those in the configured CA bundle
+func getTLSConfig() (*tls.Config, error) {
+	bundle := getConfigValue("cli", "ca-bundle")
+	if bundle == "" {
+		bundle = os.Getenv(caBundleEnv)
+	}
+
+	if bundle == "" {
+		return nil, nil
+	}
+
+	pem, err := ioutil.ReadFile(bundle)
+	if err != nil {
+		return nil, fmt.Errorf("Unable to read CA bundle \"%s\" (%s)", bundle, err.Error())
+	}
+
+	pool, err := x509.SystemCertPool()
+	if err != nil || pool == nil {
+		pool = x509.NewCertPool()
+	}
+
+	if !pool.AppendCertsFromPEM(pem) {
+		return nil, fmt.Errorf("CA bundle \"%s\" does not contain any PEM encoded certificates", bundle)
+	}
+
+	return &tls.Config{RootCAs: pool}, nil
+}
+
+// getBindAddress resolves the configured local address, or nil when unset
+func getBindAddress() (net.IP, error) {
+	if address := getConfigValue("cli", "bind-address"); address != "" {
+		ip := net.ParseIP(address)
+		if ip == nil {
+			return nil, fmt.Errorf("Invalid bind-address \"%s\", must be an IP address", address)
+		}
+
+		return ip, nil
+	}
+
+	name := getConfigValue("cli", "bind-interface")
+	if name == "" {
+		return nil, nil
+	}
+
+	iface, err := net.InterfaceByName(name)
+	if err != nil {
+		return nil, fmt.Errorf("Invalid bind-interface \"%s\" (%s)", name, err.Error())
+	}
+
+	addrs, err := iface.Addrs()
+	if err != nil {
+		return nil, fmt.Errorf("Unable to read addresses of interface \"%s\" (%s)", name, err.Error())
+	}
+
+	// Prefer IPv4, as that is what most registry mirrors are reachable on
+	var ip net.IP
+	for _, addr := range addrs {
+		ipNet, ok := addr.(*net.IPNet)
+		if !ok {
+			continue
+		}
+
+		if ipNet.IP.To4() != nil {
+			return ipNet.IP, nil
+		}
+
+		if ip == nil {
+			ip = ipNet.IP
+		}
+	}
+
+	if ip == nil {
+		return nil, fmt.Errorf("Interface \"%s\" has no usable addresses", name)
+	}
+
+	return ip, nil
+}
@@ -0,0 +1,253 @@
+/*
+ Copyright 2018. Akamai Technologies, Inc
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	akamai "github.com/akamai/cli-common-golang"
+	"github.com/fatih/color"
+	"github.com/urfave/cli"
+)
+
+// installBinaryPackage installs the pre-built binary of a registry release
+// instead of cloning and building the package, so that no language runtime
+// is needed. The cli.json of the package is written from the registry's
+// description of its commands.
+func installBinaryPackage(repo string, subpath string, opts installOptions) error {
+	srcPath, err := opts.getSrcPath()
+	if err != nil {
+		return err
+	}
+
+	_ = os.MkdirAll(srcPath, 0775)
+
+	if err := checkDiskSpace(srcPath, opts.estimatedSize); err != nil {
+		return err
+	}
+
+	dirName := getPackageDirName(repo, subpath)
+
+	p := opts.getProgress()
+	p.Start(fmt.Sprintf("Attempting to download the %s/%s binary of %s...", opts.binary.OS, opts.binary.Arch, dirName))
+
+	stage, err := newStagedInstall(srcPath, dirName)
+	if err != nil {
+		p.Fail()
+		return cli.NewExitError(color.RedString(err.Error()), 1)
+	}
+
+	if err := writeBinaryPackage(stage.dir, *opts.binary, opts.commands, opts.insecure); err != nil {
+		stage.abort()
+
+		p.Fail()
+		return cli.NewExitError(color.RedString("Unable to install binary: %s", err.Error()), 1)
+	}
+
+	if err := stage.promote(); err != nil {
+		p.Fail()
+		return cli.NewExitError(color.RedString("Unable to move the package into place: %s", err.Error()), 1)
+	}
+
+	manifest := packageManifest{Repo: repo, Subpath: subpath, Version: opts.version, Binary: opts.binary.URL, BinarySHA256: opts.binary.SHA256}
+	if opts.release != nil {
+		manifest.Commit = opts.release.Commit
+	}
+	if err := writeManifest(dirName, manifest); err != nil {
+		removeAllForce(stage.target)
+
+		p.Fail()
+		return cli.NewExitError(color.RedString("Unable to record package manifest: "+err.Error()), 1)
+	}
+	logInfo("installed binary", "package", dirName, "url", opts.binary.URL)
+
+	p.Ok()
+
+	warnCommandConflicts(dirName)
+
+	return nil
+}
+
+// writeBinaryPackage downloads a release binary into the bin directory of a
+// new package in dir, and writes its cli.json. A binary that does not match
+// its checksum, or has none, is refused unless insecure is set.
+func writeBinaryPackage(dir string, binary releaseBinary, commands []Command, insecure bool) error {
+	if len(commands) == 0 {
+		return fmt.Errorf("the registry lists no commands for the package")
+	}
+
+	if binary.SHA256 == "" && !insecure {
+		return fmt.Errorf("the registry publishes no checksum for %s. Use --insecure to install it anyway", binary.URL)
+	}
+
+	binDir := filepath.Join(dir, "bin")
+	if err := os.MkdirAll(binDir, 0775); err != nil {
+		return err
+	}
+
+	client, err := getDownloadHTTPClient()
+	if err != nil {
+		return err
+	}
+
+	res, _, err := getBinary(client, []string{binary.URL})
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	download, err := ioutil.TempFile(dir, "download")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(download.Name())
+
+	hash := sha256.New()
+	_, err = io.Copy(io.MultiWriter(download, hash), res.Body)
+	download.Close()
+	if err != nil {
+		return err
+	}
+
+	if binary.SHA256 != "" && !checksumMatches(binary.SHA256, hash.Sum(nil)) {
+		if !insecure {
+			return fmt.Errorf("checksum of %s does not match the release. Use --insecure to install it anyway", binary.URL)
+		}
+		fmt.Fprintln(akamai.App.ErrWriter, color.YellowString("Warning: checksum of %s does not match the release, installing anyway because of --insecure", binary.URL))
+	}
+
+	// An archive holds the executables of all of the commands, a plain binary is that of the first
+	if getArchiveSuffix(binary.URL) != "" {
+		err = extractPackageArchive(download.Name(), binDir)
+	} else {
+		target := filepath.Join(binDir, getBinaryName(commands[0]))
+		if err = os.Rename(download.Name(), target); err == nil {
+			err = os.Chmod(target, 0775)
+		}
+	}
+	if err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(struct {
+		Commands []Command `json:"commands"`
+	}{commands}, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return ioutil.WriteFile(filepath.Join(dir, "cli.json"), data, 0664)
+}
+
+// getBinaryName returns the file name of the executable of cmd on this platform
+func getBinaryName(cmd Command) string {
+	name := "akamai-" + strings.ToLower(cmd.Name)
+	if runtime.GOOS == "windows" {
+		name += ".exe"
+	}
+
+	return name
+}
+
+// updateBinaryPackage replaces a package installed from a binary with the
+// binary of the registry's latest release, if it is newer
+func updateBinaryPackage(cmd string, repoDir string, manifest packageManifest, opts installOptions, p progress) (updateStatus, error) {
+	name := filepath.Base(getPackageRoot(repoDir))
+
+	list, err := fetchPackageList(fetchOptions{})
+	if err != nil {
+		p.Fail()
+		return updateFailed, cli.NewExitError(color.RedString(err.Error()), 1)
+	}
+
+	pkg, ok := list.findPackage(normalizePackageName(name))
+	if !ok {
+		p.Fail()
+		return updateFailed, cli.NewExitError(color.RedString("Package \"%s\" is no longer in the package list, reinstall it with --from-source to update it", name), 1)
+	}
+
+	release, ok := pkg.findRelease("")
+	if !ok {
+		p.WarnOk()
+		printUpdateMessage(opts, "command \"%s\" has no release in the package list, reinstall it with --from-source to update it", cmd)
+		return updateSkipped, nil
+	}
+
+	binary, ok := release.findBinary(runtime.GOOS, getBinaryArchs(runtime.GOOS, getHostArch()))
+	if !ok {
+		p.WarnOk()
+		printUpdateMessage(opts, "release %s of command \"%s\" has no binary for this platform, reinstall it with --from-source to update it", release.Version, cmd)
+		return updateSkipped, nil
+	}
+
+	if binary.URL == manifest.Binary && binary.SHA256 == manifest.BinarySHA256 {
+		p.WarnOk()
+		printUpdateMessage(opts, "command \"%s\" already up-to-date", cmd)
+		return updateCurrent, nil
+	}
+
+	if opts.changelog {
+		p.Ok()
+		printChangelog(name, getNewerReleases(pkg, manifest.Commit), nil, false)
+
+		if !confirmUpdate(name, opts) {
+			printInfo(akamai.App.Writer, "Skipped updating \"%s\"", cmd)
+			return updateSkipped, nil
+		}
+		p.Start(fmt.Sprintf("Updating \"%s\" command...", cmd))
+	}
+
+	// Download next to the package, so it is only replaced once the new one is complete
+	root := getPackageRoot(repoDir)
+	tmp := root + ".update"
+	os.RemoveAll(tmp)
+	if err := writeBinaryPackage(tmp, binary, pkg.Commands, opts.insecure); err != nil {
+		os.RemoveAll(tmp)
+		p.Fail()
+		return updateFailed, cli.NewExitError(color.RedString("Unable to update command: %s", err.Error()), 1)
+	}
+
+	if err := os.RemoveAll(root); err != nil {
+		os.RemoveAll(tmp)
+		p.Fail()
+		return updateFailed, cli.NewExitError(color.RedString("Unable to update command: %s", err.Error()), 1)
+	}
+
+	if err := os.Rename(tmp, root); err != nil {
+		p.Fail()
+		return updateFailed, cli.NewExitError(color.RedString("Unable to update command: %s", err.Error()), 1)
+	}
+
+	manifest.Binary = binary.URL
+	manifest.BinarySHA256 = binary.SHA256
+	manifest.Commit = release.Commit
+	if err := writeManifest(name, manifest); err != nil {
+		fmt.Fprintln(akamai.App.ErrWriter, color.YellowString("Unable to record package manifest: %s", err.Error()))
+	}
+
+	p.Ok()
+
+	return updateUpdated, nil
+}
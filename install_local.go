@@ -0,0 +1,373 @@
+/*
+ Copyright 2018. Akamai Technologies, Inc
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package main
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/fatih/color"
+	"github.com/mitchellh/go-homedir"
+	"github.com/urfave/cli"
+)
+
+var archiveSuffixes = []string{".tar.gz", ".tgz", ".tar"}
+
+// isLocalInstallTarget reports whether an install target names a local
+// directory or archive, rather than a repository
+func isLocalInstallTarget(target string) bool {
+	if getArchiveSuffix(target) != "" {
+		return true
+	}
+
+	for _, prefix := range []string{"./", "../", ".\\", "..\\", "~"} {
+		if strings.HasPrefix(target, prefix) {
+			return true
+		}
+	}
+
+	return target == "." || target == ".." || filepath.IsAbs(target) || strings.HasPrefix(target, "/")
+}
+
+func getArchiveSuffix(path string) string {
+	for _, suffix := range archiveSuffixes {
+		if strings.HasSuffix(strings.ToLower(path), suffix) {
+			return suffix
+		}
+	}
+
+	return ""
+}
+
+// getLocalPackageName returns the directory name to install a local package
+// as, e.g. cli-purge for cli-purge-1.2.0.tar.gz
+func getLocalPackageName(path string) string {
+	name := filepath.Base(path)
+	if suffix := getArchiveSuffix(name); suffix != "" {
+		name = name[:len(name)-len(suffix)]
+		name = regexp.MustCompile(`-v?\d+(\.\d+)*$`).ReplaceAllString(name, "")
+	}
+
+	return name
+}
+
+// installLocalPackage installs a package from a directory or tarball, without
+// any network access. The package is copied into the CLI home, and then set up
+// like a package cloned from a repository.
+func installLocalPackage(target string, opts installOptions) error {
+	path, err := homedir.Expand(target)
+	if err == nil {
+		path, err = filepath.Abs(path)
+	}
+	if err != nil {
+		return cli.NewExitError(color.RedString("Unable to find %s: %s", target, err.Error()), 1)
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		return cli.NewExitError(color.RedString("Unable to find %s", target), 1)
+	}
+
+	srcPath, err := opts.getSrcPath()
+	if err != nil {
+		return err
+	}
+
+	_ = os.MkdirAll(srcPath, 0775)
+
+	if err := checkDiskSpace(srcPath, opts.estimatedSize); err != nil {
+		return err
+	}
+
+	p := opts.getProgress()
+	p.Start(fmt.Sprintf("Attempting to install command from %s...", path))
+
+	stage, err := newStagedInstall(srcPath, getLocalPackageName(path))
+	if err != nil {
+		p.Fail()
+
+		return cli.NewExitError(color.RedString(err.Error()), 1)
+	}
+
+	if info.IsDir() {
+		err = copyPackageDir(path, stage.dir)
+	} else {
+		err = extractPackageArchive(path, stage.dir)
+	}
+
+	if err != nil {
+		stage.abort()
+
+		p.Fail()
+		return cli.NewExitError(color.RedString("Unable to copy package: %s", err.Error()), 1)
+	}
+	logInfo("copied local package", "source", path, "dir", stage.dir)
+
+	return setupPackage(stage, "", packageManifest{Source: path}, "", opts, p)
+}
+
+// copyPackageDir copies a package checkout, leaving out its git metadata: the
+// copy is a snapshot, which is updated by installing it again
+func copyPackageDir(src string, dst string) error {
+	return filepath.Walk(src, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+
+		rel, err := filepath.Rel(src, path)
+		if err != nil {
+			return err
+		}
+
+		if info.IsDir() && info.Name() == ".git" {
+			return filepath.SkipDir
+		}
+
+		target := filepath.Join(dst, rel)
+		switch {
+		case info.IsDir():
+			return os.MkdirAll(target, info.Mode()|0700)
+		case info.Mode()&os.ModeSymlink != 0:
+			link, err := os.Readlink(path)
+			if err != nil {
+				return err
+			}
+			return createSymlink(link, target)
+		case info.Mode().IsRegular():
+			return copyFile(path, target, info.Mode())
+		}
+
+		return nil
+	})
+}
+
+// createSymlink creates a symlink to link at target or, where symlinks can't be
+// created, such as on Windows without Developer Mode, a copy of what it links to
+func createSymlink(link string, target string) error {
+	err := os.Symlink(link, target)
+	if err == nil {
+		return nil
+	}
+
+	src := link
+	if !filepath.IsAbs(src) {
+		src = filepath.Join(filepath.Dir(target), link)
+	}
+
+	info, statErr := os.Stat(src)
+	switch {
+	case statErr != nil:
+		return err
+	case info.IsDir():
+		return copyPackageDir(src, target)
+	}
+
+	return copyFile(src, target, info.Mode())
+}
+
+func copyFile(src string, dst string, mode os.FileMode) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err := os.OpenFile(dst, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, mode)
+	if err != nil {
+		return err
+	}
+
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+
+	return out.Close()
+}
+
+// extractPackageArchive extracts a (gzipped) tarball into dst. Archives with a
+// single top-level directory, like those Github creates for releases, are
+// extracted from within it.
+func extractPackageArchive(archive string, dst string) error {
+	file, err := os.Open(archive)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	var r io.Reader = file
+	if getArchiveSuffix(archive) != ".tar" {
+		gz, err := gzip.NewReader(file)
+		if err != nil {
+			return err
+		}
+		defer gz.Close()
+		r = gz
+	}
+
+	if err := os.MkdirAll(dst, 0775); err != nil {
+		return err
+	}
+
+	links := make(archiveLinks)
+	tr := tar.NewReader(r)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+
+		target, err := getArchiveEntryPath(dst, header.Name)
+		if err != nil {
+			return err
+		}
+		if err := links.check(header.Name); err != nil {
+			return err
+		}
+
+		switch header.Typeflag {
+		case tar.TypeDir:
+			err = os.MkdirAll(target, os.FileMode(header.Mode)|0700)
+		case tar.TypeReg, tar.TypeRegA:
+			if err = os.MkdirAll(filepath.Dir(target), 0775); err == nil {
+				err = extractArchiveFile(tr, target, os.FileMode(header.Mode))
+			}
+		case tar.TypeSymlink:
+			if err = links.add(header.Name, header.Linkname); err == nil {
+				if err = os.MkdirAll(filepath.Dir(target), 0775); err == nil {
+					err = createSymlink(header.Linkname, target)
+				}
+			}
+		}
+
+		if err != nil {
+			return err
+		}
+	}
+
+	return unwrapPackageDir(dst)
+}
+
+// getArchiveEntryPath returns where an archive entry is extracted to, refusing
+// entries that would end up outside of dst
+func getArchiveEntryPath(dst string, name string) (string, error) {
+	name = filepath.Clean(filepath.FromSlash(name))
+	if filepath.IsAbs(name) || strings.HasPrefix(name, string(os.PathSeparator)) || name == ".." || strings.HasPrefix(name, ".."+string(os.PathSeparator)) {
+		return "", fmt.Errorf("archive contains a path outside of the package: %s", name)
+	}
+
+	return filepath.Join(dst, name), nil
+}
+
+// archiveLinks are the symlinks extracted from an archive so far, by their
+// cleaned path in the archive. Nothing is extracted through them, and they may
+// not point through each other, or a chain of links could lead outside of the
+// package while each link looks fine on its own.
+type archiveLinks map[string]bool
+
+// check returns an error if the entry name would be extracted through, or in
+// place of, one of the links
+func (links archiveLinks) check(name string) error {
+	for path := filepath.Clean(filepath.FromSlash(name)); path != "." && path != string(filepath.Separator); path = filepath.Dir(path) {
+		if links[path] {
+			return fmt.Errorf("archive contains a path through a link: %s", name)
+		}
+	}
+
+	return nil
+}
+
+// add records the link entry name to linkname, which must lead to a path in
+// the package without passing through another link
+func (links archiveLinks) add(name string, linkname string) error {
+	if filepath.IsAbs(linkname) || strings.HasPrefix(linkname, "/") {
+		return fmt.Errorf("archive contains a link outside of the package: %s", name)
+	}
+
+	path := filepath.Dir(filepath.Clean(filepath.FromSlash(name)))
+	parts := strings.Split(filepath.ToSlash(linkname), "/")
+	for i, part := range parts {
+		switch {
+		case part == "" || part == ".":
+			continue
+		case links[path]:
+			return fmt.Errorf("archive contains a link through another link: %s", name)
+		case part == "..":
+			if path == "." {
+				return fmt.Errorf("archive contains a link outside of the package: %s", name)
+			}
+			path = filepath.Dir(path)
+		default:
+			path = filepath.Join(path, part)
+			if links[path] && i < len(parts)-1 {
+				return fmt.Errorf("archive contains a link through another link: %s", name)
+			}
+		}
+	}
+
+	links[filepath.Clean(filepath.FromSlash(name))] = true
+
+	return nil
+}
+
+func extractArchiveFile(r io.Reader, target string, mode os.FileMode) error {
+	out, err := os.OpenFile(target, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, mode|0600)
+	if err != nil {
+		return err
+	}
+
+	if _, err := io.Copy(out, r); err != nil {
+		out.Close()
+		return err
+	}
+
+	return out.Close()
+}
+
+// unwrapPackageDir moves the contents of the only directory in dir up into dir,
+// unless dir already contains a cli.json
+func unwrapPackageDir(dir string) error {
+	if _, err := os.Stat(filepath.Join(dir, "cli.json")); err == nil {
+		return nil
+	}
+
+	entries, err := ioutil.ReadDir(dir)
+	if err != nil || len(entries) != 1 || !entries[0].IsDir() {
+		return err
+	}
+
+	tmp := dir + ".extract"
+	if err := os.Rename(dir, tmp); err != nil {
+		return err
+	}
+
+	if err := os.Rename(filepath.Join(tmp, entries[0].Name()), dir); err != nil {
+		return err
+	}
+
+	return os.RemoveAll(tmp)
+}
@@ -0,0 +1,127 @@
+/*
+ Copyright 2018. Akamai Technologies, Inc
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package main
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestIsLocalInstallTarget(t *testing.T) {
+	targetTests := []struct {
+		target string
+		local  bool
+	}{
+		{"./cli-purge", true},
+		{"../checkouts/cli-purge", true},
+		{"/opt/packages/cli-purge", true},
+		{"~/cli-purge", true},
+		{".", true},
+		{"cli-purge-1.2.0.tar.gz", true},
+		{"packages/cli-purge.tgz", true},
+		{"purge", false},
+		{"akamai/cli-purge", false},
+		{"purge@1.2.0", false},
+		{"https://github.com/akamai/cli-purge.git", false},
+		{"git@github.com:akamai/cli-purge.git", false},
+	}
+
+	for _, tt := range targetTests {
+		if local := isLocalInstallTarget(tt.target); local != tt.local {
+			t.Errorf("isLocalInstallTarget(%s) => %t, wanted: %t", tt.target, local, tt.local)
+		}
+	}
+}
+
+func TestGetLocalPackageName(t *testing.T) {
+	nameTests := map[string]string{
+		filepath.Join("opt", "cli-purge"):              "cli-purge",
+		filepath.Join("opt", "cli-purge-1.2.0.tar.gz"): "cli-purge",
+		"cli-property-v0.6.1.tgz":                      "cli-property",
+		"my-package.tar":                               "my-package",
+	}
+
+	for path, expected := range nameTests {
+		if name := getLocalPackageName(path); name != expected {
+			t.Errorf("getLocalPackageName(%s) => %s, wanted: %s", path, name, expected)
+		}
+	}
+}
+
+func TestGetArchiveEntryPath(t *testing.T) {
+	dst := filepath.Join("src", "cli-purge")
+
+	entryTests := []struct {
+		name     string
+		expected string
+		valid    bool
+	}{
+		{"cli-purge-1.2.0/cli.json", filepath.Join(dst, "cli-purge-1.2.0", "cli.json"), true},
+		{"./bin/akamai-purge", filepath.Join(dst, "bin", "akamai-purge"), true},
+		{"a/../b", filepath.Join(dst, "b"), true},
+		{"../escape", "", false},
+		{"a/../../escape", "", false},
+		{"/etc/passwd", "", false},
+	}
+
+	for _, tt := range entryTests {
+		path, err := getArchiveEntryPath(dst, tt.name)
+		if tt.valid && (err != nil || path != tt.expected) {
+			t.Errorf("getArchiveEntryPath(%s) => %s, %v, wanted: %s", tt.name, path, err, tt.expected)
+		}
+		if !tt.valid && err == nil {
+			t.Errorf("getArchiveEntryPath(%s) => %s, wanted an error", tt.name, path)
+		}
+	}
+}
+
+func TestArchiveLinks(t *testing.T) {
+	type entry struct {
+		name     string
+		linkname string
+	}
+
+	linkTests := []struct {
+		entries []entry
+		valid   bool
+	}{
+		{[]entry{{"bin/akamai-purge", "../lib/purge.js"}, {"lib/purge.js", ""}}, true},
+		{[]entry{{"current", "."}, {"latest", "current"}}, true},
+		{[]entry{{"escape", "../outside"}}, false},
+		{[]entry{{"escape", "/etc"}}, false},
+		{[]entry{{"a", "."}, {"b", "a/.."}, {"b/evil", ""}}, false},
+		{[]entry{{"a", "."}, {"a/evil", ""}}, false},
+		{[]entry{{"a", "lib"}, {"a", ""}}, false},
+	}
+
+	for _, tt := range linkTests {
+		links := make(archiveLinks)
+		var err error
+		for _, e := range tt.entries {
+			if err = links.check(e.name); err == nil && e.linkname != "" {
+				err = links.add(e.name, e.linkname)
+			}
+			if err != nil {
+				break
+			}
+		}
+
+		if (err == nil) != tt.valid {
+			t.Errorf("archiveLinks(%v) => %v, wanted valid: %t", tt.entries, err, tt.valid)
+		}
+	}
+}
@@ -0,0 +1,104 @@
+/*
+ Copyright 2018. Akamai Technologies, Inc
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// stagingSuffix is that of the directory a package is installed in before it
+// is moved into place
+const stagingSuffix = ".install"
+
+// activeStages are the package directories being installed by this process
+var activeStages = struct {
+	sync.Mutex
+	dirs map[string]bool
+}{dirs: make(map[string]bool)}
+
+// stagedInstall is a package being cloned, or copied, and built in a staging
+// directory. Nothing in its package directory exists until it is promoted,
+// so an install that fails or is interrupted leaves no half-installed package
+// behind for list and update to trip over.
+type stagedInstall struct {
+	// dir is where the package is installed, named like its package directory
+	// for the build tools that use the name
+	dir string
+	// target is the package directory it is promoted to
+	target string
+}
+
+// newStagedInstall returns the staging directory for installing the package
+// dirName in srcPath
+func newStagedInstall(srcPath string, dirName string) (*stagedInstall, error) {
+	target := filepath.Join(srcPath, dirName)
+	if _, err := os.Stat(target); err == nil {
+		return nil, fmt.Errorf("Package directory already exists (%s)", target)
+	}
+
+	activeStages.Lock()
+	defer activeStages.Unlock()
+	if activeStages.dirs[target] {
+		return nil, fmt.Errorf("%s is already being installed", dirName)
+	}
+
+	// Left behind by an install that was interrupted
+	root := target + stagingSuffix
+	if err := removeAllForce(root); err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(root, 0775); err != nil {
+		return nil, err
+	}
+	activeStages.dirs[target] = true
+
+	return &stagedInstall{dir: filepath.Join(root, dirName), target: target}, nil
+}
+
+// promote moves the installed package into its package directory
+func (s *stagedInstall) promote() error {
+	defer s.release()
+
+	if err := os.Rename(s.dir, s.target); err != nil {
+		removeAllForce(filepath.Dir(s.dir))
+		return err
+	}
+	logInfo("installed package", "dir", s.target)
+
+	// Only an empty directory is left, clean can remove it too
+	if err := removeAllForce(filepath.Dir(s.dir)); err != nil {
+		logWarn("unable to remove staging directory", "dir", filepath.Dir(s.dir), "error", err)
+	}
+
+	return nil
+}
+
+// abort removes the staged package
+func (s *stagedInstall) abort() {
+	removeAllForce(filepath.Dir(s.dir))
+	logDebug("removed staged package", "dir", s.dir)
+	s.release()
+}
+
+func (s *stagedInstall) release() {
+	activeStages.Lock()
+	delete(activeStages.dirs, s.target)
+	activeStages.Unlock()
+}
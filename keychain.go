@@ -0,0 +1,136 @@
+/*
+ Copyright 2018. Akamai Technologies, Inc
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package main
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/fatih/color"
+	"github.com/go-ini/ini"
+	"github.com/mitchellh/go-homedir"
+	"github.com/urfave/cli"
+)
+
+const (
+	// keychainService is the service secrets are stored under in the OS keychain
+	keychainService = "akamai-cli"
+	// keychainPlaceholder replaces a client_secret in .edgerc once it is stored in the keychain
+	keychainPlaceholder = "<stored in keychain>"
+)
+
+var errKeychainNotFound = errors.New("secret not found in keychain")
+
+// getKeychainAccount returns the keychain account for a section of a .edgerc file
+func getKeychainAccount(edgerc string, section string) string {
+	return section + "@" + edgerc
+}
+
+// getEdgercPath returns the absolute path of the .edgerc file a command uses:
+// the one given with --edgerc, else the one from the current profile, else ~/.edgerc
+func getEdgercPath(args []string) string {
+	path := getFlagValue(args, "edgerc")
+	if path == "" {
+		path = os.Getenv(edgercEnv)
+	}
+	if path == "" {
+		path = defaultEdgerc
+	}
+
+	if expanded, err := homedir.Expand(path); err == nil {
+		path = expanded
+	}
+	if abs, err := filepath.Abs(path); err == nil {
+		path = abs
+	}
+
+	return path
+}
+
+// getFlagValue returns the value of --name in args, given as --name value or --name=value
+func getFlagValue(args []string, name string) string {
+	for i, arg := range args {
+		if arg == "--" {
+			break
+		}
+
+		flag := strings.TrimLeft(arg, "-")
+		if flag == arg {
+			continue
+		}
+
+		if flag == name && i+1 < len(args) {
+			return args[i+1]
+		}
+
+		if strings.HasPrefix(flag, name+"=") {
+			return strings.TrimPrefix(flag, name+"=")
+		}
+	}
+
+	return ""
+}
+
+// getEdgercEnvPrefix returns the prefix of the environment variables the
+// EdgeGrid libraries read the credentials of a section from, e.g. AKAMAI_PAPI_
+func getEdgercEnvPrefix(section string) string {
+	if section == "" || section == "default" {
+		return "AKAMAI_"
+	}
+
+	return "AKAMAI_" + strings.ToUpper(strings.Replace(section, "-", "_", -1)) + "_"
+}
+
+// applyKeychainEnv gives installed commands the credentials of every section
+// of their .edgerc whose client secret is stored in the keychain, by way of
+// the environment variables the EdgeGrid libraries read instead of .edgerc
+func applyKeychainEnv(args []string) error {
+	path := getEdgercPath(args)
+	if _, err := os.Stat(path); err != nil {
+		return nil
+	}
+
+	edgerc, err := ini.Load(path)
+	if err != nil {
+		return nil
+	}
+
+	for _, section := range edgerc.Sections() {
+		keys := section.KeysHash()
+		if keys["client_secret"] != keychainPlaceholder {
+			continue
+		}
+
+		secret, err := keychainGet(keychainService, getKeychainAccount(path, section.Name()))
+		if err != nil {
+			return cli.NewExitError(color.RedString("Unable to read the client secret of [%s] from the keychain: %s", section.Name(), err.Error()), 1)
+		}
+
+		prefix := getEdgercEnvPrefix(section.Name())
+		os.Setenv(prefix+"HOST", keys["host"])
+		os.Setenv(prefix+"CLIENT_TOKEN", keys["client_token"])
+		os.Setenv(prefix+"ACCESS_TOKEN", keys["access_token"])
+		os.Setenv(prefix+"CLIENT_SECRET", secret)
+		if value, ok := keys["max-body"]; ok {
+			os.Setenv(prefix+"MAX_BODY", value)
+		}
+	}
+
+	return nil
+}
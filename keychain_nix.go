@@ -0,0 +1,104 @@
+// +build !windows
+
+/*
+ Copyright 2018. Akamai Technologies, Inc
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"os/exec"
+	"runtime"
+	"strings"
+)
+
+// keychainSet stores a secret in the macOS Keychain, or the secret service
+// (GNOME Keyring, KWallet) on other systems, by way of secret-tool
+func keychainSet(service string, account string, secret string) error {
+	if runtime.GOOS == "darwin" {
+		// Commands are given on stdin, to keep the secret out of the process list
+		cmd := exec.Command("security", "-i")
+		cmd.Stdin = strings.NewReader(fmt.Sprintf("add-generic-password -U -s %s -a %s -w %s\n", quoteKeychainArg(service), quoteKeychainArg(account), quoteKeychainArg(secret)))
+		return runKeychainCommand(cmd)
+	}
+
+	cmd := exec.Command("secret-tool", "store", "--label", "Akamai CLI: "+account, "service", service, "account", account)
+	cmd.Stdin = strings.NewReader(secret)
+	return runKeychainCommand(cmd)
+}
+
+func keychainGet(service string, account string) (string, error) {
+	cmd := exec.Command("secret-tool", "lookup", "service", service, "account", account)
+	if runtime.GOOS == "darwin" {
+		cmd = exec.Command("security", "find-generic-password", "-s", service, "-a", account, "-w")
+	}
+
+	stdout := &bytes.Buffer{}
+	cmd.Stdout = stdout
+	if err := runKeychainCommand(cmd); err != nil {
+		if _, ok := err.(keychainCommandError); ok {
+			return "", errKeychainNotFound
+		}
+		return "", err
+	}
+
+	secret := strings.TrimRight(stdout.String(), "\r\n")
+	if secret == "" {
+		return "", errKeychainNotFound
+	}
+
+	return secret, nil
+}
+
+func keychainDelete(service string, account string) error {
+	cmd := exec.Command("secret-tool", "clear", "service", service, "account", account)
+	if runtime.GOOS == "darwin" {
+		cmd = exec.Command("security", "delete-generic-password", "-s", service, "-a", account)
+	}
+
+	return runKeychainCommand(cmd)
+}
+
+// keychainCommandError is a keychain tool that ran, but failed
+type keychainCommandError struct {
+	error
+}
+
+func runKeychainCommand(cmd *exec.Cmd) error {
+	if _, err := exec.LookPath(cmd.Path); err != nil {
+		if runtime.GOOS == "darwin" {
+			return fmt.Errorf("unable to find the security tool")
+		}
+		return fmt.Errorf("unable to find secret-tool, install libsecret (e.g. libsecret-tools) to use the secret service")
+	}
+
+	stderr := &bytes.Buffer{}
+	cmd.Stderr = stderr
+	if err := cmd.Run(); err != nil {
+		if message := strings.TrimSpace(stderr.String()); message != "" {
+			return keychainCommandError{fmt.Errorf("%s: %s", cmd.Args[0], message)}
+		}
+		return keychainCommandError{err}
+	}
+
+	return nil
+}
+
+// quoteKeychainArg quotes an argument for "security -i"
+func quoteKeychainArg(arg string) string {
+	return "'" + strings.Replace(arg, "'", `'\''`, -1) + "'"
+}
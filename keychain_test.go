@@ -0,0 +1,54 @@
+/*
+ Copyright 2018. Akamai Technologies, Inc
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package main
+
+import "testing"
+
+func TestGetFlagValue(t *testing.T) {
+	flagTests := []struct {
+		args  []string
+		value string
+	}{
+		{[]string{"list", "--edgerc", "/tmp/edgerc"}, "/tmp/edgerc"},
+		{[]string{"--edgerc=/tmp/edgerc", "list"}, "/tmp/edgerc"},
+		{[]string{"-edgerc", "/tmp/edgerc"}, "/tmp/edgerc"},
+		{[]string{"list", "--section", "papi"}, ""},
+		{[]string{"list", "--", "--edgerc", "/tmp/edgerc"}, ""},
+		{[]string{"list", "--edgerc"}, ""},
+	}
+
+	for _, tt := range flagTests {
+		if value := getFlagValue(tt.args, "edgerc"); value != tt.value {
+			t.Errorf("getFlagValue(%q, edgerc) => %s, wanted: %s", tt.args, value, tt.value)
+		}
+	}
+}
+
+func TestGetEdgercEnvPrefix(t *testing.T) {
+	prefixTests := map[string]string{
+		"default":   "AKAMAI_",
+		"":          "AKAMAI_",
+		"papi":      "AKAMAI_PAPI_",
+		"papi-prod": "AKAMAI_PAPI_PROD_",
+	}
+
+	for section, want := range prefixTests {
+		if prefix := getEdgercEnvPrefix(section); prefix != want {
+			t.Errorf("getEdgercEnvPrefix(%s) => %s, wanted: %s", section, prefix, want)
+		}
+	}
+}
@@ -0,0 +1,82 @@
+/*
+ Copyright 2018. Akamai Technologies, Inc
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// defaultPathExt is used on Windows when PATHEXT is not set
+const defaultPathExt = ".COM;.EXE;.BAT;.CMD"
+
+// findExecutable returns the first of names that is an executable file in
+// dirs, like exec.LookPath limited to dirs, and without looking in the current
+// directory first, as exec.LookPath does on Windows
+func findExecutable(dirs []string, names []string) (string, bool) {
+	for _, name := range names {
+		for _, dir := range dirs {
+			for _, ext := range getExecutableExtensions() {
+				path := filepath.Join(dir, name+ext)
+				if info, err := os.Stat(path); err == nil && !info.IsDir() && isExecutableFile(path, info) {
+					return path, true
+				}
+			}
+		}
+	}
+
+	return "", false
+}
+
+// parsePathExt returns the lower-cased extensions in a PATHEXT value, in order
+func parsePathExt(pathext string) []string {
+	if strings.TrimSpace(pathext) == "" {
+		pathext = defaultPathExt
+	}
+
+	var exts []string
+	for _, ext := range strings.Split(pathext, ";") {
+		ext = strings.ToLower(strings.TrimSpace(ext))
+		if ext == "" {
+			continue
+		}
+		if !strings.HasPrefix(ext, ".") {
+			ext = "." + ext
+		}
+		exts = append(exts, ext)
+	}
+
+	return exts
+}
+
+// quoteBatchArgs builds the command line for a batch file run with cmd /s /c,
+// quoting the arguments that contain spaces or characters cmd.exe would
+// otherwise interpret
+func quoteBatchArgs(args []string) string {
+	quoted := make([]string, len(args))
+	for i, arg := range args {
+		if arg != "" && !strings.ContainsAny(arg, " \t\"&|<>^(),;=!%") {
+			quoted[i] = arg
+			continue
+		}
+
+		quoted[i] = `"` + strings.Replace(arg, `"`, `""`, -1) + `"`
+	}
+
+	return strings.Join(quoted, " ")
+}
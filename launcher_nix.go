@@ -0,0 +1,115 @@
+// +build !windows
+
+/*
+ Copyright 2018. Akamai Technologies, Inc
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package main
+
+import (
+	"os"
+	"os/exec"
+	"syscall"
+
+	"github.com/mattn/go-isatty"
+)
+
+// getExecutableExtensions returns the extensions an executable may have
+func getExecutableExtensions() []string {
+	return []string{""}
+}
+
+func isExecutableFile(path string, info os.FileInfo) bool {
+	return info.Mode()&0111 != 0
+}
+
+// newLaunchCommand returns the command to run an installed executable with its arguments
+func newLaunchCommand(executable []string) *exec.Cmd {
+	return exec.Command(executable[0], executable[1:]...)
+}
+
+// newShellCommand returns the command to run a command line with the shell
+func newShellCommand(line string) *exec.Cmd {
+	return exec.Command("/bin/sh", "-c", line)
+}
+
+// newDetachedCommand returns a command that keeps running in its own session
+// after the CLI exits
+func newDetachedCommand(name string, args ...string) *exec.Cmd {
+	cmd := exec.Command(name, args...)
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setsid: true}
+
+	return cmd
+}
+
+// setProcessGroup runs cmd in a process group of its own, so that it can be
+// stopped along with everything it starts. Only when stdin is not a terminal:
+// commands outside the terminal's foreground group are stopped when they read
+// from it, so interactive commands only have cmd itself stopped.
+func setProcessGroup(cmd *exec.Cmd) {
+	if isatty.IsTerminal(os.Stdin.Fd()) {
+		return
+	}
+
+	if cmd.SysProcAttr == nil {
+		cmd.SysProcAttr = &syscall.SysProcAttr{}
+	}
+	cmd.SysProcAttr.Setpgid = true
+}
+
+// setNonInteractiveProcessGroup runs cmd in a process group of its own, for
+// commands that don't read from the terminal, whatever stdin is
+func setNonInteractiveProcessGroup(cmd *exec.Cmd) {
+	if cmd.SysProcAttr == nil {
+		cmd.SysProcAttr = &syscall.SysProcAttr{}
+	}
+	cmd.SysProcAttr.Setpgid = true
+}
+
+// terminateProcessGroup asks cmd, and its process group if it has one, to exit
+func terminateProcessGroup(cmd *exec.Cmd) {
+	signalProcessGroup(cmd, syscall.SIGTERM)
+}
+
+// killProcessGroup kills cmd, and its process group if it has one
+func killProcessGroup(cmd *exec.Cmd) {
+	signalProcessGroup(cmd, syscall.SIGKILL)
+}
+
+// forwardSignal passes sig on to cmd, and its process group if it has one,
+// and returns whether it did. Interrupts from the terminal already reach the
+// commands in its foreground group, and are not passed on twice.
+func forwardSignal(cmd *exec.Cmd, sig os.Signal) bool {
+	unixSig, ok := sig.(syscall.Signal)
+	if !ok || (sig == os.Interrupt && !hasProcessGroup(cmd)) {
+		return false
+	}
+
+	signalProcessGroup(cmd, unixSig)
+	return true
+}
+
+func hasProcessGroup(cmd *exec.Cmd) bool {
+	return cmd.SysProcAttr != nil && cmd.SysProcAttr.Setpgid
+}
+
+func signalProcessGroup(cmd *exec.Cmd, sig syscall.Signal) {
+	if hasProcessGroup(cmd) {
+		syscall.Kill(-cmd.Process.Pid, sig)
+		return
+	}
+
+	cmd.Process.Signal(sig)
+}
@@ -0,0 +1,54 @@
+/*
+ Copyright 2018. Akamai Technologies, Inc
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParsePathExt(t *testing.T) {
+	pathExtTests := map[string][]string{
+		"":                        {".com", ".exe", ".bat", ".cmd"},
+		".COM;.EXE;.BAT;.CMD;.PY": {".com", ".exe", ".bat", ".cmd", ".py"},
+		".exe;;EXE ; .ps1;":       {".exe", ".exe", ".ps1"},
+	}
+
+	for pathext, want := range pathExtTests {
+		if exts := parsePathExt(pathext); !reflect.DeepEqual(exts, want) {
+			t.Errorf("parsePathExt(%q) => %q, wanted: %q", pathext, exts, want)
+		}
+	}
+}
+
+func TestQuoteBatchArgs(t *testing.T) {
+	quoteTests := []struct {
+		args []string
+		line string
+	}{
+		{[]string{`C:\cli\akamai-purge.cmd`, "invalidate", "--cpcode", "123"}, `C:\cli\akamai-purge.cmd invalidate --cpcode 123`},
+		{[]string{`C:\Program Files\akamai-purge.cmd`, "a b"}, `"C:\Program Files\akamai-purge.cmd" "a b"`},
+		{[]string{"purge.bat", "a&b", "x|y", "^", "100%"}, `purge.bat "a&b" "x|y" "^" "100%"`},
+		{[]string{"purge.bat", `say "hi"`, ""}, `purge.bat "say ""hi""" ""`},
+	}
+
+	for _, tt := range quoteTests {
+		if line := quoteBatchArgs(tt.args); line != tt.line {
+			t.Errorf("quoteBatchArgs(%q) => %s, wanted: %s", tt.args, line, tt.line)
+		}
+	}
+}
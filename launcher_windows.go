@@ -0,0 +1,126 @@
+/*
+ Copyright 2018. Akamai Technologies, Inc
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package main
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+)
+
+// getExecutableExtensions returns the extensions an executable may have, from PATHEXT
+func getExecutableExtensions() []string {
+	return parsePathExt(os.Getenv("PATHEXT"))
+}
+
+func isExecutableFile(path string, info os.FileInfo) bool {
+	ext := strings.ToLower(filepath.Ext(path))
+	for _, executableExt := range getExecutableExtensions() {
+		if ext == executableExt {
+			return true
+		}
+	}
+
+	return false
+}
+
+// newLaunchCommand returns the command to run an installed executable with
+// its arguments. Batch files are run by cmd.exe, with their arguments quoted
+// for it rather than for programs that parse their own command line.
+func newLaunchCommand(executable []string) *exec.Cmd {
+	switch strings.ToLower(filepath.Ext(executable[0])) {
+	case ".bat", ".cmd":
+	default:
+		return exec.Command(executable[0], executable[1:]...)
+	}
+
+	comspec := getComSpec()
+	cmd := exec.Command(comspec)
+	cmd.SysProcAttr = &syscall.SysProcAttr{
+		CmdLine: quoteBatchArgs([]string{comspec}) + ` /d /s /c "` + quoteBatchArgs(executable) + `"`,
+	}
+
+	return cmd
+}
+
+// newShellCommand returns the command to run a command line with cmd.exe
+func newShellCommand(line string) *exec.Cmd {
+	comspec := getComSpec()
+	cmd := exec.Command(comspec)
+	cmd.SysProcAttr = &syscall.SysProcAttr{
+		CmdLine: quoteBatchArgs([]string{comspec}) + ` /d /s /c "` + line + `"`,
+	}
+
+	return cmd
+}
+
+// getComSpec returns the path of cmd.exe
+func getComSpec() string {
+	if comspec := os.Getenv("ComSpec"); comspec != "" {
+		return comspec
+	}
+
+	return "cmd.exe"
+}
+
+const (
+	detachedProcess       = 0x00000008
+	createNewProcessGroup = 0x00000200
+)
+
+// newDetachedCommand returns a command that keeps running without a console
+// after the CLI exits
+func newDetachedCommand(name string, args ...string) *exec.Cmd {
+	cmd := exec.Command(name, args...)
+	cmd.SysProcAttr = &syscall.SysProcAttr{CreationFlags: detachedProcess | createNewProcessGroup}
+
+	return cmd
+}
+
+// setProcessGroup does nothing, the processes cmd starts are found by taskkill
+func setProcessGroup(cmd *exec.Cmd) {
+}
+
+// setNonInteractiveProcessGroup does nothing, like setProcessGroup
+func setNonInteractiveProcessGroup(cmd *exec.Cmd) {
+}
+
+// terminateProcessGroup asks cmd, and the processes it started, to exit
+func terminateProcessGroup(cmd *exec.Cmd) {
+	exec.Command("taskkill", "/T", "/PID", strconv.Itoa(cmd.Process.Pid)).Run()
+}
+
+// forwardSignal asks cmd to exit when the CLI is terminated, and returns
+// whether it did. Ctrl-C already reaches every process on the console.
+func forwardSignal(cmd *exec.Cmd, sig os.Signal) bool {
+	if sig == os.Interrupt {
+		return false
+	}
+
+	terminateProcessGroup(cmd)
+	return true
+}
+
+// killProcessGroup kills cmd and the processes it started
+func killProcessGroup(cmd *exec.Cmd) {
+	if err := exec.Command("taskkill", "/T", "/F", "/PID", strconv.Itoa(cmd.Process.Pid)).Run(); err != nil {
+		cmd.Process.Kill()
+	}
+}
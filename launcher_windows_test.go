@@ -0,0 +1,57 @@
+/*
+ Copyright 2018. Akamai Technologies, Inc
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package main
+
+import (
+	"os"
+	"testing"
+)
+
+func TestIsExecutableFile(t *testing.T) {
+	os.Setenv("PATHEXT", ".COM;.EXE;.BAT;.CMD")
+	defer os.Unsetenv("PATHEXT")
+
+	executableTests := map[string]bool{
+		`C:\cli\src\cli-purge\akamai-purge.exe`: true,
+		`C:\cli\src\cli-purge\akamai-purge.EXE`: true,
+		`C:\cli\src\cli-purge\akamai-purge.cmd`: true,
+		`C:\cli\src\cli-purge\akamai-purge`:     false,
+		`C:\cli\src\cli-purge\akamai-purge.js`:  false,
+		`C:\cli\src\cli-purge\akamai-purge.ps1`: false,
+	}
+
+	for path, executable := range executableTests {
+		if isExecutable := isExecutableFile(path, nil); isExecutable != executable {
+			t.Errorf("isExecutableFile(%s) => %t, wanted: %t", path, isExecutable, executable)
+		}
+	}
+}
+
+func TestNewLaunchCommand(t *testing.T) {
+	os.Setenv("ComSpec", `C:\Windows\system32\cmd.exe`)
+
+	cmd := newLaunchCommand([]string{`C:\cli\src\cli-purge\akamai-purge.exe`, "a b"})
+	if cmd.SysProcAttr != nil {
+		t.Errorf("newLaunchCommand(akamai-purge.exe) => CmdLine %s, wanted: none", cmd.SysProcAttr.CmdLine)
+	}
+
+	cmd = newLaunchCommand([]string{`C:\cli\src\cli-purge\akamai-purge.cmd`, "a&b"})
+	want := `C:\Windows\system32\cmd.exe /d /s /c "C:\cli\src\cli-purge\akamai-purge.cmd "a&b""`
+	if cmd.SysProcAttr == nil || cmd.SysProcAttr.CmdLine != want {
+		t.Errorf("newLaunchCommand(akamai-purge.cmd) => %+v, wanted CmdLine: %s", cmd.SysProcAttr, want)
+	}
+}
@@ -0,0 +1,189 @@
+/*
+ Copyright 2018. Akamai Technologies, Inc
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	akamai "github.com/akamai/cli-common-golang"
+)
+
+const (
+	// logEnv sets the log level: debug, info, warn, or error. --verbose and --debug set it to info and debug.
+	logEnv = "AKAMAI_CLI_LOG"
+	// logFileEnv names a file to append log lines to, instead of stderr
+	logFileEnv = "AKAMAI_CLI_LOGFILE"
+	// logFormatEnv selects the log line format: text (key=value, the default) or json
+	logFormatEnv = "AKAMAI_CLI_LOG_FORMAT"
+)
+
+type logLevel int
+
+const (
+	logLevelDebug logLevel = iota
+	logLevelInfo
+	logLevelWarn
+	logLevelError
+	logLevelOff
+)
+
+var logLevelNames = map[logLevel]string{
+	logLevelDebug: "debug",
+	logLevelInfo:  "info",
+	logLevelWarn:  "warn",
+	logLevelError: "error",
+}
+
+var logger struct {
+	sync.Mutex
+	configured bool
+	level      logLevel
+	json       bool
+	out        io.Writer
+}
+
+// configureLogging (re)reads the log settings from the environment. Logging
+// is off unless a level or log file is set.
+func configureLogging() {
+	logger.Lock()
+	defer logger.Unlock()
+
+	configureLoggingLocked()
+}
+
+func configureLoggingLocked() {
+	logger.configured = true
+	logger.level = logLevelOff
+	logger.json = strings.ToLower(os.Getenv(logFormatEnv)) == "json"
+	logger.out = os.Stderr
+	if akamai.App != nil && akamai.App.ErrWriter != nil {
+		logger.out = akamai.App.ErrWriter
+	}
+
+	if path := os.Getenv(logFileEnv); path != "" {
+		file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0664)
+		if err != nil {
+			fmt.Fprintf(logger.out, "Unable to open log file %s: %s\n", path, err.Error())
+		} else {
+			logger.out = file
+			logger.level = logLevelInfo
+		}
+	}
+
+	level := strings.ToLower(os.Getenv(logEnv))
+	for l, name := range logLevelNames {
+		if level == name {
+			logger.level = l
+		}
+	}
+}
+
+func logDebug(msg string, keyvals ...interface{}) { logAt(logLevelDebug, msg, keyvals...) }
+func logInfo(msg string, keyvals ...interface{})  { logAt(logLevelInfo, msg, keyvals...) }
+func logWarn(msg string, keyvals ...interface{})  { logAt(logLevelWarn, msg, keyvals...) }
+func logError(msg string, keyvals ...interface{}) { logAt(logLevelError, msg, keyvals...) }
+
+// logAt writes a log line with msg and the given key/value pairs, if level is enabled
+func logAt(level logLevel, msg string, keyvals ...interface{}) {
+	logger.Lock()
+	defer logger.Unlock()
+
+	if !logger.configured {
+		configureLoggingLocked()
+	}
+
+	if level < logger.level {
+		return
+	}
+
+	keys := []string{"time", "level", "msg"}
+	values := []interface{}{time.Now().Format(time.RFC3339), logLevelNames[level], msg}
+	for i := 0; i+1 < len(keyvals); i += 2 {
+		keys = append(keys, fmt.Sprint(keyvals[i]))
+		value := keyvals[i+1]
+		if err, ok := value.(error); ok {
+			value = err.Error()
+		}
+		values = append(values, value)
+	}
+
+	fmt.Fprintln(logger.out, formatLogLine(keys, values, logger.json))
+}
+
+func formatLogLine(keys []string, values []interface{}, asJSON bool) string {
+	buf := &bytes.Buffer{}
+
+	if asJSON {
+		// Written by hand to keep the keys in order
+		buf.WriteString("{")
+		for i, key := range keys {
+			if i > 0 {
+				buf.WriteString(",")
+			}
+			k, _ := json.Marshal(key)
+			v, err := json.Marshal(values[i])
+			if err != nil {
+				v, _ = json.Marshal(fmt.Sprint(values[i]))
+			}
+			buf.Write(k)
+			buf.WriteString(":")
+			buf.Write(v)
+		}
+		buf.WriteString("}")
+
+		return buf.String()
+	}
+
+	for i, key := range keys {
+		if i > 0 {
+			buf.WriteString(" ")
+		}
+
+		value := fmt.Sprint(values[i])
+		if value == "" || strings.ContainsAny(value, " \t\n\"=") {
+			value = strconv.Quote(value)
+		}
+		buf.WriteString(key + "=" + value)
+	}
+
+	return buf.String()
+}
+
+// runCommand runs a subprocess, logging its arguments, outcome and duration
+func runCommand(cmd *exec.Cmd) error {
+	start := time.Now()
+	logDebug("exec start", "cmd", strings.Join(cmd.Args, " "), "dir", cmd.Dir)
+
+	err := cmd.Run()
+	if err != nil {
+		logWarn("exec failed", "cmd", strings.Join(cmd.Args, " "), "dir", cmd.Dir, "duration", time.Since(start).String(), "error", err)
+		return err
+	}
+
+	logDebug("exec done", "cmd", strings.Join(cmd.Args, " "), "dir", cmd.Dir, "duration", time.Since(start).String())
+
+	return nil
+}
@@ -0,0 +1,38 @@
+/*
+ Copyright 2018. Akamai Technologies, Inc
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package main
+
+import "testing"
+
+func TestFormatLogLine(t *testing.T) {
+	keys := []string{"level", "msg", "status", "cmd", "dir"}
+	values := []interface{}{"info", "http request", 200, `npm install --save="x"`, ""}
+
+	formatTests := []struct {
+		json bool
+		line string
+	}{
+		{false, `level=info msg="http request" status=200 cmd="npm install --save=\"x\"" dir=""`},
+		{true, `{"level":"info","msg":"http request","status":200,"cmd":"npm install --save=\"x\"","dir":""}`},
+	}
+
+	for _, tt := range formatTests {
+		if line := formatLogLine(keys, values, tt.json); line != tt.line {
+			t.Errorf("formatLogLine(json: %t) => %s, wanted: %s", tt.json, line, tt.line)
+		}
+	}
+}
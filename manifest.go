@@ -0,0 +1,135 @@
+/*
+ Copyright 2018. Akamai Technologies, Inc
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package main
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// packageManifest records how an installed package was obtained, so that
+// later operations (update, uninstall) can find their way back to it.
+//
+// Manifests live outside of the package checkout, in the manifests directory
+// of the CLI home, and are keyed by the package directory name under src.
+type packageManifest struct {
+	Repo    string `json:"repo"`
+	Subpath string `json:"subpath,omitempty"`
+	// Version is the tag the package is pinned to, if it was installed with <package>@<version>
+	Version string `json:"version,omitempty"`
+	// Source is the local directory or archive the package was installed from, instead of Repo
+	Source string `json:"source,omitempty"`
+	// PreviousCommit is the commit checked out before the last update, for akamai rollback
+	PreviousCommit string `json:"previousCommit,omitempty"`
+	// Commit is the commit checked out by the last install, update, or rollback, for akamai verify
+	Commit string `json:"commit,omitempty"`
+	// Binary is the URL of the pre-built binary the package was installed from, instead of its source
+	Binary string `json:"binary,omitempty"`
+	// BinarySHA256 is the checksum of Binary, for akamai verify
+	BinarySHA256 string `json:"binarySha256,omitempty"`
+	// Disabled packages stay installed, but their commands can't be run, see akamai package disable
+	Disabled bool `json:"disabled,omitempty"`
+}
+
+func getManifestPath(name string) (string, error) {
+	cliPath, err := getAkamaiCliPath()
+	if err != nil {
+		return "", err
+	}
+
+	// Workspace packages keep their manifests in the workspace
+	if isWorkspacePackage(name) {
+		cliPath = filepath.Join(findWorkspace(), workspaceDir)
+	}
+
+	manifestPath := filepath.Join(cliPath, "manifests")
+	if err := os.MkdirAll(manifestPath, 0775); err != nil {
+		return "", err
+	}
+
+	return filepath.Join(manifestPath, name+".json"), nil
+}
+
+func readManifest(name string) (packageManifest, error) {
+	manifest := packageManifest{}
+
+	path, err := getManifestPath(name)
+	if err != nil {
+		return manifest, err
+	}
+
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return manifest, err
+	}
+
+	err = json.Unmarshal(data, &manifest)
+	return manifest, err
+}
+
+func writeManifest(name string, manifest packageManifest) error {
+	path, err := getManifestPath(name)
+	if err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return ioutil.WriteFile(path, data, 0664)
+}
+
+func removeManifest(name string) {
+	path, err := getManifestPath(name)
+	if err != nil {
+		return
+	}
+
+	os.Remove(path)
+}
+
+// getPackageRoot returns the top-level directory under src, or the src of the
+// workspace, that contains dir.
+// For most packages this is the package directory itself, but packages installed
+// from a monorepo subpath live below the root of their checkout.
+func getPackageRoot(dir string) string {
+	srcPath, err := getAkamaiCliSrcPath()
+	if err != nil {
+		return dir
+	}
+
+	srcPaths := []string{srcPath}
+	if workspaceSrcPath, ok := getWorkspaceSrcPath(); ok {
+		srcPaths = append([]string{workspaceSrcPath}, srcPaths...)
+	}
+
+	for _, srcPath := range srcPaths {
+		rel, err := filepath.Rel(srcPath, dir)
+		if err != nil || rel == "." || strings.HasPrefix(rel, "..") {
+			continue
+		}
+
+		return filepath.Join(srcPath, strings.Split(rel, string(os.PathSeparator))[0])
+	}
+
+	return dir
+}
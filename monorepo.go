@@ -0,0 +1,69 @@
+/*
+ Copyright 2018. Akamai Technologies, Inc
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package main
+
+import (
+	"path"
+	"path/filepath"
+
+	"gopkg.in/src-d/go-git.v4"
+	"gopkg.in/src-d/go-git.v4/plumbing"
+)
+
+// Packages installed from a subpath of a monorepo each have their own
+// checkout of the whole repository, but only the commits that change their
+// subpath are updates to them.
+
+// getSubpathTreeHash returns the hash of the subpath directory at a commit
+func getSubpathTreeHash(repo *git.Repository, hash plumbing.Hash, subpath string) (plumbing.Hash, error) {
+	commit, err := repo.CommitObject(hash)
+	if err != nil {
+		return plumbing.ZeroHash, err
+	}
+
+	tree, err := commit.Tree()
+	if err != nil {
+		return plumbing.ZeroHash, err
+	}
+
+	subtree, err := tree.Tree(path.Clean(filepath.ToSlash(subpath)))
+	if err != nil {
+		return plumbing.ZeroHash, err
+	}
+
+	return subtree.Hash, nil
+}
+
+// subpathChanged returns whether subpath differs between two commits. Without
+// a subpath, or if either commit can't be read, it is assumed to have changed.
+func subpathChanged(repo *git.Repository, from plumbing.Hash, to plumbing.Hash, subpath string) bool {
+	if subpath == "" {
+		return from != to
+	}
+
+	fromHash, err := getSubpathTreeHash(repo, from, subpath)
+	if err != nil {
+		return true
+	}
+
+	toHash, err := getSubpathTreeHash(repo, to, subpath)
+	if err != nil {
+		return true
+	}
+
+	return fromHash != toHash
+}
@@ -0,0 +1,94 @@
+/*
+ Copyright 2018. Akamai Technologies, Inc
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+const offlineEnv = "AKAMAI_CLI_OFFLINE"
+
+// globalFlagsWithValues are the global flags that take a separate value, e.g. --format json
+var globalFlagsWithValues = map[string]bool{
+	"proxy":    true,
+	"registry": true,
+	"format":   true,
+	"profile":  true,
+	"package":  true,
+}
+
+// isOffline reports whether network access has been disabled, with --offline
+// or by setting AKAMAI_CLI_OFFLINE
+func isOffline() bool {
+	switch strings.ToLower(os.Getenv(offlineEnv)) {
+	case "", "0", "false", "no":
+		return false
+	}
+
+	return true
+}
+
+// detectGlobalFlags looks for --offline, --verbose, --debug, --quiet and --no-color among the global
+// flags, so that checks made before the app parses its arguments (upgrades,
+// pings) honor them too
+func detectGlobalFlags(args []string) {
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		if !strings.HasPrefix(arg, "-") {
+			return
+		}
+
+		switch strings.TrimLeft(arg, "-") {
+		case "offline":
+			os.Setenv(offlineEnv, "1")
+		case "verbose":
+			os.Setenv(logEnv, "info")
+		case "debug":
+			os.Setenv(logEnv, "debug")
+		case "quiet", "q":
+			os.Setenv(quietEnv, "1")
+		case "no-color":
+			os.Setenv(noColorEnv, "1")
+		}
+
+		if globalFlagsWithValues[strings.TrimLeft(arg, "-")] {
+			// Skip the flag value
+			i++
+		}
+	}
+}
+
+// getCommandArg returns the command being run, the first argument after the global flags
+func getCommandArg(args []string) string {
+	for i := 0; i < len(args); i++ {
+		if !strings.HasPrefix(args[i], "-") {
+			return args[i]
+		}
+
+		if globalFlagsWithValues[strings.TrimLeft(args[i], "-")] {
+			i++
+		}
+	}
+
+	return ""
+}
+
+func offlineError(action string) error {
+	return fmt.Errorf("Unable to %s while offline, network access is disabled by --offline or %s", action, offlineEnv)
+}
@@ -0,0 +1,151 @@
+/*
+ Copyright 2018. Akamai Technologies, Inc
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	akamai "github.com/akamai/cli-common-golang"
+	"github.com/urfave/cli"
+)
+
+// operationLockFile, in the CLI home, is locked while packages are installed,
+// updated, or removed, so that concurrent runs don't change the same packages
+const operationLockFile = "operation.lock"
+
+// operationLockEnv is set for the processes a locked operation runs, such as
+// hooks, which may run akamai again as part of the same operation
+const operationLockEnv = "AKAMAI_CLI_OPERATION_LOCK"
+
+// backgroundCheckOperation is the name the background update check holds the
+// lock under. Commands wait for it rather than fail, as it is not the user's.
+const backgroundCheckOperation = "background update check"
+
+// waitFlag is the --wait flag of the commands that take the operation lock
+var waitFlag = cli.BoolFlag{
+	Name:  "wait",
+	Usage: "Wait for another package operation in progress to finish, instead of failing",
+}
+
+// operationHolder is written to the lock file by the process holding it
+type operationHolder struct {
+	PID     int    `json:"pid"`
+	Command string `json:"command"`
+	Started string `json:"started"`
+}
+
+// withOperationLock wraps a package management command so that it runs while
+// holding the operation lock. If another operation holds it, the command
+// fails, or with --wait, waits for it to finish.
+func withOperationLock(name string, action func(*cli.Context) error) func(*cli.Context) error {
+	return func(c *cli.Context) error {
+		if os.Getenv(operationLockEnv) != "" {
+			return action(c)
+		}
+
+		unlock, err := lockOperation(name, c.Bool("wait"))
+		if err != nil {
+			return err
+		}
+		defer unlock()
+
+		os.Setenv(operationLockEnv, strconv.Itoa(os.Getpid()))
+		defer os.Unsetenv(operationLockEnv)
+
+		return action(c)
+	}
+}
+
+// lockOperation takes the operation lock for the command name, and returns the
+// function that releases it
+func lockOperation(name string, wait bool) (func(), error) {
+	cliPath, err := getAkamaiCliPath()
+	if err != nil {
+		return nil, err
+	}
+
+	path := filepath.Join(cliPath, operationLockFile)
+	file, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0644)
+	if err != nil {
+		return nil, newError(errGeneral, "Unable to open %s: %s", path, err.Error())
+	}
+
+	locked, err := lockFile(file, false)
+	if err == nil && !locked {
+		holder := describeOperationHolder(path)
+		if !wait && readOperationHolder(path).Command != backgroundCheckOperation {
+			file.Close()
+			return nil, newError(errLocked, "Another package operation is in progress (%s). Wait for it to finish, or pass --wait.", holder)
+		}
+
+		printInfo(akamai.App.ErrWriter, "Waiting for another package operation to finish (%s)...", holder)
+		locked, err = lockFile(file, true)
+	}
+	if err != nil {
+		file.Close()
+		return nil, newError(errGeneral, "Unable to lock %s: %s", path, err.Error())
+	}
+
+	logDebug("operation lock taken", "path", path, "command", name)
+	writeOperationHolder(file, name)
+
+	return func() {
+		file.Truncate(0)
+		unlockFile(file)
+		file.Close()
+		logDebug("operation lock released", "path", path, "command", name)
+	}, nil
+}
+
+func writeOperationHolder(file *os.File, name string) {
+	data, _ := json.Marshal(operationHolder{
+		PID:     os.Getpid(),
+		Command: name,
+		Started: time.Now().Format(time.RFC3339),
+	})
+
+	file.Truncate(0)
+	file.WriteAt(data, 0)
+}
+
+// readOperationHolder returns what the lock file at path says about who holds
+// it, if anything
+func readOperationHolder(path string) operationHolder {
+	holder := operationHolder{}
+	if data, err := ioutil.ReadFile(path); err == nil {
+		json.Unmarshal(data, &holder)
+	}
+
+	return holder
+}
+
+// describeOperationHolder returns who holds the lock at path, as far as it
+// says, e.g. "akamai update, pid 1234, started 2018-06-01T10:00:00Z"
+func describeOperationHolder(path string) string {
+	holder := readOperationHolder(path)
+	if holder.PID == 0 {
+		return "unknown"
+	}
+
+	return fmt.Sprintf("%s %s, pid %d, started %s", self(), holder.Command, holder.PID, holder.Started)
+}
@@ -0,0 +1,329 @@
+/*
+ Copyright 2018. Akamai Technologies, Inc
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	akamai "github.com/akamai/cli-common-golang"
+	"github.com/fatih/color"
+	"github.com/urfave/cli"
+)
+
+// formatEnv holds the output format set with --format
+const formatEnv = "AKAMAI_CLI_FORMAT"
+
+const (
+	formatTable = "table"
+	formatJSON  = "json"
+	formatYAML  = "yaml"
+)
+
+// getOutputFormat returns the output format for package management commands,
+// table (the usual human readable output), json, or yaml
+func getOutputFormat() string {
+	switch format := strings.ToLower(os.Getenv(formatEnv)); format {
+	case formatJSON, formatYAML:
+		return format
+	}
+
+	return formatTable
+}
+
+func validateOutputFormat(format string) error {
+	switch strings.ToLower(format) {
+	case formatTable, formatJSON, formatYAML:
+		return nil
+	}
+
+	return fmt.Errorf("Invalid format \"%s\", must be one of: table, json, yaml", format)
+}
+
+// printStructured writes v to stdout in the selected structured format
+func printStructured(v interface{}) error {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return cli.NewExitError(color.RedString(err.Error()), 1)
+	}
+
+	if getOutputFormat() == formatYAML {
+		var generic interface{}
+		if err := json.Unmarshal(data, &generic); err != nil {
+			return cli.NewExitError(color.RedString(err.Error()), 1)
+		}
+
+		fmt.Fprint(akamai.App.Writer, encodeYAML(generic))
+		return nil
+	}
+
+	fmt.Fprintln(akamai.App.Writer, string(data))
+	return nil
+}
+
+// packageResult is the outcome of installing, updating, or uninstalling one package
+type packageResult struct {
+	Package  string `json:"package"`
+	Status   string `json:"status"`
+	ExitCode int    `json:"exitCode"`
+	// ErrorID is one of the E_ identifiers in errors.go
+	ErrorID string `json:"errorId,omitempty"`
+	Error   string `json:"error,omitempty"`
+}
+
+type commandReport struct {
+	Command  string          `json:"command"`
+	ExitCode int             `json:"exitCode"`
+	ErrorID  string          `json:"errorId,omitempty"`
+	Error    string          `json:"error,omitempty"`
+	Results  []packageResult `json:"results"`
+}
+
+// commandError is written for commands without a report when they fail
+type commandError struct {
+	Command  string `json:"command"`
+	ExitCode int    `json:"exitCode"`
+	ErrorID  string `json:"errorId"`
+	Error    string `json:"error,omitempty"`
+}
+
+var report struct {
+	sync.Mutex
+	results []packageResult
+}
+
+// recordPackageResult notes the outcome for a package, for structured output,
+// and returns err unchanged
+func recordPackageResult(name string, err error) error {
+	result := packageResult{Package: name, Status: "ok"}
+	if err != nil {
+		result.Status = "failed"
+		result.ExitCode = getExitCode(err)
+		result.ErrorID = getErrorID(err)
+		result.Error = strings.TrimSpace(stripColor(err.Error()))
+	}
+
+	report.Lock()
+	report.results = append(report.results, result)
+	report.Unlock()
+
+	return err
+}
+
+// withReport wraps a package management command so that, with --format json or
+// yaml, its usual output goes to stderr and a report of what happened to each
+// package is written to stdout
+func withReport(name string, action func(*cli.Context) error) func(*cli.Context) error {
+	return func(c *cli.Context) error {
+		if getOutputFormat() == formatTable {
+			return action(c)
+		}
+
+		stdout := akamai.App.Writer
+		akamai.App.Writer = akamai.App.ErrWriter
+		err := action(c)
+		akamai.App.Writer = stdout
+
+		report.Lock()
+		results := append([]packageResult{}, report.results...)
+		report.Unlock()
+
+		cmdReport := commandReport{Command: name, Results: results}
+		if err != nil {
+			cmdReport.ExitCode = getExitCode(err)
+			cmdReport.ErrorID = getErrorID(err)
+			cmdReport.Error = strings.TrimSpace(stripColor(err.Error()))
+		}
+
+		if printErr := printStructured(cmdReport); printErr != nil {
+			return printErr
+		}
+
+		return err
+	}
+}
+
+// withErrorReport wraps a command that has no report so that, with --format
+// json or yaml, its failures are written to stdout with their identifier.
+// Package commands that fail are not, as stdout is theirs.
+func withErrorReport(name string, action func(*cli.Context) error) func(*cli.Context) error {
+	return func(c *cli.Context) error {
+		err := action(c)
+		if err == nil || getOutputFormat() == formatTable || getErrorID(err) == errCommandFailed {
+			return err
+		}
+
+		cmdError := commandError{
+			Command:  name,
+			ExitCode: getExitCode(err),
+			ErrorID:  getErrorID(err),
+			Error:    strings.TrimSpace(stripColor(err.Error())),
+		}
+		if printErr := printStructured(cmdError); printErr != nil {
+			return printErr
+		}
+
+		return err
+	}
+}
+
+func getExitCode(err error) int {
+	if exitErr, ok := err.(cli.ExitCoder); ok {
+		return exitErr.ExitCode()
+	}
+
+	return 1
+}
+
+// stripColor removes terminal color sequences from s
+func stripColor(s string) string {
+	buf := &bytes.Buffer{}
+	for i := 0; i < len(s); i++ {
+		if s[i] == '\x1b' && i+1 < len(s) && s[i+1] == '[' {
+			j := i + 2
+			for j < len(s) && s[j] != 'm' {
+				j++
+			}
+			i = j
+			continue
+		}
+		buf.WriteByte(s[i])
+	}
+
+	return buf.String()
+}
+
+// encodeYAML renders a decoded JSON value as YAML. Object keys are sorted.
+func encodeYAML(v interface{}) string {
+	buf := &bytes.Buffer{}
+	writeYAML(buf, v, 0)
+
+	return buf.String()
+}
+
+func writeYAML(buf *bytes.Buffer, v interface{}, indent int) {
+	pad := strings.Repeat("  ", indent)
+
+	switch value := v.(type) {
+	case map[string]interface{}:
+		if len(value) == 0 {
+			buf.WriteString(pad + "{}\n")
+			return
+		}
+
+		keys := make([]string, 0, len(value))
+		for key := range value {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+
+		for _, key := range keys {
+			buf.WriteString(pad + yamlScalar(key) + ":")
+			writeYAMLValue(buf, value[key], indent)
+		}
+	case []interface{}:
+		if len(value) == 0 {
+			buf.WriteString(pad + "[]\n")
+			return
+		}
+
+		for _, item := range value {
+			// Objects start on the same line as their dash
+			if object, ok := item.(map[string]interface{}); ok && len(object) > 0 {
+				nested := &bytes.Buffer{}
+				writeYAML(nested, object, indent+1)
+				buf.WriteString(pad + "- " + nested.String()[len(pad)+2:])
+				continue
+			}
+
+			buf.WriteString(pad + "-")
+			writeYAMLValue(buf, item, indent)
+		}
+	default:
+		buf.WriteString(pad + yamlScalar(value) + "\n")
+	}
+}
+
+// writeYAMLValue writes the value of a key or list item, inline if it is a
+// scalar or empty, or on the following lines otherwise
+func writeYAMLValue(buf *bytes.Buffer, v interface{}, indent int) {
+	switch value := v.(type) {
+	case map[string]interface{}:
+		if len(value) > 0 {
+			buf.WriteString("\n")
+			writeYAML(buf, value, indent+1)
+			return
+		}
+		buf.WriteString(" {}\n")
+	case []interface{}:
+		if len(value) > 0 {
+			buf.WriteString("\n")
+			writeYAML(buf, value, indent+1)
+			return
+		}
+		buf.WriteString(" []\n")
+	default:
+		buf.WriteString(" " + yamlScalar(value) + "\n")
+	}
+}
+
+func yamlScalar(v interface{}) string {
+	switch value := v.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return strconv.FormatBool(value)
+	case float64:
+		return strconv.FormatFloat(value, 'f', -1, 64)
+	case string:
+		if yamlNeedsQuotes(value) {
+			return strconv.Quote(value)
+		}
+		return value
+	}
+
+	return strconv.Quote(fmt.Sprint(v))
+}
+
+// yamlNeedsQuotes reports whether a string would be read back as something
+// else, or break the document, if written unquoted
+func yamlNeedsQuotes(s string) bool {
+	if s == "" || strings.TrimSpace(s) != s {
+		return true
+	}
+
+	switch strings.ToLower(s) {
+	case "true", "false", "yes", "no", "on", "off", "null", "~":
+		return true
+	}
+
+	if _, err := strconv.ParseFloat(s, 64); err == nil {
+		return true
+	}
+
+	if strings.ContainsAny(s[:1], "-?:,[]{}#&*!|>'\"%@`") {
+		return true
+	}
+
+	return strings.ContainsAny(s, "\n\t\\") || strings.Contains(s, ": ") || strings.Contains(s, " #") || strings.HasSuffix(s, ":")
+}
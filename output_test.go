@@ -0,0 +1,71 @@
+/*
+ Copyright 2018. Akamai Technologies, Inc
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package main
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestEncodeYAML(t *testing.T) {
+	yamlTests := []struct {
+		json string
+		yaml string
+	}{
+		{`{"command": "install", "exitCode": 0, "results": []}`, "command: install\nexitCode: 0\nresults: []\n"},
+		{
+			`{"results": [{"package": "purge", "status": "ok"}, {"package": "dns", "status": "failed", "error": "Unable to clone: 404"}]}`,
+			"results:\n  - package: purge\n    status: ok\n  - error: \"Unable to clone: 404\"\n    package: dns\n    status: failed\n",
+		},
+		{`[{"aliases": ["p", "prop"], "name": "property"}]`, "- aliases:\n    - p\n    - prop\n  name: property\n"},
+		{`{"a": {}, "b": null, "c": true, "d": "1.0"}`, "a: {}\nb: null\nc: true\nd: \"1.0\"\n"},
+	}
+
+	for _, tt := range yamlTests {
+		var v interface{}
+		if err := json.Unmarshal([]byte(tt.json), &v); err != nil {
+			t.Fatal(err)
+		}
+
+		if yaml := encodeYAML(v); yaml != tt.yaml {
+			t.Errorf("encodeYAML(%s) => %q, wanted: %q", tt.json, yaml, tt.yaml)
+		}
+	}
+}
+
+func TestYAMLNeedsQuotes(t *testing.T) {
+	quoteTests := map[string]bool{
+		"purge":        false,
+		"cli-purge":    false,
+		"hello world":  false,
+		"":             true,
+		" padded":      true,
+		"yes":          true,
+		"Null":         true,
+		"1.2":          true,
+		"-dash":        true,
+		"key: value":   true,
+		"comment #tag": true,
+		"line\nbreak":  true,
+	}
+
+	for s, expected := range quoteTests {
+		if quotes := yamlNeedsQuotes(s); quotes != expected {
+			t.Errorf("yamlNeedsQuotes(%q) => %t, wanted: %t", s, quotes, expected)
+		}
+	}
+}
@@ -17,34 +17,122 @@
 package main
 
 import (
-	"bytes"
+	"crypto/sha256"
 	"encoding/json"
+	"fmt"
 	"io"
 	"io/ioutil"
-	"net/http"
 	"os"
 	"path/filepath"
 	"runtime"
 	"strings"
-	"text/template"
 
+	akamai "github.com/akamai/cli-common-golang"
+	"github.com/fatih/color"
 	"github.com/urfave/cli"
 )
 
 type commandPackage struct {
 	Commands []Command `json:"commands"`
 
-	Requirements struct {
-		Go     string `json:"go"`
-		Php    string `json:"php"`
-		Node   string `json:"node"`
-		Ruby   string `json:"ruby"`
-		Python string `json:"python"`
-	} `json:"requirements"`
+	Requirements packageRequirements `json:"requirements"`
+
+	License packageLicense `json:"license"`
+
+	// Dependencies maps the Akamai CLI packages this package needs to semver ranges
+	Dependencies map[string]string `json:"dependencies"`
+
+	// Docker is how the package runs with "akamai config set <package>.runtime docker"
+	Docker packageDocker `json:"docker"`
 
 	action interface{}
 }
 
+// packageRequirements are the minimum versions of the language runtimes a
+// package needs, or * for any version
+type packageRequirements struct {
+	Go     string `json:"go"`
+	Php    string `json:"php"`
+	Node   string `json:"node"`
+	Ruby   string `json:"ruby"`
+	Python string `json:"python"`
+}
+
+// runtimes maps the runtimes in runtimeBinaries to their required versions
+func (requirements packageRequirements) runtimes() map[string]string {
+	runtimes := make(map[string]string)
+	for runtime, version := range map[string]string{
+		"go":     requirements.Go,
+		"php":    requirements.Php,
+		"node":   requirements.Node,
+		"ruby":   requirements.Ruby,
+		"python": requirements.Python,
+	} {
+		if version != "" {
+			runtimes[runtime] = version
+		}
+	}
+
+	return runtimes
+}
+
+// packageLicense may be given in cli.json either as a plain license name, or
+// as an object describing the license and whether it must be accepted
+type packageLicense struct {
+	Name              string `json:"name"`
+	File              string `json:"file"`
+	Text              string `json:"text"`
+	RequireAcceptance bool   `json:"require-acceptance"`
+}
+
+func (license *packageLicense) UnmarshalJSON(data []byte) error {
+	var name string
+	if err := json.Unmarshal(data, &name); err == nil {
+		license.Name = name
+		return nil
+	}
+
+	type licenseObject packageLicense
+	return json.Unmarshal(data, (*licenseObject)(license))
+}
+
+// getLicenseText returns the full license text to present to the user
+func (license packageLicense) getLicenseText(dir string) string {
+	if path, ok := license.getFilePath(dir); ok {
+		if text, err := ioutil.ReadFile(path); err == nil {
+			return string(text)
+		}
+	}
+
+	if license.Text != "" {
+		return license.Text
+	}
+
+	return license.Name
+}
+
+// getFilePath returns the path of the license file in the package in dir. A
+// file outside of the package, or a link to one, is refused, so that a package
+// can't show another of the user's files as its license.
+func (license packageLicense) getFilePath(dir string) (string, bool) {
+	if license.File == "" || filepath.IsAbs(license.File) {
+		return "", false
+	}
+
+	path := filepath.Join(dir, license.File)
+	if !isPathInside(dir, path) {
+		return "", false
+	}
+
+	realDir, dirErr := filepath.EvalSymlinks(dir)
+	realPath, pathErr := filepath.EvalSymlinks(path)
+	if dirErr == nil && pathErr == nil && !isPathInside(realDir, realPath) {
+		return "", false
+	}
+
+	return path, true
+}
+
 func readPackage(dir string) (commandPackage, error) {
 	if _, err := os.Stat(filepath.Join(dir, "cli.json")); err != nil {
 		dir = filepath.Dir(dir)
@@ -71,32 +159,114 @@ func readPackage(dir string) (commandPackage, error) {
 	return packageData, nil
 }
 
-func getPackagePaths() string {
-	path := ""
+// getPackageDirs returns the directories of the installed packages that are
+// not disabled, in the order their commands are looked for
+func getPackageDirs() []string {
+	var dirs []string
+	for _, dir := range getAllPackageDirs() {
+		if manifest, err := readManifest(filepath.Base(getPackageRoot(dir))); err != nil || !manifest.Disabled {
+			dirs = append(dirs, dir)
+		}
+	}
+
+	return prioritizePackageDirs(dirs, getPackagePriority(), os.Getenv(packageEnv))
+}
+
+// getAllPackageDirs returns the directory of each installed package, including
+// disabled ones, taking into account packages installed from a monorepo subpath.
+// The packages of the current workspace come first, and replace the global
+// packages of the same name.
+func getAllPackageDirs() []string {
+	dirs := getWorkspacePackageDirs()
+
 	akamaiCliPath, err := getAkamaiCliSrcPath()
-	if err == nil && akamaiCliPath != "" {
-		paths, _ := filepath.Glob(filepath.Join(akamaiCliPath, "*"))
-		if len(paths) > 0 {
-			path += strings.Join(paths, string(os.PathListSeparator))
+	if err != nil || akamaiCliPath == "" {
+		return dirs
+	}
+
+	for _, dir := range getPackageDirsIn(akamaiCliPath) {
+		if !isWorkspacePackage(filepath.Base(getPackageRoot(dir))) {
+			dirs = append(dirs, dir)
 		}
 	}
 
-	return path
+	return dirs
+}
+
+// getPackageDirsIn returns the directory of each package installed in srcPath
+func getPackageDirsIn(srcPath string) []string {
+	var dirs []string
+
+	paths, _ := filepath.Glob(filepath.Join(srcPath, "*"))
+	for _, path := range paths {
+		// Packages still being installed or updated
+		if isPendingPackageDir(filepath.Base(path)) {
+			continue
+		}
+
+		if manifest, err := readManifest(filepath.Base(path)); err == nil && manifest.Subpath != "" {
+			path = filepath.Join(path, manifest.Subpath)
+		}
+		dirs = append(dirs, path)
+	}
+
+	return dirs
+}
+
+func getPackagePaths() string {
+	return strings.Join(getPackageDirs(), string(os.PathListSeparator))
+}
+
+// getInstalledCommandVersions maps each installed command to the version declared in its cli.json
+func getInstalledCommandVersions() map[string]string {
+	versions := make(map[string]string)
+	for _, dir := range getPackageDirs() {
+		cmdPackage, err := readPackage(dir)
+		if err != nil {
+			continue
+		}
+
+		for _, cmd := range cmdPackage.Commands {
+			versions[cmd.Name] = cmd.Version
+		}
+	}
+
+	return versions
+}
+
+// getInstalledPackageVersion finds the installed version of a registry package by
+// looking for any of its commands amongst the installed ones
+func getInstalledPackageVersion(pkg packageListPackage, installed map[string]string) (string, bool) {
+	for _, cmd := range pkg.Commands {
+		if version, ok := installed[strings.ToLower(cmd.Name)]; ok {
+			return version, true
+		}
+	}
+
+	return "", false
 }
 
 func getPackageBinPaths() string {
+	return getBinPaths(getPackageDirs())
+}
+
+// getBinPaths returns the directories commands are looked for in: each
+// package directory, followed by the bin directories of the packages
+func getBinPaths(dirs []string) string {
 	path := ""
-	akamaiCliPath, err := getAkamaiCliSrcPath()
-	if err == nil && akamaiCliPath != "" {
-		paths, _ := filepath.Glob(filepath.Join(akamaiCliPath, "*"))
-		if len(paths) > 0 {
-			path += strings.Join(paths, string(os.PathListSeparator))
-		}
-		paths, _ = filepath.Glob(filepath.Join(akamaiCliPath, "*", "bin"))
-		if len(paths) > 0 {
-			path += string(os.PathListSeparator) + strings.Join(paths, string(os.PathListSeparator))
+	if len(dirs) > 0 {
+		path += strings.Join(dirs, string(os.PathListSeparator))
+	}
+
+	var binPaths []string
+	for _, dir := range dirs {
+		if stat, err := os.Stat(filepath.Join(dir, "bin")); err == nil && stat.IsDir() {
+			binPaths = append(binPaths, filepath.Join(dir, "bin"))
 		}
 	}
+	if len(binPaths) > 0 {
+		path += string(os.PathListSeparator) + strings.Join(binPaths, string(os.PathListSeparator))
+	}
 
 	return path
 }
@@ -143,47 +313,60 @@ func determineCommandLanguage(cmdPackage commandPackage) string {
 	return ""
 }
 
-func downloadBin(dir string, cmd Command) bool {
-	cmd.Arch = runtime.GOARCH
-
-	cmd.OS = runtime.GOOS
-	if runtime.GOOS == "darwin" {
-		cmd.OS = "mac"
-	}
-
-	if runtime.GOOS == "windows" {
-		cmd.BinSuffix = ".exe"
-	}
-
-	t := template.Must(template.New("url").Parse(cmd.Bin))
-	buf := &bytes.Buffer{}
-	if err := t.Execute(buf, cmd); err != nil {
+func downloadBin(dir string, cmd Command, opts installOptions) bool {
+	urls, err := getBinaryURLs(cmd)
+	if err != nil {
 		return false
 	}
 
-	url := buf.String()
-
-	bin, err := os.Create(filepath.Join(dir, "akamai-"+strings.ToLower(cmd.Name)+cmd.BinSuffix))
-	bin.Chmod(0775)
+	client, err := getDownloadHTTPClient()
 	if err != nil {
 		return false
 	}
-	defer bin.Close()
 
-	res, err := http.Get(url)
+	res, url, err := getBinary(client, urls)
 	if err != nil {
+		logWarn("unable to download binary", "command", cmd.Name, "error", err)
 		return false
 	}
 	defer res.Body.Close()
 
-	if res.StatusCode != 200 {
+	expected := opts.release.getChecksum(url)
+	if expected == "" {
+		if !opts.insecure {
+			fmt.Fprintln(akamai.App.ErrWriter, color.RedString("The registry publishes no checksum for %s. Use --insecure to install it anyway.", url))
+			return false
+		}
+		fmt.Fprintln(akamai.App.ErrWriter, color.YellowString("Warning: the registry publishes no checksum for %s, installing it unverified because of --insecure", url))
+	}
+
+	binSuffix := ""
+	if runtime.GOOS == "windows" {
+		binSuffix = ".exe"
+	}
+
+	bin, err := os.Create(filepath.Join(dir, "akamai-"+strings.ToLower(cmd.Name)+binSuffix))
+	if err != nil {
 		return false
 	}
+	bin.Chmod(0775)
+	defer bin.Close()
 
-	n, err := io.Copy(bin, res.Body)
+	hash := sha256.New()
+	n, err := io.Copy(io.MultiWriter(bin, hash), res.Body)
 	if err != nil || n == 0 {
 		return false
 	}
 
+	if expected != "" && !checksumMatches(expected, hash.Sum(nil)) {
+		if !opts.insecure {
+			bin.Close()
+			os.Remove(bin.Name())
+			fmt.Fprintln(akamai.App.ErrWriter, color.RedString("Checksum of %s does not match the release. Use --insecure to install it anyway.", url))
+			return false
+		}
+		fmt.Fprintln(akamai.App.ErrWriter, color.YellowString("Warning: checksum of %s does not match the release, installing anyway because of --insecure", url))
+	}
+
 	return true
 }
@@ -0,0 +1,79 @@
+/*
+ Copyright 2018. Akamai Technologies, Inc
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// packageEnvPrefix starts the config keys holding the environment variables
+// for a package's commands, e.g. property.env.FOO is env-FOO in [property]
+const packageEnvPrefix = "env-"
+
+// applyPackageEnv sets the environment variables configured for the package in
+// packageDir and the command being run, with those for the command winning
+func applyPackageEnv(packageDir string, cmd string) {
+	for _, section := range getPackageConfigSections(packageDir, cmd) {
+		env := getPackageEnv(getConfigSectionValues(section))
+
+		names := make([]string, 0, len(env))
+		for name := range env {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		for _, name := range names {
+			logDebug("setting package environment", "section", section, "name", name)
+			os.Setenv(name, env[name])
+		}
+	}
+}
+
+// getPackageConfigSections returns the config sections for the package in
+// packageDir and the command being run, e.g. [property] and [property-manager],
+// in increasing precedence. Either may be empty.
+func getPackageConfigSections(packageDir string, cmd string) []string {
+	var sections []string
+	if packageDir != "" {
+		sections = append(sections, strings.TrimPrefix(filepath.Base(packageDir), "cli-"))
+	}
+	if cmd != "" && (len(sections) == 0 || sections[0] != cmd) {
+		sections = append(sections, cmd)
+	}
+
+	return sections
+}
+
+// getPackageEnv returns the environment variables set in the values of a
+// config section, by their name
+func getPackageEnv(values map[string]string) map[string]string {
+	env := map[string]string{}
+	for key, value := range values {
+		if !strings.HasPrefix(key, packageEnvPrefix) {
+			continue
+		}
+
+		if name := strings.TrimPrefix(key, packageEnvPrefix); name != "" {
+			env[name] = value
+		}
+	}
+
+	return env
+}
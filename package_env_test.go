@@ -0,0 +1,40 @@
+/*
+ Copyright 2018. Akamai Technologies, Inc
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestGetPackageEnv(t *testing.T) {
+	envTests := []struct {
+		values map[string]string
+		env    map[string]string
+	}{
+		{map[string]string{"env-FOO": "bar", "env-PAPI_DEBUG": "1"}, map[string]string{"FOO": "bar", "PAPI_DEBUG": "1"}},
+		{map[string]string{"env-FOO": "", "section": "papi"}, map[string]string{"FOO": ""}},
+		{map[string]string{"env-": "bar", "environment": "prod"}, map[string]string{}},
+		{nil, map[string]string{}},
+	}
+
+	for _, tt := range envTests {
+		if env := getPackageEnv(tt.values); !reflect.DeepEqual(env, tt.env) {
+			t.Errorf("getPackageEnv(%v) => %v, wanted: %v", tt.values, env, tt.env)
+		}
+	}
+}
@@ -22,13 +22,14 @@ import (
 	"os/exec"
 	"path/filepath"
 	"regexp"
+	"runtime"
 	"strings"
 
 	"github.com/fatih/color"
 	"github.com/urfave/cli"
 )
 
-func installGolang(dir string, cmdPackage commandPackage) (bool, error) {
+func installGolang(dir string, cmdPackage commandPackage, p progress) (bool, error) {
 	bin, err := exec.LookPath("go")
 	if err != nil {
 		return false, cli.NewExitError("Unable to locate Go runtime", 1)
@@ -48,14 +49,16 @@ func installGolang(dir string, cmdPackage commandPackage) (bool, error) {
 	if err != nil {
 		return false, cli.NewExitError(color.RedString("Unable to determine CLI home directory"), 1)
 	}
-	os.Setenv("GOPATH", os.Getenv("GOPATH")+string(os.PathListSeparator)+goPath)
+	// Set on each command rather than the process, packages may be installed concurrently
+	env := append(os.Environ(), "GOPATH="+os.Getenv("GOPATH")+string(os.PathListSeparator)+goPath)
 
 	if _, err := os.Stat(filepath.Join(dir, "glide.lock")); err == nil {
 		bin, err := exec.LookPath("glide")
 		if err == nil {
 			cmd := exec.Command(bin, "install")
 			cmd.Dir = dir
-			err = cmd.Run()
+			cmd.Env = env
+			err = runBuildCommand(cmd, p)
 			if err != nil {
 				return false, cli.NewExitError(err.Error(), 1)
 			}
@@ -65,10 +68,14 @@ func installGolang(dir string, cmdPackage commandPackage) (bool, error) {
 	}
 
 	execName := "akamai-" + strings.ToLower(strings.TrimPrefix(strings.TrimPrefix(filepath.Base(dir), "akamai-"), "cli-"))
+	if runtime.GOOS == "windows" {
+		execName += ".exe"
+	}
 
 	cmd := exec.Command(bin, "build", "-o", execName, ".")
 	cmd.Dir = dir
-	err = cmd.Run()
+	cmd.Env = env
+	err = runBuildCommand(cmd, p)
 	if err != nil {
 		return false, cli.NewExitError(err.Error(), 1)
 	}
@@ -27,7 +27,7 @@ import (
 	"github.com/urfave/cli"
 )
 
-func installJavaScript(dir string, cmdPackage commandPackage) (bool, error) {
+func installJavaScript(dir string, cmdPackage commandPackage, p progress) (bool, error) {
 	bin, err := exec.LookPath("node")
 	if err != nil {
 		bin, err = exec.LookPath("nodejs")
@@ -51,7 +51,7 @@ func installJavaScript(dir string, cmdPackage commandPackage) (bool, error) {
 		if err == nil {
 			cmd := exec.Command(bin, "install")
 			cmd.Dir = dir
-			err = cmd.Run()
+			err = runBuildCommand(cmd, p)
 			if err != nil {
 				return false, err
 			}
@@ -64,7 +64,7 @@ func installJavaScript(dir string, cmdPackage commandPackage) (bool, error) {
 		if err == nil {
 			cmd := exec.Command(bin, "install")
 			cmd.Dir = dir
-			err = cmd.Run()
+			err = runBuildCommand(cmd, p)
 			if err != nil {
 				return false, err
 			}
@@ -26,7 +26,7 @@ import (
 	"github.com/urfave/cli"
 )
 
-func installPHP(dir string, cmdPackage commandPackage) (bool, error) {
+func installPHP(dir string, cmdPackage commandPackage, p progress) (bool, error) {
 	bin, err := exec.LookPath("php")
 	if err != nil {
 		return false, cli.NewExitError("Unable to locate PHP runtime", 1)
@@ -50,7 +50,7 @@ func installPHP(dir string, cmdPackage commandPackage) (bool, error) {
 		if _, err := os.Stat(filepath.Join(dir, "composer.phar")); err == nil {
 			cmd := exec.Command(bin, filepath.Join(dir, "composer.phar"), "install")
 			cmd.Dir = dir
-			err = cmd.Run()
+			err = runBuildCommand(cmd, p)
 			if err != nil {
 				return false, err
 			}
@@ -61,7 +61,7 @@ func installPHP(dir string, cmdPackage commandPackage) (bool, error) {
 		if err == nil {
 			cmd := exec.Command(bin, "install")
 			cmd.Dir = dir
-			err = cmd.Run()
+			err = runBuildCommand(cmd, p)
 			if err != nil {
 				return false, err
 			}
@@ -72,7 +72,7 @@ func installPHP(dir string, cmdPackage commandPackage) (bool, error) {
 		if err == nil {
 			cmd := exec.Command(bin, "install")
 			cmd.Dir = dir
-			err = cmd.Run()
+			err = runBuildCommand(cmd, p)
 			if err != nil {
 				return false, err
 			}
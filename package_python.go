@@ -30,7 +30,7 @@ import (
 	"github.com/urfave/cli"
 )
 
-func installPython(dir string, cmdPackage commandPackage) (bool, error) {
+func installPython(dir string, cmdPackage commandPackage, p progress) (bool, error) {
 	bins, err := findPythonBins(cmdPackage.Requirements.Python)
 	if err != nil {
 		return false, err
@@ -52,10 +52,10 @@ func installPython(dir string, cmdPackage commandPackage) (bool, error) {
 		}
 
 		if err == nil {
-			os.Setenv("PYTHONUSERBASE", dir)
 			cmd := exec.Command(bins.pip, "install", "--user", "--ignore-installed", "-r", "requirements.txt")
 			cmd.Dir = dir
-			err = cmd.Run()
+			cmd.Env = append(os.Environ(), "PYTHONUSERBASE="+dir)
+			err = runBuildCommand(cmd, p)
 			if err != nil {
 				return false, err
 			}
@@ -166,11 +166,11 @@ func migratePythonPackage(cmd string, dir string) error {
 			return cli.NewExitError(color.RedString("You must reinstall this package to continue"), -1)
 		}
 
-		if err := uninstallPackage(cmd); err != nil {
+		if err := uninstallPackage(cmd, false); err != nil {
 			return err
 		}
 
-		if err := installPackage(cmd, false); err != nil {
+		if err := installPackage(cmd, "", installOptions{}); err != nil {
 			return err
 		}
 	}
@@ -27,7 +27,7 @@ import (
 	"github.com/urfave/cli"
 )
 
-func installRuby(dir string, cmdPackage commandPackage) (bool, error) {
+func installRuby(dir string, cmdPackage commandPackage, p progress) (bool, error) {
 	bin, err := exec.LookPath("ruby")
 	if err != nil {
 		return false, cli.NewExitError(color.RedString("Unable to locate Ruby runtime"), 1)
@@ -48,7 +48,7 @@ func installRuby(dir string, cmdPackage commandPackage) (bool, error) {
 		if err == nil {
 			cmd := exec.Command(bin, "install")
 			cmd.Dir = dir
-			err = cmd.Run()
+			err = runBuildCommand(cmd, p)
 			if err != nil {
 				return false, err
 			}
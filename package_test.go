@@ -0,0 +1,47 @@
+/*
+ Copyright 2018. Akamai Technologies, Inc
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package main
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestGetLicenseFilePath(t *testing.T) {
+	dir := filepath.Join("src", "cli-purge")
+
+	fileTests := []struct {
+		file string
+		path string
+		ok   bool
+	}{
+		{"LICENSE", filepath.Join(dir, "LICENSE"), true},
+		{"docs/../LICENSE.md", filepath.Join(dir, "LICENSE.md"), true},
+		{"", "", false},
+		{".", "", false},
+		{"../cli-property/LICENSE", "", false},
+		{"../../../.edgerc", "", false},
+		{"/home/user/.edgerc", "", false},
+	}
+
+	for _, tt := range fileTests {
+		path, ok := packageLicense{File: tt.file}.getFilePath(dir)
+		if path != tt.path || ok != tt.ok {
+			t.Errorf("getFilePath(%s) => %s, %t, wanted: %s, %t", tt.file, path, ok, tt.path, tt.ok)
+		}
+	}
+}
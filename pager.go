@@ -0,0 +1,76 @@
+/*
+ Copyright 2018. Akamai Technologies, Inc
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package main
+
+import (
+	"bytes"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+
+	akamai "github.com/akamai/cli-common-golang"
+	"github.com/mattn/go-isatty"
+)
+
+// withPager runs print, sending its output through $PAGER when stdout is a
+// terminal and the output would not fit on screen
+func withPager(print func() error) error {
+	if !isatty.IsTerminal(os.Stdout.Fd()) && !isatty.IsCygwinTerminal(os.Stdout.Fd()) {
+		return print()
+	}
+
+	writer := akamai.App.Writer
+	output := &bytes.Buffer{}
+	akamai.App.Writer = output
+	err := print()
+	akamai.App.Writer = writer
+	if err != nil {
+		return err
+	}
+
+	rows, _, sizeErr := getTerminalSize()
+	if sizeErr != nil || bytes.Count(output.Bytes(), []byte("\n")) < rows {
+		_, err := output.WriteTo(writer)
+		return err
+	}
+
+	pager := strings.Fields(getPager())
+	cmd := exec.Command(pager[0], pager[1:]...)
+	cmd.Stdin = output
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		// Fall back to printing everything, the output must not be lost
+		_, err := output.WriteTo(writer)
+		return err
+	}
+
+	return nil
+}
+
+func getPager() string {
+	if pager := strings.TrimSpace(os.Getenv("PAGER")); pager != "" {
+		return pager
+	}
+
+	if runtime.GOOS == "windows" {
+		return "more"
+	}
+
+	return "less -R"
+}
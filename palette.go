@@ -0,0 +1,83 @@
+/*
+ Copyright 2018. Akamai Technologies, Inc
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package main
+
+import (
+	"fmt"
+	"hash/fnv"
+	"sort"
+	"strings"
+
+	"github.com/fatih/color"
+)
+
+// Palettes used to color package names. A package always hashes to the same
+// entry, so its color is stable between runs. Colors are disabled entirely when
+// color.NoColor is set.
+var palettes = map[string][]*color.Color{
+	"default": {
+		color.New(color.FgGreen),
+		color.New(color.FgCyan),
+		color.New(color.FgMagenta),
+		color.New(color.FgYellow),
+		color.New(color.FgBlue),
+	},
+	"high-contrast": {
+		color.New(color.FgHiWhite, color.Bold),
+		color.New(color.FgHiYellow, color.Bold),
+		color.New(color.FgHiCyan, color.Bold),
+		color.New(color.FgHiGreen, color.Bold),
+	},
+	// Avoids red/green pairings, which are the hardest to tell apart
+	"colorblind": {
+		color.New(color.FgBlue),
+		color.New(color.FgYellow),
+		color.New(color.FgHiBlue),
+		color.New(color.FgWhite, color.Bold),
+	},
+}
+
+func getPaletteNames() []string {
+	names := make([]string, 0, len(palettes))
+	for name := range palettes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+func validatePalette(name string) error {
+	if _, ok := palettes[name]; !ok {
+		return fmt.Errorf("Unknown palette \"%s\", must be one of: %s", name, strings.Join(getPaletteNames(), ", "))
+	}
+
+	return nil
+}
+
+// getPackageColor deterministically picks a color for a package from the given palette
+func getPackageColor(palette string, name string) *color.Color {
+	colors, ok := palettes[palette]
+	if !ok {
+		colors = palettes["default"]
+	}
+
+	hash := fnv.New32a()
+	hash.Write([]byte(name))
+
+	return colors[hash.Sum32()%uint32(len(colors))]
+}
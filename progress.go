@@ -0,0 +1,259 @@
+/*
+ Copyright 2018. Akamai Technologies, Inc
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	akamai "github.com/akamai/cli-common-golang"
+	"github.com/fatih/color"
+	"github.com/mattn/go-isatty"
+)
+
+// progress reports the steps of a long running operation, such as an install
+type progress interface {
+	Start(message string)
+	// Update reports how the current step is going, e.g. a percentage, or the
+	// latest line of output from a build
+	Update(detail string)
+	Ok()
+	WarnOk()
+	Fail()
+	// Interactive reports whether the user can be asked questions
+	Interactive() bool
+}
+
+// progressUpdateInterval limits how often the spinner is redrawn with new details
+const progressUpdateInterval = 200 * time.Millisecond
+
+// maxProgressDetail is the longest detail shown next to a step, in characters
+const maxProgressDetail = 60
+
+// spinnerProgress shows a spinner for the current step, with the latest progress
+// details next to it. When stderr is not a terminal it prints a plain line as
+// each step finishes instead. Only one spinner can run at a time, so it must not
+// be used concurrently.
+type spinnerProgress struct {
+	message string
+	detail  string
+	updated time.Time
+	plain   bool
+	// quiet shows nothing, for --quiet
+	quiet bool
+}
+
+func newSpinnerProgress() *spinnerProgress {
+	return &spinnerProgress{
+		plain: !isatty.IsTerminal(os.Stderr.Fd()) && !isatty.IsCygwinTerminal(os.Stderr.Fd()),
+		quiet: isQuiet(),
+	}
+}
+
+func (p *spinnerProgress) Start(message string) {
+	p.message = message
+	p.detail = ""
+	if !p.plain && !p.quiet {
+		akamai.StartSpinner(message, message+"... ["+color.GreenString("OK")+"]\n")
+	}
+}
+
+func (p *spinnerProgress) Update(detail string) {
+	detail = formatProgressDetail(detail)
+	if p.plain || p.quiet || detail == "" || detail == p.detail || time.Since(p.updated) < progressUpdateInterval {
+		return
+	}
+
+	p.detail = detail
+	p.updated = time.Now()
+
+	// The spinner prefix cannot be changed while it runs, so start a new one
+	akamai.StopSpinner("", false)
+	akamai.StartSpinner(fmt.Sprintf("%s (%s) ", p.message, detail), "")
+}
+
+func (p *spinnerProgress) Ok() {
+	p.finish(color.GreenString("OK"))
+}
+
+func (p *spinnerProgress) WarnOk() {
+	p.finish(color.CyanString("OK"))
+}
+
+func (p *spinnerProgress) Fail() {
+	p.finish(color.RedString("FAIL"))
+}
+
+func (p *spinnerProgress) Interactive() bool {
+	return isatty.IsTerminal(os.Stdout.Fd()) || isatty.IsCygwinTerminal(os.Stdout.Fd())
+}
+
+func (p *spinnerProgress) finish(status string) {
+	if p.quiet {
+		return
+	}
+
+	final := fmt.Sprintf("%s... [%s]\n", p.message, status)
+	if p.plain {
+		fmt.Fprint(akamai.App.ErrWriter, final)
+		return
+	}
+
+	akamai.StopSpinner(final, false)
+}
+
+var progressLock sync.Mutex
+
+// lineProgress prints a line, prefixed with the name of what is in progress, as
+// each step finishes. It is safe to use concurrently, and never asks questions.
+type lineProgress struct {
+	name    string
+	message string
+}
+
+func newLineProgress(name string) *lineProgress {
+	return &lineProgress{name: name}
+}
+
+func (p *lineProgress) Start(message string) {
+	p.message = message
+}
+
+// Update does nothing, details from concurrent steps would only be noise
+func (p *lineProgress) Update(detail string) {}
+
+func (p *lineProgress) Ok() {
+	p.finish(color.GreenString("OK"))
+}
+
+func (p *lineProgress) WarnOk() {
+	p.finish(color.CyanString("OK"))
+}
+
+func (p *lineProgress) Fail() {
+	p.finish(color.RedString("FAIL"))
+}
+
+func (p *lineProgress) Interactive() bool {
+	return false
+}
+
+func (p *lineProgress) finish(status string) {
+	if isQuiet() {
+		return
+	}
+
+	progressLock.Lock()
+	defer progressLock.Unlock()
+
+	fmt.Fprintf(akamai.App.Writer, "%s: %s... [%s]\n", color.New(color.Bold).Sprint(p.name), p.message, status)
+}
+
+// formatProgressDetail trims a line of output to fit next to a step
+func formatProgressDetail(detail string) string {
+	detail = strings.Join(strings.Fields(stripColor(detail)), " ")
+	detail = strings.TrimSuffix(detail, ", done.")
+	if len(detail) > maxProgressDetail {
+		detail = detail[:maxProgressDetail-3] + "..."
+	}
+
+	return detail
+}
+
+// outputTailLines is how many lines of output a progressWriter keeps
+const outputTailLines = 10
+
+// progressWriter passes each line written to it, such as the output of a build
+// or git's progress messages, to a progress as its details. It keeps the last
+// few lines, to explain failures.
+type progressWriter struct {
+	sync.Mutex
+	p       progress
+	partial []byte
+	tail    []string
+}
+
+func newProgressWriter(p progress) *progressWriter {
+	return &progressWriter{p: p}
+}
+
+func (w *progressWriter) Write(data []byte) (int, error) {
+	w.Lock()
+	defer w.Unlock()
+
+	w.partial = append(w.partial, data...)
+	for {
+		// git redraws its progress lines with carriage returns
+		i := bytes.IndexAny(w.partial, "\r\n")
+		if i == -1 {
+			break
+		}
+
+		line := strings.TrimSpace(string(w.partial[:i]))
+		w.partial = w.partial[i+1:]
+		if line == "" {
+			continue
+		}
+
+		w.p.Update(line)
+		w.tail = append(w.tail, line)
+		if len(w.tail) > outputTailLines {
+			w.tail = w.tail[1:]
+		}
+	}
+
+	return len(data), nil
+}
+
+// Tail returns the last lines written, including any unterminated line
+func (w *progressWriter) Tail() []string {
+	w.Lock()
+	defer w.Unlock()
+
+	tail := append([]string(nil), w.tail...)
+	if line := strings.TrimSpace(string(w.partial)); line != "" {
+		tail = append(tail, line)
+	}
+	if len(tail) > outputTailLines {
+		tail = tail[len(tail)-outputTailLines:]
+	}
+
+	return tail
+}
+
+// runBuildCommand runs a step of a package build, showing its output as the
+// details of p, and adding the last lines of output to the error if it fails
+func runBuildCommand(cmd *exec.Cmd, p progress) error {
+	w := newProgressWriter(p)
+	cmd.Stdout = w
+	cmd.Stderr = w
+
+	if err := runCommand(cmd); err != nil {
+		if tail := w.Tail(); len(tail) > 0 {
+			return fmt.Errorf("%s failed: %s\n%s", filepath.Base(cmd.Path), err.Error(), strings.Join(tail, "\n"))
+		}
+		return err
+	}
+
+	return nil
+}
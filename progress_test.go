@@ -0,0 +1,68 @@
+/*
+ Copyright 2018. Akamai Technologies, Inc
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+type recordingProgress struct {
+	lineProgress
+	details []string
+}
+
+func (p *recordingProgress) Update(detail string) {
+	p.details = append(p.details, detail)
+}
+
+func TestProgressWriter(t *testing.T) {
+	p := &recordingProgress{}
+	w := newProgressWriter(p)
+
+	for _, chunk := range []string{"Counting objects:  50% (1/2)\r", "Counting objects: 100% (2/2), done.\n", "\nadded 12 pack", "ages\n", "done"} {
+		w.Write([]byte(chunk))
+	}
+
+	wantDetails := []string{"Counting objects:  50% (1/2)", "Counting objects: 100% (2/2), done.", "added 12 packages"}
+	if !reflect.DeepEqual(p.details, wantDetails) {
+		t.Errorf("Update() calls => %q, wanted: %q", p.details, wantDetails)
+	}
+
+	wantTail := append(wantDetails, "done")
+	if tail := w.Tail(); !reflect.DeepEqual(tail, wantTail) {
+		t.Errorf("Tail() => %q, wanted: %q", tail, wantTail)
+	}
+}
+
+func TestFormatProgressDetail(t *testing.T) {
+	detailTests := []struct {
+		detail string
+		want   string
+	}{
+		{"Receiving objects:  45% (90/200)", "Receiving objects: 45% (90/200)"},
+		{"Counting objects: 100% (2/2), done.", "Counting objects: 100% (2/2)"},
+		{"\x1b[32mok\x1b[0m", "ok"},
+		{"Collecting requests>=2.0 from https://files.pythonhosted.org/packages/requests.whl", "Collecting requests>=2.0 from https://files.pythonhosted...."},
+	}
+
+	for _, tt := range detailTests {
+		if detail := formatProgressDetail(tt.detail); detail != tt.want {
+			t.Errorf("formatProgressDetail(%q) => %q, wanted: %q", tt.detail, detail, tt.want)
+		}
+	}
+}
@@ -0,0 +1,60 @@
+/*
+ Copyright 2018. Akamai Technologies, Inc
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/fatih/color"
+)
+
+const (
+	// quietEnv suppresses progress and informational messages when set, like --quiet
+	quietEnv = "AKAMAI_CLI_QUIET"
+	// noColorEnv disables colored output when set to anything, see https://no-color.org
+	noColorEnv = "NO_COLOR"
+)
+
+// isQuiet reports whether only results, warnings, and errors should be printed
+func isQuiet() bool {
+	switch strings.ToLower(os.Getenv(quietEnv)) {
+	case "", "0", "false", "no":
+		return false
+	}
+
+	return true
+}
+
+// configureColor turns off colored output if --no-color or NO_COLOR was given.
+// Color is already off when stdout is not a terminal.
+func configureColor() {
+	if os.Getenv(noColorEnv) != "" {
+		color.NoColor = true
+	}
+}
+
+// printInfo prints an informational message, unless --quiet is set
+func printInfo(w io.Writer, format string, a ...interface{}) {
+	if isQuiet() {
+		return
+	}
+
+	fmt.Fprintln(w, color.CyanString(format, a...))
+}
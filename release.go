@@ -0,0 +1,142 @@
+/*
+ Copyright 2018. Akamai Technologies, Inc
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package main
+
+import (
+	"encoding/hex"
+	"fmt"
+	"strings"
+
+	"gopkg.in/src-d/go-git.v4"
+)
+
+// packageRelease is a release of a package as published by the registry, used
+// to check that what gets installed is what the registry vouched for
+type packageRelease struct {
+	Version string `json:"version"`
+	// Commit is the git commit the release was tagged at
+	Commit string `json:"commit"`
+	// Checksums maps binary download URLs to their SHA-256, as hex
+	Checksums map[string]string `json:"checksums"`
+	// Signature is a base64 ed25519 signature of Commit, made with the index key
+	Signature string `json:"signature"`
+	// Binaries are pre-built executables of the release, installed instead of building it from source
+	Binaries []releaseBinary `json:"binaries,omitempty"`
+	// Notes are the release notes, shown before updating to the release
+	Notes string `json:"notes,omitempty"`
+}
+
+// releaseBinary is the pre-built executable of a release for one platform, or
+// an archive of the executables of all its commands
+type releaseBinary struct {
+	// OS and Arch are Go's names for the platform, e.g. linux and amd64
+	OS   string `json:"os"`
+	Arch string `json:"arch"`
+	URL  string `json:"url"`
+	// SHA256 is the hex checksum the download must match
+	SHA256 string `json:"sha256"`
+}
+
+// findRelease returns the registry's release of version, or of the latest
+// version if none is given
+func (pkg packageListPackage) findRelease(version string) (packageRelease, bool) {
+	if version == "" {
+		version = pkg.Version
+	}
+
+	for _, release := range pkg.Releases {
+		if strings.TrimPrefix(release.Version, "v") == strings.TrimPrefix(version, "v") {
+			return release, true
+		}
+	}
+
+	return packageRelease{}, false
+}
+
+// findBinary returns the binary of the release for goos, for the first of
+// archs it has one for
+func (release packageRelease) findBinary(goos string, archs []string) (releaseBinary, bool) {
+	for _, arch := range archs {
+		for _, binary := range release.Binaries {
+			if strings.EqualFold(binary.OS, goos) && strings.EqualFold(binary.Arch, arch) {
+				return binary, true
+			}
+		}
+	}
+
+	return releaseBinary{}, false
+}
+
+func (release *packageRelease) getChecksum(url string) string {
+	if release == nil {
+		return ""
+	}
+
+	return release.Checksums[url]
+}
+
+// verifyRelease checks that the repository at dir is checked out at the
+// release commit, and that the commit is signed when an index key is
+// configured. If checkout is set, the release commit is checked out first.
+func verifyRelease(dir string, release packageRelease, checkout bool) error {
+	if release.Commit == "" {
+		return nil
+	}
+
+	if checkout {
+		if err := checkoutVersion(dir, release.Commit); err != nil {
+			return fmt.Errorf("release commit %s was not found", release.Commit)
+		}
+	}
+
+	repo, err := git.PlainOpen(dir)
+	if err != nil {
+		return err
+	}
+
+	head, err := repo.Head()
+	if err != nil {
+		return err
+	}
+
+	if !strings.EqualFold(head.Hash().String(), release.Commit) {
+		return fmt.Errorf("checked out commit %s does not match release commit %s", head.Hash().String(), release.Commit)
+	}
+
+	key, err := getIndexPublicKey()
+	if err != nil || key == nil {
+		return err
+	}
+
+	if release.Signature == "" {
+		return fmt.Errorf("release %s is not signed", release.Version)
+	}
+
+	if err := verifyIndexSignature(key, []byte(release.Commit), []byte(release.Signature)); err != nil {
+		return fmt.Errorf("release %s %s", release.Version, err.Error())
+	}
+
+	return nil
+}
+
+// checksumMatches compares a SHA-256 sum to an expected hex checksum,
+// which may be written as sha256:<hex>
+func checksumMatches(expected string, sum []byte) bool {
+	expected = strings.TrimPrefix(strings.TrimSpace(expected), "sha256:")
+
+	return strings.EqualFold(expected, hex.EncodeToString(sum))
+}
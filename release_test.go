@@ -0,0 +1,104 @@
+/*
+ Copyright 2018. Akamai Technologies, Inc
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package main
+
+import (
+	"crypto/sha256"
+	"testing"
+)
+
+func TestFindRelease(t *testing.T) {
+	pkg := packageListPackage{
+		Version: "1.1.0",
+		Releases: []packageRelease{
+			{Version: "1.0.0", Commit: "a"},
+			{Version: "v1.1.0", Commit: "b"},
+		},
+	}
+
+	releaseTests := []struct {
+		version string
+		commit  string
+		found   bool
+	}{
+		{"", "b", true},
+		{"1.0.0", "a", true},
+		{"v1.0.0", "a", true},
+		{"1.1.0", "b", true},
+		{"2.0.0", "", false},
+	}
+
+	for _, tt := range releaseTests {
+		release, ok := pkg.findRelease(tt.version)
+		if ok != tt.found || release.Commit != tt.commit {
+			t.Errorf("findRelease(%s) => %s, %t, wanted: %s, %t", tt.version, release.Commit, ok, tt.commit, tt.found)
+		}
+	}
+}
+
+func TestChecksumMatches(t *testing.T) {
+	sum := sha256.Sum256([]byte("akamai"))
+	hexSum := "a3c4d61dbebabbafa0ddfca7387178c235329ba0026536b6943ec3dac5ec09be"
+
+	checksumTests := []struct {
+		expected string
+		matches  bool
+	}{
+		{hexSum, true},
+		{"sha256:" + hexSum, true},
+		{"A3C4D61DBEBABBAFA0DDFCA7387178C235329BA0026536B6943EC3DAC5EC09BE", true},
+		{hexSum[1:], false},
+		{"", false},
+	}
+
+	for _, tt := range checksumTests {
+		if checksumMatches(tt.expected, sum[:]) != tt.matches {
+			t.Errorf("checksumMatches(%s) => %t, wanted: %t", tt.expected, !tt.matches, tt.matches)
+		}
+	}
+}
+
+func TestFindBinary(t *testing.T) {
+	release := packageRelease{
+		Binaries: []releaseBinary{
+			{OS: "linux", Arch: "amd64", URL: "linux-amd64"},
+			{OS: "darwin", Arch: "amd64", URL: "darwin-amd64"},
+			{OS: "darwin", Arch: "arm64", URL: "darwin-arm64"},
+		},
+	}
+
+	binaryTests := []struct {
+		goos  string
+		archs []string
+		url   string
+		found bool
+	}{
+		{"linux", []string{"amd64"}, "linux-amd64", true},
+		{"Linux", []string{"AMD64"}, "linux-amd64", true},
+		{"darwin", []string{"arm64", "amd64"}, "darwin-arm64", true},
+		{"darwin", []string{"386", "amd64"}, "darwin-amd64", true},
+		{"linux", []string{"arm64"}, "", false},
+		{"windows", []string{"amd64"}, "", false},
+	}
+
+	for _, tt := range binaryTests {
+		binary, ok := release.findBinary(tt.goos, tt.archs)
+		if ok != tt.found || binary.URL != tt.url {
+			t.Errorf("findBinary(%s, %v) => %s, %t, wanted: %s, %t", tt.goos, tt.archs, binary.URL, ok, tt.url, tt.found)
+		}
+	}
+}
@@ -0,0 +1,129 @@
+/*
+ Copyright 2018. Akamai Technologies, Inc
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package main
+
+import (
+	"net/http"
+	"os"
+	"strconv"
+	"time"
+
+	"gopkg.in/src-d/go-git.v4/plumbing/transport"
+)
+
+// noRetryEnv disables retries when set, like --no-retry
+const noRetryEnv = "AKAMAI_CLI_NO_RETRY"
+
+// retryPolicy controls how transient network failures are retried. The
+// delay between attempts starts at backoff and doubles after each attempt,
+// and retrying stops once maxElapsed would be exceeded.
+type retryPolicy struct {
+	attempts   int
+	backoff    time.Duration
+	maxElapsed time.Duration
+}
+
+var defaultRetryPolicy = retryPolicy{
+	attempts:   3,
+	backoff:    time.Second,
+	maxElapsed: 30 * time.Second,
+}
+
+// getRetryPolicy reads the policy from cli.retry-attempts, cli.retry-backoff,
+// and cli.retry-max-elapsed, falling back to the defaults for invalid values
+func getRetryPolicy() retryPolicy {
+	policy := defaultRetryPolicy
+
+	if os.Getenv(noRetryEnv) != "" {
+		policy.attempts = 1
+		return policy
+	}
+
+	if value, err := strconv.Atoi(getConfigValue("cli", "retry-attempts")); err == nil && value > 0 {
+		policy.attempts = value
+	}
+
+	if value, err := time.ParseDuration(getConfigValue("cli", "retry-backoff")); err == nil && value >= 0 {
+		policy.backoff = value
+	}
+
+	if value, err := time.ParseDuration(getConfigValue("cli", "retry-max-elapsed")); err == nil && value >= 0 {
+		policy.maxElapsed = value
+	}
+
+	return policy
+}
+
+// permanentError marks a failure that trying again will not fix
+type permanentError struct {
+	error
+}
+
+func permanent(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	return permanentError{err}
+}
+
+// withRetry calls fn until it succeeds, returns a permanent error, or the policy
+// is exhausted, and returns the last error
+func withRetry(policy retryPolicy, fn func() error) error {
+	start := time.Now()
+	delay := policy.backoff
+
+	for attempt := 1; ; attempt++ {
+		err := fn()
+		if err == nil {
+			return nil
+		}
+
+		if p, ok := err.(permanentError); ok {
+			return p.error
+		}
+
+		if attempt >= policy.attempts || time.Since(start)+delay > policy.maxElapsed {
+			return err
+		}
+
+		time.Sleep(delay)
+		delay *= 2
+	}
+}
+
+// isRetryableStatus reports whether an HTTP status is worth retrying
+func isRetryableStatus(status int) bool {
+	return status == http.StatusTooManyRequests || status >= 500
+}
+
+// permanentGitError marks the git errors that retrying will not fix
+func permanentGitError(err error) error {
+	switch err {
+	case transport.ErrRepositoryNotFound, transport.ErrEmptyRemoteRepository, transport.ErrAuthenticationRequired, transport.ErrAuthorizationFailed, transport.ErrInvalidAuthMethod:
+		return permanent(err)
+	}
+
+	return err
+}
+
+// isPermanentGitError reports whether err is a git error that retrying, or
+// trying another way, will not fix
+func isPermanentGitError(err error) bool {
+	_, ok := permanentGitError(err).(permanentError)
+	return ok
+}
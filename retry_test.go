@@ -0,0 +1,84 @@
+/*
+ Copyright 2018. Akamai Technologies, Inc
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package main
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"gopkg.in/src-d/go-git.v4/plumbing/transport"
+)
+
+func TestWithRetry(t *testing.T) {
+	transient := errors.New("connection reset")
+
+	retryTests := []struct {
+		policy    retryPolicy
+		failures  int
+		permanent bool
+		calls     int
+		success   bool
+	}{
+		{retryPolicy{attempts: 3, maxElapsed: time.Minute}, 0, false, 1, true},
+		{retryPolicy{attempts: 3, maxElapsed: time.Minute}, 2, false, 3, true},
+		{retryPolicy{attempts: 3, maxElapsed: time.Minute}, 5, false, 3, false},
+		{retryPolicy{attempts: 3, maxElapsed: time.Minute}, 5, true, 1, false},
+		{retryPolicy{attempts: 1, maxElapsed: time.Minute}, 5, false, 1, false},
+		{retryPolicy{attempts: 5, backoff: 10 * time.Millisecond, maxElapsed: 25 * time.Millisecond}, 5, false, 2, false},
+	}
+
+	for _, tt := range retryTests {
+		calls := 0
+		err := withRetry(tt.policy, func() error {
+			calls++
+			if calls > tt.failures {
+				return nil
+			}
+			if tt.permanent {
+				return permanent(transient)
+			}
+			return transient
+		})
+
+		if calls != tt.calls || (err == nil) != tt.success {
+			t.Errorf("withRetry(%+v) with %d failures => %d calls, error: %v, wanted: %d calls", tt.policy, tt.failures, calls, err, tt.calls)
+		}
+
+		if err != nil && err != transient {
+			t.Errorf("withRetry(%+v) => %v, wanted the last error", tt.policy, err)
+		}
+	}
+}
+
+func TestIsPermanentGitError(t *testing.T) {
+	gitErrorTests := []struct {
+		err       error
+		permanent bool
+	}{
+		{transport.ErrRepositoryNotFound, true},
+		{transport.ErrAuthenticationRequired, true},
+		{errors.New("connection reset"), false},
+		{nil, false},
+	}
+
+	for _, tt := range gitErrorTests {
+		if isPermanentGitError(tt.err) != tt.permanent {
+			t.Errorf("isPermanentGitError(%v) => %t, wanted: %t", tt.err, !tt.permanent, tt.permanent)
+		}
+	}
+}
@@ -0,0 +1,163 @@
+/*
+ Copyright 2018. Akamai Technologies, Inc
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package main
+
+import (
+	"fmt"
+	"os/exec"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// runtimeBinaries are the executables that indicate a language runtime is
+// installed, in the order they are looked for
+var runtimeBinaries = map[string][]string{
+	"go":     {"go"},
+	"php":    {"php"},
+	"node":   {"node", "nodejs"},
+	"ruby":   {"ruby"},
+	"python": {"python3", "python", "python2"},
+}
+
+// detectRuntimes returns the language runtimes found on the PATH
+func detectRuntimes() map[string]bool {
+	runtimes := make(map[string]bool)
+	for runtime, bins := range runtimeBinaries {
+		for _, bin := range bins {
+			if _, err := exec.LookPath(bin); err == nil {
+				runtimes[runtime] = true
+				break
+			}
+		}
+	}
+
+	return runtimes
+}
+
+// runtimeVersionArgs are the arguments that make each runtime print its version
+var runtimeVersionArgs = map[string][]string{
+	"go":     {"version"},
+	"php":    {"-v"},
+	"node":   {"-v"},
+	"ruby":   {"-v"},
+	"python": {"--version"},
+}
+
+var runtimeVersionPatterns = map[string]*regexp.Regexp{
+	"go":     regexp.MustCompile(`go version go(\d+(?:\.\d+)*)`),
+	"php":    regexp.MustCompile(`PHP (\d+\.\d+\.\d+)`),
+	"node":   regexp.MustCompile(`v(\d+\.\d+\.\d+)`),
+	"ruby":   regexp.MustCompile(`ruby (\d+\.\d+\.\d+)`),
+	"python": regexp.MustCompile(`Python (\d+\.\d+\.\d+)`),
+}
+
+// getRuntimeVersion runs a runtime binary to find out its version
+func getRuntimeVersion(runtime string, bin string) (string, bool) {
+	output, err := exec.Command(bin, runtimeVersionArgs[runtime]...).CombinedOutput()
+	if err != nil {
+		return "", false
+	}
+
+	return parseRuntimeVersion(runtime, string(output))
+}
+
+func parseRuntimeVersion(runtime string, output string) (string, bool) {
+	pattern, ok := runtimeVersionPatterns[runtime]
+	if !ok {
+		return "", false
+	}
+
+	matches := pattern.FindStringSubmatch(output)
+	if matches == nil {
+		return "", false
+	}
+
+	return matches[1], true
+}
+
+func findRuntimeBin(runtime string, required string) string {
+	if runtime == "python" {
+		bins, err := findPythonBins(required)
+		if err != nil {
+			return ""
+		}
+		return bins.python
+	}
+
+	for _, bin := range runtimeBinaries[runtime] {
+		if path, err := exec.LookPath(bin); err == nil {
+			return path
+		}
+	}
+
+	return ""
+}
+
+// checkRuntimeRequirements checks the local language runtimes against the
+// requirements of a package, so that installing it fails early with a clear
+// error rather than halfway through its build
+func checkRuntimeRequirements(requirements packageRequirements) error {
+	runtimes := requirements.runtimes()
+	names := make([]string, 0, len(runtimes))
+	for runtime := range runtimes {
+		names = append(names, runtime)
+	}
+	sort.Strings(names)
+
+	var problems []string
+	for _, runtime := range names {
+		required := runtimes[runtime]
+		bin := findRuntimeBin(runtime, required)
+
+		version := ""
+		if bin != "" {
+			var ok bool
+			if version, ok = getRuntimeVersion(runtime, bin); !ok {
+				// Leave it to the build to find out
+				logWarn("unable to determine runtime version", "runtime", runtime, "bin", bin)
+				continue
+			}
+		}
+
+		if err := checkRuntimeVersion(runtime, required, version, bin != ""); err != nil {
+			problems = append(problems, err.Error())
+		}
+	}
+
+	if len(problems) > 0 {
+		return fmt.Errorf("%s", strings.Join(problems, "; "))
+	}
+
+	return nil
+}
+
+// checkRuntimeVersion compares the installed version of a runtime with the required one
+func checkRuntimeVersion(runtime string, required string, version string, found bool) error {
+	if !found {
+		if required == "*" {
+			return fmt.Errorf("requires %s, but it was not found", runtime)
+		}
+		return fmt.Errorf("requires %s >= %s, but it was not found", runtime, required)
+	}
+
+	if required != "*" && version != "" && versionCompare(required, version) == -1 {
+		return fmt.Errorf("requires %s >= %s, found %s", runtime, required, version)
+	}
+
+	return nil
+}
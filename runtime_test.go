@@ -0,0 +1,76 @@
+/*
+ Copyright 2018. Akamai Technologies, Inc
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package main
+
+import "testing"
+
+func TestParseRuntimeVersion(t *testing.T) {
+	versionTests := []struct {
+		runtime string
+		output  string
+		version string
+	}{
+		{"go", "go version go1.10.3 darwin/amd64\n", "1.10.3"},
+		{"go", "go version go1.11 linux/amd64\n", "1.11"},
+		{"php", "PHP 7.2.7 (cli) (built: Jun 22 2018 06:27:50) ( NTS )\n", "7.2.7"},
+		{"node", "v8.11.3\n", "8.11.3"},
+		{"ruby", "ruby 2.5.1p57 (2018-03-29 revision 63029) [x86_64-darwin17]\n", "2.5.1"},
+		{"python", "Python 3.6.5\n", "3.6.5"},
+		{"python", "command not found", ""},
+	}
+
+	for _, tt := range versionTests {
+		if version, _ := parseRuntimeVersion(tt.runtime, tt.output); version != tt.version {
+			t.Errorf("parseRuntimeVersion(%s, %q) => %s, wanted: %s", tt.runtime, tt.output, version, tt.version)
+		}
+	}
+}
+
+func TestCheckRuntimeVersion(t *testing.T) {
+	versionTests := []struct {
+		required string
+		version  string
+		found    bool
+		err      string
+	}{
+		{"3.8", "3.8.1", true, ""},
+		{"3.8.0", "3.8.0", true, ""},
+		{"*", "2.7.15", true, ""},
+		{"3.8", "", true, ""},
+		{"3.8", "3.6", true, "requires python >= 3.8, found 3.6"},
+		{"3.8", "", false, "requires python >= 3.8, but it was not found"},
+		{"*", "", false, "requires python, but it was not found"},
+	}
+
+	for _, tt := range versionTests {
+		err := checkRuntimeVersion("python", tt.required, tt.version, tt.found)
+		message := ""
+		if err != nil {
+			message = err.Error()
+		}
+		if message != tt.err {
+			t.Errorf("checkRuntimeVersion(%s, %s, %t) => %q, wanted: %q", tt.required, tt.version, tt.found, message, tt.err)
+		}
+	}
+}
+
+func TestPackageRequirementsRuntimes(t *testing.T) {
+	runtimes := packageRequirements{Go: "1.9.0", Python: "*"}.runtimes()
+	if len(runtimes) != 2 || runtimes["go"] != "1.9.0" || runtimes["python"] != "*" {
+		t.Errorf("runtimes() => %v, wanted: map[go:1.9.0 python:*]", runtimes)
+	}
+}
@@ -0,0 +1,244 @@
+/*
+ Copyright 2018. Akamai Technologies, Inc
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// parseVersionParts parses a (possibly partial) version such as 1.2.3, v1.2, or
+// 1.x, returning its parts and how many were given before any wildcard.
+// Pre-release and build suffixes are ignored.
+func parseVersionParts(version string) ([3]int, int, bool) {
+	var parts [3]int
+
+	version = strings.TrimPrefix(strings.TrimSpace(version), "v")
+	if i := strings.IndexAny(version, "-+"); i != -1 {
+		version = version[:i]
+	}
+
+	if version == "" || version == "*" || version == "x" || version == "X" {
+		return parts, 0, true
+	}
+
+	fields := strings.Split(version, ".")
+	if len(fields) > 3 {
+		return parts, 0, false
+	}
+
+	for i, field := range fields {
+		if field == "*" || field == "x" || field == "X" {
+			return parts, i, true
+		}
+
+		n, err := strconv.Atoi(field)
+		if err != nil || n < 0 {
+			return parts, 0, false
+		}
+		parts[i] = n
+	}
+
+	return parts, len(fields), true
+}
+
+func formatVersionParts(parts [3]int) string {
+	return fmt.Sprintf("%d.%d.%d", parts[0], parts[1], parts[2])
+}
+
+// versionSatisfies reports whether version matches a semver range constraint.
+// Ranges are made of comparators (1.2.3, =1.2.3, >1.2, >=1.2.3, <2, <=2.1,
+// ^1.2.3, ~1.2.3, 1.x, *) separated by spaces, all of which must match, and
+// any of several ranges may match when separated by ||.
+func versionSatisfies(version string, constraint string) bool {
+	parts, n, ok := parseVersionParts(version)
+	if !ok || n == 0 {
+		return false
+	}
+	version = formatVersionParts(parts)
+
+	for _, alternative := range strings.Split(constraint, "||") {
+		satisfied := true
+		for _, comparator := range strings.Fields(strings.Replace(alternative, ",", " ", -1)) {
+			if !versionMatchesComparator(version, comparator) {
+				satisfied = false
+				break
+			}
+		}
+
+		if satisfied {
+			return true
+		}
+	}
+
+	return false
+}
+
+func versionMatchesComparator(version string, comparator string) bool {
+	op := ""
+	for _, prefix := range []string{">=", "<=", ">", "<", "=", "^", "~"} {
+		if strings.HasPrefix(comparator, prefix) {
+			op = prefix
+			break
+		}
+	}
+
+	parts, n, ok := parseVersionParts(strings.TrimPrefix(comparator, op))
+	if !ok {
+		return false
+	}
+
+	// versionCompare returns 1 when its right argument is newer
+	cmp := -versionCompare(version, formatVersionParts(parts))
+
+	switch op {
+	case ">":
+		if n < 3 {
+			// >1.2 means the next minor version or later
+			return versionMatchesComparator(version, ">="+formatVersionParts(nextVersion(parts, n)))
+		}
+		return cmp > 0
+	case ">=":
+		return cmp >= 0
+	case "<":
+		return cmp < 0
+	case "<=":
+		if n < 3 {
+			return versionMatchesComparator(version, "<"+formatVersionParts(nextVersion(parts, n)))
+		}
+		return cmp <= 0
+	case "^":
+		if n == 0 {
+			return true
+		}
+		// Anything up to the next change of the leftmost non-zero part
+		upper := 0
+		for upper < n-1 && parts[upper] == 0 {
+			upper++
+		}
+		return cmp >= 0 && -versionCompare(version, formatVersionParts(nextVersion(parts, upper+1))) < 0
+	case "~":
+		if n > 2 {
+			n = 2
+		}
+		if n == 0 {
+			return true
+		}
+		return cmp >= 0 && -versionCompare(version, formatVersionParts(nextVersion(parts, n))) < 0
+	case "", "=":
+		if n == 0 {
+			return true
+		}
+		if n == 3 {
+			return cmp == 0
+		}
+		return cmp >= 0 && -versionCompare(version, formatVersionParts(nextVersion(parts, n))) < 0
+	}
+
+	return false
+}
+
+// nextVersion increments the nth part of a version (counting from 1), zeroing
+// the parts after it, e.g. the next version of 1.2 at 2 parts is 1.3.0
+func nextVersion(parts [3]int, n int) [3]int {
+	if n == 0 {
+		return parts
+	}
+
+	next := parts
+	next[n-1]++
+	for i := n; i < 3; i++ {
+		next[i] = 0
+	}
+
+	return next
+}
+
+// compareVersions compares two versions, including their pre-release suffixes,
+// returning -1 if a is older than b, 0 if they are the same, and 1 if a is newer.
+// A pre-release is older than the release it precedes, e.g. 1.2.0-beta.1 < 1.2.0.
+func compareVersions(a string, b string) int {
+	aParts, _, _ := parseVersionParts(a)
+	bParts, _, _ := parseVersionParts(b)
+	for i := range aParts {
+		if aParts[i] != bParts[i] {
+			if aParts[i] < bParts[i] {
+				return -1
+			}
+			return 1
+		}
+	}
+
+	aPre, bPre := getPrerelease(a), getPrerelease(b)
+	switch {
+	case aPre == bPre:
+		return 0
+	case aPre == "":
+		return 1
+	case bPre == "":
+		return -1
+	}
+
+	aFields, bFields := strings.Split(aPre, "."), strings.Split(bPre, ".")
+	for i := 0; i < len(aFields) && i < len(bFields); i++ {
+		if aFields[i] == bFields[i] {
+			continue
+		}
+
+		aNum, aErr := strconv.Atoi(aFields[i])
+		bNum, bErr := strconv.Atoi(bFields[i])
+		switch {
+		case aErr == nil && bErr == nil:
+			if aNum < bNum {
+				return -1
+			}
+			return 1
+		case aErr == nil:
+			// Numeric identifiers sort before alphanumeric ones
+			return -1
+		case bErr == nil:
+			return 1
+		case aFields[i] < bFields[i]:
+			return -1
+		default:
+			return 1
+		}
+	}
+
+	switch {
+	case len(aFields) < len(bFields):
+		return -1
+	case len(aFields) > len(bFields):
+		return 1
+	}
+
+	return 0
+}
+
+// getPrerelease returns the pre-release suffix of a version, e.g. beta.1 for 1.2.0-beta.1+build.5
+func getPrerelease(version string) string {
+	if i := strings.Index(version, "+"); i != -1 {
+		version = version[:i]
+	}
+
+	if i := strings.Index(version, "-"); i != -1 {
+		return version[i+1:]
+	}
+
+	return ""
+}
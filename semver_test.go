@@ -0,0 +1,94 @@
+/*
+ Copyright 2018. Akamai Technologies, Inc
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package main
+
+import "testing"
+
+func TestVersionSatisfies(t *testing.T) {
+	satisfiesTests := []struct {
+		version    string
+		constraint string
+		result     bool
+	}{
+		{"1.2.3", "", true},
+		{"1.2.3", "*", true},
+		{"1.2.3", "1.2.3", true},
+		{"v1.2.3", "=1.2.3", true},
+		{"1.2.4", "1.2.3", false},
+		{"1.2.9", "1.2", true},
+		{"1.3.0", "1.2", false},
+		{"1.9.0", "1.x", true},
+		{"1.2.3", ">=1.2.3", true},
+		{"1.2.2", ">=1.2.3", false},
+		{"1.2.3", ">1.2.3", false},
+		{"1.2.9", ">1.2", false},
+		{"1.3.0", ">1.2", true},
+		{"1.9.9", "<2", true},
+		{"2.0.0", "<2", false},
+		{"2.1.9", "<=2.1", true},
+		{"2.2.0", "<=2.1", false},
+		{"1.9.0", "^1.2.3", true},
+		{"2.0.0", "^1.2.3", false},
+		{"1.2.2", "^1.2.3", false},
+		{"0.2.9", "^0.2.3", true},
+		{"0.3.0", "^0.2.3", false},
+		{"0.0.4", "^0.0.3", false},
+		{"1.2.9", "~1.2.3", true},
+		{"1.3.0", "~1.2.3", false},
+		{"1.9.0", "~1", true},
+		{"1.5.0", ">=1.0.0 <2.0.0", true},
+		{"2.5.0", ">=1.0.0 <2.0.0", false},
+		{"3.1.0", "^1.0.0 || ^3.0.0", true},
+		{"2.1.0", "^1.0.0 || ^3.0.0", false},
+		{"1.2.3-beta", "1.2.3", true},
+		{"unknown", "*", false},
+		{"1.2.3", "^one", false},
+	}
+
+	for _, tt := range satisfiesTests {
+		if result := versionSatisfies(tt.version, tt.constraint); result != tt.result {
+			t.Errorf("versionSatisfies(%s, %s) => %t, wanted: %t", tt.version, tt.constraint, result, tt.result)
+		}
+	}
+}
+
+func TestCompareVersions(t *testing.T) {
+	compareTests := []struct {
+		a      string
+		b      string
+		result int
+	}{
+		{"1.2.3", "1.2.3", 0},
+		{"v1.2.3", "1.2.3", 0},
+		{"1.2.3", "1.2.4", -1},
+		{"1.10.0", "1.9.0", 1},
+		{"1.2.0-beta.1", "1.2.0", -1},
+		{"1.2.0", "1.2.0-beta.1", 1},
+		{"1.2.0-beta.1", "1.1.9", 1},
+		{"1.2.0-beta.2", "1.2.0-beta.10", -1},
+		{"1.2.0-beta", "1.2.0-beta.1", -1},
+		{"1.2.0-alpha.1", "1.2.0-beta.1", -1},
+		{"1.2.0-1", "1.2.0-beta", -1},
+		{"1.2.0-rc.1+build.5", "1.2.0-rc.1", 0},
+	}
+
+	for _, tt := range compareTests {
+		if result := compareVersions(tt.a, tt.b); result != tt.result {
+			t.Errorf("compareVersions(%s, %s) => %d, wanted: %d", tt.a, tt.b, result, tt.result)
+		}
+	}
+}
@@ -0,0 +1,75 @@
+/*
+ Copyright 2018. Akamai Technologies, Inc
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package main
+
+import (
+	"encoding/base64"
+	"errors"
+	"strings"
+
+	"golang.org/x/crypto/ed25519"
+)
+
+// getIndexPublicKey returns the ed25519 key package lists must be signed with,
+// from the base64 encoded cli.index-public-key, or nil if verification is not configured
+func getIndexPublicKey() (ed25519.PublicKey, error) {
+	value := strings.TrimSpace(getConfigValue("cli", "index-public-key"))
+	if value == "" {
+		return nil, nil
+	}
+
+	key, err := decodePublicKey(value)
+	if err != nil {
+		return nil, errors.New("index-public-key must be a base64 encoded ed25519 public key")
+	}
+
+	return key, nil
+}
+
+func decodePublicKey(value string) (ed25519.PublicKey, error) {
+	key, err := base64.StdEncoding.DecodeString(strings.TrimSpace(value))
+	if err != nil || len(key) != ed25519.PublicKeySize {
+		return nil, errors.New("not a base64 encoded ed25519 public key")
+	}
+
+	return ed25519.PublicKey(key), nil
+}
+
+// verifyIndexSignature checks a base64 encoded detached signature of data
+func verifyIndexSignature(key ed25519.PublicKey, data []byte, signature []byte) error {
+	if err := verifySignature(key, data, signature); err != errSignatureMismatch {
+		return err
+	}
+
+	return errors.New("signature does not match the configured index-public-key")
+}
+
+var errSignatureMismatch = errors.New("signature does not match")
+
+// verifySignature checks a base64 encoded detached ed25519 signature of data
+func verifySignature(key ed25519.PublicKey, data []byte, signature []byte) error {
+	sig, err := base64.StdEncoding.DecodeString(strings.TrimSpace(string(signature)))
+	if err != nil || len(sig) != ed25519.SignatureSize {
+		return errors.New("signature is malformed")
+	}
+
+	if !ed25519.Verify(key, data, sig) {
+		return errSignatureMismatch
+	}
+
+	return nil
+}
@@ -0,0 +1,56 @@
+/*
+ Copyright 2018. Akamai Technologies, Inc
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package main
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"testing"
+
+	"golang.org/x/crypto/ed25519"
+)
+
+func TestVerifyIndexSignature(t *testing.T) {
+	public, private, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	data := []byte(`{"version": 1, "packages": []}`)
+	signature := []byte(base64.StdEncoding.EncodeToString(ed25519.Sign(private, data)) + "\n")
+
+	verifyTests := []struct {
+		data      []byte
+		signature []byte
+		valid     bool
+	}{
+		{data, signature, true},
+		{[]byte(`{"version": 2, "packages": []}`), signature, false},
+		{data, []byte("not a signature"), false},
+		{data, []byte(""), false},
+	}
+
+	for _, tt := range verifyTests {
+		err := verifyIndexSignature(public, tt.data, tt.signature)
+		if tt.valid && err != nil {
+			t.Errorf("verifyIndexSignature(%s, %s) => %s, wanted no error", tt.data, tt.signature, err.Error())
+		}
+		if !tt.valid && err == nil {
+			t.Errorf("verifyIndexSignature(%s, %s) => no error, wanted an error", tt.data, tt.signature)
+		}
+	}
+}
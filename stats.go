@@ -29,6 +29,9 @@ import (
 //
 // This is done by generating an anonymous UUID that events are tied to
 
+// analyticsTrackingID is the property that statistics and usage reports are sent to
+const analyticsTrackingID = "UA-34796267-23"
+
 func setupUuid() error {
 	if getConfigValue("cli", "client-id") == "" {
 		uuid, err := securerandom.Uuid()
@@ -44,12 +47,12 @@ func setupUuid() error {
 }
 
 func trackEvent(action string, value string) {
-	if getConfigValue("cli", "enable-cli-statistics") == "false" {
+	if getConfigValue("cli", "enable-cli-statistics") == "false" || isOffline() {
 		return
 	}
 
 	form := url.Values{}
-	form.Add("tid", "UA-34796267-23")
+	form.Add("tid", analyticsTrackingID)
 	form.Add("v", "1")                                  // Version 1
 	form.Add("aip", "1")                                // Anonymize IP
 	form.Add("cid", getConfigValue("cli", "client-id")) // Unique Cilent ID
@@ -58,7 +61,11 @@ func trackEvent(action string, value string) {
 	form.Add("ea", action)                              // Action
 	form.Add("el", value)                               // Label
 
-	hc := http.Client{}
+	hc, err := getHTTPClient()
+	if err != nil {
+		return
+	}
+
 	req, err := http.NewRequest("POST", "https://www.google-analytics.com/collect", strings.NewReader(form.Encode()))
 	if err != nil {
 		return
@@ -69,7 +76,7 @@ func trackEvent(action string, value string) {
 }
 
 func checkPing() {
-	if getConfigValue("cli", "enable-cli-statistics") == "false" {
+	if getConfigValue("cli", "enable-cli-statistics") == "false" || isOffline() {
 		return
 	}
 
@@ -0,0 +1,312 @@
+/*
+ Copyright 2018. Akamai Technologies, Inc
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/urfave/cli"
+)
+
+// Usage reporting is off unless "akamai config set cli.telemetry on" is run.
+// It records which command was run (never its arguments), how long it took,
+// whether it succeeded, and the CLI version. Events are spooled to disk, and
+// sent in batches while a later command runs, so reporting never slows
+// anything down and nothing is lost while offline.
+
+const (
+	telemetryURL        = "https://www.google-analytics.com/batch"
+	telemetrySpoolFile  = "telemetry.jsonl"
+	telemetryBatchSize  = 20
+	telemetryMaxAge     = 24 * time.Hour
+	telemetryMaxSpooled = 500
+	// telemetryWait is how long a command waits, once done, for a batch still being sent
+	telemetryWait = 2 * time.Second
+)
+
+type telemetryEvent struct {
+	Command  string    `json:"command"`
+	Duration int64     `json:"durationMs"`
+	Success  bool      `json:"success"`
+	Version  string    `json:"version"`
+	Time     time.Time `json:"time"`
+}
+
+// telemetryDone is closed once the batch started by startTelemetry has been sent
+var telemetryDone chan struct{}
+
+func isTelemetryEnabled() bool {
+	switch strings.ToLower(getConfigValue("cli", "telemetry")) {
+	case "on", "true", "yes", "1":
+		return true
+	}
+
+	return false
+}
+
+// startTelemetry sends the spooled events in the background, if there are
+// enough of them, or they have waited long enough
+func startTelemetry() {
+	if !isTelemetryEnabled() || isOffline() {
+		return
+	}
+
+	done := make(chan struct{})
+	telemetryDone = done
+	go func() {
+		defer close(done)
+		if err := flushTelemetry(); err != nil {
+			logDebug("telemetry not sent", "error", err)
+		}
+	}()
+}
+
+// withTelemetry wraps the action of a command to record its usage
+func withTelemetry(builtin bool, action interface{}) interface{} {
+	if action == nil {
+		return nil
+	}
+
+	return func(c *cli.Context) error {
+		start := time.Now()
+		err := cli.HandleAction(action, c)
+		recordTelemetry(getTelemetryCommand(c.Command.Name, builtin), time.Since(start), err == nil)
+
+		return err
+	}
+}
+
+// getTelemetryCommand returns the name a command is reported as. Commands from
+// packages that are not Akamai's own are all reported as "third-party".
+func getTelemetryCommand(name string, builtin bool) string {
+	if builtin {
+		return name
+	}
+
+	exec, err := findExec(name)
+	if err != nil {
+		return "third-party"
+	}
+
+	dir := findPackageDir(filepath.Dir(exec[len(exec)-1]))
+	manifest, err := readManifest(filepath.Base(getPackageRoot(dir)))
+	if err != nil || !isOfficialPackageRepo(manifest.Repo) {
+		return "third-party"
+	}
+
+	return name
+}
+
+func isOfficialPackageRepo(repo string) bool {
+	return strings.HasPrefix(repo, "https://github.com/akamai/cli-") || strings.HasPrefix(repo, "git@github.com:akamai/cli-")
+}
+
+func recordTelemetry(command string, duration time.Duration, success bool) {
+	if !isTelemetryEnabled() {
+		return
+	}
+
+	if err := setupUuid(); err != nil {
+		return
+	}
+
+	event := telemetryEvent{
+		Command:  command,
+		Duration: int64(duration / time.Millisecond),
+		Success:  success,
+		Version:  VERSION,
+		Time:     time.Now().UTC(),
+	}
+	if err := spoolTelemetry([]telemetryEvent{event}); err != nil {
+		logDebug("unable to spool telemetry", "error", err)
+	}
+
+	if telemetryDone != nil {
+		select {
+		case <-telemetryDone:
+		case <-time.After(telemetryWait):
+		}
+	}
+}
+
+func getTelemetrySpoolPath() (string, error) {
+	cachePath, err := getAkamaiCliCachePath()
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(cachePath, telemetrySpoolFile), nil
+}
+
+// spoolTelemetry appends events to the spool, a line each
+func spoolTelemetry(events []telemetryEvent) error {
+	path, err := getTelemetrySpoolPath()
+	if err != nil {
+		return err
+	}
+
+	buf := &bytes.Buffer{}
+	for _, event := range events {
+		data, err := json.Marshal(event)
+		if err != nil {
+			return err
+		}
+		buf.Write(data)
+		buf.WriteString("\n")
+	}
+
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0664)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	_, err = file.Write(buf.Bytes())
+	return err
+}
+
+func readTelemetrySpool(path string) ([]telemetryEvent, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var events []telemetryEvent
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		var event telemetryEvent
+		if err := json.Unmarshal(scanner.Bytes(), &event); err == nil {
+			events = append(events, event)
+		}
+	}
+
+	return events, scanner.Err()
+}
+
+// shouldSendTelemetry reports whether the spooled events make a batch
+func shouldSendTelemetry(events []telemetryEvent, now time.Time) bool {
+	if len(events) == 0 {
+		return false
+	}
+
+	return len(events) >= telemetryBatchSize || now.Sub(events[0].Time) >= telemetryMaxAge
+}
+
+func flushTelemetry() error {
+	path, err := getTelemetrySpoolPath()
+	if err != nil {
+		return err
+	}
+
+	events, err := readTelemetrySpool(path)
+	if err != nil || !shouldSendTelemetry(events, time.Now()) {
+		return nil
+	}
+
+	// Take the spool, so that other commands start a new one while it is sent
+	sending := fmt.Sprintf("%s.%d", path, os.Getpid())
+	if err := os.Rename(path, sending); err != nil {
+		return err
+	}
+	defer os.Remove(sending)
+
+	if events, err = readTelemetrySpool(sending); err != nil {
+		return err
+	}
+
+	for len(events) > 0 {
+		n := telemetryBatchSize
+		if n > len(events) {
+			n = len(events)
+		}
+
+		if err := sendTelemetry(events[:n]); err != nil {
+			// Keep the rest for next time, dropping the oldest if there are too many
+			if len(events) > telemetryMaxSpooled {
+				events = events[len(events)-telemetryMaxSpooled:]
+			}
+			spoolTelemetry(events)
+			return err
+		}
+		events = events[n:]
+	}
+
+	return nil
+}
+
+func sendTelemetry(events []telemetryEvent) error {
+	hc, err := getHTTPClient()
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequest("POST", telemetryURL, strings.NewReader(encodeTelemetryBatch(events, getConfigValue("cli", "client-id"))))
+	if err != nil {
+		return err
+	}
+	req.Header.Add("Content-Type", "text/plain")
+
+	res, err := hc.Do(req)
+	if err != nil {
+		return err
+	}
+	res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected status %s", res.Status)
+	}
+
+	return nil
+}
+
+// encodeTelemetryBatch encodes events as a batch of analytics hits, one per line
+func encodeTelemetryBatch(events []telemetryEvent, clientID string) string {
+	lines := make([]string, 0, len(events))
+	for _, event := range events {
+		result := "success"
+		if !event.Success {
+			result = "failure"
+		}
+
+		form := url.Values{}
+		form.Add("tid", analyticsTrackingID)
+		form.Add("v", "1")
+		form.Add("aip", "1")
+		form.Add("cid", clientID)
+		form.Add("t", "event")
+		form.Add("ec", "akamai-cli-usage")
+		form.Add("ea", event.Command)
+		form.Add("el", result)
+		form.Add("ev", strconv.FormatInt(event.Duration, 10))
+		form.Add("an", "akamai-cli")
+		form.Add("av", event.Version)
+		lines = append(lines, form.Encode())
+	}
+
+	return strings.Join(lines, "\n")
+}
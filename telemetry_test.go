@@ -0,0 +1,84 @@
+/*
+ Copyright 2018. Akamai Technologies, Inc
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package main
+
+import (
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestShouldSendTelemetry(t *testing.T) {
+	now := time.Date(2018, 6, 1, 12, 0, 0, 0, time.UTC)
+	events := func(n int, age time.Duration) []telemetryEvent {
+		var events []telemetryEvent
+		for i := 0; i < n; i++ {
+			events = append(events, telemetryEvent{Command: "list", Time: now.Add(-age)})
+		}
+		return events
+	}
+
+	sendTests := []struct {
+		events []telemetryEvent
+		send   bool
+	}{
+		{nil, false},
+		{events(1, time.Hour), false},
+		{events(telemetryBatchSize-1, time.Hour), false},
+		{events(telemetryBatchSize, time.Hour), true},
+		{events(1, telemetryMaxAge), true},
+	}
+
+	for _, tt := range sendTests {
+		if send := shouldSendTelemetry(tt.events, now); send != tt.send {
+			t.Errorf("shouldSendTelemetry(%d events) => %t, wanted: %t", len(tt.events), send, tt.send)
+		}
+	}
+}
+
+func TestEncodeTelemetryBatch(t *testing.T) {
+	batch := encodeTelemetryBatch([]telemetryEvent{
+		{Command: "install", Duration: 1500, Success: true, Version: "0.6.0"},
+		{Command: "third-party", Duration: 20, Success: false, Version: "0.6.0"},
+	}, "client")
+
+	lines := strings.Split(batch, "\n")
+	if len(lines) != 2 {
+		t.Fatalf("encodeTelemetryBatch() => %d lines, wanted: 2", len(lines))
+	}
+
+	hitTests := []struct {
+		line   string
+		action string
+		label  string
+		value  string
+	}{
+		{lines[0], "install", "success", "1500"},
+		{lines[1], "third-party", "failure", "20"},
+	}
+
+	for _, tt := range hitTests {
+		hit, err := url.ParseQuery(tt.line)
+		if err != nil {
+			t.Fatalf("encodeTelemetryBatch() => %q: %s", tt.line, err.Error())
+		}
+		if hit.Get("ea") != tt.action || hit.Get("el") != tt.label || hit.Get("ev") != tt.value || hit.Get("cid") != "client" || hit.Get("av") != "0.6.0" {
+			t.Errorf("encodeTelemetryBatch() => %q, wanted action %s, label %s, value %s", tt.line, tt.action, tt.label, tt.value)
+		}
+	}
+}
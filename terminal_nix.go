@@ -0,0 +1,65 @@
+// +build !windows
+
+/*
+ Copyright 2018. Akamai Technologies, Inc
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// makeRawTerminal switches the terminal on stdin to raw mode, returning a function that restores it
+func makeRawTerminal() (func(), error) {
+	state, err := stty("-g")
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := stty("raw", "-echo"); err != nil {
+		return nil, err
+	}
+
+	return func() {
+		stty(strings.TrimSpace(state))
+	}, nil
+}
+
+// getTerminalSize returns the number of rows and columns of the terminal on stdin
+func getTerminalSize() (int, int, error) {
+	size, err := stty("size")
+	if err != nil {
+		return 0, 0, err
+	}
+
+	var rows, cols int
+	if _, err := fmt.Sscanf(size, "%d %d", &rows, &cols); err != nil {
+		return 0, 0, err
+	}
+
+	return rows, cols, nil
+}
+
+func stty(args ...string) (string, error) {
+	cmd := exec.Command("stty", args...)
+	cmd.Stdin = os.Stdin
+	output, err := cmd.Output()
+
+	return string(output), err
+}
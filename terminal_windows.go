@@ -0,0 +1,27 @@
+/*
+ Copyright 2018. Akamai Technologies, Inc
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package main
+
+import "errors"
+
+func makeRawTerminal() (func(), error) {
+	return nil, errors.New("Interactive mode is not supported on Windows")
+}
+
+func getTerminalSize() (int, int, error) {
+	return 0, 0, errors.New("Unable to determine terminal size on Windows")
+}
@@ -0,0 +1,130 @@
+/*
+ Copyright 2018. Akamai Technologies, Inc
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	akamai "github.com/akamai/cli-common-golang"
+	"github.com/fatih/color"
+	"github.com/urfave/cli"
+)
+
+// noUpdateFooterEnv turns off the update footer when set, like cli.update-footer off
+const noUpdateFooterEnv = "AKAMAI_CLI_NO_UPDATE_FOOTER"
+
+const defaultUpdateFooterInterval = 24 * time.Hour
+
+func isUpdateFooterEnabled() bool {
+	if os.Getenv(noUpdateFooterEnv) != "" {
+		return false
+	}
+
+	switch strings.ToLower(getConfigValue("cli", "update-footer")) {
+	case "off", "false", "no", "0":
+		return false
+	}
+
+	return true
+}
+
+// getUpdateFooterInterval returns cli.update-footer-interval, or the default
+func getUpdateFooterInterval() time.Duration {
+	if interval, err := time.ParseDuration(getConfigValue("cli", "update-footer-interval")); err == nil && interval >= 0 {
+		return interval
+	}
+
+	return defaultUpdateFooterInterval
+}
+
+// withUpdateFooter wraps the action of a command to print the updates found
+// by the last background check once it has run successfully
+func withUpdateFooter(builtin bool, action interface{}) interface{} {
+	if action == nil {
+		return nil
+	}
+
+	return func(c *cli.Context) error {
+		err := cli.HandleAction(action, c)
+		if err == nil {
+			printUpdateFooter(c.Command.Name, builtin)
+		}
+
+		return err
+	}
+}
+
+// printUpdateFooter prints a line about the updates available for the command
+// that was run, or otherwise the CLI and other packages, at most once per
+// cli.update-footer-interval
+func printUpdateFooter(cmd string, builtin bool) {
+	if !isUpdateFooterEnabled() || !showUpdateNotice(os.Args[1:]) {
+		return
+	}
+
+	updateStateLock.Lock()
+	defer updateStateLock.Unlock()
+
+	state := readUpdateState()
+	if time.Since(state.Notified) < getUpdateFooterInterval() {
+		return
+	}
+
+	pkg := ""
+	if !builtin {
+		pkg = getCommandPackageName(cmd)
+	}
+
+	footer := formatUpdateFooter(state, VERSION, cmd, pkg, isPackageInstalled)
+	if footer == "" {
+		return
+	}
+
+	fmt.Fprintln(akamai.App.ErrWriter, color.CyanString(footer))
+
+	state.Notified = time.Now()
+	if err := writeUpdateState(state); err != nil {
+		logWarn("unable to save update state", "error", err)
+	}
+}
+
+// formatUpdateFooter returns the update of pkg, the package of the command
+// cmd, if one is available, or else the summary of formatUpdateNotice
+func formatUpdateFooter(state updateState, version string, cmd string, pkg string, installed func(string) bool) string {
+	for _, update := range state.Packages {
+		if pkg != "" && update.Name == pkg {
+			return fmt.Sprintf("%s %s is available (installed: %s), run \"%s update %s\"", strings.TrimPrefix(pkg, "cli-"), update.NewVersion, update.OldVersion, self(), cmd)
+		}
+	}
+
+	return formatUpdateNotice(state, version, installed)
+}
+
+// getCommandPackageName returns the directory name of the installed package
+// providing cmd, or ""
+func getCommandPackageName(cmd string) string {
+	exec, err := findExec(cmd)
+	if err != nil {
+		return ""
+	}
+
+	return filepath.Base(getPackageRoot(findPackageDir(filepath.Dir(exec[len(exec)-1]))))
+}
@@ -0,0 +1,45 @@
+/*
+ Copyright 2018. Akamai Technologies, Inc
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package main
+
+import "testing"
+
+func TestFormatUpdateFooter(t *testing.T) {
+	installed := func(name string) bool { return true }
+	purge := updateStatePackage{Name: "cli-purge", OldVersion: "1.0.0", NewVersion: "1.1.0"}
+	property := updateStatePackage{Name: "cli-property", OldVersion: "0.6.0", NewVersion: "0.7.0"}
+
+	footerTests := []struct {
+		state  updateState
+		cmd    string
+		pkg    string
+		footer string
+	}{
+		{updateState{}, "purge", "cli-purge", ""},
+		{updateState{Packages: []updateStatePackage{purge, property}}, "purge", "cli-purge", "purge 1.1.0 is available (installed: 1.0.0), run \"" + self() + " update purge\""},
+		{updateState{Packages: []updateStatePackage{purge, property}, CliVersion: "1.3.0"}, "property-manager", "cli-property", "property 0.7.0 is available (installed: 0.6.0), run \"" + self() + " update property-manager\""},
+		{updateState{Packages: []updateStatePackage{property}}, "purge", "cli-purge", "1 update available, run \"" + self() + " update\""},
+		{updateState{Packages: []updateStatePackage{purge}}, "list", "", "1 update available, run \"" + self() + " update\""},
+		{updateState{CliVersion: "1.3.0"}, "list", "", "Akamai CLI 1.3.0 is available, run \"" + self() + " upgrade\""},
+	}
+
+	for _, tt := range footerTests {
+		if footer := formatUpdateFooter(tt.state, "1.2.0", tt.cmd, tt.pkg, installed); footer != tt.footer {
+			t.Errorf("formatUpdateFooter(%+v, %s) => %s, wanted: %s", tt.state, tt.cmd, footer, tt.footer)
+		}
+	}
+}
@@ -1,4 +1,5 @@
-//+build !noautoupgrade
+//go:build !noautoupgrade
+// +build !noautoupgrade
 
 /*
  Copyright 2018. Akamai Technologies, Inc
@@ -19,16 +20,17 @@
 package main
 
 import (
-	"bytes"
+	"crypto/sha256"
 	"encoding/hex"
+	"encoding/json"
 	"fmt"
+	"io"
 	"io/ioutil"
 	"net/http"
 	"os"
 	"path/filepath"
 	"runtime"
 	"strings"
-	"text/template"
 	"time"
 
 	akamai "github.com/akamai/cli-common-golang"
@@ -36,9 +38,27 @@ import (
 	"github.com/inconshreveable/go-update"
 	"github.com/kardianos/osext"
 	"github.com/mattn/go-isatty"
+	"github.com/urfave/cli"
 )
 
+// upgradeBackupDir, in the CLI home, keeps the binary the last upgrade replaced
+const upgradeBackupDir = "upgrade-backup"
+
+// upgradeBackup records the binary the last upgrade replaced, for "akamai upgrade --undo"
+type upgradeBackup struct {
+	Version string `json:"version"`
+	// File is in upgradeBackupDir, so the record survives moving the CLI home
+	File   string `json:"file"`
+	SHA256 string `json:"sha256"`
+	Time   string `json:"time"`
+	path   string
+}
+
 func checkForUpgrade(force bool) string {
+	if isOffline() {
+		return ""
+	}
+
 	if !isatty.IsTerminal(os.Stdout.Fd()) && !isatty.IsCygwinTerminal(os.Stdout.Fd()) {
 		return ""
 	}
@@ -76,7 +96,8 @@ func checkForUpgrade(force bool) string {
 		}
 
 		latestVersion := getLatestReleaseVersion()
-		if versionCompare(VERSION, latestVersion) == 1 {
+		// Never offer an older version, e.g. a stable release after switching back from beta
+		if compareVersions(latestVersion, VERSION) > 0 {
 			if !force {
 				fmt.Fprintf(
 					akamai.App.Writer,
@@ -97,13 +118,36 @@ func checkForUpgrade(force bool) string {
 	return ""
 }
 
+// getLatestReleaseVersion returns the newest release on the upgrade channel,
+// or "0" if it can't be determined
 func getLatestReleaseVersion() string {
-	client := &http.Client{
-		CheckRedirect: func(req *http.Request, via []*http.Request) error {
-			return http.ErrUseLastResponse
-		},
+	if channel := getUpgradeChannel(); channel != channelStable {
+		return getLatestChannelReleaseVersion(channel)
+	}
+
+	shared, err := getHTTPClient()
+	if err != nil {
+		return "0"
+	}
+
+	client := *shared
+	client.CheckRedirect = func(req *http.Request, via []*http.Request) error {
+		return http.ErrUseLastResponse
 	}
-	resp, err := client.Head("https://github.com/akamai/cli/releases/latest")
+	var resp *http.Response
+	err = withRetry(getRetryPolicy(), func() error {
+		resp, err = client.Head("https://github.com/akamai/cli/releases/latest")
+		if err != nil {
+			return err
+		}
+		resp.Body.Close()
+
+		if isRetryableStatus(resp.StatusCode) {
+			return fmt.Errorf("unexpected status %s", resp.Status)
+		}
+
+		return nil
+	})
 	if err != nil {
 		return "0"
 	}
@@ -118,78 +162,114 @@ func getLatestReleaseVersion() string {
 	return latestVersion
 }
 
-func upgradeCli(latestVersion string) bool {
-	akamai.StartSpinner("Upgrading Akamai CLI", "Upgrading Akamai CLI...... ["+color.GreenString("OK")+"]\n\n")
+// getLatestChannelReleaseVersion looks through the published releases, including
+// pre-releases, for the newest one on channel
+func getLatestChannelReleaseVersion(channel string) string {
+	client, err := getHTTPClient()
+	if err != nil {
+		return "0"
+	}
 
-	cmd := Command{
-		Version: latestVersion,
-		Bin:     "https://github.com/akamai/cli/releases/download/{{.Version}}/akamai-{{.Version}}-{{.OS}}{{.Arch}}{{.BinSuffix}}",
-		Arch:    runtime.GOARCH,
-		OS:      runtime.GOOS,
+	var releases []struct {
+		TagName string `json:"tag_name"`
+		Draft   bool   `json:"draft"`
 	}
+	err = withRetry(getRetryPolicy(), func() error {
+		resp, err := client.Get("https://api.github.com/repos/akamai/cli/releases?per_page=100")
+		if err != nil {
+			return err
+		}
+		defer resp.Body.Close()
 
-	if runtime.GOOS == "darwin" {
-		cmd.OS = "mac"
+		if resp.StatusCode != http.StatusOK {
+			err := fmt.Errorf("unexpected status %s", resp.Status)
+			if isRetryableStatus(resp.StatusCode) {
+				return err
+			}
+			return permanent(err)
+		}
+
+		return permanent(json.NewDecoder(resp.Body).Decode(&releases))
+	})
+	if err != nil {
+		logWarn("unable to list releases", "channel", channel, "error", err)
+		return "0"
 	}
 
-	if runtime.GOOS == "windows" {
-		cmd.BinSuffix = ".exe"
+	var tags []string
+	for _, release := range releases {
+		if !release.Draft {
+			tags = append(tags, release.TagName)
+		}
 	}
 
-	t := template.Must(template.New("url").Parse(cmd.Bin))
-	buf := &bytes.Buffer{}
-	if err := t.Execute(buf, cmd); err != nil {
-		trackEvent("upgrade.failed", "to: "+latestVersion+" from:"+VERSION)
-		return false
+	if latest := selectChannelRelease(tags, channel); latest != "" {
+		return latest
 	}
 
-	url := buf.String()
+	return "0"
+}
 
-	resp, err := http.Get(url)
-	defer resp.Body.Close()
-	if err != nil || resp.StatusCode != 200 {
-		akamai.StopSpinnerFail()
-		fmt.Fprintln(akamai.App.ErrWriter, color.RedString("Unable to download release, please try again."))
+func upgradeCli(latestVersion string) bool {
+	p := newSpinnerProgress()
+	p.Start("Upgrading Akamai CLI")
+
+	cmd := Command{
+		Version: latestVersion,
+		Bin:     "https://github.com/akamai/cli/releases/download/{{.Version}}/akamai-{{.Version}}-{{.OS}}{{.Arch}}{{.BinSuffix}}",
+	}
+
+	urls, err := getBinaryURLs(cmd)
+	if err != nil {
+		p.Fail()
 		trackEvent("upgrade.failed", "to: "+latestVersion+" from:"+VERSION)
 		return false
 	}
 
-	shaResp, err := http.Get(url + ".sig")
-	defer shaResp.Body.Close()
-	if err != nil || shaResp.StatusCode != 200 {
-		akamai.StopSpinnerFail()
-		fmt.Fprintln(akamai.App.ErrWriter, color.RedString("Unable to retrieve signature for verification, please try again."))
+	client, err := getDownloadHTTPClient()
+	if err != nil {
+		p.Fail()
+		fmt.Fprintln(akamai.App.ErrWriter, color.RedString(err.Error()))
 		trackEvent("upgrade.failed", "to: "+latestVersion+" from:"+VERSION)
 		return false
 	}
 
-	shabody, err := ioutil.ReadAll(shaResp.Body)
+	resp, url, err := getBinary(client, urls)
 	if err != nil {
-		akamai.StopSpinnerFail()
-		fmt.Fprintln(akamai.App.ErrWriter, color.RedString("Unable to retrieve signature for verification, please try again."))
+		p.Fail()
+		logWarn("unable to download release", "version", latestVersion, "error", err)
+		fmt.Fprintln(akamai.App.ErrWriter, color.RedString("Unable to download release, please try again."))
 		trackEvent("upgrade.failed", "to: "+latestVersion+" from:"+VERSION)
 		return false
 	}
+	defer resp.Body.Close()
 
-	shasum, err := hex.DecodeString(strings.TrimSpace(string(shabody)))
+	shasum, err := getUpgradeChecksum(client, url)
 	if err != nil {
-		akamai.StopSpinnerFail()
-		fmt.Fprintln(akamai.App.ErrWriter, color.RedString("Unable to retrieve signature for verification, please try again."))
+		p.Fail()
+		fmt.Fprintln(akamai.App.ErrWriter, color.RedString("Unable to verify release, aborting upgrade: %s", err.Error()))
 		trackEvent("upgrade.failed", "to: "+latestVersion+" from:"+VERSION)
 		return false
 	}
 
 	selfPath, err := osext.Executable()
 	if err != nil {
-		akamai.StopSpinnerFail()
+		p.Fail()
 		fmt.Fprintln(akamai.App.ErrWriter, color.RedString("Unable to determine install location"))
 		trackEvent("upgrade.failed", "to: "+latestVersion+" from:"+VERSION)
 		return false
 	}
 
-	err = update.Apply(resp.Body, update.Options{TargetPath: selfPath, Checksum: shasum})
+	// Keep the current version, so that the upgrade can be undone
+	backupPath, backupErr := getUpgradeBackupPath(VERSION)
+	options := update.Options{TargetPath: selfPath, Checksum: shasum}
+	if backupErr == nil {
+		options.OldSavePath = backupPath
+	}
+
+	err = update.Apply(resp.Body, options)
 	if err != nil {
-		akamai.StopSpinnerFail()
+		p.Fail()
 		if rerr := update.RollbackError(err); rerr != nil {
 			fmt.Fprintln(akamai.App.ErrWriter, color.RedString("Unable to install or rollback, please re-install."))
 			trackEvent("upgrade.failed", "to: "+latestVersion+" from:"+VERSION)
@@ -204,7 +284,13 @@ func upgradeCli(latestVersion string) bool {
 	}
 
 	trackEvent("upgrade.success", "to: "+latestVersion+" from:"+VERSION)
-	akamai.StopSpinnerOk()
+	p.Ok()
+
+	if backupErr == nil {
+		if err := recordUpgradeBackup(VERSION, backupPath); err != nil {
+			logWarn("unable to record the previous version", "path", backupPath, "error", err)
+		}
+	}
 
 	if err == nil {
 		os.Args[0] = selfPath
@@ -218,12 +304,208 @@ func upgradeCli(latestVersion string) bool {
 	return true
 }
 
+// getUpgradeBackupPath returns where the binary of version is kept once an
+// upgrade replaces it
+func getUpgradeBackupPath(version string) (string, error) {
+	cliPath, err := getAkamaiCliPath()
+	if err != nil {
+		return "", err
+	}
+
+	dir := filepath.Join(cliPath, upgradeBackupDir)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", err
+	}
+
+	name := "akamai-" + version
+	if runtime.GOOS == "windows" {
+		name += ".exe"
+	}
+
+	return filepath.Join(dir, name), nil
+}
+
+// recordUpgradeBackup notes that the binary of version was kept at path, and
+// removes any other kept binary
+func recordUpgradeBackup(version string, path string) error {
+	sum, err := getFileSHA256(path)
+	if err != nil {
+		return err
+	}
+
+	dir := filepath.Dir(path)
+	entries, _ := filepath.Glob(filepath.Join(dir, "akamai-*"))
+	for _, entry := range entries {
+		if entry != path {
+			os.Remove(entry)
+		}
+	}
+
+	data, err := json.MarshalIndent(upgradeBackup{
+		Version: version,
+		File:    filepath.Base(path),
+		SHA256:  sum,
+		Time:    time.Now().Format(time.RFC3339),
+	}, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return ioutil.WriteFile(filepath.Join(dir, "backup.json"), data, 0644)
+}
+
+func readUpgradeBackup() (upgradeBackup, error) {
+	backup := upgradeBackup{}
+
+	cliPath, err := getAkamaiCliPath()
+	if err != nil {
+		return backup, err
+	}
+
+	dir := filepath.Join(cliPath, upgradeBackupDir)
+	data, err := ioutil.ReadFile(filepath.Join(dir, "backup.json"))
+	if err != nil {
+		return backup, err
+	}
+
+	if err := json.Unmarshal(data, &backup); err != nil {
+		return backup, err
+	}
+	backup.path = filepath.Join(dir, filepath.Base(backup.File))
+
+	return backup, nil
+}
+
+func getFileSHA256(path string) (string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+
+	hash := sha256.New()
+	if _, err := io.Copy(hash, file); err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+// undoUpgrade puts back the binary the last upgrade, or downgrade, replaced.
+// The binary it replaces is kept in turn, so undoing again redoes the upgrade.
+func undoUpgrade() error {
+	backup, err := readUpgradeBackup()
+	if err != nil {
+		return cli.NewExitError(color.RedString("There is no previous version to go back to"), 1)
+	}
+
+	checksum, err := hex.DecodeString(backup.SHA256)
+	if err != nil {
+		return cli.NewExitError(color.RedString("Invalid checksum for the previous version: %s", err.Error()), 1)
+	}
+
+	file, err := os.Open(backup.path)
+	if err != nil {
+		return cli.NewExitError(color.RedString("Unable to open the previous version: %s", err.Error()), 1)
+	}
+	defer file.Close()
+
+	selfPath, err := osext.Executable()
+	if err != nil {
+		return cli.NewExitError(color.RedString("Unable to determine install location"), 1)
+	}
+
+	p := newSpinnerProgress()
+	p.Start(fmt.Sprintf("Going back to Akamai CLI %s...", backup.Version))
+
+	options := update.Options{TargetPath: selfPath, Checksum: checksum}
+	replacedPath, replacedErr := getUpgradeBackupPath(VERSION)
+	if replacedErr == nil && replacedPath != backup.path {
+		options.OldSavePath = replacedPath
+	}
+
+	if err := update.Apply(file, options); err != nil {
+		p.Fail()
+		if rerr := update.RollbackError(err); rerr != nil {
+			return cli.NewExitError(color.RedString("Unable to go back or restore the current version, please re-install."), 1)
+		}
+		return cli.NewExitError(color.RedString("Unable to go back to the previous version: %s", err.Error()), 1)
+	}
+	file.Close()
+	logInfo("upgrade undone", "from", VERSION, "to", backup.Version)
+	trackEvent("upgrade.undo", "to: "+backup.Version+" from:"+VERSION)
+
+	p.Ok()
+
+	if options.OldSavePath != "" {
+		if err := recordUpgradeBackup(VERSION, options.OldSavePath); err != nil {
+			logWarn("unable to record the replaced version", "path", options.OldSavePath, "error", err)
+		}
+	}
+
+	printInfo(akamai.App.Writer, "Akamai CLI %s is installed, run \"%s upgrade --undo\" to go back to %s", backup.Version, self(), VERSION)
+	return nil
+}
+
+// getUpgradeChecksum returns the published checksum of the release binary at url,
+// verified against the embedded public key. Releases without signed checksums
+// are refused.
+func getUpgradeChecksum(client *http.Client, url string) ([]byte, error) {
+	key, err := getUpgradePublicKey()
+	if err != nil {
+		return nil, err
+	}
+
+	base := url[:strings.LastIndex(url, "/")]
+	filename := url[strings.LastIndex(url, "/")+1:]
+
+	sums, err := downloadUpgradeFile(client, base+"/"+upgradeSumsFile)
+	if err != nil {
+		return nil, fmt.Errorf("unable to retrieve %s: %s", upgradeSumsFile, err.Error())
+	}
+
+	signature, err := downloadUpgradeFile(client, base+"/"+upgradeSumsFile+".sig")
+	if err != nil {
+		return nil, fmt.Errorf("unable to retrieve %s.sig: %s", upgradeSumsFile, err.Error())
+	}
+
+	return verifyUpgradeChecksum(sums, signature, key, filename)
+}
+
+func downloadUpgradeFile(client *http.Client, url string) ([]byte, error) {
+	resp, err := client.Get(url)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status %s", resp.Status)
+	}
+
+	return ioutil.ReadAll(resp.Body)
+}
+
 func getUpgradeCommand() *commandPackage {
 	return &commandPackage{
 		Commands: []Command{
 			{
 				Name:        "upgrade",
 				Description: "Upgrade Akamai CLI to the latest version",
+				Flags: []cli.Flag{
+					cli.StringFlag{
+						Name:  "channel",
+						Usage: "Upgrade channel to use from now on: stable, beta, or nightly",
+					},
+					cli.StringFlag{
+						Name:  "to",
+						Usage: "Install release `VERSION`, which may be older than the current one",
+					},
+					cli.BoolFlag{
+						Name:  "undo",
+						Usage: "Go back to the version the last upgrade replaced",
+					},
+				},
 			},
 		},
 		action: cmdUpgrade,
@@ -0,0 +1,100 @@
+/*
+ Copyright 2018. Akamai Technologies, Inc
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Upgrade channels, each of which also receives the releases of the more stable ones
+const (
+	channelStable  = "stable"
+	channelBeta    = "beta"
+	channelNightly = "nightly"
+)
+
+var upgradeChannels = []string{channelStable, channelBeta, channelNightly}
+
+// getUpgradeChannel returns the channel set with cli.upgrade-channel, stable by default
+func getUpgradeChannel() string {
+	channel := strings.ToLower(strings.TrimSpace(getConfigValue("cli", "upgrade-channel")))
+	if validateUpgradeChannel(channel) != nil {
+		return channelStable
+	}
+
+	return channel
+}
+
+func validateUpgradeChannel(channel string) error {
+	for _, c := range upgradeChannels {
+		if channel == c {
+			return nil
+		}
+	}
+
+	return fmt.Errorf("Invalid upgrade channel \"%s\", must be one of: %s", channel, strings.Join(upgradeChannels, ", "))
+}
+
+// getReleaseChannel returns the channel a release belongs to, based on the
+// pre-release suffix of its tag: none for stable, alpha, beta, or rc for beta,
+// and anything else (e.g. 1.2.0-nightly.20180601) for nightly
+func getReleaseChannel(tag string) string {
+	prerelease := strings.ToLower(getPrerelease(tag))
+	if prerelease == "" {
+		return channelStable
+	}
+
+	for _, prefix := range []string{"alpha", "beta", "rc"} {
+		if strings.HasPrefix(prerelease, prefix) {
+			return channelBeta
+		}
+	}
+
+	return channelNightly
+}
+
+// channelIncludes reports whether releases of the given channel are offered on channel
+func channelIncludes(channel string, releaseChannel string) bool {
+	for _, c := range upgradeChannels {
+		if c == releaseChannel {
+			return true
+		}
+
+		if c == channel {
+			return false
+		}
+	}
+
+	return false
+}
+
+// selectChannelRelease returns the newest of tags offered on channel, or "" if there is none
+func selectChannelRelease(tags []string, channel string) string {
+	latest := ""
+	for _, tag := range tags {
+		if !channelIncludes(channel, getReleaseChannel(tag)) {
+			continue
+		}
+
+		if latest == "" || compareVersions(tag, latest) > 0 {
+			latest = tag
+		}
+	}
+
+	return latest
+}
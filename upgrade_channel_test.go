@@ -0,0 +1,41 @@
+/*
+ Copyright 2018. Akamai Technologies, Inc
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package main
+
+import "testing"
+
+func TestSelectChannelRelease(t *testing.T) {
+	tags := []string{"1.1.0", "1.2.0-nightly.20180601", "1.1.1-rc.1", "1.0.9", "1.1.1-beta.2"}
+
+	channelTests := []struct {
+		tags    []string
+		channel string
+		result  string
+	}{
+		{tags, channelStable, "1.1.0"},
+		{tags, channelBeta, "1.1.1-rc.1"},
+		{tags, channelNightly, "1.2.0-nightly.20180601"},
+		{append(tags, "1.3.0"), channelNightly, "1.3.0"},
+		{[]string{"1.2.0-beta.1"}, channelStable, ""},
+	}
+
+	for _, tt := range channelTests {
+		if result := selectChannelRelease(tt.tags, tt.channel); result != tt.result {
+			t.Errorf("selectChannelRelease(%v, %s) => %s, wanted: %s", tt.tags, tt.channel, result, tt.result)
+		}
+	}
+}
@@ -30,6 +30,10 @@ func upgradeCli(latestVersion string) bool {
 	return false
 }
 
+func undoUpgrade() error {
+	return nil
+}
+
 func getUpgradeCommand() *commandPackage {
 	return nil
 }
@@ -0,0 +1,88 @@
+/*
+ Copyright 2018. Akamai Technologies, Inc
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package main
+
+import (
+	"encoding/hex"
+	"fmt"
+	"strings"
+
+	"golang.org/x/crypto/ed25519"
+)
+
+// upgradePublicKey is the base64 encoded ed25519 key that release checksums
+// are signed with, see build.sh. It is part of the source so that every build
+// verifies upgrades, not just those made with build.sh.
+const upgradePublicKey = "omoPpQJz1guVUJQ9P7buN1GheGA+k6DZRtT/XYgrcI8="
+
+// upgradeSumsFile lists the SHA256 sums of all binaries of a release, in the
+// format of sha256sum, and is signed by upgradeSumsFile.sig
+const upgradeSumsFile = "SHA256SUMS"
+
+func getUpgradePublicKey() (ed25519.PublicKey, error) {
+	key, err := decodePublicKey(upgradePublicKey)
+	if err != nil {
+		return nil, fmt.Errorf("the embedded upgrade public key is invalid: %s", err.Error())
+	}
+
+	return key, nil
+}
+
+// verifyUpgradeChecksum returns the checksum of filename from the sums of a
+// release, after checking the signature of the sums with key
+func verifyUpgradeChecksum(sums []byte, signature []byte, key ed25519.PublicKey, filename string) ([]byte, error) {
+	if key == nil {
+		return nil, fmt.Errorf("no upgrade public key is available to verify the release checksums")
+	}
+
+	if len(signature) == 0 {
+		return nil, fmt.Errorf("the release checksums are not signed")
+	}
+
+	if err := verifySignature(key, sums, signature); err != nil {
+		return nil, fmt.Errorf("the release checksums failed signature verification: %s", err.Error())
+	}
+
+	sum, ok := findSHA256Sum(sums, filename)
+	if !ok {
+		return nil, fmt.Errorf("no checksum is published for %s", filename)
+	}
+
+	checksum, err := hex.DecodeString(sum)
+	if err != nil || len(checksum) != 32 {
+		return nil, fmt.Errorf("the published checksum for %s is malformed", filename)
+	}
+
+	return checksum, nil
+}
+
+// findSHA256Sum looks up filename in the output of sha256sum
+func findSHA256Sum(sums []byte, filename string) (string, bool) {
+	for _, line := range strings.Split(string(sums), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) != 2 {
+			continue
+		}
+
+		// Binary mode entries are marked with a *
+		if strings.TrimPrefix(fields[1], "*") == filename {
+			return strings.ToLower(fields[0]), true
+		}
+	}
+
+	return "", false
+}
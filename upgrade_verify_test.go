@@ -0,0 +1,75 @@
+/*
+ Copyright 2018. Akamai Technologies, Inc
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package main
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"strings"
+	"testing"
+
+	"golang.org/x/crypto/ed25519"
+)
+
+func TestVerifyUpgradeChecksum(t *testing.T) {
+	public, private, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	other, _, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sum := strings.Repeat("ab", 32)
+	sums := []byte(strings.Repeat("cd", 32) + "  akamai-1.0.0-linuxamd64\n" + sum + " *akamai-1.0.0-macamd64\n")
+	signature := []byte(base64.StdEncoding.EncodeToString(ed25519.Sign(private, sums)))
+
+	verifyTests := []struct {
+		sums      []byte
+		signature []byte
+		key       ed25519.PublicKey
+		filename  string
+		valid     bool
+	}{
+		{sums, signature, public, "akamai-1.0.0-macamd64", true},
+		{sums, nil, nil, "akamai-1.0.0-macamd64", false},
+		{sums, signature, nil, "akamai-1.0.0-macamd64", false},
+		{sums, nil, public, "akamai-1.0.0-macamd64", false},
+		{sums, signature, other, "akamai-1.0.0-macamd64", false},
+		{append([]byte(sum+"  akamai-1.0.0-windowsamd64.exe\n"), sums...), signature, public, "akamai-1.0.0-windowsamd64.exe", false},
+		{sums, signature, public, "akamai-1.0.0-linux386", false},
+		{[]byte("xyz  akamai-1.0.0-macamd64"), []byte(base64.StdEncoding.EncodeToString(ed25519.Sign(private, []byte("xyz  akamai-1.0.0-macamd64")))), public, "akamai-1.0.0-macamd64", false},
+	}
+
+	for _, tt := range verifyTests {
+		checksum, err := verifyUpgradeChecksum(tt.sums, tt.signature, tt.key, tt.filename)
+		if tt.valid && (err != nil || hex.EncodeToString(checksum) != sum) {
+			t.Errorf("verifyUpgradeChecksum(%s) => %x, %v, wanted %s", tt.filename, checksum, err, sum)
+		}
+		if !tt.valid && err == nil {
+			t.Errorf("verifyUpgradeChecksum(%s) => no error, wanted an error", tt.filename)
+		}
+	}
+}
+
+func TestGetUpgradePublicKey(t *testing.T) {
+	if key, err := getUpgradePublicKey(); err != nil || len(key) != ed25519.PublicKeySize {
+		t.Errorf("getUpgradePublicKey() => %x, %v, wanted the embedded key", key, err)
+	}
+}
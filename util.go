@@ -24,29 +24,37 @@ import (
 	"path/filepath"
 	"strconv"
 	"strings"
+	"sync"
+	"syscall"
+	"time"
 
 	akamai "github.com/akamai/cli-common-golang"
 	"github.com/fatih/color"
+	"github.com/go-ini/ini"
 	"github.com/mitchellh/go-homedir"
 	"github.com/urfave/cli"
 )
 
+// cliHomeEnv overrides the directory .akamai-cli is in
+const cliHomeEnv = "AKAMAI_CLI_HOME"
+
+var (
+	homeRedirectOnce sync.Once
+	homeRedirect     string
+)
+
 func self() string {
 	return filepath.Base(os.Args[0])
 }
 
 func getAkamaiCliPath() (string, error) {
-	cliHome := os.Getenv("AKAMAI_CLI_HOME")
-	if cliHome == "" {
-		var err error
-		cliHome, err = homedir.Dir()
-		if err != nil {
-			return "", cli.NewExitError("Package install directory could not be found. Please set $AKAMAI_CLI_HOME.", -1)
-		}
+	cliHome, err := getAkamaiCliHome()
+	if err != nil {
+		return "", cli.NewExitError("Package install directory could not be found. Please set $AKAMAI_CLI_HOME.", -1)
 	}
 
 	cliPath := filepath.Join(cliHome, ".akamai-cli")
-	err := os.MkdirAll(cliPath, 0755)
+	err = os.MkdirAll(cliPath, 0755)
 	if err != nil {
 		return "", cli.NewExitError("Unable to create Akamai CLI root directory.", -1)
 	}
@@ -54,6 +62,49 @@ func getAkamaiCliPath() (string, error) {
 	return cliPath, nil
 }
 
+// getAkamaiCliHome returns the directory .akamai-cli is in: AKAMAI_CLI_HOME,
+// or cli.home in the config of the user's home directory, or that directory
+func getAkamaiCliHome() (string, error) {
+	if cliHome := os.Getenv(cliHomeEnv); cliHome != "" {
+		return cliHome, nil
+	}
+
+	home, err := homedir.Dir()
+	if err != nil {
+		return "", err
+	}
+
+	homeRedirectOnce.Do(func() {
+		homeRedirect = readHomeRedirect(home)
+	})
+	if homeRedirect != "" {
+		return homeRedirect, nil
+	}
+
+	return home, nil
+}
+
+// readHomeRedirect returns the cli.home set in the config in home, which
+// moves everything else elsewhere, see akamai migrate-home
+func readHomeRedirect(home string) string {
+	configPath := filepath.Join(home, ".akamai-cli", "config")
+	if _, err := os.Stat(configPath); err != nil {
+		return ""
+	}
+
+	iniFile, err := ini.Load(configPath)
+	if err != nil {
+		return ""
+	}
+
+	redirect, err := homedir.Expand(iniFile.Section("cli").Key("home").String())
+	if err != nil || redirect == "" || filepath.Clean(redirect) == filepath.Clean(home) {
+		return ""
+	}
+
+	return redirect
+}
+
 func getAkamaiCliSrcPath() (string, error) {
 	cliHome, _ := getAkamaiCliPath()
 
@@ -80,6 +131,63 @@ func getAkamaiCliCachePath() (string, error) {
 }
 
 func findExec(cmd string) ([]string, error) {
+	return findExecIn(cmd, filepath.SplitList(getPackageBinPaths()))
+}
+
+// findExecIn looks for the executable of cmd in packagePaths, returning it
+// with the interpreter it runs with, if any
+func findExecIn(cmd string, packagePaths []string) ([]string, error) {
+	cmdFile, compiled, err := findCommandFile(cmd, packagePaths)
+	if err != nil {
+		return nil, err
+	}
+
+	if compiled {
+		return []string{cmdFile}, nil
+	}
+
+	packageDir := findPackageDir(filepath.Dir(cmdFile))
+	cmdPackage, err := readPackage(packageDir)
+	if err != nil {
+		return nil, err
+	}
+
+	language := determineCommandLanguage(cmdPackage)
+	bin := ""
+	var executable []string
+	switch {
+	// Compiled Languages
+	case language == "go" || language == "c#" || language == "csharp":
+		err = nil
+		executable = []string{cmdFile}
+	case language == "javascript":
+		bin, err = exec.LookPath("node")
+		if err != nil {
+			bin, err = exec.LookPath("nodejs")
+		}
+		executable = []string{bin, cmdFile}
+	case language == "python":
+		var bins pythonBins
+		bins, err = findPythonBins(cmdPackage.Requirements.Python)
+		bin = bins.python
+
+		executable = []string{bin, cmdFile}
+		// Other languages (php, perl, ruby, etc.)
+	default:
+		bin, err = exec.LookPath(language)
+		executable = []string{bin, cmdFile}
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return executable, nil
+}
+
+// findCommandFile looks for the file of cmd in packagePaths: an executable,
+// in which case compiled is set, or a script that needs an interpreter
+func findCommandFile(cmd string, packagePaths []string) (file string, compiled bool, err error) {
 	// "command" becomes: akamai-command, and akamaiCommand
 	// "command-name" becomes: akamai-command-name, and akamaiCommandName
 	cmdName := "akamai"
@@ -89,113 +197,83 @@ func findExec(cmd string) ([]string, error) {
 		cmdNameTitle += strings.Title(strings.ToLower(cmdPart))
 	}
 
-	systemPath := os.Getenv("PATH")
-	packagePaths := getPackageBinPaths()
-	os.Setenv("PATH", packagePaths)
-
-	// Quick look for executables on the path
-	var path string
-	path, err := exec.LookPath(cmdName)
-	if err != nil {
-		path, err = exec.LookPath(cmdNameTitle)
-	}
-
-	if path != "" {
-		os.Setenv("PATH", systemPath)
-		return []string{path}, nil
+	// Quick look for executables in the package bin directories
+	if path, ok := findExecutable(packagePaths, []string{cmdName, cmdNameTitle}); ok {
+		return path, true, nil
 	}
 
-	os.Setenv("PATH", systemPath)
-	if packagePaths == "" {
-		return nil, errors.New("No executables found.")
-	}
-
-	for _, path := range filepath.SplitList(packagePaths) {
+	for _, path := range packagePaths {
 		filePaths := []string{
 			// Search for <path>/akamai-command, <path>/akamaiCommand
 			filepath.Join(path, cmdName),
 			filepath.Join(path, cmdNameTitle),
 
 			// Search for <path>/akamai-command.*, <path>/akamaiCommand.*
-			// This should catch .exe, .bat, .com, .cmd, and .jar
+			// This should catch scripts, such as .js, .py, and .jar
 			filepath.Join(path, cmdName+".*"),
 			filepath.Join(path, cmdNameTitle+".*"),
 		}
 
-		var files []string
 		for _, filePath := range filePaths {
-			files, _ = filepath.Glob(filePath)
-			if len(files) > 0 {
-				break
-			}
-		}
-
-		if len(files) == 0 {
-			continue
-		}
-
-		cmdFile := files[0]
-
-		packageDir := findPackageDir(filepath.Dir(cmdFile))
-		cmdPackage, err := readPackage(packageDir)
-		if err != nil {
-			return nil, err
-		}
-
-		language := determineCommandLanguage(cmdPackage)
-		bin := ""
-		var cmd []string
-		switch {
-		// Compiled Languages
-		case language == "go" || language == "c#" || language == "csharp":
-			err = nil
-			cmd = []string{cmdFile}
-		case language == "javascript":
-			bin, err = exec.LookPath("node")
-			if err != nil {
-				bin, err = exec.LookPath("nodejs")
+			if files, _ := filepath.Glob(filePath); len(files) > 0 {
+				return files[0], false, nil
 			}
-			cmd = []string{bin, cmdFile}
-		case language == "python":
-			var bins pythonBins
-			bins, err = findPythonBins(cmdPackage.Requirements.Python)
-			bin = bins.python
-
-			cmd = []string{bin, cmdFile}
-			// Other languages (php, perl, ruby, etc.)
-		default:
-			bin, err = exec.LookPath(language)
-			cmd = []string{bin, cmdFile}
 		}
-
-		if err != nil {
-			return nil, err
-		}
-
-		return cmd, nil
 	}
 
-	return nil, errors.New("No executables found.")
+	return "", false, errors.New("No executables found.")
 }
 
 func passthruCommand(executable []string) error {
-	subCmd := exec.Command(executable[0], executable[1:]...)
+	return passthruCommandTimeout(executable, 0)
+}
+
+// passthruCommandTimeout runs executable like passthruCommand, stopping it
+// once it has run for timeout, if not 0
+func passthruCommandTimeout(executable []string, timeout time.Duration) error {
+	subCmd := newLaunchCommand(executable)
 	subCmd.Stdin = os.Stdin
 	subCmd.Stderr = os.Stderr
 	subCmd.Stdout = os.Stdout
-	err := subCmd.Run()
+	err := runCommandSupervised(subCmd, timeout)
+	if _, ok := err.(errExecTimeout); ok {
+		return newError(errTimeout, "Command timed out after %s and was stopped. Change the limit with \"%s config set cli.%s <duration>\".", timeout, self(), execTimeoutKey)
+	}
 	if err != nil {
-		return cli.NewExitError("", 1)
+		return cli.NewExitError("", getProcessExitCode(err))
 	}
 	return nil
 }
 
+// getProcessExitCode returns the exit code of a command that failed to run,
+// 128 plus the signal for one killed by a signal, like shells do, or 1 if it
+// did not start or the code is unknown
+func getProcessExitCode(err error) int {
+	if exitErr, ok := err.(*exec.ExitError); ok {
+		if status, ok := exitErr.Sys().(syscall.WaitStatus); ok {
+			switch {
+			case status.ExitStatus() > 0:
+				return status.ExitStatus()
+			case status.Signaled() && status.Signal() > 0:
+				return 128 + int(status.Signal())
+			}
+		}
+	}
+
+	return 1
+}
+
 func githubize(repo string) string {
+	// A repository given with its host, e.g. github.com/org/tools
+	if host := strings.SplitN(repo, "/", 2)[0]; strings.Contains(repo, "/") && strings.Contains(host, ".") && !strings.Contains(host, ":") {
+		return "https://" + strings.TrimSuffix(repo, ".git") + ".git"
+	}
+
 	if strings.HasPrefix(repo, "http") || strings.HasPrefix(repo, "ssh") || strings.HasSuffix(repo, ".git") {
 		return strings.TrimPrefix(repo, "ssh://")
 	}
 
-	if strings.HasPrefix(repo, "file://") {
+	if strings.HasPrefix(repo, "file://") || strings.HasPrefix(repo, "git://") || scpLikeURL.MatchString(repo) {
 		return repo
 	}
 
@@ -211,6 +289,77 @@ func githubize(repo string) string {
 	return "https://github.com/" + repo + ".git"
 }
 
+// parseInstallTarget splits an install argument of the form <repo>#<subpath>
+// or <repo>//<subpath>, used to install a package that lives in a
+// subdirectory of a repository. The subpath is relative to the root of the
+// repository, and must not lead out of it.
+func parseInstallTarget(target string) (string, string, error) {
+	repo, subpath := target, ""
+	if parts := strings.SplitN(target, "#", 2); len(parts) == 2 {
+		repo, subpath = parts[0], parts[1]
+	} else {
+		// The // of a URL scheme does not start a subpath
+		start := 0
+		if pos := strings.Index(target, "://"); pos != -1 {
+			start = pos + 3
+		}
+
+		if pos := strings.Index(target[start:], "//"); pos != -1 {
+			repo, subpath = target[:start+pos], target[start+pos+2:]
+		}
+	}
+
+	cleaned := filepath.Clean(subpath)
+	if filepath.VolumeName(cleaned) != "" {
+		return repo, "", newError(errUsage, "Invalid subpath \"%s\", it must be relative to the repository", subpath)
+	}
+
+	cleaned = strings.Trim(cleaned, string(os.PathSeparator)+"/")
+	if slashed := filepath.ToSlash(cleaned); slashed == ".." || strings.HasPrefix(slashed, "../") {
+		return repo, "", newError(errUsage, "Invalid subpath \"%s\", it must be inside the repository", subpath)
+	}
+
+	if cleaned == "." {
+		cleaned = ""
+	}
+
+	return repo, cleaned, nil
+}
+
+// getPackageDirName returns the directory a package is installed in. Each
+// package installed from a monorepo gets its own checkout, named after the
+// repository and the subpath.
+func getPackageDirName(repo string, subpath string) string {
+	dirName := strings.TrimSuffix(filepath.Base(repo), ".git")
+	if subpath != "" {
+		dirName += "-" + strings.Replace(filepath.ToSlash(subpath), "/", "-", -1)
+	}
+
+	return dirName
+}
+
+// isPathInside returns whether path is below dir, and not dir itself
+func isPathInside(dir string, path string) bool {
+	rel, err := filepath.Rel(dir, path)
+	return err == nil && rel != "." && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)) && !filepath.IsAbs(rel)
+}
+
+// parseInstallVersion splits a version pin off an install argument of the
+// form <target>@<version>. The user in git@github.com:... is not a version.
+func parseInstallVersion(target string) (string, string) {
+	pos := strings.LastIndex(target, "@")
+	if pos == -1 {
+		return target, ""
+	}
+
+	version := target[pos+1:]
+	if version == "" || strings.ContainsAny(version, "/:") {
+		return target, ""
+	}
+
+	return target[:pos], version
+}
+
 func versionCompare(left string, right string) int {
 	leftParts := strings.Split(left, ".")
 	leftMajor, _ := strconv.Atoi(leftParts[0])
@@ -255,6 +404,22 @@ func versionCompare(left string, right string) int {
 	return 1
 }
 
+// formatBytes renders a byte count in human readable form, e.g. 1.5 MB
+func formatBytes(bytes uint64) string {
+	const unit = 1024
+	if bytes < unit {
+		return fmt.Sprintf("%d B", bytes)
+	}
+
+	div, exp := uint64(unit), 0
+	for n := bytes / unit; n >= unit; n /= unit {
+		div *= unit
+		exp++
+	}
+
+	return fmt.Sprintf("%.1f %cB", float64(bytes)/float64(div), "KMGTPE"[exp])
+}
+
 func showBanner() {
 	fmt.Fprintln(akamai.App.ErrWriter)
 	bg := color.New(color.BgMagenta)
@@ -266,3 +431,35 @@ func showBanner() {
 	fmt.Fprintf(akamai.App.ErrWriter, bg.Sprintf(strings.Repeat(" ", 60)+"\n"))
 	fmt.Fprintln(akamai.App.ErrWriter)
 }
+
+// levenshtein returns the number of single character edits needed to turn a into b
+func levenshtein(a string, b string) int {
+	ra, rb := []rune(a), []rune(b)
+
+	prev := make([]int, len(rb)+1)
+	curr := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+
+	for i := 1; i <= len(ra); i++ {
+		curr[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+
+			curr[j] = prev[j] + 1
+			if curr[j-1]+1 < curr[j] {
+				curr[j] = curr[j-1] + 1
+			}
+			if prev[j-1]+cost < curr[j] {
+				curr[j] = prev[j-1] + cost
+			}
+		}
+		prev, curr = curr, prev
+	}
+
+	return prev[len(rb)]
+}
@@ -55,6 +55,10 @@ func TestGithubize(t *testing.T) {
 		{"file:///local/repo/path", "file:///local/repo/path"},
 		{"file:///local/repo/path.git", "file:///local/repo/path.git"},
 		{"ssh://example.org:/repo/path", "example.org:/repo/path"},
+		{"git@github.example.com:team/cli-foo.git", "git@github.example.com:team/cli-foo.git"},
+		{"git@github.example.com:team/cli-foo", "git@github.example.com:team/cli-foo"},
+		{"github.com/example/tools", "https://github.com/example/tools.git"},
+		{"git.example.com/team/tools.git", "https://git.example.com/team/tools.git"},
 	}
 
 	for _, tt := range githubizeTests {
@@ -63,3 +67,123 @@ func TestGithubize(t *testing.T) {
 		}
 	}
 }
+
+func TestParseInstallTarget(t *testing.T) {
+	installTargetTests := []struct {
+		target  string
+		repo    string
+		subpath string
+	}{
+		{"property", "property", ""},
+		{"https://github.com/example/tools.git", "https://github.com/example/tools.git", ""},
+		{"https://github.com/example/tools.git#packages/foo", "https://github.com/example/tools.git", "packages/foo"},
+		{"example/tools#/packages/foo/", "example/tools", "packages/foo"},
+		{"github.com/example/tools//packages/foo", "github.com/example/tools", "packages/foo"},
+		{"https://github.com/example/tools.git//packages/foo", "https://github.com/example/tools.git", "packages/foo"},
+		{"file:///srv/git/tools//packages/foo", "file:///srv/git/tools", "packages/foo"},
+		{"git@github.com:example/tools.git//packages/foo", "git@github.com:example/tools.git", "packages/foo"},
+		{"file:///srv/git/tools", "file:///srv/git/tools", ""},
+		{"example/tools#packages/../foo", "example/tools", "foo"},
+		{"example/tools#/../foo", "example/tools", "foo"},
+	}
+
+	for _, tt := range installTargetTests {
+		if repo, subpath, err := parseInstallTarget(tt.target); err != nil || repo != tt.repo || subpath != tt.subpath {
+			t.Errorf("parseInstallTarget(%s) => (%s, %s, %v), wanted: (%s, %s)", tt.target, repo, subpath, err, tt.repo, tt.subpath)
+		}
+	}
+
+	// Subpaths that lead out of the clone are refused
+	for _, target := range []string{
+		"example/tools#..",
+		"example/tools#../../etc",
+		"example/tools#packages/../../etc",
+		"example/tools//../x",
+		"github.com/example/tools//packages/../..",
+	} {
+		if _, subpath, err := parseInstallTarget(target); err == nil || getErrorID(err) != errUsage {
+			t.Errorf("parseInstallTarget(%s) => %s, %v, wanted: %s error", target, subpath, err, errUsage)
+		}
+	}
+}
+
+func TestGetPackageDirName(t *testing.T) {
+	dirNameTests := []struct {
+		repo    string
+		subpath string
+		dirName string
+	}{
+		{"https://github.com/akamai/cli-property.git", "", "cli-property"},
+		{"https://github.com/example/tools.git", "packages/foo", "tools-packages-foo"},
+		{"file:///srv/git/tools", "foo", "tools-foo"},
+	}
+
+	for _, tt := range dirNameTests {
+		if dirName := getPackageDirName(tt.repo, tt.subpath); dirName != tt.dirName {
+			t.Errorf("getPackageDirName(%s, %s) => %s, wanted: %s", tt.repo, tt.subpath, dirName, tt.dirName)
+		}
+	}
+}
+
+func TestFormatBytes(t *testing.T) {
+	formatTests := []struct {
+		bytes  uint64
+		result string
+	}{
+		{0, "0 B"},
+		{1023, "1023 B"},
+		{1024, "1.0 KB"},
+		{1536, "1.5 KB"},
+		{100 * 1024 * 1024, "100.0 MB"},
+		{5 * 1024 * 1024 * 1024, "5.0 GB"},
+	}
+
+	for _, tt := range formatTests {
+		if result := formatBytes(tt.bytes); result != tt.result {
+			t.Errorf("formatBytes(%d) => %s, wanted: %s", tt.bytes, result, tt.result)
+		}
+	}
+}
+
+func TestLevenshtein(t *testing.T) {
+	distanceTests := []struct {
+		a        string
+		b        string
+		distance int
+	}{
+		{"", "", 0},
+		{"property", "property", 0},
+		{"proprety", "property", 2},
+		{"purg", "purge", 1},
+		{"", "dns", 3},
+		{"kitten", "sitting", 3},
+	}
+
+	for _, tt := range distanceTests {
+		if distance := levenshtein(tt.a, tt.b); distance != tt.distance {
+			t.Errorf("levenshtein(%s, %s) => %d, wanted: %d", tt.a, tt.b, distance, tt.distance)
+		}
+	}
+}
+
+func TestParseInstallVersion(t *testing.T) {
+	versionTests := []struct {
+		target  string
+		rest    string
+		version string
+	}{
+		{"property", "property", ""},
+		{"property@1.2.0", "property", "1.2.0"},
+		{"akamai/cli-property@v1.2.0", "akamai/cli-property", "v1.2.0"},
+		{"git@github.com:akamai/cli-property.git", "git@github.com:akamai/cli-property.git", ""},
+		{"git@github.com:akamai/cli-property.git@1.2.0", "git@github.com:akamai/cli-property.git", "1.2.0"},
+		{"example/tools#packages/foo@2.0.0", "example/tools#packages/foo", "2.0.0"},
+		{"property@", "property@", ""},
+	}
+
+	for _, tt := range versionTests {
+		if rest, version := parseInstallVersion(tt.target); rest != tt.rest || version != tt.version {
+			t.Errorf("parseInstallVersion(%s) => (%s, %s), wanted: (%s, %s)", tt.target, rest, version, tt.rest, tt.version)
+		}
+	}
+}
@@ -0,0 +1,276 @@
+/*
+ Copyright 2018. Akamai Technologies, Inc
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+
+	akamai "github.com/akamai/cli-common-golang"
+	"github.com/fatih/color"
+)
+
+// A workspace is a project directory with an .akamai-cli.yaml, which lists the
+// packages the project needs:
+//
+//   packages:
+//     - property@1.2.0
+//     - purge
+//
+// "akamai workspace sync" installs them in the .akamai-cli-workspace directory
+// of the project, and while working in the project their commands are run
+// instead of those of the same packages installed globally. Only workspaces
+// synced by the user are used, so that a project someone else checked in an
+// .akamai-cli-workspace to cannot take over the user's commands.
+
+const (
+	workspaceFile = ".akamai-cli.yaml"
+	// workspaceDir holds the src and manifests of the workspace packages, like the CLI home does
+	workspaceDir = ".akamai-cli-workspace"
+	// trustedWorkspacesKey is the [cli] setting listing, separated like PATH,
+	// the workspaces "akamai workspace sync" was run in
+	trustedWorkspacesKey = "trusted-workspaces"
+)
+
+var (
+	workspaceOnce    sync.Once
+	workspaceRoot    string
+	workspaceTrusted bool
+	untrustedWarning sync.Once
+)
+
+// workspaceConfig is the contents of .akamai-cli.yaml
+type workspaceConfig struct {
+	// Packages are install targets, with an optional @<version> pin
+	Packages []string
+}
+
+// findWorkspace returns the project directory the current directory is in, if
+// it has a workspace file
+func findWorkspace() string {
+	workspaceOnce.Do(func() {
+		dir, err := os.Getwd()
+		if err != nil {
+			return
+		}
+		workspaceRoot = findWorkspaceFrom(dir)
+		workspaceTrusted = workspaceRoot != "" && isTrustedWorkspace(workspaceRoot, getTrustedWorkspaces())
+	})
+
+	return workspaceRoot
+}
+
+// findWorkspaceFrom returns the nearest of dir and its parents with a
+// workspace file, or ""
+func findWorkspaceFrom(dir string) string {
+	for {
+		if info, err := os.Stat(filepath.Join(dir, workspaceFile)); err == nil && !info.IsDir() {
+			return dir
+		}
+
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return ""
+		}
+		dir = parent
+	}
+}
+
+// getWorkspaceSrcPath returns where the packages of the current workspace are
+// installed, and whether there is a trusted workspace. The packages of an
+// untrusted one are ignored, with a warning.
+func getWorkspaceSrcPath() (string, bool) {
+	root := findWorkspace()
+	if root == "" {
+		return "", false
+	}
+
+	srcPath := filepath.Join(root, workspaceDir, "src")
+	if !workspaceTrusted {
+		if _, err := os.Stat(srcPath); err == nil {
+			untrustedWarning.Do(func() {
+				fmt.Fprintln(akamai.App.ErrWriter, color.YellowString(
+					"Warning: ignoring the packages of the workspace at %s, which you have not synced. Run \"%s workspace sync\" there to use them",
+					root, self(),
+				))
+			})
+		}
+		return "", false
+	}
+
+	return srcPath, true
+}
+
+func getTrustedWorkspaces() []string {
+	var trusted []string
+	for _, root := range filepath.SplitList(getConfigValue("cli", trustedWorkspacesKey)) {
+		if root = strings.TrimSpace(root); root != "" {
+			trusted = append(trusted, filepath.Clean(root))
+		}
+	}
+
+	return trusted
+}
+
+// isTrustedWorkspace returns whether root is one of the trusted workspaces
+func isTrustedWorkspace(root string, trusted []string) bool {
+	root = filepath.Clean(root)
+	for _, dir := range trusted {
+		if dir == root {
+			return true
+		}
+	}
+
+	return false
+}
+
+// trustWorkspace records root as a workspace whose packages are used
+func trustWorkspace(root string) error {
+	trusted := getTrustedWorkspaces()
+	if !isTrustedWorkspace(root, trusted) {
+		trusted = append(trusted, filepath.Clean(root))
+		setConfigValue("cli", trustedWorkspacesKey, strings.Join(trusted, string(filepath.ListSeparator)))
+		if err := saveConfig(); err != nil {
+			return err
+		}
+		logInfo("trusted workspace", "path", root)
+	}
+
+	if root == findWorkspace() {
+		workspaceTrusted = true
+	}
+
+	return nil
+}
+
+// getWorkspacePackageDirs returns the directories of the packages installed
+// in the current workspace
+func getWorkspacePackageDirs() []string {
+	srcPath, ok := getWorkspaceSrcPath()
+	if !ok {
+		return nil
+	}
+
+	return getPackageDirsIn(srcPath)
+}
+
+// isWorkspacePackage returns whether name is installed in the current workspace
+func isWorkspacePackage(name string) bool {
+	srcPath, ok := getWorkspaceSrcPath()
+	if !ok || name == "" {
+		return false
+	}
+
+	_, err := os.Stat(filepath.Join(srcPath, name))
+	return err == nil
+}
+
+func readWorkspaceConfig(path string) (workspaceConfig, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return workspaceConfig{}, err
+	}
+
+	return parseWorkspaceConfig(data)
+}
+
+// parseWorkspaceConfig reads the small subset of YAML a workspace file needs:
+// comments, and a packages key with a list of strings
+func parseWorkspaceConfig(data []byte) (workspaceConfig, error) {
+	parsed := workspaceConfig{}
+	inPackages := false
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for n := 1; scanner.Scan(); n++ {
+		line := stripYAMLComment(scanner.Text())
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" || trimmed == "---" {
+			continue
+		}
+
+		if strings.HasPrefix(trimmed, "- ") || trimmed == "-" {
+			if !inPackages || line == trimmed {
+				return parsed, fmt.Errorf("line %d: unexpected list item", n)
+			}
+
+			value, err := unquoteYAML(strings.TrimSpace(strings.TrimPrefix(trimmed, "-")))
+			if err != nil || value == "" {
+				return parsed, fmt.Errorf("line %d: invalid package", n)
+			}
+			parsed.Packages = append(parsed.Packages, value)
+			continue
+		}
+
+		if line != trimmed {
+			return parsed, fmt.Errorf("line %d: unexpected indentation", n)
+		}
+
+		parts := strings.SplitN(trimmed, ":", 2)
+		if len(parts) != 2 {
+			return parsed, fmt.Errorf("line %d: expected a key", n)
+		}
+
+		switch key, value := strings.TrimSpace(parts[0]), strings.TrimSpace(parts[1]); {
+		case key != "packages":
+			return parsed, fmt.Errorf("line %d: unknown key \"%s\"", n, key)
+		case value == "" || value == "[]":
+			inPackages = value == ""
+		default:
+			return parsed, fmt.Errorf("line %d: packages must be a list", n)
+		}
+	}
+
+	return parsed, scanner.Err()
+}
+
+// stripYAMLComment removes a comment from a line, a # starts one at the start
+// of the line or after a space, so the #<subpath> of install targets is kept
+func stripYAMLComment(line string) string {
+	inQuotes := rune(0)
+	for i, c := range line {
+		switch {
+		case inQuotes != 0:
+			if c == inQuotes {
+				inQuotes = 0
+			}
+		case c == '"' || c == '\'':
+			inQuotes = c
+		case c == '#' && (i == 0 || line[i-1] == ' ' || line[i-1] == '\t'):
+			return strings.TrimRight(line[:i], " \t")
+		}
+	}
+
+	return line
+}
+
+func unquoteYAML(value string) (string, error) {
+	switch {
+	case len(value) >= 2 && value[0] == '"' && value[len(value)-1] == '"':
+		return strconv.Unquote(value)
+	case len(value) >= 2 && value[0] == '\'' && value[len(value)-1] == '\'':
+		return strings.Replace(value[1:len(value)-1], "''", "'", -1), nil
+	}
+
+	return value, nil
+}
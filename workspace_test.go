@@ -0,0 +1,90 @@
+/*
+ Copyright 2018. Akamai Technologies, Inc
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseWorkspaceConfig(t *testing.T) {
+	configTests := []struct {
+		data     string
+		packages []string
+		ok       bool
+	}{
+		{"packages:\n  - property@1.2.0\n  - purge\n", []string{"property@1.2.0", "purge"}, true},
+		{"# tools\n---\npackages:\n- property # pinned later\n", []string{"property"}, true},
+		{"packages:\n  - https://github.com/example/cli-tools.git#packages/foo\n", []string{"https://github.com/example/cli-tools.git#packages/foo"}, true},
+		{"packages:\n  - \"property@1.2.0\"\n  - 'purge'\n", []string{"property@1.2.0", "purge"}, true},
+		{"packages: []\n", nil, true},
+		{"", nil, true},
+		{"- property\n", nil, false},
+		{"packages: property\n", nil, false},
+		{"tools:\n  - property\n", nil, false},
+		{"packages:\n  -\n", nil, false},
+	}
+
+	for _, tt := range configTests {
+		config, err := parseWorkspaceConfig([]byte(tt.data))
+		if (err == nil) != tt.ok || (tt.ok && !reflect.DeepEqual(config.Packages, tt.packages)) {
+			t.Errorf("parseWorkspaceConfig(%q) => %v, %v, wanted: %v", tt.data, config.Packages, err, tt.packages)
+		}
+	}
+}
+
+func TestParseWorkspacePackage(t *testing.T) {
+	packageTests := []struct {
+		target  string
+		name    string
+		version string
+	}{
+		{"property@1.2.0", "cli-property", "1.2.0"},
+		{"purge", "cli-purge", ""},
+		{"https://github.com/example/cli-tools.git#packages/foo", "cli-tools-packages-foo", ""},
+		{"git@github.com:example/cli-foo.git@v2", "cli-foo", "v2"},
+	}
+
+	for _, tt := range packageTests {
+		pkg, err := parseWorkspacePackage("/project", tt.target)
+		if err != nil || pkg.name != tt.name || pkg.version != tt.version {
+			t.Errorf("parseWorkspacePackage(%s) => %s, %s, %v, wanted: %s, %s", tt.target, pkg.name, pkg.version, err, tt.name, tt.version)
+		}
+	}
+}
+
+func TestIsTrustedWorkspace(t *testing.T) {
+	trusted := []string{"/home/user/project", "/srv/tools"}
+
+	trustTests := []struct {
+		root    string
+		trusted bool
+	}{
+		{"/home/user/project", true},
+		{"/home/user/project/", true},
+		{"/srv/tools/../tools", true},
+		{"/home/user", false},
+		{"/home/user/project/sub", false},
+		{"/tmp/checkout", false},
+	}
+
+	for _, tt := range trustTests {
+		if ok := isTrustedWorkspace(tt.root, trusted); ok != tt.trusted {
+			t.Errorf("isTrustedWorkspace(%s) => %t, wanted: %t", tt.root, ok, tt.trusted)
+		}
+	}
+}